@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -137,6 +138,8 @@ type Client interface {
 
 	// KeyspaceClient manages keyspace metadata.
 	KeyspaceClient
+	// GCClient manages the GC safe points of keyspaces.
+	GCClient
 	// Close closes the client.
 	Close()
 }
@@ -393,6 +396,11 @@ type client struct {
 	// For internal usage.
 	checkTSDeadlineCh    chan struct{}
 	leaderNetworkFailure int32
+
+	// httpClient is used to access the HTTP API of PD, e.g. the keyspace management API.
+	httpClientOnce sync.Once
+	httpClient     *http.Client
+	httpClientErr  error
 }
 
 // NewClient creates a PD client.
@@ -0,0 +1,182 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/gcpb"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tikv/pd/client/grpcutil"
+	"google.golang.org/grpc"
+)
+
+// GCClient manages the GC safe points of keyspaces.
+// The TTL semantics of the keyspace-level service safe points are the same as the cluster-wide ones.
+type GCClient interface {
+	// LoadKeyspaceGCSafePoint loads the GC safe point of the given keyspace.
+	// It returns 0 if the GC safe point of the keyspace has never been set,
+	// and an error if the keyspace does not exist.
+	LoadKeyspaceGCSafePoint(ctx context.Context, keyspaceID uint32) (uint64, error)
+	// GetKeyspaceMinServiceGCSafePoint returns the minimum service safepoint of the given keyspace.
+	// If no service blocks the GC of the keyspace, the current TSO is returned.
+	GetKeyspaceMinServiceGCSafePoint(ctx context.Context, keyspaceID uint32) (uint64, error)
+	// UpdateKeyspaceGCSafePoint updates the GC safe point of the given keyspace and returns the
+	// new GC safe point. The update fails if the given safe point is not greater than the current
+	// one or greater than the minimum service safepoint of the keyspace, and the current GC safe
+	// point is returned instead.
+	UpdateKeyspaceGCSafePoint(ctx context.Context, keyspaceID uint32, safePoint uint64) (uint64, error)
+	// UpdateKeyspaceServiceGCSafePoint updates the safepoint for specific service in the given
+	// keyspace and returns the GC safe point of the keyspace. The update fails if the given safe
+	// point is less than the returned GC safe point. Pass a ttl <= 0 to remove the safepoint.
+	UpdateKeyspaceServiceGCSafePoint(ctx context.Context, keyspaceID uint32, serviceID string, ttl int64, safePoint uint64) (uint64, error)
+}
+
+// gcClient returns the GCClient from current PD leader.
+func (c *client) gcClient() gcpb.GCClient {
+	if cc, ok := c.clientConns.Load(c.GetLeaderAddr()); ok {
+		return gcpb.NewGCClient(cc.(*grpc.ClientConn))
+	}
+	return nil
+}
+
+func (c *client) gcRequestHeader() *gcpb.RequestHeader {
+	return &gcpb.RequestHeader{
+		ClusterId: c.clusterID,
+	}
+}
+
+func (c *client) gcRespForErr(observer prometheus.Observer, start time.Time, err error, header *gcpb.ResponseHeader) error {
+	if err != nil || header.GetError() != nil {
+		observer.Observe(time.Since(start).Seconds())
+		if err != nil {
+			c.ScheduleCheckLeader()
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(errors.New(header.GetError().String()))
+	}
+	return nil
+}
+
+// encodeKeyspaceID encodes the keyspace id into the space id used by the GC service.
+func encodeKeyspaceID(keyspaceID uint32) []byte {
+	return []byte(strconv.FormatUint(uint64(keyspaceID), 10))
+}
+
+// LoadKeyspaceGCSafePoint loads the GC safe point of the given keyspace.
+// There is no dedicated RPC to get the GC safe point of a single keyspace, so it sends
+// an update with a zero safe point, which never succeeds and returns the current one.
+func (c *client) LoadKeyspaceGCSafePoint(ctx context.Context, keyspaceID uint32) (uint64, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("gcClient.LoadKeyspaceGCSafePoint", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDurationLoadKeyspaceGCSafePoint.Observe(time.Since(start).Seconds()) }()
+
+	ctx, cancel := context.WithTimeout(ctx, c.option.timeout)
+	req := &gcpb.UpdateGCSafePointRequest{
+		Header:  c.gcRequestHeader(),
+		SpaceId: encodeKeyspaceID(keyspaceID),
+	}
+	ctx = grpcutil.BuildForwardContext(ctx, c.GetLeaderAddr())
+	resp, err := c.gcClient().UpdateGCSafePoint(ctx, req)
+	cancel()
+
+	if err = c.gcRespForErr(cmdFailedDurationLoadKeyspaceGCSafePoint, start, err, resp.GetHeader()); err != nil {
+		return 0, err
+	}
+	return resp.GetNewSafePoint(), nil
+}
+
+// GetKeyspaceMinServiceGCSafePoint returns the minimum service safepoint of the given keyspace.
+func (c *client) GetKeyspaceMinServiceGCSafePoint(ctx context.Context, keyspaceID uint32) (uint64, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("gcClient.GetKeyspaceMinServiceGCSafePoint", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDurationGetKeyspaceMinServiceGCSafePoint.Observe(time.Since(start).Seconds()) }()
+
+	ctx, cancel := context.WithTimeout(ctx, c.option.timeout)
+	req := &gcpb.GetMinServiceSafePointRequest{
+		Header:  c.gcRequestHeader(),
+		SpaceId: encodeKeyspaceID(keyspaceID),
+	}
+	ctx = grpcutil.BuildForwardContext(ctx, c.GetLeaderAddr())
+	resp, err := c.gcClient().GetMinServiceSafePoint(ctx, req)
+	cancel()
+
+	if err = c.gcRespForErr(cmdFailedDurationGetKeyspaceMinServiceGCSafePoint, start, err, resp.GetHeader()); err != nil {
+		return 0, err
+	}
+	return resp.GetSafePoint(), nil
+}
+
+// UpdateKeyspaceGCSafePoint updates the GC safe point of the given keyspace.
+func (c *client) UpdateKeyspaceGCSafePoint(ctx context.Context, keyspaceID uint32, safePoint uint64) (uint64, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("gcClient.UpdateKeyspaceGCSafePoint", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDurationUpdateKeyspaceGCSafePoint.Observe(time.Since(start).Seconds()) }()
+
+	ctx, cancel := context.WithTimeout(ctx, c.option.timeout)
+	req := &gcpb.UpdateGCSafePointRequest{
+		Header:    c.gcRequestHeader(),
+		SpaceId:   encodeKeyspaceID(keyspaceID),
+		SafePoint: safePoint,
+	}
+	ctx = grpcutil.BuildForwardContext(ctx, c.GetLeaderAddr())
+	resp, err := c.gcClient().UpdateGCSafePoint(ctx, req)
+	cancel()
+
+	if err = c.gcRespForErr(cmdFailedDurationUpdateKeyspaceGCSafePoint, start, err, resp.GetHeader()); err != nil {
+		return 0, err
+	}
+	return resp.GetNewSafePoint(), nil
+}
+
+// UpdateKeyspaceServiceGCSafePoint updates the safepoint for specific service in the given keyspace.
+func (c *client) UpdateKeyspaceServiceGCSafePoint(ctx context.Context, keyspaceID uint32, serviceID string, ttl int64, safePoint uint64) (uint64, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("gcClient.UpdateKeyspaceServiceGCSafePoint", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDurationUpdateKeyspaceServiceGCSafePoint.Observe(time.Since(start).Seconds()) }()
+
+	ctx, cancel := context.WithTimeout(ctx, c.option.timeout)
+	req := &gcpb.UpdateServiceSafePointRequest{
+		Header:    c.gcRequestHeader(),
+		SpaceId:   encodeKeyspaceID(keyspaceID),
+		ServiceId: []byte(serviceID),
+		TTL:       ttl,
+		SafePoint: safePoint,
+	}
+	ctx = grpcutil.BuildForwardContext(ctx, c.GetLeaderAddr())
+	resp, err := c.gcClient().UpdateServiceSafePoint(ctx, req)
+	cancel()
+
+	if err = c.gcRespForErr(cmdFailedDurationUpdateKeyspaceServiceGCSafePoint, start, err, resp.GetHeader()); err != nil {
+		return 0, err
+	}
+	return resp.GetGcSafePoint(), nil
+}
@@ -15,24 +15,63 @@
 package pd
 
 import (
+	"bytes"
 	"context"
-	"go.uber.org/zap"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/kvproto/pkg/keyspacepb"
 	"github.com/pingcap/log"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tikv/pd/client/grpcutil"
+	"github.com/tikv/pd/client/tlsutil"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
+// keyspaceAPIPrefix is the prefix of the keyspace management HTTP API of PD.
+const keyspaceAPIPrefix = "/pd/api/v2/keyspaces"
+
 // KeyspaceClient manages keyspace metadata.
 type KeyspaceClient interface {
 	// LoadKeyspace load and return target keyspace's metadata.
 	LoadKeyspace(ctx context.Context, name string) (*keyspacepb.KeyspaceMeta, error)
 	// WatchKeyspaces watches keyspace meta changes.
 	WatchKeyspaces(ctx context.Context) (chan []*keyspacepb.KeyspaceMeta, error)
+	// WatchKeyspacesFromRevision watches keyspace meta changes after the given revision,
+	// 0 means starting with all current keyspaces. The watch reconnects to the PD leader
+	// automatically and resumes from where it left off until ctx is canceled.
+	WatchKeyspacesFromRevision(ctx context.Context, revision int64) (chan *KeyspaceWatchResponse, error)
+	// CreateKeyspace creates a keyspace with the given name and config.
+	CreateKeyspace(ctx context.Context, name string, config map[string]string) (*keyspacepb.KeyspaceMeta, error)
+	// UpdateKeyspaceConfig updates the config of the target keyspace with JSON merge patch
+	// semantics: a nil value removes the config entry, otherwise the entry is put.
+	UpdateKeyspaceConfig(ctx context.Context, name string, patch map[string]*string) (*keyspacepb.KeyspaceMeta, error)
+	// UpdateKeyspaceState updates the state of the target keyspace.
+	UpdateKeyspaceState(ctx context.Context, name string, state keyspacepb.KeyspaceState) (*keyspacepb.KeyspaceMeta, error)
+	// LoadKeyspaces loads at most limit keyspaces starting from the given page token, along with
+	// the token of the next page. An empty page token starts from the first keyspace, and an empty
+	// next page token means there are no more keyspaces. A limit of 0 loads all the keyspaces.
+	LoadKeyspaces(ctx context.Context, pageToken string, limit int) ([]*keyspacepb.KeyspaceMeta, string, error)
+}
+
+// KeyspaceWatchResponse is the keyspace meta changes received by WatchKeyspacesFromRevision.
+type KeyspaceWatchResponse struct {
+	Keyspaces []*keyspacepb.KeyspaceMeta
+	// Revision is the revision to resume the watch from with WatchKeyspacesFromRevision
+	// without missing any changes after this response. It advances with the responses,
+	// and is reset to the revision of the reload if the watched revision has been compacted.
+	Revision int64
 }
 
 // keyspaceClient returns the KeyspaceClient from current PD leader.
@@ -105,9 +144,384 @@ func (c *client) WatchKeyspaces(ctx context.Context) (chan []*keyspacepb.Keyspac
 				if err != nil {
 					return
 				}
-				keyspaceWatcherChan <- resp.Keyspaces
+				select {
+				case keyspaceWatcherChan <- resp.Keyspaces:
+				case <-ctx.Done():
+					close(keyspaceWatcherChan)
+					return
+				}
 			}
 		}
 	}()
 	return keyspaceWatcherChan, err
 }
+
+// WatchKeyspacesFromRevision watches keyspace meta changes after the given revision.
+// The first response contains all current keyspaces if the revision is 0 or has been compacted.
+// PD ends a resumable watch stream right after each response, along with the revision after the
+// response in the trailer, and the client resumes from it with a new stream at once. Once the stream
+// breaks, it reconnects to the PD leader and resumes from the revision of the last response. The
+// changes replayed after resuming are deduplicated, so only the keyspaces which actually changed are
+// sent. The returned channel is closed once ctx is canceled.
+func (c *client) WatchKeyspacesFromRevision(ctx context.Context, revision int64) (chan *KeyspaceWatchResponse, error) {
+	stream, cancel, revision, resumable, err := c.createKeyspaceWatchStream(ctx, revision)
+	if err != nil {
+		return nil, err
+	}
+	watchChan := make(chan *KeyspaceWatchResponse)
+	go c.keyspaceWatchLoop(ctx, stream, cancel, revision, resumable, watchChan)
+	return watchChan, nil
+}
+
+// createKeyspaceWatchStream creates a keyspace watch stream resuming from the given revision, and
+// returns the revision the stream actually starts from, which is carried by the response header.
+// A PD without the revision in the response header does not support resuming the watch.
+func (c *client) createKeyspaceWatchStream(ctx context.Context, revision int64) (
+	keyspacepb.Keyspace_WatchKeyspacesClient, context.CancelFunc, int64, bool, error) {
+	keyspaceClient := c.keyspaceClient()
+	if keyspaceClient == nil {
+		c.ScheduleCheckLeader()
+		return nil, nil, 0, false, errors.Errorf("[pd] failed to get the keyspace client of leader %s", c.GetLeaderAddr())
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	cctx = metadata.AppendToOutgoingContext(cctx, grpcutil.KeyspaceWatchRevisionKey, strconv.FormatInt(revision, 10))
+	req := &keyspacepb.WatchKeyspacesRequest{
+		Header: c.requestHeader(),
+	}
+	stream, err := keyspaceClient.WatchKeyspaces(cctx, req)
+	if err != nil {
+		cancel()
+		c.ScheduleCheckLeader()
+		return nil, nil, 0, false, errors.WithStack(err)
+	}
+	header, err := stream.Header()
+	if err != nil {
+		cancel()
+		c.ScheduleCheckLeader()
+		return nil, nil, 0, false, errors.WithStack(err)
+	}
+	// A PD without resumable watch starts from all current keyspaces every time.
+	revision, resumable := parseKeyspaceWatchRevision(header)
+	return stream, cancel, revision, resumable, nil
+}
+
+// parseKeyspaceWatchRevision parses the keyspace watch revision from the metadata of the response.
+func parseKeyspaceWatchRevision(md metadata.MD) (int64, bool) {
+	values := md.Get(grpcutil.KeyspaceWatchRevisionKey)
+	if len(values) == 0 {
+		return 0, false
+	}
+	revision, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return revision, true
+}
+
+func (c *client) keyspaceWatchLoop(ctx context.Context, stream keyspacepb.Keyspace_WatchKeyspacesClient,
+	cancel context.CancelFunc, revision int64, resumable bool, watchChan chan *KeyspaceWatchResponse) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("[pd] panic in keyspace client `WatchKeyspacesFromRevision`", zap.Any("error", r))
+		}
+	}()
+	defer close(watchChan)
+	defer func() { cancel() }()
+
+	// received records the latest meta of the keyspaces that have been sent, to deduplicate the
+	// changes replayed after resuming.
+	received := make(map[uint32]*keyspacepb.KeyspaceMeta)
+	for {
+		resp, err := stream.Recv()
+		if err == nil && resumable {
+			// The stream ends right after the response, and the trailer carries the revision to resume from.
+			if _, err = stream.Recv(); err == io.EOF {
+				if newRevision, ok := parseKeyspaceWatchRevision(stream.Trailer()); ok {
+					revision = newRevision
+				}
+			} else if err == nil {
+				err = errors.New("[pd] keyspace watch stream does not end after the response")
+			}
+		}
+		if resp.GetHeader().GetError() != nil {
+			log.Warn("[pd] keyspace watch met error", zap.String("error", resp.GetHeader().GetError().String()))
+		} else if keyspaces := filterChangedKeyspaces(received, resp.GetKeyspaces()); len(keyspaces) > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case watchChan <- &KeyspaceWatchResponse{Keyspaces: keyspaces, Revision: revision}:
+			}
+		}
+		if err == nil {
+			continue
+		}
+
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		// Resume at once if the resumable stream ends as expected.
+		wait := !resumable || err != io.EOF
+		if wait {
+			log.Warn("[pd] keyspace watch stream is broken, try to resume it",
+				zap.Int64("revision", revision), zap.Error(err))
+		}
+		for {
+			if wait {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(retryInterval):
+				}
+			}
+			wait = true
+			newStream, newCancel, newRevision, newResumable, err := c.createKeyspaceWatchStream(ctx, revision)
+			if err == nil {
+				stream, cancel, revision, resumable = newStream, newCancel, newRevision, newResumable
+				break
+			}
+			log.Warn("[pd] failed to resume keyspace watch", zap.Int64("revision", revision), zap.Error(err))
+		}
+	}
+}
+
+// filterChangedKeyspaces returns the keyspaces which differ from the received ones, and records them.
+func filterChangedKeyspaces(received map[uint32]*keyspacepb.KeyspaceMeta, metas []*keyspacepb.KeyspaceMeta) []*keyspacepb.KeyspaceMeta {
+	keyspaces := make([]*keyspacepb.KeyspaceMeta, 0, len(metas))
+	for _, meta := range metas {
+		if old, ok := received[meta.GetId()]; ok && keyspaceMetaEqual(old, meta) {
+			continue
+		}
+		received[meta.GetId()] = meta
+		keyspaces = append(keyspaces, meta)
+	}
+	return keyspaces
+}
+
+func keyspaceMetaEqual(a, b *keyspacepb.KeyspaceMeta) bool {
+	return a.GetId() == b.GetId() &&
+		a.GetName() == b.GetName() &&
+		a.GetState() == b.GetState() &&
+		a.GetCreatedAt() == b.GetCreatedAt() &&
+		a.GetStateChangedAt() == b.GetStateChangedAt() &&
+		reflect.DeepEqual(a.GetConfig(), b.GetConfig())
+}
+
+// keyspaceMeta is the JSON representation of the keyspace meta used by the keyspace HTTP API.
+type keyspaceMeta struct {
+	ID             uint32            `json:"id"`
+	Name           string            `json:"name"`
+	State          string            `json:"state"`
+	CreatedAt      int64             `json:"created_at"`
+	StateChangedAt int64             `json:"state_changed_at"`
+	Config         map[string]string `json:"config"`
+}
+
+func (meta *keyspaceMeta) toKeyspaceMeta() *keyspacepb.KeyspaceMeta {
+	return &keyspacepb.KeyspaceMeta{
+		Id:             meta.ID,
+		Name:           meta.Name,
+		State:          keyspacepb.KeyspaceState(keyspacepb.KeyspaceState_value[meta.State]),
+		CreatedAt:      meta.CreatedAt,
+		StateChangedAt: meta.StateChangedAt,
+		Config:         meta.Config,
+	}
+}
+
+// CreateKeyspace creates a keyspace with the given name and config.
+func (c *client) CreateKeyspace(ctx context.Context, name string, config map[string]string) (*keyspacepb.KeyspaceMeta, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("keyspaceClient.CreateKeyspace", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDurationCreateKeyspace.Observe(time.Since(start).Seconds()) }()
+
+	body := &struct {
+		Name   string            `json:"name"`
+		Config map[string]string `json:"config"`
+	}{name, config}
+	meta := &keyspaceMeta{}
+	err := c.requestKeyspaceAPI(ctx, cmdFailedDurationCreateKeyspace, start, http.MethodPost, "", false, body, meta)
+	if err != nil {
+		return nil, err
+	}
+	return meta.toKeyspaceMeta(), nil
+}
+
+// UpdateKeyspaceConfig updates the config of the target keyspace.
+func (c *client) UpdateKeyspaceConfig(ctx context.Context, name string, patch map[string]*string) (*keyspacepb.KeyspaceMeta, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("keyspaceClient.UpdateKeyspaceConfig", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDurationUpdateKeyspaceConfig.Observe(time.Since(start).Seconds()) }()
+
+	body := &struct {
+		Config map[string]*string `json:"config"`
+	}{patch}
+	meta := &keyspaceMeta{}
+	err := c.requestKeyspaceAPI(ctx, cmdFailedDurationUpdateKeyspaceConfig, start, http.MethodPatch,
+		"/"+url.PathEscape(name)+"/config", true, body, meta)
+	if err != nil {
+		return nil, err
+	}
+	return meta.toKeyspaceMeta(), nil
+}
+
+// UpdateKeyspaceState updates the state of the target keyspace.
+func (c *client) UpdateKeyspaceState(ctx context.Context, name string, state keyspacepb.KeyspaceState) (*keyspacepb.KeyspaceMeta, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("keyspaceClient.UpdateKeyspaceState", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDurationUpdateKeyspaceState.Observe(time.Since(start).Seconds()) }()
+
+	body := &struct {
+		State string `json:"state"`
+	}{state.String()}
+	meta := &keyspaceMeta{}
+	err := c.requestKeyspaceAPI(ctx, cmdFailedDurationUpdateKeyspaceState, start, http.MethodPut,
+		"/"+url.PathEscape(name)+"/state", true, body, meta)
+	if err != nil {
+		return nil, err
+	}
+	return meta.toKeyspaceMeta(), nil
+}
+
+// LoadKeyspaces loads a page of keyspaces.
+func (c *client) LoadKeyspaces(ctx context.Context, pageToken string, limit int) ([]*keyspacepb.KeyspaceMeta, string, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("keyspaceClient.LoadKeyspaces", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDurationLoadKeyspaces.Observe(time.Since(start).Seconds()) }()
+
+	query := url.Values{}
+	if pageToken != "" {
+		query.Set("page_token", pageToken)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	path := ""
+	if len(query) > 0 {
+		path = "?" + query.Encode()
+	}
+	resp := &struct {
+		Keyspaces     []*keyspaceMeta `json:"keyspaces"`
+		NextPageToken string          `json:"next_page_token"`
+	}{}
+	err := c.requestKeyspaceAPI(ctx, cmdFailedDurationLoadKeyspaces, start, http.MethodGet, path, true, nil, resp)
+	if err != nil {
+		return nil, "", err
+	}
+	keyspaces := make([]*keyspacepb.KeyspaceMeta, 0, len(resp.Keyspaces))
+	for _, meta := range resp.Keyspaces {
+		keyspaces = append(keyspaces, meta.toKeyspaceMeta())
+	}
+	return keyspaces, resp.NextPageToken, nil
+}
+
+// requestKeyspaceAPI sends the request to the keyspace HTTP API of the PD leader, and decodes
+// the response into out. It retries when the leader is unavailable, and falls back to a follower,
+// which redirects the request to the leader, when the leader is unreachable and forwarding is enabled.
+// The requests which are not idempotent are not retried after the network errors, since they may
+// have been applied already.
+func (c *client) requestKeyspaceAPI(ctx context.Context, observer prometheus.Observer, start time.Time,
+	method, path string, idempotent bool, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			observer.Observe(time.Since(start).Seconds())
+			return errors.WithStack(err)
+		}
+	}
+	var (
+		err          error
+		networkError bool
+	)
+	for i := 0; i < maxRetryTimes; i++ {
+		addr := c.GetLeaderAddr()
+		if networkError && c.option.enableForwarding {
+			if followers := c.GetFollowerAddrs(); len(followers) > 0 {
+				addr = followers[rand.Intn(len(followers))]
+			}
+		}
+		var retryable bool
+		retryable, networkError, err = c.doKeyspaceRequest(ctx, addr+keyspaceAPIPrefix+path, method, body, out)
+		if err == nil || !retryable {
+			break
+		}
+		c.ScheduleCheckLeader()
+		if networkError && !idempotent {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			observer.Observe(time.Since(start).Seconds())
+			return errors.WithStack(ctx.Err())
+		case <-time.After(retryInterval):
+		}
+	}
+	if err != nil {
+		observer.Observe(time.Since(start).Seconds())
+	}
+	return err
+}
+
+// doKeyspaceRequest sends a single request to the keyspace HTTP API. It returns whether
+// the request could be retried and whether the error is caused by the network.
+func (c *client) doKeyspaceRequest(ctx context.Context, target, method string, body []byte, out interface{}) (retryable, networkError bool, err error) {
+	httpClient, err := c.getHTTPClient()
+	if err != nil {
+		return false, false, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.option.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return false, false, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return true, true, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, true, errors.WithStack(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		err = errors.Errorf("[pd] %s %s failed, status: %s, message: %s", method, target, resp.Status, strings.TrimSpace(string(content)))
+		// The leader may be unavailable temporarily, e.g. during the leader election.
+		return resp.StatusCode == http.StatusServiceUnavailable, false, err
+	}
+	return false, false, errors.WithStack(json.Unmarshal(content, out))
+}
+
+// getHTTPClient returns the HTTP client to access the HTTP API of PD.
+func (c *client) getHTTPClient() (*http.Client, error) {
+	c.httpClientOnce.Do(func() {
+		tlsCfg, err := tlsutil.TLSConfig{
+			CAPath:   c.security.CAPath,
+			CertPath: c.security.CertPath,
+			KeyPath:  c.security.KeyPath,
+
+			SSLCABytes:   c.security.SSLCABytes,
+			SSLCertBytes: c.security.SSLCertBytes,
+			SSLKEYBytes:  c.security.SSLKEYBytes,
+		}.ToTLSConfig()
+		if err != nil {
+			c.httpClientErr = err
+			return
+		}
+		c.httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}
+	})
+	return c.httpClient, c.httpClientErr
+}
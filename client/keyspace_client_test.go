@@ -0,0 +1,66 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyspaceRequestRetry(t *testing.T) {
+	re := require.New(t)
+	var creates, updates int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var count int32
+		if r.Method == http.MethodPost {
+			count = atomic.AddInt32(&creates, 1)
+		} else {
+			count = atomic.AddInt32(&updates, 1)
+		}
+		// Break the connection of the first request, whose result is unknown to the client.
+		if count == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			re.NoError(err)
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`{"id":1,"name":"ks","state":"ENABLED"}`))
+	}))
+	defer server.Close()
+
+	cli := &client{baseClient: &baseClient{checkLeaderCh: make(chan struct{}, 1), option: newOption()}}
+	cli.leader.Store(server.URL)
+	defer func() {
+		if cli.httpClient != nil {
+			cli.httpClient.CloseIdleConnections()
+		}
+	}()
+
+	// The creation is not retried, since it may have been applied.
+	_, err := cli.CreateKeyspace(context.Background(), "ks", nil)
+	re.Error(err)
+	re.Equal(int32(1), atomic.LoadInt32(&creates))
+	// The idempotent requests are retried.
+	value := "1"
+	meta, err := cli.UpdateKeyspaceConfig(context.Background(), "ks", map[string]*string{"k": &value})
+	re.NoError(err)
+	re.Equal("ks", meta.GetName())
+	re.Equal(int32(2), atomic.LoadInt32(&updates))
+}
@@ -100,6 +100,15 @@ var (
 	cmdDurationSplitRegions             = cmdDuration.WithLabelValues("split_regions")
 	cmdDurationSplitAndScatterRegions   = cmdDuration.WithLabelValues("split_and_scatter_regions")
 	cmdDurationLoadKeyspace             = cmdDuration.WithLabelValues("load_keyspace")
+	cmdDurationLoadKeyspaces            = cmdDuration.WithLabelValues("load_keyspaces")
+	cmdDurationCreateKeyspace           = cmdDuration.WithLabelValues("create_keyspace")
+	cmdDurationUpdateKeyspaceConfig     = cmdDuration.WithLabelValues("update_keyspace_config")
+	cmdDurationUpdateKeyspaceState      = cmdDuration.WithLabelValues("update_keyspace_state")
+
+	cmdDurationLoadKeyspaceGCSafePoint          = cmdDuration.WithLabelValues("load_keyspace_gc_safe_point")
+	cmdDurationGetKeyspaceMinServiceGCSafePoint = cmdDuration.WithLabelValues("get_keyspace_min_service_gc_safe_point")
+	cmdDurationUpdateKeyspaceGCSafePoint        = cmdDuration.WithLabelValues("update_keyspace_gc_safe_point")
+	cmdDurationUpdateKeyspaceServiceGCSafePoint = cmdDuration.WithLabelValues("update_keyspace_service_gc_safe_point")
 
 	cmdFailDurationGetRegion                  = cmdFailedDuration.WithLabelValues("get_region")
 	cmdFailDurationTSO                        = cmdFailedDuration.WithLabelValues("tso")
@@ -111,8 +120,18 @@ var (
 	cmdFailedDurationGetAllStores             = cmdFailedDuration.WithLabelValues("get_all_stores")
 	cmdFailedDurationUpdateGCSafePoint        = cmdFailedDuration.WithLabelValues("update_gc_safe_point")
 	cmdFailedDurationUpdateServiceGCSafePoint = cmdFailedDuration.WithLabelValues("update_service_gc_safe_point")
-	cmdFailedDurationLoadKeyspace             = cmdDuration.WithLabelValues("load_keyspace")
-	requestDurationTSO                        = requestDuration.WithLabelValues("tso")
+	cmdFailedDurationLoadKeyspace             = cmdFailedDuration.WithLabelValues("load_keyspace")
+	cmdFailedDurationLoadKeyspaces            = cmdFailedDuration.WithLabelValues("load_keyspaces")
+	cmdFailedDurationCreateKeyspace           = cmdFailedDuration.WithLabelValues("create_keyspace")
+	cmdFailedDurationUpdateKeyspaceConfig     = cmdFailedDuration.WithLabelValues("update_keyspace_config")
+	cmdFailedDurationUpdateKeyspaceState      = cmdFailedDuration.WithLabelValues("update_keyspace_state")
+
+	cmdFailedDurationLoadKeyspaceGCSafePoint          = cmdFailedDuration.WithLabelValues("load_keyspace_gc_safe_point")
+	cmdFailedDurationGetKeyspaceMinServiceGCSafePoint = cmdFailedDuration.WithLabelValues("get_keyspace_min_service_gc_safe_point")
+	cmdFailedDurationUpdateKeyspaceGCSafePoint        = cmdFailedDuration.WithLabelValues("update_keyspace_gc_safe_point")
+	cmdFailedDurationUpdateKeyspaceServiceGCSafePoint = cmdFailedDuration.WithLabelValues("update_keyspace_service_gc_safe_point")
+
+	requestDurationTSO = requestDuration.WithLabelValues("tso")
 )
 
 func init() {
@@ -28,8 +28,15 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
-// ForwardMetadataKey is used to record the forwarded host of PD.
-const ForwardMetadataKey = "pd-forwarded-host"
+const (
+	// ForwardMetadataKey is used to record the forwarded host of PD.
+	ForwardMetadataKey = "pd-forwarded-host"
+	// KeyspaceWatchRevisionKey is used to record the revision a keyspace watch starts from.
+	// In requests, it is the revision to resume the watch from, 0 or absent means starting with
+	// all current keyspaces. In the response header, it is the revision the watch actually starts from,
+	// and in the response trailer, it is the revision to resume from after the only response of the watch.
+	KeyspaceWatchRevisionKey = "pd-keyspace-watch-revision"
+)
 
 // TLSConfig is the configuration for supporting tls.
 type TLSConfig struct {
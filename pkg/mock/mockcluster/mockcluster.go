@@ -155,6 +155,11 @@ func (mc *Cluster) RegionWriteStats() map[uint64][]*statistics.HotPeerStat {
 	return mc.HotCache.RegionStats(statistics.Write, mc.GetHotRegionCacheHitsThreshold())
 }
 
+// PredictedRegionStats returns the stats of the peers which are predicted to be hot soon.
+func (mc *Cluster) PredictedRegionStats(rw statistics.RWType) map[uint64][]*statistics.HotPeerStat {
+	return mc.HotPeriodPredictor.PredictedPeerStats(rw, time.Now(), mc.GetRegion)
+}
+
 // HotRegionsFromStore picks hot regions in specify store.
 func (mc *Cluster) HotRegionsFromStore(store uint64, kind statistics.RWType) []*core.RegionInfo {
 	stats := hotRegionsFromStore(mc.HotCache, store, kind, mc.GetHotRegionCacheHitsThreshold())
@@ -685,7 +690,7 @@ func (mc *Cluster) newMockRegionInfo(regionID uint64, leaderStoreID uint64, othe
 	var followerStoreIDs []uint64
 	var learnerStoreIDs []uint64
 	for _, storeID := range otherPeerStoreIDs {
-		if store := mc.GetStore(storeID); store != nil && store.IsTiFlash() {
+		if store := mc.GetStore(storeID); store != nil && store.GetEngine() != core.EngineTiKV {
 			learnerStoreIDs = append(learnerStoreIDs, storeID)
 		} else {
 			followerStoreIDs = append(followerStoreIDs, storeID)
@@ -819,6 +824,9 @@ func (mc *Cluster) CheckRegionRead(region *core.RegionInfo) []*statistics.HotPee
 	interval := reportInterval.GetEndTimestamp() - reportInterval.GetStartTimestamp()
 	for _, peer := range region.GetPeers() {
 		peerInfo := core.NewPeerInfo(peer, region.GetLoads(), interval)
+		if store := mc.GetStore(peer.GetStoreId()); store != nil {
+			peerInfo.WithEngine(store.GetEngine())
+		}
 		item := mc.HotCache.CheckReadPeerSync(peerInfo, region)
 		if item != nil {
 			items = append(items, item)
@@ -836,6 +844,9 @@ func (mc *Cluster) CheckRegionWrite(region *core.RegionInfo) []*statistics.HotPe
 	interval := reportInterval.GetEndTimestamp() - reportInterval.GetStartTimestamp()
 	for _, peer := range region.GetPeers() {
 		peerInfo := core.NewPeerInfo(peer, region.GetLoads(), interval)
+		if store := mc.GetStore(peer.GetStoreId()); store != nil {
+			peerInfo.WithEngine(store.GetEngine())
+		}
 		item := mc.HotCache.CheckWritePeerSync(peerInfo, region)
 		if item != nil {
 			items = append(items, item)
@@ -853,6 +864,9 @@ func (mc *Cluster) CheckRegionLeaderRead(region *core.RegionInfo) []*statistics.
 	interval := reportInterval.GetEndTimestamp() - reportInterval.GetStartTimestamp()
 	peer := region.GetLeader()
 	peerInfo := core.NewPeerInfo(peer, region.GetLoads(), interval)
+	if store := mc.GetStore(peer.GetStoreId()); store != nil {
+		peerInfo.WithEngine(store.GetEngine())
+	}
 	item := mc.HotCache.CheckReadPeerSync(peerInfo, region)
 	if item != nil {
 		items = append(items, item)
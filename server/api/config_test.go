@@ -178,6 +178,41 @@ func (suite *configTestSuite) TestConfigSchedule() {
 	suite.Equal(*sc1, *sc)
 }
 
+func (suite *configTestSuite) TestConfigOperatorQuotas() {
+	re := suite.Require()
+	addr := fmt.Sprintf("%s/config/schedule", suite.urlPrefix)
+	postData, err := json.Marshal(map[string]interface{}{
+		"operator-quotas": map[string]interface{}{
+			"balance-hot-region-scheduler": map[string]interface{}{"max-waiting": 10, "weight": 2},
+		},
+	})
+	suite.NoError(err)
+	suite.NoError(tu.CheckPostJSON(testDialClient, addr, postData, tu.StatusOK(re)))
+	// the quotas of different sources are updated separately.
+	postData, err = json.Marshal(map[string]interface{}{
+		"operator-quotas": map[string]interface{}{
+			"merge-checker": map[string]interface{}{"max-waiting": 3},
+		},
+	})
+	suite.NoError(err)
+	suite.NoError(tu.CheckPostJSON(testDialClient, addr, postData, tu.StatusOK(re)))
+
+	sc := &config.ScheduleConfig{}
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, addr, sc))
+	suite.Equal(map[string]config.OperatorQuotaConfig{
+		"balance-hot-region-scheduler": {MaxWaiting: 10, Weight: 2},
+		"merge-checker":                {MaxWaiting: 3},
+	}, sc.OperatorQuotas)
+
+	postData, err = json.Marshal(map[string]interface{}{
+		"operator-quotas": map[string]interface{}{
+			"merge-checker": map[string]interface{}{"weight": -1},
+		},
+	})
+	suite.NoError(err)
+	suite.NoError(tu.CheckPostJSON(testDialClient, addr, postData, tu.StatusNotOK(re)))
+}
+
 func (suite *configTestSuite) TestConfigReplication() {
 	re := suite.Require()
 	addr := fmt.Sprintf("%s/config/replicate", suite.urlPrefix)
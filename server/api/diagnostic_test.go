@@ -101,7 +101,10 @@ func (suite *diagnosticTestSuite) TestSchedulerDiagnosticAPI() {
 	suite.Equal("disabled", result.Status)
 
 	evictLeaderURL := suite.urlPrefix + "/" + schedulers.EvictLeaderName
-	suite.NoError(tu.CheckGetJSON(testDialClient, evictLeaderURL, nil, tu.StatusNotOK(re)))
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, evictLeaderURL, result))
+	suite.Equal("disabled", result.Status)
+	shuffleRegionURL := suite.urlPrefix + "/" + schedulers.ShuffleRegionName
+	suite.NoError(tu.CheckGetJSON(testDialClient, shuffleRegionURL, nil, tu.StatusNotOK(re)))
 
 	input := make(map[string]interface{})
 	input["name"] = schedulers.BalanceRegionName
@@ -110,6 +113,9 @@ func (suite *diagnosticTestSuite) TestSchedulerDiagnosticAPI() {
 	err = tu.CheckPostJSON(testDialClient, suite.schedulerPrifex, body, tu.StatusOK(suite.Require()))
 	suite.NoError(err)
 	suite.checkStatus("pending", balanceRegionURL)
+	// The result explains why each store cannot be scheduled.
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, balanceRegionURL, result))
+	suite.NotEmpty(result.StoreReasons)
 
 	input = make(map[string]interface{})
 	input["delay"] = 30
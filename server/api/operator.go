@@ -17,14 +17,19 @@ package api
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pingcap/errors"
 	"github.com/tikv/pd/pkg/apiutil"
+	"github.com/tikv/pd/pkg/slice"
 	"github.com/tikv/pd/pkg/typeutil"
 	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/schedule"
 	"github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/server/storage"
 	"github.com/unrolled/render"
 )
 
@@ -345,6 +350,87 @@ func (h *operatorHandler) DeleteOperatorByRegion(w http.ResponseWriter, r *http.
 	h.r.JSON(w, http.StatusOK, "The pending operator is canceled.")
 }
 
+// RemovedOperators is the result of removing operators in bulk.
+type RemovedOperators struct {
+	DryRun bool `json:"dry-run"`
+	Count  int  `json:"count"`
+	// Reason describes the filter selecting the operators.
+	Reason  string   `json:"reason"`
+	Regions []uint64 `json:"regions"`
+}
+
+// @Tags     operator
+// @Summary  Cancel the running operators selected by the filter in bulk.
+// @Param    desc       query  string   false  "The operator description"
+// @Param    scheduler  query  string   false  "The scheduler which created the operators"
+// @Param    kind       query  string   false  "The operator kind"
+// @Param    store_id   query  integer  false  "A store involved in the operators"
+// @Param    start_key  query  string   false  "The start key of the regions"
+// @Param    end_key    query  string   false  "The end key of the regions"
+// @Param    min_age    query  string   false  "The min duration since the operators are created"
+// @Param    dry_run    query  boolean  false  "Only count the selected operators without cancelling them"
+// @Produce  json
+// @Success  200  {object}  RemovedOperators
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /operators [delete]
+func (h *operatorHandler) DeleteOperators(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &schedule.OperatorFilter{
+		Desc:      query.Get("desc"),
+		Scheduler: query.Get("scheduler"),
+		StartKey:  []byte(query.Get("start_key")),
+		EndKey:    []byte(query.Get("end_key")),
+	}
+	var err error
+	if str := query.Get("kind"); str != "" {
+		if filter.Kind, err = operator.ParseOperatorKind(str); err != nil {
+			h.r.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if str := query.Get("store_id"); str != "" {
+		if filter.StoreID, err = strconv.ParseUint(str, 10, 64); err != nil {
+			h.r.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if str := query.Get("min_age"); str != "" {
+		if filter.MinAge, err = time.ParseDuration(str); err != nil {
+			h.r.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	dryRun := false
+	if str := query.Get("dry_run"); str != "" {
+		if dryRun, err = strconv.ParseBool(str); err != nil {
+			h.r.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	// Avoid cancelling all the operators by mistake.
+	if filter.IsEmpty() {
+		h.r.JSON(w, http.StatusBadRequest, "at least one filter condition is required")
+		return
+	}
+
+	ops, err := h.RemoveOperators(filter, dryRun)
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	result := &RemovedOperators{
+		DryRun:  dryRun,
+		Count:   len(ops),
+		Reason:  filter.String(),
+		Regions: make([]uint64, 0, len(ops)),
+	}
+	for _, op := range ops {
+		result.Regions = append(result.Regions, op.RegionID())
+	}
+	h.r.JSON(w, http.StatusOK, result)
+}
+
 // @Tags     operator
 // @Summary  lists the finished operators since the given timestamp in second.
 // @Param    from  query  integer  false  "From Unix timestamp"
@@ -371,6 +457,142 @@ func (h *operatorHandler) GetOperatorRecords(w http.ResponseWriter, r *http.Requ
 	h.r.JSON(w, http.StatusOK, records)
 }
 
+// @Tags     operator
+// @Summary  lists the persisted history of finished operators, from the oldest to the newest. The history is kept in the local leveldb of the PD which was the leader when the operators finished. It is not replicated to the other PDs, so the history recorded by the former leaders is lost after the leader changes.
+// @Param    from       query  integer  false  "From Unix timestamp"
+// @Param    to         query  integer  false  "To Unix timestamp"
+// @Param    region_id  query  integer  false  "The Region the operators are applied to"
+// @Param    store_id   query  integer  false  "A store involved in the operators"
+// @Param    scheduler  query  string   false  "The scheduler which created the operators"
+// @Param    kind       query  string   false  "The operator kind"
+// @Param    status     query  string   false  "The finished status"  Enums(success, canceled, replaced, expired, timeout)
+// @Param    limit      query  integer  false  "Limit count"  default(1000)
+// @Produce  json
+// @Success  200  {object}  storage.HistoryOperators
+// @Failure  400  {string}  string  "The request is invalid."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /operators/history [get]
+func (h *operatorHandler) GetOperatorHistory(w http.ResponseWriter, r *http.Request) {
+	filter, err := newOperatorHistoryFilter(r)
+	if err != nil {
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	iter := h.GetHistoryOperatorIter(filter.from.UnixNano()/int64(time.Millisecond), filter.to.UnixNano()/int64(time.Millisecond))
+	results := make([]*storage.HistoryOperator, 0)
+	var next *storage.HistoryOperator
+	for next, err = iter.Next(); next != nil && err == nil; next, err = iter.Next() {
+		if filter.match(next) {
+			results = append(results, next)
+			if len(results) >= filter.limit {
+				iter.Release()
+				break
+			}
+		}
+	}
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, &storage.HistoryOperators{HistoryOperator: results})
+}
+
+const (
+	defaultOperatorHistoryLimit = 1000
+	maxOperatorHistoryLimit     = 10000
+)
+
+type operatorHistoryFilter struct {
+	limit     int
+	from, to  time.Time
+	regionID  uint64
+	storeID   uint64
+	scheduler string
+	kind      operator.OpKind
+	status    string
+}
+
+func newOperatorHistoryFilter(r *http.Request) (*operatorHistoryFilter, error) {
+	query := r.URL.Query()
+	filter := &operatorHistoryFilter{
+		limit:     defaultOperatorHistoryLimit,
+		to:        time.Now(),
+		scheduler: query.Get("scheduler"),
+		status:    query.Get("status"),
+	}
+	parseTime := func(key string, t *time.Time) error {
+		if str := query.Get(key); str != "" {
+			v, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				return err
+			}
+			*t = time.Unix(v, 0)
+		}
+		return nil
+	}
+	parseID := func(key string, id *uint64) (err error) {
+		if str := query.Get(key); str != "" {
+			*id, err = strconv.ParseUint(str, 10, 64)
+		}
+		return
+	}
+	if err := parseTime("from", &filter.from); err != nil {
+		return nil, err
+	}
+	if err := parseTime("to", &filter.to); err != nil {
+		return nil, err
+	}
+	if err := parseID("region_id", &filter.regionID); err != nil {
+		return nil, err
+	}
+	if err := parseID("store_id", &filter.storeID); err != nil {
+		return nil, err
+	}
+	if str := query.Get("limit"); str != "" {
+		limit, err := strconv.Atoi(str)
+		if err != nil {
+			return nil, err
+		}
+		if limit <= 0 {
+			return nil, errors.Errorf("invalid limit %d", limit)
+		}
+		filter.limit = limit
+	}
+	if filter.limit > maxOperatorHistoryLimit {
+		filter.limit = maxOperatorHistoryLimit
+	}
+	if str := query.Get("kind"); str != "" {
+		kind, err := operator.ParseOperatorKind(str)
+		if err != nil {
+			return nil, err
+		}
+		filter.kind = kind
+	}
+	return filter, nil
+}
+
+func (f *operatorHistoryFilter) match(op *storage.HistoryOperator) bool {
+	if f.regionID != 0 && op.RegionID != f.regionID {
+		return false
+	}
+	if f.storeID != 0 && !slice.Contains(op.StoreIDs, f.storeID) {
+		return false
+	}
+	if f.scheduler != "" && op.SchedulerName != f.scheduler {
+		return false
+	}
+	if f.status != "" && !strings.EqualFold(op.Status, f.status) {
+		return false
+	}
+	if f.kind != 0 {
+		kind, err := operator.ParseOperatorKind(op.Kind)
+		if err != nil || kind&f.kind != f.kind {
+			return false
+		}
+	}
+	return true
+}
+
 func parseStoreIDsAndPeerRole(ids interface{}, roles interface{}) (map[uint64]placement.PeerRoleType, bool) {
 	items, ok := ids.([]interface{})
 	if !ok {
@@ -16,9 +16,11 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"strconv"
 	"strings"
 	"testing"
@@ -29,6 +31,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/tikv/pd/pkg/apiutil"
 	"github.com/tikv/pd/pkg/mock/mockhbstream"
 	tu "github.com/tikv/pd/pkg/testutil"
@@ -37,6 +40,7 @@ import (
 	"github.com/tikv/pd/server/core"
 	pdoperator "github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/server/storage"
 	"github.com/tikv/pd/server/versioninfo"
 )
 
@@ -137,6 +141,97 @@ func (suite *operatorTestSuite) TestAddRemovePeer() {
 	suite.Contains(records, "operator not found")
 }
 
+func (suite *operatorTestSuite) TestDeleteOperators() {
+	re := suite.Require()
+	operatorsURL := fmt.Sprintf("%s/operators", suite.urlPrefix)
+	// The filter is required and must be valid.
+	for _, query := range []string{"", "?dry_run=true", "?kind=unknown", "?store_id=a", "?min_age=1", "?kind=region&dry_run=a"} {
+		req, err := http.NewRequest(http.MethodDelete, operatorsURL+query, nil)
+		suite.NoError(err)
+		resp, err := testDialClient.Do(req)
+		suite.NoError(err)
+		resp.Body.Close()
+		suite.Equal(http.StatusBadRequest, resp.StatusCode, query)
+	}
+
+	mustPutStore(re, suite.svr, 1, metapb.StoreState_Up, metapb.NodeState_Serving, nil)
+	mustPutStore(re, suite.svr, 2, metapb.StoreState_Up, metapb.NodeState_Serving, nil)
+	mustPutRegion(re, suite.svr, 10, 1, []byte("a"), []byte("b"))
+	mustPutRegion(re, suite.svr, 11, 1, []byte("b"), []byte("c"))
+	for _, regionID := range []uint64{10, 11} {
+		body := fmt.Sprintf(`{"name":"add-peer", "region_id": %d, "store_id": 2}`, regionID)
+		suite.NoError(tu.CheckPostJSON(testDialClient, operatorsURL, []byte(body), tu.StatusOK(re)))
+	}
+
+	var result RemovedOperators
+	remove := func(query string) {
+		req, err := http.NewRequest(http.MethodDelete, operatorsURL+query, nil)
+		suite.NoError(err)
+		resp, err := testDialClient.Do(req)
+		suite.NoError(err)
+		defer resp.Body.Close()
+		suite.Equal(http.StatusOK, resp.StatusCode)
+		suite.NoError(apiutil.ReadJSON(resp.Body, &result))
+	}
+	remove("?store_id=2&dry_run=true")
+	suite.True(result.DryRun)
+	suite.Equal(2, result.Count)
+	suite.ElementsMatch([]uint64{10, 11}, result.Regions)
+	remove("?kind=admin&start_key=b")
+	suite.False(result.DryRun)
+	suite.Equal(1, result.Count)
+	suite.Equal([]uint64{11}, result.Regions)
+	suite.Contains(mustReadURL(re, fmt.Sprintf("%s/%d", operatorsURL, 11)), "CANCEL")
+	remove("?desc=admin-add-peer")
+	suite.Equal([]uint64{10}, result.Regions)
+}
+
+func (suite *operatorTestSuite) TestOperatorHistory() {
+	re := suite.Require()
+	now := time.Now()
+	toMs := func(t time.Time) int64 { return t.UnixNano() / int64(time.Millisecond) }
+	operators := []*storage.HistoryOperator{
+		{FinishTime: toMs(now.Add(-time.Hour)), RegionID: 1, Kind: "leader", SchedulerName: "balance-leader-scheduler", Status: "Success", StoreIDs: []uint64{1, 2}},
+		{FinishTime: toMs(now.Add(-time.Minute)), RegionID: 2, Kind: "region", SchedulerName: "balance-region-scheduler", Status: "Canceled", StoreIDs: []uint64{2, 3}},
+		{FinishTime: toMs(now.Add(-time.Second)), RegionID: 1, Kind: "admin,region", Status: "Timeout", StoreIDs: []uint64{3, 4}},
+	}
+	batch := new(leveldb.Batch)
+	for i, op := range operators {
+		value, err := json.Marshal(op)
+		suite.NoError(err)
+		batch.Put([]byte(storage.OperatorHistoryPath(op.FinishTime, op.RegionID, uint64(i))), value)
+	}
+	suite.NoError(suite.svr.GetOperatorHistoryStorage().Write(batch, nil))
+
+	historyURL := fmt.Sprintf("%s/operators/history", suite.urlPrefix)
+	testCases := []struct {
+		query   string
+		regions []uint64
+	}{
+		{fmt.Sprintf("from=%d", now.Add(-2*time.Hour).Unix()), []uint64{1, 2, 1}},
+		{fmt.Sprintf("from=%d&to=%d", now.Add(-2*time.Hour).Unix(), now.Add(-30*time.Minute).Unix()), []uint64{1}},
+		{fmt.Sprintf("from=%d&region_id=1", now.Add(-2*time.Hour).Unix()), []uint64{1, 1}},
+		{fmt.Sprintf("from=%d&store_id=3", now.Add(-2*time.Hour).Unix()), []uint64{2, 1}},
+		{fmt.Sprintf("from=%d&scheduler=balance-region-scheduler", now.Add(-2*time.Hour).Unix()), []uint64{2}},
+		{fmt.Sprintf("from=%d&kind=region", now.Add(-2*time.Hour).Unix()), []uint64{2, 1}},
+		{fmt.Sprintf("from=%d&status=timeout", now.Add(-2*time.Hour).Unix()), []uint64{1}},
+		{fmt.Sprintf("from=%d&limit=2", now.Add(-2*time.Hour).Unix()), []uint64{1, 2}},
+		{fmt.Sprintf("from=%d&store_id=3&limit=1", now.Add(-2*time.Hour).Unix()), []uint64{2}},
+	}
+	for _, testCase := range testCases {
+		var result storage.HistoryOperators
+		suite.NoError(tu.ReadGetJSON(re, testDialClient, historyURL+"?"+testCase.query, &result), testCase.query)
+		regions := make([]uint64, 0, len(result.HistoryOperator))
+		for _, op := range result.HistoryOperator {
+			regions = append(regions, op.RegionID)
+		}
+		suite.Equal(testCase.regions, regions, testCase.query)
+	}
+	suite.NoError(tu.CheckGetJSON(testDialClient, historyURL+"?kind=unknown", nil, tu.StatusNotOK(re)))
+	suite.NoError(tu.CheckGetJSON(testDialClient, historyURL+"?store_id=a", nil, tu.StatusNotOK(re)))
+	suite.NoError(tu.CheckGetJSON(testDialClient, historyURL+"?limit=0", nil, tu.StatusNotOK(re)))
+}
+
 func (suite *operatorTestSuite) TestMergeRegionOperator() {
 	re := suite.Require()
 	r1 := newTestRegionInfo(10, 1, []byte(""), []byte("b"), core.SetWrittenBytes(1000), core.SetReadBytes(1000), core.SetRegionConfVer(1), core.SetRegionVersion(1))
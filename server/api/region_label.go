@@ -24,6 +24,7 @@ import (
 	"github.com/tikv/pd/pkg/errs"
 	"github.com/tikv/pd/server"
 	"github.com/tikv/pd/server/schedule/labeler"
+	"github.com/tikv/pd/server/statistics"
 	"github.com/unrolled/render"
 )
 
@@ -222,3 +223,75 @@ func (h *regionLabelHandler) GetRegionLabels(w http.ResponseWriter, r *http.Requ
 	labels := cluster.GetRegionLabeler().GetRegionLabels(region)
 	h.rd.JSON(w, http.StatusOK, labels)
 }
+
+// LabeledRegionsInfo contains a page of the regions with a label, and the
+// statistics of all the regions with the label.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type LabeledRegionsInfo struct {
+	// Stats contains the total size and keys, and the leader and peer distribution of the stores.
+	Stats        *statistics.RegionStats `json:"stats"`
+	WrittenBytes uint64                  `json:"written_bytes"`
+	WrittenKeys  uint64                  `json:"written_keys"`
+	ReadBytes    uint64                  `json:"read_bytes"`
+	ReadKeys     uint64                  `json:"read_keys"`
+	Regions      []RegionInfo            `json:"regions"`
+}
+
+// @Tags     region_label
+// @Summary  List the regions with a label and the statistics of them.
+// @Param    key     path   string   true   "Label key"
+// @Param    value   path   string   true   "Label value"
+// @Param    offset  query  integer  false  "Offset of the regions sorted by the start key"  default(0)
+// @Param    limit   query  integer  false  "Limit count"                                    default(16)
+// @Produce  json
+// @Success  200  {object}  LabeledRegionsInfo
+// @Failure  400  {string}  string  "The input is invalid."
+// @Router   /regions/label/{key}/{value} [get]
+func (h *regionLabelHandler) GetRegionsByLabel(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r)
+	key, err := url.PathUnescape(mux.Vars(r)["key"])
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	value, err := url.PathUnescape(mux.Vars(r)["value"])
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, limit := 0, defaultRegionLimit
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			h.rd.JSON(w, http.StatusBadRequest, "invalid offset")
+			return
+		}
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			h.rd.JSON(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+	}
+	if limit > maxRegionLimit {
+		limit = maxRegionLimit
+	}
+
+	regions := cluster.GetRegionsByLabel(key, value)
+	info := &LabeledRegionsInfo{Stats: statistics.GetRegionStats(regions)}
+	for _, region := range regions {
+		info.WrittenBytes += region.GetBytesWritten()
+		info.WrittenKeys += region.GetKeysWritten()
+		info.ReadBytes += region.GetBytesRead()
+		info.ReadKeys += region.GetKeysRead()
+	}
+	if offset > len(regions) {
+		offset = len(regions)
+	}
+	if offset+limit < len(regions) {
+		regions = regions[:offset+limit]
+	}
+	info.Regions = convertToAPIRegions(regions[offset:]).Regions
+	h.rd.JSON(w, http.StatusOK, info)
+}
@@ -17,14 +17,17 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"sort"
 	"testing"
 
+	"github.com/docker/go-units"
 	"github.com/stretchr/testify/suite"
 	"github.com/tikv/pd/pkg/apiutil"
 	tu "github.com/tikv/pd/pkg/testutil"
 	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/schedule/labeler"
 )
 
@@ -113,3 +116,48 @@ func makeKeyRanges(keys ...string) []interface{} {
 	}
 	return res
 }
+
+func (suite *regionLabelTestSuite) TestGetRegionsByLabel() {
+	re := suite.Require()
+	rule := &labeler.LabelRule{ID: "regions", Labels: []labeler.RegionLabel{{Key: "app", Value: "a"}}, RuleType: "key-range", Data: makeKeyRanges("61", "63")}
+	data, err := json.Marshal(rule)
+	suite.NoError(err)
+	err = tu.CheckPostJSON(testDialClient, suite.urlPrefix+"rule", data, tu.StatusOK(re))
+	suite.NoError(err)
+	regions := []*core.RegionInfo{
+		newTestRegionInfo(100, 1, []byte("a"), []byte("a1")),
+		newTestRegionInfo(101, 1, []byte("a1"), []byte("b")),
+		newTestRegionInfo(102, 2, []byte("b"), []byte("c")),
+		newTestRegionInfo(103, 2, []byte("c"), []byte("d")),
+	}
+	for _, region := range regions {
+		mustRegionHeartbeat(re, suite.svr, region)
+	}
+
+	prefix := fmt.Sprintf("%s%s/api/v1/regions/label/app/a", suite.svr.GetAddr(), apiPrefix)
+	var info LabeledRegionsInfo
+	err = tu.ReadGetJSON(re, testDialClient, prefix+"?limit=2", &info)
+	suite.NoError(err)
+	suite.Equal(3, info.Stats.Count)
+	suite.Equal(int64(30), info.Stats.StorageSize)
+	suite.Equal(map[uint64]int{1: 2, 2: 1}, info.Stats.StoreLeaderCount)
+	suite.Equal(uint64(300*units.MiB), info.WrittenBytes)
+	suite.Equal(uint64(600*units.MiB), info.ReadBytes)
+	suite.Len(info.Regions, 2)
+	suite.Equal(uint64(100), info.Regions[0].ID)
+
+	// the next page.
+	err = tu.ReadGetJSON(re, testDialClient, prefix+"?offset=2&limit=2", &info)
+	suite.NoError(err)
+	suite.Len(info.Regions, 1)
+	suite.Equal(uint64(102), info.Regions[0].ID)
+
+	err = tu.ReadGetJSON(re, testDialClient, prefix+"?offset=10", &info)
+	suite.NoError(err)
+	suite.Empty(info.Regions)
+	err = tu.CheckGetJSON(testDialClient, prefix+"?offset=-1", nil, tu.Status(re, http.StatusBadRequest))
+	suite.NoError(err)
+
+	_, err = apiutil.DoDelete(testDialClient, suite.urlPrefix+"rule/regions")
+	suite.NoError(err)
+}
@@ -129,7 +129,9 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	operatorHandler := newOperatorHandler(handler, rd)
 	registerFunc(apiRouter, "/operators", operatorHandler.GetOperators, setMethods(http.MethodGet), setAuditBackend(prometheus))
 	registerFunc(apiRouter, "/operators", operatorHandler.CreateOperator, setMethods(http.MethodPost), setAuditBackend(localLog, prometheus))
+	registerFunc(apiRouter, "/operators", operatorHandler.DeleteOperators, setMethods(http.MethodDelete), setAuditBackend(localLog, prometheus))
 	registerFunc(apiRouter, "/operators/records", operatorHandler.GetOperatorRecords, setMethods(http.MethodGet), setAuditBackend(prometheus))
+	registerFunc(apiRouter, "/operators/history", operatorHandler.GetOperatorHistory, setMethods(http.MethodGet), setAuditBackend(prometheus))
 	registerFunc(apiRouter, "/operators/{region_id}", operatorHandler.GetOperatorsByRegion, setMethods(http.MethodGet), setAuditBackend(prometheus))
 	registerFunc(apiRouter, "/operators/{region_id}", operatorHandler.DeleteOperatorByRegion, setMethods(http.MethodDelete), setAuditBackend(localLog, prometheus))
 
@@ -142,6 +144,14 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	registerFunc(apiRouter, "/schedulers", schedulerHandler.CreateScheduler, setMethods(http.MethodPost), setAuditBackend(localLog, prometheus))
 	registerFunc(apiRouter, "/schedulers/{name}", schedulerHandler.DeleteScheduler, setMethods(http.MethodDelete), setAuditBackend(localLog, prometheus))
 	registerFunc(apiRouter, "/schedulers/{name}", schedulerHandler.PauseOrResumeScheduler, setMethods(http.MethodPost), setAuditBackend(localLog, prometheus))
+	registerFunc(apiRouter, "/schedulers/dry-run/{name}", schedulerHandler.GetSchedulerDryRunResult, setMethods(http.MethodGet), setAuditBackend(prometheus))
+	registerFunc(apiRouter, "/schedulers/dry-run/{name}", schedulerHandler.SetSchedulerDryRun, setMethods(http.MethodPost), setAuditBackend(localLog, prometheus))
+
+	schedulingWindowHandler := newSchedulingWindowHandler(svr, rd)
+	registerFunc(apiRouter, "/scheduling-windows", schedulingWindowHandler.GetAllSchedulingWindows, setMethods(http.MethodGet), setAuditBackend(prometheus))
+	registerFunc(apiRouter, "/scheduling-windows/{name}", schedulingWindowHandler.GetSchedulingWindows, setMethods(http.MethodGet), setAuditBackend(prometheus))
+	registerFunc(apiRouter, "/scheduling-windows/{name}", schedulingWindowHandler.SetSchedulingWindows, setMethods(http.MethodPost), setAuditBackend(localLog, prometheus))
+	registerFunc(apiRouter, "/scheduling-windows/{name}", schedulingWindowHandler.DeleteSchedulingWindows, setMethods(http.MethodDelete), setAuditBackend(localLog, prometheus))
 
 	diagnosticHandler := newDiagnosticHandler(svr, rd)
 	registerFunc(clusterRouter, "/schedulers/diagnostic/{name}", diagnosticHandler.GetDiagnosticResult, setMethods(http.MethodGet), setAuditBackend(prometheus))
@@ -177,6 +187,11 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	registerFunc(clusterRouter, "/config/rules/region/{region}", rulesHandler.GetRulesByRegion, setMethods(http.MethodGet), setAuditBackend(prometheus))
 	registerFunc(clusterRouter, "/config/rules/region/{region}/detail", rulesHandler.CheckRegionPlacementRule, setMethods(http.MethodGet), setAuditBackend(prometheus))
 	registerFunc(clusterRouter, "/config/rules/key/{key}", rulesHandler.GetRulesByKey, setMethods(http.MethodGet), setAuditBackend(prometheus))
+	registerFunc(clusterRouter, "/config/rules/compliance", rulesHandler.GetComplianceReport, setMethods(http.MethodGet), setAuditBackend(prometheus))
+	registerFunc(clusterRouter, "/config/rules/versions", rulesHandler.GetRuleVersions, setMethods(http.MethodGet), setAuditBackend(prometheus))
+	registerFunc(clusterRouter, "/config/rules/versions/diff", rulesHandler.DiffRuleVersions, setMethods(http.MethodGet), setAuditBackend(prometheus))
+	registerFunc(clusterRouter, "/config/rules/versions/{version}", rulesHandler.GetRuleVersion, setMethods(http.MethodGet), setAuditBackend(prometheus))
+	registerFunc(clusterRouter, "/config/rules/versions/{version}/rollback", rulesHandler.RollbackRuleVersion, setMethods(http.MethodPost), setAuditBackend(localLog, prometheus))
 	registerFunc(clusterRouter, "/config/rule/{group}/{id}", rulesHandler.GetRuleByGroupAndID, setMethods(http.MethodGet), setAuditBackend(prometheus))
 	registerFunc(clusterRouter, "/config/rule", rulesHandler.SetRule, setMethods(http.MethodPost), setAuditBackend(localLog, prometheus))
 	registerFunc(clusterRouter, "/config/rule/{group}/{id}", rulesHandler.DeleteRuleByGroup, setMethods(http.MethodDelete), setAuditBackend(localLog, prometheus))
@@ -204,6 +219,7 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	registerFunc(clusterRouter, "/config/region-label/rules", regionLabelHandler.PatchRegionLabelRules, setMethods(http.MethodPatch), setAuditBackend(localLog, prometheus))
 	registerFunc(clusterRouter, "/region/id/{id}/label/{key}", regionLabelHandler.GetRegionLabelByKey, setMethods(http.MethodGet), setAuditBackend(prometheus))
 	registerFunc(clusterRouter, "/region/id/{id}/labels", regionLabelHandler.GetRegionLabels, setMethods(http.MethodGet), setAuditBackend(prometheus))
+	registerFunc(escapeRouter, "/regions/label/{key}/{value}", regionLabelHandler.GetRegionsByLabel, setMethods(http.MethodGet), setAuditBackend(prometheus))
 
 	storeHandler := newStoreHandler(handler, rd)
 	registerFunc(clusterRouter, "/store/{id}", storeHandler.GetStore, setMethods(http.MethodGet), setAuditBackend(prometheus))
@@ -344,6 +360,7 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	// service GC safepoint API
 	serviceGCSafepointHandler := newServiceGCSafepointHandler(svr, rd)
 	registerFunc(apiRouter, "/gc/safepoint", serviceGCSafepointHandler.GetGCSafePoint, setMethods(http.MethodGet), setAuditBackend(prometheus))
+	registerFunc(apiRouter, "/gc/safepoint/diagnose", serviceGCSafepointHandler.DiagnoseGCSafePoint, setMethods(http.MethodGet), setAuditBackend(prometheus))
 	registerFunc(apiRouter, "/gc/safepoint/{service_id}", serviceGCSafepointHandler.DeleteGCSafePoint, setMethods(http.MethodDelete), setAuditBackend(localLog, prometheus))
 
 	// min resolved ts API
@@ -65,11 +65,12 @@ func (h *ruleHandler) GetAllRules(w http.ResponseWriter, r *http.Request) {
 // @Tags     rule
 // @Summary  Set all rules for the cluster. If there is an error, modifications are promised to be rollback in memory, but may fail to rollback disk. You probably want to request again to make rules in memory/disk consistent.
 // @Produce  json
-// @Param    rules  body      []placement.Rule  true  "Parameters of rules"
-// @Success  200    {string}  string            "Update rules successfully."
-// @Failure  400    {string}  string            "The input is invalid."
-// @Failure  412    {string}  string            "Placement rules feature is disabled."
-// @Failure  500    {string}  string            "PD server failed to proceed the request."
+// @Param    rules    body      []placement.Rule  true   "Parameters of rules"
+// @Param    dry_run  query     boolean           false  "Only estimate the impact of the rules without applying them"
+// @Success  200      {string}  string            "Update rules successfully."
+// @Failure  400      {string}  string            "The input is invalid."
+// @Failure  412      {string}  string            "Placement rules feature is disabled."
+// @Failure  500      {string}  string            "PD server failed to proceed the request."
 // @Router   /config/rules [get]
 func (h *ruleHandler) SetAllRules(w http.ResponseWriter, r *http.Request) {
 	cluster := getCluster(r)
@@ -81,14 +82,24 @@ func (h *ruleHandler) SetAllRules(w http.ResponseWriter, r *http.Request) {
 	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &rules); err != nil {
 		return
 	}
+	if dryRun, ok := h.parseDryRun(w, r); !ok {
+		return
+	} else if dryRun {
+		preview, err := cluster.GetRuleManager().SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).
+			PreviewRules(rules)
+		h.respondRuleImpact(w, cluster, preview, err)
+		return
+	}
 	for _, v := range rules {
 		if err := h.syncReplicateConfigWithDefaultRule(v); err != nil {
 			h.rd.JSON(w, http.StatusBadRequest, err.Error())
 			return
 		}
 	}
-	if err := cluster.GetRuleManager().SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).
-		SetRules(rules); err != nil {
+	manager := cluster.GetRuleManager()
+	if err := manager.WithVersionSource(ruleVersionSource(r), func() error {
+		return manager.SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).SetRules(rules)
+	}); err != nil {
 		if errs.ErrRuleContent.Equal(err) || errs.ErrHexDecodingString.Equal(err) {
 			h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		} else {
@@ -224,7 +235,8 @@ func (h *ruleHandler) GetRuleByGroupAndID(w http.ResponseWriter, r *http.Request
 // @Tags     rule
 // @Summary  Update rule of cluster.
 // @Accept   json
-// @Param    rule  body  placement.Rule  true  "Parameters of rule"
+// @Param    rule     body   placement.Rule  true   "Parameters of rule"
+// @Param    dry_run  query  boolean         false  "Only estimate the impact of the rule without applying it"
 // @Produce  json
 // @Success  200  {string}  string  "Update rule successfully."
 // @Failure  400  {string}  string  "The input is invalid."
@@ -241,13 +253,23 @@ func (h *ruleHandler) SetRule(w http.ResponseWriter, r *http.Request) {
 	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &rule); err != nil {
 		return
 	}
+	if dryRun, ok := h.parseDryRun(w, r); !ok {
+		return
+	} else if dryRun {
+		preview, err := cluster.GetRuleManager().SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).
+			PreviewRules([]*placement.Rule{&rule})
+		h.respondRuleImpact(w, cluster, preview, err)
+		return
+	}
 	oldRule := cluster.GetRuleManager().GetRule(rule.GroupID, rule.ID)
 	if err := h.syncReplicateConfigWithDefaultRule(&rule); err != nil {
 		h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	if err := cluster.GetRuleManager().SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).
-		SetRule(&rule); err != nil {
+	manager := cluster.GetRuleManager()
+	if err := manager.WithVersionSource(ruleVersionSource(r), func() error {
+		return manager.SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).SetRule(&rule)
+	}); err != nil {
 		if errs.ErrRuleContent.Equal(err) || errs.ErrHexDecodingString.Equal(err) {
 			h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		} else {
@@ -275,6 +297,31 @@ func (h *ruleHandler) syncReplicateConfigWithDefaultRule(rule *placement.Rule) e
 	return nil
 }
 
+// parseDryRun parses the dry_run query parameter, and responds 400 if it is invalid.
+func (h *ruleHandler) parseDryRun(w http.ResponseWriter, r *http.Request) (dryRun bool, ok bool) {
+	if str := r.URL.Query().Get("dry_run"); str != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(str); err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return false, false
+		}
+	}
+	return dryRun, true
+}
+
+// respondRuleImpact responds the estimated impact of the rules in preview on the current regions.
+func (h *ruleHandler) respondRuleImpact(w http.ResponseWriter, rc *cluster.RaftCluster, preview *placement.RulePreview, err error) {
+	if err != nil {
+		if errs.ErrRuleContent.Equal(err) || errs.ErrHexDecodingString.Equal(err) {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		} else {
+			h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, preview.EstimateImpact(rc, rc.GetRegions()))
+}
+
 // @Tags     rule
 // @Summary  Delete rule of cluster.
 // @Param    group  path  string  true  "The name of group"
@@ -292,7 +339,10 @@ func (h *ruleHandler) DeleteRuleByGroup(w http.ResponseWriter, r *http.Request)
 	}
 	group, id := mux.Vars(r)["group"], mux.Vars(r)["id"]
 	rule := cluster.GetRuleManager().GetRule(group, id)
-	if err := cluster.GetRuleManager().DeleteRule(group, id); err != nil {
+	manager := cluster.GetRuleManager()
+	if err := manager.WithVersionSource(ruleVersionSource(r), func() error {
+		return manager.DeleteRule(group, id)
+	}); err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -306,7 +356,8 @@ func (h *ruleHandler) DeleteRuleByGroup(w http.ResponseWriter, r *http.Request)
 // @Tags     rule
 // @Summary  Batch operations for the cluster. Operations should be independent(different ID). If there is an error, modifications are promised to be rollback in memory, but may fail to rollback disk. You probably want to request again to make rules in memory/disk consistent.
 // @Produce  json
-// @Param    operations  body      []placement.RuleOp  true  "Parameters of rule operations"
+// @Param    operations  body      []placement.RuleOp  true   "Parameters of rule operations"
+// @Param    dry_run     query     boolean             false  "Only estimate the impact of the operations without applying them"
 // @Success  200         {string}  string              "Batch operations successfully."
 // @Failure  400         {string}  string              "The input is invalid."
 // @Failure  412         {string}  string              "Placement rules feature is disabled."
@@ -322,8 +373,18 @@ func (h *ruleHandler) BatchRules(w http.ResponseWriter, r *http.Request) {
 	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &opts); err != nil {
 		return
 	}
-	if err := cluster.GetRuleManager().SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).
-		Batch(opts); err != nil {
+	if dryRun, ok := h.parseDryRun(w, r); !ok {
+		return
+	} else if dryRun {
+		preview, err := cluster.GetRuleManager().SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).
+			PreviewBatch(opts)
+		h.respondRuleImpact(w, cluster, preview, err)
+		return
+	}
+	manager := cluster.GetRuleManager()
+	if err := manager.WithVersionSource(ruleVersionSource(r), func() error {
+		return manager.SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).Batch(opts)
+	}); err != nil {
 		if errs.ErrRuleContent.Equal(err) || errs.ErrHexDecodingString.Equal(err) {
 			h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		} else {
@@ -377,7 +438,10 @@ func (h *ruleHandler) SetGroupConfig(w http.ResponseWriter, r *http.Request) {
 	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &ruleGroup); err != nil {
 		return
 	}
-	if err := cluster.GetRuleManager().SetRuleGroup(&ruleGroup); err != nil {
+	manager := cluster.GetRuleManager()
+	if err := manager.WithVersionSource(ruleVersionSource(r), func() error {
+		return manager.SetRuleGroup(&ruleGroup)
+	}); err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -402,8 +466,10 @@ func (h *ruleHandler) DeleteGroupConfig(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	id := mux.Vars(r)["id"]
-	err := cluster.GetRuleManager().DeleteRuleGroup(id)
-	if err != nil {
+	manager := cluster.GetRuleManager()
+	if err := manager.WithVersionSource(ruleVersionSource(r), func() error {
+		return manager.DeleteRuleGroup(id)
+	}); err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -447,7 +513,8 @@ func (h *ruleHandler) GetPlacementRules(w http.ResponseWriter, r *http.Request)
 
 // @Tags     rule
 // @Summary  Update all rules and groups configuration.
-// @Param    partial  query  bool  false  "if partially update rules"  default(false)
+// @Param    partial  query  bool     false  "if partially update rules"  default(false)
+// @Param    dry_run  query  boolean  false  "Only estimate the impact of the rules without applying them"
 // @Produce  json
 // @Success  200  {string}  string  "Update rules and groups successfully."
 // @Failure  400  {string}  string  "The input is invalid."
@@ -465,8 +532,18 @@ func (h *ruleHandler) SetPlacementRules(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	_, partial := r.URL.Query()["partial"]
-	if err := cluster.GetRuleManager().SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).
-		SetAllGroupBundles(groups, !partial); err != nil {
+	if dryRun, ok := h.parseDryRun(w, r); !ok {
+		return
+	} else if dryRun {
+		preview, err := cluster.GetRuleManager().SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).
+			PreviewAllGroupBundles(groups, !partial)
+		h.respondRuleImpact(w, cluster, preview, err)
+		return
+	}
+	manager := cluster.GetRuleManager()
+	if err := manager.WithVersionSource(ruleVersionSource(r), func() error {
+		return manager.SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).SetAllGroupBundles(groups, !partial)
+	}); err != nil {
 		if errs.ErrRuleContent.Equal(err) || errs.ErrHexDecodingString.Equal(err) {
 			h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		} else {
@@ -516,7 +593,10 @@ func (h *ruleHandler) DeletePlacementRuleByGroup(w http.ResponseWriter, r *http.
 		return
 	}
 	_, regex := r.URL.Query()["regexp"]
-	if err := cluster.GetRuleManager().DeleteGroupBundle(group, regex); err != nil {
+	manager := cluster.GetRuleManager()
+	if err := manager.WithVersionSource(ruleVersionSource(r), func() error {
+		return manager.DeleteGroupBundle(group, regex)
+	}); err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -525,6 +605,7 @@ func (h *ruleHandler) DeletePlacementRuleByGroup(w http.ResponseWriter, r *http.
 
 // @Tags     rule
 // @Summary  Update group and all rules belong to it.
+// @Param    dry_run  query  boolean  false  "Only estimate the impact of the rules without applying them"
 // @Produce  json
 // @Success  200  {string}  string  "Update group and rules successfully."
 // @Failure  400  {string}  string  "The input is invalid."
@@ -549,8 +630,18 @@ func (h *ruleHandler) SetPlacementRuleByGroup(w http.ResponseWriter, r *http.Req
 		h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("group id %s does not match request URI %s", group.ID, groupID))
 		return
 	}
-	if err := cluster.GetRuleManager().SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).
-		SetGroupBundle(group); err != nil {
+	if dryRun, ok := h.parseDryRun(w, r); !ok {
+		return
+	} else if dryRun {
+		preview, err := cluster.GetRuleManager().SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).
+			PreviewGroupBundle(group)
+		h.respondRuleImpact(w, cluster, preview, err)
+		return
+	}
+	manager := cluster.GetRuleManager()
+	if err := manager.WithVersionSource(ruleVersionSource(r), func() error {
+		return manager.SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).SetGroupBundle(group)
+	}); err != nil {
 		if errs.ErrRuleContent.Equal(err) || errs.ErrHexDecodingString.Equal(err) {
 			h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		} else {
@@ -560,3 +651,149 @@ func (h *ruleHandler) SetPlacementRuleByGroup(w http.ResponseWriter, r *http.Req
 	}
 	h.rd.JSON(w, http.StatusOK, "Update group and rules successfully.")
 }
+
+// ruleVersionSource returns the source of the request, which is used to annotate the rule versions.
+func ruleVersionSource(r *http.Request) string {
+	return fmt.Sprintf("%s@%s", apiutil.GetComponentNameOnHTTP(r), apiutil.GetIPAddrFromHTTPRequest(r))
+}
+
+// @Tags     rule
+// @Summary  List all reserved versions of the rules and rule groups.
+// @Produce  json
+// @Success  200  {array}   placement.RuleVersion
+// @Failure  412  {string}  string  "Placement rules feature is disabled."
+// @Router   /config/rules/versions [get]
+func (h *ruleHandler) GetRuleVersions(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r)
+	if !cluster.GetOpts().IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, cluster.GetRuleManager().GetRuleVersions())
+}
+
+// @Tags     rule
+// @Summary  Get the rules and rule groups of a version.
+// @Param    version  path  integer  true  "The version of the rules"
+// @Produce  json
+// @Success  200  {object}  placement.RuleVersion
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  404  {string}  string  "The version does not exist."
+// @Failure  412  {string}  string  "Placement rules feature is disabled."
+// @Router   /config/rules/versions/{version} [get]
+func (h *ruleHandler) GetRuleVersion(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r)
+	if !cluster.GetOpts().IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	version, err := strconv.ParseUint(mux.Vars(r)["version"], 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rv := cluster.GetRuleManager().GetRuleVersion(version)
+	if rv == nil {
+		h.rd.JSON(w, http.StatusNotFound, errs.ErrRuleVersionNotFound.FastGenByArgs(version).Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, rv)
+}
+
+// @Tags     rule
+// @Summary  Get the rules and rule groups changed from one version to another.
+// @Param    from  query  integer  true  "The version to compare from"
+// @Param    to    query  integer  true  "The version to compare to"
+// @Produce  json
+// @Success  200  {object}  placement.RuleVersionDiff
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  404  {string}  string  "The version does not exist."
+// @Failure  412  {string}  string  "Placement rules feature is disabled."
+// @Router   /config/rules/versions/diff [get]
+func (h *ruleHandler) DiffRuleVersions(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r)
+	if !cluster.GetOpts().IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	query := r.URL.Query()
+	from, err := strconv.ParseUint(query.Get("from"), 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := strconv.ParseUint(query.Get("to"), 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	diff, err := cluster.GetRuleManager().DiffRuleVersions(from, to)
+	if err != nil {
+		h.rd.JSON(w, http.StatusNotFound, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, diff)
+}
+
+// @Tags     rule
+// @Summary  Roll back the rules and rule groups to a version.
+// @Param    version  path  integer  true  "The version of the rules"
+// @Produce  json
+// @Success  200  {string}  string  "Roll back rules successfully."
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  404  {string}  string  "The version does not exist."
+// @Failure  412  {string}  string  "Placement rules feature is disabled."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /config/rules/versions/{version}/rollback [post]
+func (h *ruleHandler) RollbackRuleVersion(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r)
+	if !cluster.GetOpts().IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	version, err := strconv.ParseUint(mux.Vars(r)["version"], 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	manager := cluster.GetRuleManager()
+	oldRules := manager.GetAllRules()
+	if err := manager.WithVersionSource(ruleVersionSource(r), func() error {
+		return manager.SetKeyType(h.svr.GetConfig().PDServerCfg.KeyType).RollbackRuleVersion(version)
+	}); err != nil {
+		switch {
+		case errs.ErrRuleVersionNotFound.Equal(err):
+			h.rd.JSON(w, http.StatusNotFound, err.Error())
+		case errs.ErrRuleContent.Equal(err) || errs.ErrHexDecodingString.Equal(err):
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		default:
+			h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	for _, rule := range append(oldRules, manager.GetAllRules()...) {
+		cluster.AddSuspectKeyRange(rule.StartKey, rule.EndKey)
+	}
+	h.rd.JSON(w, http.StatusOK, "Roll back rules successfully.")
+}
+
+// @Tags     rule
+// @Summary  Get the report of the regions violating the placement rules, which is generated periodically.
+// @Produce  json
+// @Success  200  {object}  placement.ComplianceReport
+// @Failure  404  {string}  string  "The report is not generated yet."
+// @Failure  412  {string}  string  "Placement rules feature is disabled."
+// @Router   /config/rules/compliance [get]
+func (h *ruleHandler) GetComplianceReport(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r)
+	if !cluster.GetOpts().IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	report := cluster.GetComplianceReport()
+	if report == nil {
+		h.rd.JSON(w, http.StatusNotFound, "the compliance report is not generated yet")
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, report)
+}
@@ -836,6 +836,108 @@ func (suite *ruleTestSuite) TestBundleBadRequest() {
 	}
 }
 
+func (suite *ruleTestSuite) TestDryRun() {
+	re := suite.Require()
+	rule := placement.Rule{GroupID: "pd", ID: "default", Role: "voter", Count: 5}
+	data, err := json.Marshal(rule)
+	suite.NoError(err)
+	var impact placement.RuleImpact
+	err = tu.CheckPostJSON(testDialClient, suite.urlPrefix+"/rule?dry_run=true", data, tu.StatusOK(re), tu.ExtractJSON(re, &impact))
+	suite.NoError(err)
+	// All regions need to add peers since there are not enough peers.
+	suite.Positive(impact.RegionCount)
+	suite.Equal(impact.RegionCount, impact.AddPeer.Count)
+	// The rule is not applied.
+	var got placement.Rule
+	err = tu.ReadGetJSON(re, testDialClient, suite.urlPrefix+"/rule/pd/default", &got)
+	suite.NoError(err)
+	suite.Equal(3, got.Count)
+
+	// Batch
+	ops, err := json.Marshal([]placement.RuleOp{{Rule: &rule, Action: placement.RuleOpAdd}})
+	suite.NoError(err)
+	impact = placement.RuleImpact{}
+	err = tu.CheckPostJSON(testDialClient, suite.urlPrefix+"/rules/batch?dry_run=true", ops, tu.StatusOK(re), tu.ExtractJSON(re, &impact))
+	suite.NoError(err)
+	suite.Equal(impact.RegionCount, impact.AddPeer.Count)
+
+	// Bundle
+	bundle, err := json.Marshal(placement.GroupBundle{ID: "pd", Rules: []*placement.Rule{&rule}})
+	suite.NoError(err)
+	impact = placement.RuleImpact{}
+	err = tu.CheckPostJSON(testDialClient, suite.urlPrefix+"/placement-rule/pd?dry_run=true", bundle, tu.StatusOK(re), tu.ExtractJSON(re, &impact))
+	suite.NoError(err)
+	suite.Equal(impact.RegionCount, impact.AddPeer.Count)
+	err = tu.ReadGetJSON(re, testDialClient, suite.urlPrefix+"/rule/pd/default", &got)
+	suite.NoError(err)
+	suite.Equal(3, got.Count)
+
+	// Bad request
+	err = tu.CheckPostJSON(testDialClient, suite.urlPrefix+"/rule?dry_run=foo", data, tu.Status(re, http.StatusBadRequest))
+	suite.NoError(err)
+	rule.Count = -1
+	data, err = json.Marshal(rule)
+	suite.NoError(err)
+	err = tu.CheckPostJSON(testDialClient, suite.urlPrefix+"/rule?dry_run=true", data, tu.Status(re, http.StatusBadRequest))
+	suite.NoError(err)
+}
+
+func (suite *ruleTestSuite) TestRuleVersions() {
+	re := suite.Require()
+	var versions []placement.RuleVersion
+	err := tu.ReadGetJSON(re, testDialClient, suite.urlPrefix+"/rules/versions", &versions)
+	suite.NoError(err)
+	suite.NotEmpty(versions)
+	base := versions[len(versions)-1].Version
+
+	rule := placement.Rule{GroupID: "a", ID: "10", StartKeyHex: "1111", EndKeyHex: "3333", Role: "voter", Count: 1}
+	data, err := json.Marshal(rule)
+	suite.NoError(err)
+	err = tu.CheckPostJSON(testDialClient, suite.urlPrefix+"/rule", data, tu.StatusOK(re))
+	suite.NoError(err)
+	err = tu.ReadGetJSON(re, testDialClient, suite.urlPrefix+"/rules/versions", &versions)
+	suite.NoError(err)
+	latest := versions[len(versions)-1]
+	suite.Equal(base+1, latest.Version)
+	suite.Contains(latest.Source, "anonymous@")
+
+	// Get
+	var rv placement.RuleVersion
+	err = tu.ReadGetJSON(re, testDialClient, fmt.Sprintf("%s/rules/versions/%d", suite.urlPrefix, latest.Version), &rv)
+	suite.NoError(err)
+	suite.Len(rv.Rules, 2)
+	err = tu.CheckGetJSON(testDialClient, suite.urlPrefix+"/rules/versions/100000", nil, tu.Status(re, http.StatusNotFound))
+	suite.NoError(err)
+
+	// Diff
+	var diff placement.RuleVersionDiff
+	err = tu.ReadGetJSON(re, testDialClient, fmt.Sprintf("%s/rules/versions/diff?from=%d&to=%d", suite.urlPrefix, base, latest.Version), &diff)
+	suite.NoError(err)
+	suite.Len(diff.Rules, 1)
+	suite.Nil(diff.Rules[0].Old)
+	suite.Equal([2]string{"a", "10"}, diff.Rules[0].New.Key())
+	err = tu.CheckGetJSON(testDialClient, suite.urlPrefix+"/rules/versions/diff?from=foo&to=1", nil, tu.Status(re, http.StatusBadRequest))
+	suite.NoError(err)
+
+	// Rollback
+	err = tu.CheckPostJSON(testDialClient, fmt.Sprintf("%s/rules/versions/%d/rollback", suite.urlPrefix, base), nil, tu.StatusOK(re))
+	suite.NoError(err)
+	err = tu.CheckGetJSON(testDialClient, suite.urlPrefix+"/rule/a/10", nil, tu.Status(re, http.StatusNotFound))
+	suite.NoError(err)
+	err = tu.ReadGetJSON(re, testDialClient, suite.urlPrefix+"/rules/versions", &versions)
+	suite.NoError(err)
+	suite.Equal(base+2, versions[len(versions)-1].Version)
+	err = tu.CheckPostJSON(testDialClient, suite.urlPrefix+"/rules/versions/100000/rollback", nil, tu.Status(re, http.StatusNotFound))
+	suite.NoError(err)
+}
+
+func (suite *ruleTestSuite) TestComplianceReport() {
+	re := suite.Require()
+	// the report is generated periodically.
+	err := tu.CheckGetJSON(testDialClient, suite.urlPrefix+"/rules/compliance", nil, tu.Status(re, http.StatusNotFound))
+	suite.NoError(err)
+}
+
 func (suite *ruleTestSuite) compareBundle(b1, b2 placement.GroupBundle) {
 	suite.Equal(b2.ID, b1.ID)
 	suite.Equal(b2.Index, b1.Index)
@@ -25,6 +25,7 @@ import (
 	"github.com/tikv/pd/pkg/apiutil"
 	"github.com/tikv/pd/pkg/errs"
 	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/schedule/window"
 	"github.com/tikv/pd/server/schedulers"
 	"github.com/unrolled/render"
 )
@@ -45,6 +46,12 @@ func newSchedulerHandler(svr *server.Server, r *render.Render) *schedulerHandler
 	}
 }
 
+// schedulerWindowStatus is the scheduling window status of a scheduler.
+type schedulerWindowStatus struct {
+	Name string `json:"name"`
+	*window.Status
+}
+
 type schedulerPausedPeriod struct {
 	Name     string    `json:"name"`
 	PausedAt time.Time `json:"paused_at"`
@@ -53,6 +60,8 @@ type schedulerPausedPeriod struct {
 
 // @Tags     scheduler
 // @Summary  List all created schedulers by status.
+// @Param    status  query  string  false  "The status of the schedulers, can be paused, disabled or dry-run."
+// @Param    window  query  bool    false  "Whether to show the current scheduling window of the schedulers."
 // @Produce  json
 // @Success  200  {array}   string
 // @Failure  500  {string}  string  "PD server failed to proceed the request."
@@ -122,7 +131,34 @@ func (h *schedulerHandler) GetSchedulers(w http.ResponseWriter, r *http.Request)
 			}
 		}
 		h.r.JSON(w, http.StatusOK, disabledSchedulers)
+	case "dry-run":
+		var dryRunSchedulers []string
+		for _, scheduler := range schedulers {
+			dryRun, err := h.Handler.IsSchedulerDryRun(scheduler)
+			if err != nil {
+				h.r.JSON(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+
+			if dryRun {
+				dryRunSchedulers = append(dryRunSchedulers, scheduler)
+			}
+		}
+		h.r.JSON(w, http.StatusOK, dryRunSchedulers)
 	default:
+		if _, windowFlag := r.URL.Query()["window"]; windowFlag {
+			windowStatuses := make([]schedulerWindowStatus, 0, len(schedulers))
+			for _, scheduler := range schedulers {
+				status, err := h.Handler.GetSchedulingWindowStatus(scheduler)
+				if err != nil {
+					h.r.JSON(w, http.StatusInternalServerError, err.Error())
+					return
+				}
+				windowStatuses = append(windowStatuses, schedulerWindowStatus{Name: scheduler, Status: status})
+			}
+			h.r.JSON(w, http.StatusOK, windowStatuses)
+			return
+		}
 		h.r.JSON(w, http.StatusOK, schedulers)
 	}
 }
@@ -229,6 +265,11 @@ func (h *schedulerHandler) CreateScheduler(w http.ResponseWriter, r *http.Reques
 			h.r.JSON(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+	case schedulers.BalanceKeyspaceName:
+		if err := h.AddBalanceKeyspaceScheduler(); err != nil {
+			h.r.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
 	case schedulers.SplitBucketName:
 		if err := h.AddSplitBucketScheduler(); err != nil {
 			h.r.JSON(w, http.StatusInternalServerError, err.Error())
@@ -345,6 +386,54 @@ func (h *schedulerHandler) PauseOrResumeScheduler(w http.ResponseWriter, r *http
 	h.r.JSON(w, http.StatusOK, "Pause or resume the scheduler successfully.")
 }
 
+// FIXME: details of input json body params
+// @Tags     scheduler
+// @Summary  Enable or disable the dry-run mode of a scheduler.
+// @Accept   json
+// @Param    name  path  string  true  "The name of the scheduler."
+// @Param    body  body  object  true  "json params"
+// @Produce  json
+// @Success  200  {string}  string  "Set the dry-run mode of the scheduler successfully."
+// @Failure  400  {string}  string  "Bad format request."
+// @Failure  404  {string}  string  "The scheduler is not found."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /schedulers/dry-run/{name} [post]
+func (h *schedulerHandler) SetSchedulerDryRun(w http.ResponseWriter, r *http.Request) {
+	var input map[string]bool
+	if err := apiutil.ReadJSONRespondError(h.r, w, r.Body, &input); err != nil {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	enable, ok := input["enable"]
+	if !ok {
+		h.r.JSON(w, http.StatusBadRequest, "missing enable")
+		return
+	}
+	if err := h.Handler.SetSchedulerDryRun(name, enable); err != nil {
+		h.handleErr(w, err)
+		return
+	}
+	h.r.JSON(w, http.StatusOK, "Set the dry-run mode of the scheduler successfully.")
+}
+
+// @Tags     scheduler
+// @Summary  Get the operators recorded by a scheduler in dry-run mode.
+// @Param    name  path  string  true  "The name of the scheduler."
+// @Produce  json
+// @Success  200  {object}  cluster.DryRunResult
+// @Failure  404  {string}  string  "The scheduler is not found."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /schedulers/dry-run/{name} [get]
+func (h *schedulerHandler) GetSchedulerDryRunResult(w http.ResponseWriter, r *http.Request) {
+	result, err := h.Handler.GetSchedulerDryRunResult(mux.Vars(r)["name"])
+	if err != nil {
+		h.handleErr(w, err)
+		return
+	}
+	h.r.JSON(w, http.StatusOK, result)
+}
+
 type schedulerConfigHandler struct {
 	svr *server.Server
 	rd  *render.Render
@@ -27,6 +27,7 @@ import (
 	"github.com/tikv/pd/pkg/apiutil"
 	tu "github.com/tikv/pd/pkg/testutil"
 	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/cluster"
 	"github.com/tikv/pd/server/config"
 	_ "github.com/tikv/pd/server/schedulers"
 )
@@ -320,6 +321,57 @@ func (suite *scheduleTestSuite) TestAPI() {
 				suite.Equal(404, statusCode)
 			},
 		},
+		{
+			name: "evict-slow-store-scheduler",
+			extraTestFunc: func(name string) {
+				resp := make(map[string]interface{})
+				listURL := fmt.Sprintf("%s%s%s/%s/list", suite.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
+				suite.NoError(tu.ReadGetJSON(re, testDialClient, listURL, &resp))
+				suite.Equal(1.0, resp["max-evicted-stores"])
+				suite.Equal("10m0s", resp["recover-duration"])
+				suite.Empty(resp["evict-stores"])
+
+				updateURL := fmt.Sprintf("%s%s%s/%s/config", suite.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
+				body := []byte(`{"max-evicted-stores": 3, "recover-duration": "30m"}`)
+				suite.NoError(tu.CheckPostJSON(testDialClient, updateURL, body, tu.StatusOK(re)))
+				resp = make(map[string]interface{})
+				suite.NoError(tu.ReadGetJSON(re, testDialClient, listURL, &resp))
+				suite.Equal(3.0, resp["max-evicted-stores"])
+				suite.Equal("30m0s", resp["recover-duration"])
+				// invalid config
+				suite.NoError(tu.CheckPostJSON(testDialClient, updateURL, []byte(`{"max-evicted-stores": 0}`), tu.Status(re, http.StatusBadRequest)))
+				suite.NoError(tu.CheckPostJSON(testDialClient, updateURL, []byte(`{"recover-duration": "-1m"}`), tu.Status(re, http.StatusBadRequest)))
+				suite.NoError(tu.CheckPostJSON(testDialClient, updateURL, []byte(`{"evict-stores": [1]}`), tu.Status(re, http.StatusBadRequest)))
+				resp = make(map[string]interface{})
+				suite.NoError(tu.ReadGetJSON(re, testDialClient, listURL, &resp))
+				suite.Equal(3.0, resp["max-evicted-stores"])
+				suite.Empty(resp["evict-stores"])
+			},
+		},
+		{
+			name: "balance-keyspace-scheduler",
+			extraTestFunc: func(name string) {
+				resp := make(map[string]interface{})
+				listURL := fmt.Sprintf("%s%s%s/%s/list", suite.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
+				suite.NoError(tu.ReadGetJSON(re, testDialClient, listURL, &resp))
+				suite.Empty(resp["include-keyspaces"])
+				suite.Empty(resp["exclude-keyspaces"])
+				suite.Empty(resp["keyspace-priorities"])
+				suite.Equal(0.1, resp["tolerance-ratio"])
+
+				updateURL := fmt.Sprintf("%s%s%s/%s/config", suite.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
+				body := []byte(`{"include-keyspaces": [1, 2], "keyspace-priorities": {"2": 10}, "tolerance-ratio": 0.2}`)
+				suite.NoError(tu.CheckPostJSON(testDialClient, updateURL, body, tu.StatusOK(re)))
+				resp = make(map[string]interface{})
+				suite.NoError(tu.ReadGetJSON(re, testDialClient, listURL, &resp))
+				suite.Equal([]interface{}{1.0, 2.0}, resp["include-keyspaces"])
+				suite.Equal(map[string]interface{}{"2": 10.0}, resp["keyspace-priorities"])
+				suite.Equal(0.2, resp["tolerance-ratio"])
+				// invalid config
+				suite.NoError(tu.CheckPostJSON(testDialClient, updateURL, []byte(`{"tolerance-ratio": 0}`), tu.Status(re, http.StatusBadRequest)))
+				suite.NoError(tu.CheckPostJSON(testDialClient, updateURL, []byte(`{"error": 1}`), tu.Status(re, http.StatusBadRequest)))
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		input := make(map[string]interface{})
@@ -453,6 +505,48 @@ func (suite *scheduleTestSuite) TestDisable() {
 	suite.deleteScheduler(name)
 }
 
+func (suite *scheduleTestSuite) TestDryRun() {
+	name := "shuffle-leader-scheduler"
+	input := make(map[string]interface{})
+	input["name"] = name
+	body, err := json.Marshal(input)
+	suite.NoError(err)
+	suite.addScheduler(body)
+
+	re := suite.Require()
+	u := fmt.Sprintf("%s/dry-run/%s", suite.urlPrefix, name)
+	body, err = json.Marshal(map[string]interface{}{"enable": true})
+	suite.NoError(err)
+	err = tu.CheckPostJSON(testDialClient, u, body, tu.StatusOK(re))
+	suite.NoError(err)
+
+	var schedulers []string
+	err = tu.ReadGetJSON(re, testDialClient, fmt.Sprintf("%s?status=dry-run", suite.urlPrefix), &schedulers)
+	suite.NoError(err)
+	suite.Equal([]string{name}, schedulers)
+	var result cluster.DryRunResult
+	err = tu.ReadGetJSON(re, testDialClient, u, &result)
+	suite.NoError(err)
+	suite.Equal(name, result.Name)
+	suite.True(result.DryRun)
+
+	body, err = json.Marshal(map[string]interface{}{"enable": false})
+	suite.NoError(err)
+	err = tu.CheckPostJSON(testDialClient, u, body, tu.StatusOK(re))
+	suite.NoError(err)
+	err = tu.ReadGetJSON(re, testDialClient, fmt.Sprintf("%s?status=dry-run", suite.urlPrefix), &schedulers)
+	suite.NoError(err)
+	suite.Empty(schedulers)
+
+	// Bad request and non-existing scheduler.
+	err = tu.CheckPostJSON(testDialClient, u, []byte("{}"), tu.Status(re, http.StatusBadRequest))
+	suite.NoError(err)
+	err = tu.CheckPostJSON(testDialClient, fmt.Sprintf("%s/dry-run/%s", suite.urlPrefix, "test"), body, tu.Status(re, http.StatusNotFound))
+	suite.NoError(err)
+
+	suite.deleteScheduler(name)
+}
+
 func (suite *scheduleTestSuite) addScheduler(body []byte) {
 	err := tu.CheckPostJSON(testDialClient, suite.urlPrefix, body, tu.StatusOK(suite.Require()))
 	suite.NoError(err)
@@ -0,0 +1,120 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/tikv/pd/pkg/apiutil"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/schedule/window"
+	"github.com/unrolled/render"
+)
+
+type schedulingWindowHandler struct {
+	*server.Handler
+	rd *render.Render
+}
+
+func newSchedulingWindowHandler(svr *server.Server, rd *render.Render) *schedulingWindowHandler {
+	return &schedulingWindowHandler{
+		Handler: svr.GetHandler(),
+		rd:      rd,
+	}
+}
+
+// @Tags     scheduling_window
+// @Summary  List the scheduling windows of all schedulers and checkers.
+// @Produce  json
+// @Success  200  {object}  map[string]window.Policy
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /scheduling-windows [get]
+func (h *schedulingWindowHandler) GetAllSchedulingWindows(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.Handler.GetAllSchedulingWindows()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, policies)
+}
+
+// @Tags     scheduling_window
+// @Summary  Get the scheduling windows of a scheduler or checker.
+// @Param    name  path  string  true  "The name of the scheduler or checker."
+// @Produce  json
+// @Success  200  {object}  window.Policy
+// @Failure  404  {string}  string  "The scheduler or checker is not found."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /scheduling-windows/{name} [get]
+func (h *schedulingWindowHandler) GetSchedulingWindows(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.Handler.GetSchedulingWindows(mux.Vars(r)["name"])
+	if err != nil {
+		h.handleErr(w, err)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, policy)
+}
+
+// @Tags     scheduling_window
+// @Summary  Set the scheduling windows of a scheduler or checker. The scheduler or checker only runs within the windows.
+// @Accept   json
+// @Param    name  path  string         true  "The name of the scheduler or checker."
+// @Param    body  body  window.Policy  true  "The scheduling windows, empty windows remove the restriction."
+// @Produce  json
+// @Success  200  {string}  string  "Set the scheduling windows successfully."
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  404  {string}  string  "The scheduler or checker is not found."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /scheduling-windows/{name} [post]
+func (h *schedulingWindowHandler) SetSchedulingWindows(w http.ResponseWriter, r *http.Request) {
+	var policy window.Policy
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &policy); err != nil {
+		return
+	}
+	if err := h.Handler.SetSchedulingWindows(mux.Vars(r)["name"], &policy); err != nil {
+		h.handleErr(w, err)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "Set the scheduling windows successfully.")
+}
+
+// @Tags     scheduling_window
+// @Summary  Remove the scheduling windows of a scheduler or checker.
+// @Param    name  path  string  true  "The name of the scheduler or checker."
+// @Produce  json
+// @Success  200  {string}  string  "Remove the scheduling windows successfully."
+// @Failure  404  {string}  string  "The scheduler or checker is not found."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /scheduling-windows/{name} [delete]
+func (h *schedulingWindowHandler) DeleteSchedulingWindows(w http.ResponseWriter, r *http.Request) {
+	if err := h.Handler.SetSchedulingWindows(mux.Vars(r)["name"], &window.Policy{}); err != nil {
+		h.handleErr(w, err)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "Remove the scheduling windows successfully.")
+}
+
+func (h *schedulingWindowHandler) handleErr(w http.ResponseWriter, err error) {
+	switch {
+	case errs.ErrSchedulingWindow.Equal(err):
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+	case errs.ErrSchedulerNotFound.Equal(err):
+		h.rd.JSON(w, http.StatusNotFound, err.Error())
+	default:
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+	}
+}
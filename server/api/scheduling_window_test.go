@@ -0,0 +1,105 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/suite"
+	"github.com/tikv/pd/pkg/apiutil"
+	tu "github.com/tikv/pd/pkg/testutil"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/schedule/window"
+)
+
+type schedulingWindowTestSuite struct {
+	suite.Suite
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func TestSchedulingWindowTestSuite(t *testing.T) {
+	suite.Run(t, new(schedulingWindowTestSuite))
+}
+
+func (suite *schedulingWindowTestSuite) SetupSuite() {
+	re := suite.Require()
+	suite.svr, suite.cleanup = mustNewServer(re)
+	server.MustWaitLeader(re, []*server.Server{suite.svr})
+
+	addr := suite.svr.GetAddr()
+	suite.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+
+	mustBootstrapCluster(re, suite.svr)
+	mustPutStore(re, suite.svr, 1, metapb.StoreState_Up, metapb.NodeState_Serving, nil)
+}
+
+func (suite *schedulingWindowTestSuite) TearDownSuite() {
+	suite.cleanup()
+}
+
+func (suite *schedulingWindowTestSuite) TestAPI() {
+	re := suite.Require()
+	u := fmt.Sprintf("%s/scheduling-windows/merge", suite.urlPrefix)
+
+	// No windows by default.
+	var policy window.Policy
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, u, &policy))
+	suite.Empty(policy.Windows)
+
+	// Set windows which are never active now.
+	now := time.Now()
+	cron := fmt.Sprintf("0 0 1 %d *", (int(now.Month())+5)%12+1)
+	body := fmt.Sprintf(`{"windows": [{"cron": "%s", "duration": "1h", "limits": {"merge-schedule-limit": 16}}]}`, cron)
+	suite.NoError(tu.CheckPostJSON(testDialClient, u, []byte(body), tu.StatusOK(re)))
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, u, &policy))
+	suite.Len(policy.Windows, 1)
+	suite.Equal(cron, policy.Windows[0].Cron)
+	suite.Equal(time.Hour, policy.Windows[0].Duration.Duration)
+	suite.Equal(map[string]uint64{"merge-schedule-limit": 16}, policy.Windows[0].Limits)
+	var policies map[string]*window.Policy
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, fmt.Sprintf("%s/scheduling-windows", suite.urlPrefix), &policies))
+	suite.Contains(policies, "merge")
+	// The checker is paused out of its windows.
+	output := make(map[string]bool)
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, fmt.Sprintf("%s/checker/merge", suite.urlPrefix), &output))
+	suite.True(output["paused"])
+
+	// Invalid windows and non-existing target.
+	suite.NoError(tu.CheckPostJSON(testDialClient, u, []byte(`{"windows": [{"cron": "0 0 1 1", "duration": "1h"}]}`), tu.Status(re, http.StatusBadRequest)))
+	suite.NoError(tu.CheckPostJSON(testDialClient, u, []byte(`{"windows": [{"cron": "0 0 1 1 *", "duration": "1h", "limits": {"max-snapshot-count": 1}}]}`), tu.Status(re, http.StatusBadRequest)))
+	suite.NoError(tu.CheckPostJSON(testDialClient, fmt.Sprintf("%s/scheduling-windows/test", suite.urlPrefix), []byte(body), tu.Status(re, http.StatusNotFound)))
+
+	// Delete the windows.
+	_, err := apiutil.DoDelete(testDialClient, u)
+	suite.NoError(err)
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, u, &policy))
+	suite.Empty(policy.Windows)
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, fmt.Sprintf("%s/checker/merge", suite.urlPrefix), &output))
+	suite.False(output["paused"])
+
+	// The scheduler list shows the window status.
+	var statuses []map[string]interface{}
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, fmt.Sprintf("%s/schedulers?window=true", suite.urlPrefix), &statuses))
+	for _, status := range statuses {
+		suite.Contains(status, "name")
+		suite.Equal(true, status["active"])
+	}
+}
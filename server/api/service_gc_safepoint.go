@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/tikv/pd/pkg/tsoutil"
 	"github.com/tikv/pd/server"
 	"github.com/tikv/pd/server/storage/endpoint"
 	"github.com/unrolled/render"
@@ -66,6 +67,28 @@ func (h *serviceGCSafepointHandler) GetGCSafePoint(w http.ResponseWriter, r *htt
 	h.rd.JSON(w, http.StatusOK, list)
 }
 
+// @Tags     service_gc_safepoint
+// @Summary  Diagnose which service GC safepoints are holding back the GC.
+// @Produce  json
+// @Success  200  {object}  gc.GCBlockerDiagnosis
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /gc/safepoint/diagnose [get]
+func (h *serviceGCSafepointHandler) DiagnoseGCSafePoint(w http.ResponseWriter, r *http.Request) {
+	nowTS, err := h.svr.GetGlobalTS()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now, _ := tsoutil.ParseTS(nowTS)
+	lagThreshold := h.svr.GetPersistOptions().GetServiceGCSafePointLagThreshold()
+	diagnosis, err := h.svr.GetGCSafePointManager().DiagnoseServiceGCSafePoints(now, lagThreshold)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, diagnosis)
+}
+
 // @Tags     service_gc_safepoint
 // @Summary  Delete a service GC safepoint.
 // @Param    service_id  path  string  true  "Service ID"
@@ -23,7 +23,10 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/stretchr/testify/suite"
 	"github.com/tikv/pd/pkg/apiutil"
+	tu "github.com/tikv/pd/pkg/testutil"
+	"github.com/tikv/pd/pkg/tsoutil"
 	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/gc"
 	"github.com/tikv/pd/server/storage/endpoint"
 )
 
@@ -100,3 +103,44 @@ func (suite *serviceGCSafepointTestSuite) TestServiceGCSafepoint() {
 	suite.NoError(err)
 	suite.Equal(list.ServiceGCSafepoints[1:], left)
 }
+
+func (suite *serviceGCSafepointTestSuite) TestDiagnoseServiceGCSafepoint() {
+	diagnoseURL := suite.urlPrefix + "/gc/safepoint/diagnose"
+
+	storage := suite.svr.GetStorage()
+	now := time.Now()
+	toTS := func(t time.Time) uint64 {
+		return tsoutil.GenerateTS(tsoutil.GenerateTimestamp(t, 0))
+	}
+	ssps := []*endpoint.ServiceSafePoint{
+		{
+			ServiceID: "lagging",
+			ExpiredAt: now.Unix() + 100,
+			SafePoint: toTS(now.Add(-48 * time.Hour)),
+		},
+		{
+			ServiceID: "normal",
+			ExpiredAt: now.Unix() + 100,
+			SafePoint: toTS(now.Add(-time.Hour)),
+		},
+	}
+	for _, ssp := range ssps {
+		suite.NoError(storage.SaveServiceGCSafePoint(ssp))
+	}
+	defer func() {
+		for _, ssp := range ssps {
+			suite.NoError(storage.RemoveServiceGCSafePoint(ssp.ServiceID))
+		}
+	}()
+
+	diagnosis := &gc.GCBlockerDiagnosis{}
+	err := tu.ReadGetJSON(suite.Require(), testDialClient, diagnoseURL, diagnosis)
+	suite.NoError(err)
+	suite.Equal(24*time.Hour, diagnosis.LagThreshold.Duration)
+	lagging := map[string]bool{}
+	for _, ssp := range diagnosis.ServiceSafePoints {
+		lagging[ssp.ServiceID] = ssp.Lagging
+	}
+	suite.True(lagging["lagging"])
+	suite.False(lagging["normal"])
+}
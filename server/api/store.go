@@ -33,6 +33,7 @@ import (
 	"github.com/tikv/pd/server/config"
 	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/core/storelimit"
+	"github.com/tikv/pd/server/statistics"
 	"github.com/unrolled/render"
 )
 
@@ -76,7 +77,9 @@ const (
 	downStateName    = "Down"
 )
 
-func newStoreInfo(opt *config.ScheduleConfig, store *core.StoreInfo) *StoreInfo {
+// newStoreInfo returns the information of the store, the loads of the stores are only used
+// to calculate the leader score under the load-based leader schedule policies.
+func newStoreInfo(opt *config.ScheduleConfig, store *core.StoreInfo, storesLoads map[uint64][]float64) *StoreInfo {
 	s := &StoreInfo{
 		Store: &MetaStore{
 			Store:     store.GetMeta(),
@@ -88,7 +91,7 @@ func newStoreInfo(opt *config.ScheduleConfig, store *core.StoreInfo) *StoreInfo
 			UsedSize:           typeutil.ByteSize(store.GetUsedSize()),
 			LeaderCount:        store.GetLeaderCount(),
 			LeaderWeight:       store.GetLeaderWeight(),
-			LeaderScore:        store.LeaderScore(core.StringToSchedulePolicy(opt.LeaderSchedulePolicy), 0),
+			LeaderScore:        leaderScore(store, core.StringToSchedulePolicy(opt.LeaderSchedulePolicy), storesLoads),
 			LeaderSize:         store.GetLeaderSize(),
 			RegionCount:        store.GetRegionCount(),
 			RegionWeight:       store.GetRegionWeight(),
@@ -124,6 +127,18 @@ func newStoreInfo(opt *config.ScheduleConfig, store *core.StoreInfo) *StoreInfo
 	return s
 }
 
+// leaderScore returns the leader score of the store under the leader schedule policy.
+func leaderScore(store *core.StoreInfo, policy core.SchedulePolicy, storesLoads map[uint64][]float64) float64 {
+	if kind, ok := statistics.LeaderLoadStatKind(policy); ok {
+		var load float64
+		if loads := storesLoads[store.GetID()]; int(kind) < len(loads) {
+			load = loads[kind]
+		}
+		return store.LeaderLoadScore(load, 0)
+	}
+	return store.LeaderScore(policy, 0)
+}
+
 // StoresInfo records stores' info.
 type StoresInfo struct {
 	Count  int          `json:"count"`
@@ -166,7 +181,7 @@ func (h *storeHandler) GetStore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	storeInfo := newStoreInfo(h.handler.GetScheduleConfig(), store)
+	storeInfo := newStoreInfo(h.handler.GetScheduleConfig(), store, rc.GetStoresLoads())
 	h.rd.JSON(w, http.StatusOK, storeInfo)
 }
 
@@ -589,9 +604,9 @@ func (h *storesHandler) GetAllStoresLimit(w http.ResponseWriter, r *http.Request
 			return
 		}
 	}
+	rc := getCluster(r)
 	if !includeTombstone {
 		returned := make(map[uint64]config.StoreLimitConfig, len(limits))
-		rc := getCluster(r)
 		for storeID, v := range limits {
 			store := rc.GetStore(storeID)
 			if store == nil || store.IsRemoved() {
@@ -599,10 +614,33 @@ func (h *storesHandler) GetAllStoresLimit(w http.ResponseWriter, r *http.Request
 			}
 			returned[storeID] = v
 		}
-		h.rd.JSON(w, http.StatusOK, returned)
-		return
+		limits = returned
+	}
+	if h.GetScheduleConfig().StoreLimitMode != "snapshot" {
+		h.rd.JSON(w, http.StatusOK, limits)
+		return
+	}
+	// show the effective limits adjusted by the snapshot activity of stores.
+	opController := rc.GetOperatorController()
+	returned := make(map[uint64]storeLimit, len(limits))
+	for storeID, v := range limits {
+		returned[storeID] = storeLimit{
+			StoreLimitConfig: v,
+			Effective: &config.StoreLimitConfig{
+				AddPeer:    opController.GetEffectiveStoreLimit(storeID, storelimit.AddPeer),
+				RemovePeer: opController.GetEffectiveStoreLimit(storeID, storelimit.RemovePeer),
+			},
+		}
 	}
-	h.rd.JSON(w, http.StatusOK, limits)
+	h.rd.JSON(w, http.StatusOK, returned)
+}
+
+// storeLimit is the configured limit of a store with the effective limit.
+type storeLimit struct {
+	config.StoreLimitConfig
+	// Effective is the limit adjusted by the snapshot activity of the store,
+	// which is only shown in the snapshot store limit mode.
+	Effective *config.StoreLimitConfig `json:"effective,omitempty"`
 }
 
 // @Tags     store
@@ -725,6 +763,7 @@ func (h *storesHandler) GetStores(w http.ResponseWriter, r *http.Request) {
 	}
 
 	stores = urlFilter.filter(rc.GetMetaStores())
+	storesLoads := rc.GetStoresLoads()
 	for _, s := range stores {
 		storeID := s.GetId()
 		store := rc.GetStore(storeID)
@@ -733,7 +772,7 @@ func (h *storesHandler) GetStores(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		storeInfo := newStoreInfo(h.GetScheduleConfig(), store)
+		storeInfo := newStoreInfo(h.GetScheduleConfig(), store, storesLoads)
 		StoresInfo.Stores = append(StoresInfo.Stores, storeInfo)
 	}
 	StoresInfo.Count = len(StoresInfo.Stores)
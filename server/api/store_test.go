@@ -34,6 +34,7 @@ import (
 	"github.com/tikv/pd/server"
 	"github.com/tikv/pd/server/config"
 	"github.com/tikv/pd/server/core"
+	"github.com/tikv/pd/server/statistics"
 )
 
 type storeTestSuite struct {
@@ -407,18 +408,33 @@ func (suite *storeTestSuite) TestDownState() {
 		core.SetStoreStats(&pdpb.StoreStats{}),
 		core.SetLastHeartbeatTS(time.Now()),
 	)
-	storeInfo := newStoreInfo(suite.svr.GetScheduleConfig(), store)
+	storeInfo := newStoreInfo(suite.svr.GetScheduleConfig(), store, nil)
 	suite.Equal(metapb.StoreState_Up.String(), storeInfo.Store.StateName)
 
 	newStore := store.Clone(core.SetLastHeartbeatTS(time.Now().Add(-time.Minute * 2)))
-	storeInfo = newStoreInfo(suite.svr.GetScheduleConfig(), newStore)
+	storeInfo = newStoreInfo(suite.svr.GetScheduleConfig(), newStore, nil)
 	suite.Equal(disconnectedName, storeInfo.Store.StateName)
 
 	newStore = store.Clone(core.SetLastHeartbeatTS(time.Now().Add(-time.Hour * 2)))
-	storeInfo = newStoreInfo(suite.svr.GetScheduleConfig(), newStore)
+	storeInfo = newStoreInfo(suite.svr.GetScheduleConfig(), newStore, nil)
 	suite.Equal(downStateName, storeInfo.Store.StateName)
 }
 
+func TestStoreInfoLeaderScore(t *testing.T) {
+	re := require.New(t)
+	store := core.NewStoreInfo(&metapb.Store{Id: 1}, core.SetLeaderCount(10))
+	loads := make([]float64, statistics.StoreStatCount)
+	loads[statistics.StoreReadQuery] = 100
+	storesLoads := map[uint64][]float64{1: loads}
+
+	opt := &config.ScheduleConfig{LeaderSchedulePolicy: core.ByCount.String()}
+	re.Equal(10.0, newStoreInfo(opt, store, storesLoads).Status.LeaderScore)
+	opt.LeaderSchedulePolicy = core.ByReadQuery.String()
+	re.Equal(100.0, newStoreInfo(opt, store, storesLoads).Status.LeaderScore)
+	opt.LeaderSchedulePolicy = core.ByWriteQuery.String()
+	re.Zero(newStoreInfo(opt, store, storesLoads).Status.LeaderScore)
+}
+
 func (suite *storeTestSuite) TestGetAllLimit() {
 	testCases := []struct {
 		name           string
@@ -469,6 +485,29 @@ func (suite *storeTestSuite) TestGetAllLimit() {
 	}
 }
 
+func (suite *storeTestSuite) TestSnapshotStoreLimit() {
+	re := suite.Require()
+	url := fmt.Sprintf("%s/stores/limit", suite.urlPrefix)
+	opt := suite.svr.GetPersistOptions()
+	cfg := opt.GetScheduleConfig().Clone()
+	cfg.StoreLimitMode = "snapshot"
+	opt.SetScheduleConfig(cfg)
+	defer func() {
+		cfg := opt.GetScheduleConfig().Clone()
+		cfg.StoreLimitMode = "manual"
+		opt.SetScheduleConfig(cfg)
+	}()
+	suite.svr.GetRaftCluster().GetOperatorController().CollectSnapshotStats(&pdpb.StoreStats{StoreId: 1, ReceivingSnapCount: 5})
+
+	limits := make(map[uint64]storeLimit)
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, url, &limits))
+	suite.Contains(limits, uint64(1))
+	limit := limits[1]
+	suite.NotNil(limit.Effective)
+	suite.Equal(limit.AddPeer/2, limit.Effective.AddPeer)
+	suite.Equal(limit.RemovePeer, limit.Effective.RemovePeer)
+}
+
 func (suite *storeTestSuite) TestStoreLimitTTL() {
 	// add peer
 	url := fmt.Sprintf("%s/store/1/limit?ttlSecond=%v", suite.urlPrefix, 5)
@@ -130,9 +130,13 @@ func (h *trendHandler) getTrendStores() ([]trendStore, error) {
 	if err != nil {
 		return nil, err
 	}
+	var storesLoads map[uint64][]float64
+	if rc := h.svr.GetRaftCluster(); rc != nil {
+		storesLoads = rc.GetStoresLoads()
+	}
 	trendStores := make([]trendStore, 0, len(stores))
 	for _, store := range stores {
-		info := newStoreInfo(h.svr.GetScheduleConfig(), store)
+		info := newStoreInfo(h.svr.GetScheduleConfig(), store, storesLoads)
 		s := trendStore{
 			ID:              info.Store.GetId(),
 			Address:         info.Store.GetAddress(),
@@ -0,0 +1,514 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/keyspace"
+)
+
+// RegisterKeyspace register keyspace related handlers to router paths.
+func RegisterKeyspace(r *gin.RouterGroup) {
+	router := r.Group("keyspaces")
+	router.Use(middlewares.BootstrapChecker())
+	router.POST("", CreateKeyspace)
+	router.GET("", LoadAllKeyspaces)
+	router.GET("/:name", LoadKeyspace)
+	router.GET("/id/:id", LoadKeyspaceByID)
+	router.PATCH("/:name/config", UpdateKeyspaceConfig)
+	router.PUT("/:name/state", UpdateKeyspaceState)
+	router.GET("/:name/gc-progress", LoadKeyspaceGCProgress)
+	router.GET("/:name/usage", LoadKeyspaceUsage)
+	router.GET("/:name/split-status", LoadKeyspaceSplitStatus)
+}
+
+// CreateKeyspaceParams represents parameters needed when creating a new keyspace.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type CreateKeyspaceParams struct {
+	Name   string            `json:"name"`
+	Config map[string]string `json:"config"`
+	// SplitCount is the number of regions each key range of the keyspace is pre-split into.
+	// The keyspace is always split at its boundaries, 0 or 1 means no further pre-split.
+	SplitCount int `json:"split_count"`
+}
+
+// CreateKeyspaceResponse is the keyspace created along with the status of splitting its regions.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type CreateKeyspaceResponse struct {
+	*KeyspaceMeta
+	SplitStatus *keyspace.SplitStatus
+}
+
+// CreateKeyspace creates keyspace according to given input.
+// @Tags     keyspaces
+// @Summary  Create new keyspace.
+// @Param    body  body  CreateKeyspaceParams  true  "Create keyspace parameters"
+// @Produce  json
+// @Success  200  {object}  CreateKeyspaceResponse
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  409  {string}  string  "The keyspace already exists."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /keyspaces [post]
+func CreateKeyspace(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	createParams := &CreateKeyspaceParams{}
+	if err := c.BindJSON(createParams); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause().Error())
+		return
+	}
+	req := &keyspace.CreateKeyspaceRequest{
+		Name:       createParams.Name,
+		Config:     createParams.Config,
+		Now:        time.Now().Unix(),
+		SplitCount: createParams.SplitCount,
+	}
+	meta, err := manager.CreateKeyspace(req)
+	if err != nil {
+		abortWithKeyspaceError(c, err)
+		return
+	}
+	// The split runs in the background, its status can be polled with LoadKeyspaceSplitStatus.
+	splitStatus, _ := manager.GetSplitStatus(meta.GetName())
+	c.IndentedJSON(http.StatusOK, &CreateKeyspaceResponse{
+		KeyspaceMeta: &KeyspaceMeta{meta},
+		SplitStatus:  splitStatus,
+	})
+}
+
+// LoadKeyspace returns target keyspace.
+// @Tags     keyspaces
+// @Summary  Get keyspace info.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {object}  KeyspaceMeta
+// @Failure  404  {string}  string  "The keyspace does not exist."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name} [get]
+func LoadKeyspace(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	meta, err := manager.LoadKeyspace(c.Param("name"))
+	if err != nil {
+		abortWithKeyspaceError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
+}
+
+// LoadKeyspaceByID returns target keyspace specified by its ID.
+// @Tags     keyspaces
+// @Summary  Get keyspace info by ID.
+// @Param    id  path  string  true  "Keyspace ID"
+// @Produce  json
+// @Success  200  {object}  KeyspaceMeta
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  404  {string}  string  "The keyspace does not exist."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /keyspaces/id/{id} [get]
+func LoadKeyspaceByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "invalid keyspace id")
+		return
+	}
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	meta, err := manager.LoadKeyspaceByID(uint32(id))
+	if err != nil {
+		abortWithKeyspaceError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
+}
+
+// parseLoadAllQuery parses LoadAllKeyspaces' query parameters.
+// page_token:
+// The keyspace id of the scan start. If not set, scan from the default keyspace.
+// It's string of spaceID of the previous scan result's last element (next_page_token).
+// limit:
+// The maximum number of keyspace metas to return. If not set, no limit is posed.
+// Every scan scans limit + 1 keyspaces (if limit != 0), the extra scanned keyspace
+// is to check if there's more, and used to set next_page_token in response.
+func parseLoadAllQuery(c *gin.Context) (scanStart uint32, scanLimit int, err error) {
+	pageToken, set := c.GetQuery("page_token")
+	if !set || pageToken == "" {
+		// If pageToken is empty or unset, then scan from the very first keyspace.
+		scanStart = 0
+	} else {
+		scanStart64, err := strconv.ParseUint(pageToken, 10, 32)
+		if err != nil {
+			return 0, 0, err
+		}
+		scanStart = uint32(scanStart64)
+	}
+
+	limitStr, set := c.GetQuery("limit")
+	if !set || limitStr == "" || limitStr == "0" {
+		// If limit is unset or empty or 0, then no limit is posed for scan.
+		scanLimit = 0
+	} else {
+		scanLimit64, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		if scanLimit64 < 0 {
+			return 0, 0, errors.Errorf("limit should not be negative, got %d", scanLimit64)
+		}
+		// Scan an extra element for next_page_token.
+		scanLimit = int(scanLimit64) + 1
+	}
+
+	return scanStart, scanLimit, nil
+}
+
+// LoadAllKeyspacesResponse represents response given when loading all keyspaces.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type LoadAllKeyspacesResponse struct {
+	Keyspaces []*KeyspaceMeta `json:"keyspaces"`
+	// Token that can be used to read immediate next page.
+	// If it's empty, then end has been reached.
+	NextPageToken string `json:"next_page_token"`
+}
+
+// LoadAllKeyspaces loads range of keyspaces.
+// @Tags     keyspaces
+// @Summary  list keyspaces.
+// @Param    page_token  query  string  false  "page token"
+// @Param    limit       query  string  false  "maximum number of results to return"
+// @Produce  json
+// @Success  200  {object}  LoadAllKeyspacesResponse
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /keyspaces [get]
+func LoadAllKeyspaces(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	scanStart, scanLimit, err := parseLoadAllQuery(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	keyspaces, err := manager.LoadRangeKeyspace(scanStart, scanLimit)
+	if err != nil {
+		abortWithKeyspaceError(c, err)
+		return
+	}
+	resp := &LoadAllKeyspacesResponse{}
+	// If scanLimit 0 was used, there's no limit posed, so all keyspaces have been loaded.
+	// Otherwise, only a full scan indicates that there might be more to load.
+	if scanLimit == 0 || len(keyspaces) < scanLimit {
+		resp.Keyspaces = make([]*KeyspaceMeta, len(keyspaces))
+		for i, keyspace := range keyspaces {
+			resp.Keyspaces[i] = &KeyspaceMeta{keyspace}
+		}
+	} else {
+		// The extra scanned keyspace marks the start of next page.
+		resp.Keyspaces = make([]*KeyspaceMeta, len(keyspaces)-1)
+		for i, keyspace := range keyspaces[:len(keyspaces)-1] {
+			resp.Keyspaces[i] = &KeyspaceMeta{keyspace}
+		}
+		resp.NextPageToken = strconv.Itoa(int(keyspaces[len(keyspaces)-1].GetId()))
+	}
+	c.IndentedJSON(http.StatusOK, resp)
+}
+
+// UpdateConfigParams represents parameters needed to modify target keyspace's configs.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+// A Map of string to string pointer is used to differentiate between json null and "",
+// which will both be set to "" if value type is string during binding.
+type UpdateConfigParams struct {
+	Config map[string]*string `json:"config"`
+}
+
+// UpdateKeyspaceConfig updates target keyspace's config.
+// This api uses PATCH semantic and supports JSON Merge Patch.
+// format and processing rules.
+// @Tags     keyspaces
+// @Summary  Update keyspace config.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Param    body  body  UpdateConfigParams  true  "Update keyspace parameters"
+// @Produce  json
+// @Success  200  {object}  KeyspaceMeta
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  404  {string}  string  "The keyspace does not exist."
+// @Failure  409  {string}  string  "The keyspace is archived."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/config [patch]
+func UpdateKeyspaceConfig(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	name := c.Param("name")
+	configParams := &UpdateConfigParams{}
+	if err := c.BindJSON(configParams); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause().Error())
+		return
+	}
+	mutations := getMutations(configParams.Config)
+	meta, err := manager.UpdateKeyspaceConfig(name, mutations)
+	if err != nil {
+		abortWithKeyspaceError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
+}
+
+// getMutations converts a given JSON merge patch to a series of keyspace config mutations.
+func getMutations(patch map[string]*string) []*keyspace.Mutation {
+	mutations := make([]*keyspace.Mutation, 0, len(patch))
+	for k, v := range patch {
+		if v == nil {
+			mutations = append(mutations, &keyspace.Mutation{
+				Op:  keyspace.OpDel,
+				Key: k,
+			})
+		} else {
+			mutations = append(mutations, &keyspace.Mutation{
+				Op:    keyspace.OpPut,
+				Key:   k,
+				Value: *v,
+			})
+		}
+	}
+	return mutations
+}
+
+// UpdateStateParam represents parameters needed to modify target keyspace's state.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type UpdateStateParam struct {
+	State string `json:"state"`
+}
+
+// UpdateKeyspaceState update the target keyspace's state.
+// @Tags     keyspaces
+// @Summary  Update keyspace state.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Param    body  body  UpdateStateParam  true  "New state for the keyspace"
+// @Produce  json
+// @Success  200  {object}  KeyspaceMeta
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  404  {string}  string  "The keyspace does not exist."
+// @Failure  409  {string}  string  "The state transition is not allowed."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/state [put]
+func UpdateKeyspaceState(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	name := c.Param("name")
+	param := &UpdateStateParam{}
+	if err := c.BindJSON(param); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause().Error())
+		return
+	}
+	targetState, ok := keyspacepb.KeyspaceState_value[strings.ToUpper(param.State)]
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "unknown target state: "+param.State)
+		return
+	}
+	meta, err := manager.UpdateKeyspaceState(name, keyspacepb.KeyspaceState(targetState), time.Now().Unix())
+	if err != nil {
+		abortWithKeyspaceError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
+}
+
+// GCProgress contains the data cleanup progress of an archived keyspace.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type GCProgress struct {
+	Progress     float64 `json:"progress"`
+	CurrentSpeed float64 `json:"current_speed"`
+	LeftSeconds  float64 `json:"left_seconds"`
+}
+
+// LoadKeyspaceGCProgress returns the data cleanup progress of the target archived keyspace.
+// @Tags     keyspaces
+// @Summary  Get the data cleanup progress of an archived keyspace.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {object}  GCProgress
+// @Failure  404  {string}  string  "The keyspace or its progress does not exist."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/gc-progress [get]
+func LoadKeyspaceGCProgress(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	progress, leftSeconds, currentSpeed, err := manager.GetGCProgress(c.Param("name"))
+	if err != nil {
+		if errs.ErrProgressNotFound.Equal(err) {
+			c.AbortWithStatusJSON(http.StatusNotFound, err.Error())
+			return
+		}
+		abortWithKeyspaceError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, &GCProgress{
+		Progress:     progress,
+		CurrentSpeed: currentSpeed,
+		LeftSeconds:  leftSeconds,
+	})
+}
+
+// LoadKeyspaceUsage returns the approximate storage usage of the target keyspace.
+// @Tags     keyspaces
+// @Summary  Get the approximate storage usage of a keyspace.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {object}  keyspace.Usage
+// @Failure  404  {string}  string  "The keyspace does not exist."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/usage [get]
+func LoadKeyspaceUsage(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	usage, err := manager.GetKeyspaceUsage(c.Param("name"))
+	if err != nil {
+		abortWithKeyspaceError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, usage)
+}
+
+// LoadKeyspaceSplitStatus returns the status of splitting the regions of the target keyspace,
+// which is started in the background once the keyspace is created.
+// @Tags     keyspaces
+// @Summary  Get the status of splitting the regions of a keyspace.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {object}  keyspace.SplitStatus
+// @Failure  404  {string}  string  "The keyspace or its split status does not exist."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/split-status [get]
+func LoadKeyspaceSplitStatus(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	status, err := manager.GetSplitStatus(c.Param("name"))
+	if err != nil {
+		abortWithKeyspaceError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, status)
+}
+
+// abortWithKeyspaceError aborts the request with the status code corresponding to
+// the given keyspace manager error.
+func abortWithKeyspaceError(c *gin.Context, err error) {
+	switch errors.Cause(err) {
+	case keyspace.ErrKeyspaceNotFound, keyspace.ErrSplitStatusNotFound:
+		c.AbortWithStatusJSON(http.StatusNotFound, err.Error())
+	case keyspace.ErrKeyspaceExists:
+		c.AbortWithStatusJSON(http.StatusConflict, err.Error())
+	default:
+		if keyspace.IsInvalidArgumentError(err) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		if keyspace.IsIllegalStateError(err) {
+			c.AbortWithStatusJSON(http.StatusConflict, err.Error())
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// KeyspaceMeta wraps keyspacepb.KeyspaceMeta to provide custom JSON marshal.
+type KeyspaceMeta struct {
+	*keyspacepb.KeyspaceMeta
+}
+
+// keyspaceMetaJSON is the JSON representation of KeyspaceMeta.
+type keyspaceMetaJSON struct {
+	ID             uint32            `json:"id"`
+	Name           string            `json:"name"`
+	State          string            `json:"state"`
+	CreatedAt      int64             `json:"created_at"`
+	StateChangedAt int64             `json:"state_changed_at"`
+	Config         map[string]string `json:"config"`
+}
+
+func newKeyspaceMetaJSON(meta *keyspacepb.KeyspaceMeta) keyspaceMetaJSON {
+	return keyspaceMetaJSON{
+		meta.GetId(),
+		meta.GetName(),
+		meta.GetState().String(),
+		meta.GetCreatedAt(),
+		meta.GetStateChangedAt(),
+		meta.GetConfig(),
+	}
+}
+
+func (aux *keyspaceMetaJSON) toKeyspaceMeta() *keyspacepb.KeyspaceMeta {
+	return &keyspacepb.KeyspaceMeta{
+		Id:             aux.ID,
+		Name:           aux.Name,
+		State:          keyspacepb.KeyspaceState(keyspacepb.KeyspaceState_value[aux.State]),
+		CreatedAt:      aux.CreatedAt,
+		StateChangedAt: aux.StateChangedAt,
+		Config:         aux.Config,
+	}
+}
+
+// MarshalJSON creates custom marshal of KeyspaceMeta with the following:
+// 1. Keyspace State are marshaled to their corresponding name for better readability.
+func (meta *KeyspaceMeta) MarshalJSON() ([]byte, error) {
+	aux := newKeyspaceMetaJSON(meta.KeyspaceMeta)
+	return json.Marshal(&aux)
+}
+
+// UnmarshalJSON reverse KeyspaceMeta.MarshalJSON.
+func (meta *KeyspaceMeta) UnmarshalJSON(data []byte) error {
+	aux := &keyspaceMetaJSON{}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	meta.KeyspaceMeta = aux.toKeyspaceMeta()
+	return nil
+}
+
+// MarshalJSON marshals the keyspace in the same way as KeyspaceMeta, with an extra split status.
+func (resp *CreateKeyspaceResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		keyspaceMetaJSON
+		SplitStatus *keyspace.SplitStatus `json:"split_status,omitempty"`
+	}{
+		newKeyspaceMetaJSON(resp.KeyspaceMeta.KeyspaceMeta),
+		resp.SplitStatus,
+	})
+}
+
+// UnmarshalJSON reverse CreateKeyspaceResponse.MarshalJSON.
+func (resp *CreateKeyspaceResponse) UnmarshalJSON(data []byte) error {
+	aux := &struct {
+		keyspaceMetaJSON
+		SplitStatus *keyspace.SplitStatus `json:"split_status,omitempty"`
+	}{}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	resp.KeyspaceMeta = &KeyspaceMeta{aux.toKeyspaceMeta()}
+	resp.SplitStatus = aux.SplitStatus
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/coreos/go-semver/semver"
@@ -42,6 +43,7 @@ import (
 	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/core/storelimit"
 	"github.com/tikv/pd/server/id"
+	"github.com/tikv/pd/server/keyspace"
 	syncer "github.com/tikv/pd/server/region_syncer"
 	"github.com/tikv/pd/server/replication"
 	"github.com/tikv/pd/server/schedule"
@@ -49,6 +51,7 @@ import (
 	"github.com/tikv/pd/server/schedule/hbstream"
 	"github.com/tikv/pd/server/schedule/labeler"
 	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/server/schedule/window"
 	"github.com/tikv/pd/server/schedulers"
 	"github.com/tikv/pd/server/statistics"
 	"github.com/tikv/pd/server/statistics/buckets"
@@ -68,6 +71,12 @@ var (
 // regionLabelGCInterval is the interval to run region-label's GC work.
 const regionLabelGCInterval = time.Hour
 
+// complianceReportJobInterval is the interval to check the compliance of placement rules.
+const complianceReportJobInterval = 5 * time.Minute
+
+// hotRegionPredictionJobInterval is the interval to load the hot regions history for the prediction.
+const hotRegionPredictionJobInterval = time.Minute
+
 const (
 	// nodeStateCheckJobInterval is the interval to run node state check job.
 	nodeStateCheckJobInterval = 10 * time.Second
@@ -94,6 +103,8 @@ type Server interface {
 	GetBasicCluster() *core.BasicCluster
 	GetMembers() ([]*pdpb.Member, error)
 	ReplicateFileToMember(ctx context.Context, member *pdpb.Member, name string, data []byte) error
+	GetHistoryHotRegionStorage() *storage.HotRegionStorage
+	GetKeyspaceManager() *keyspace.Manager
 }
 
 // RaftCluster is used for cluster config management.
@@ -142,6 +153,8 @@ type RaftCluster struct {
 	progressManager          *progress.Manager
 	regionSyncer             *syncer.RegionSyncer
 	changedRegions           chan *core.RegionInfo
+	complianceReport         atomic.Value // stored as *placement.ComplianceReport
+	keyspaceManager          *keyspace.Manager
 }
 
 // Status saves some state information.
@@ -263,6 +276,7 @@ func (c *RaftCluster) Start(s Server) error {
 	if err != nil {
 		return err
 	}
+	c.ruleManager.SetLabelRangeResolver(c.regionLabeler, c.regionLabeler.SubscribeRuleChange)
 
 	c.replicationMode, err = replication.NewReplicationModeManager(s.GetConfig().ReplicationMode, c.storage, cluster, s)
 	if err != nil {
@@ -272,12 +286,16 @@ func (c *RaftCluster) Start(s Server) error {
 	c.coordinator = newCoordinator(c.ctx, cluster, s.GetHBStreams())
 	c.regionStats = statistics.NewRegionStatistics(c.opt, c.ruleManager, c.storeConfigManager)
 	c.limiter = NewStoreLimiter(s.GetPersistOptions())
+	c.keyspaceManager = s.GetKeyspaceManager()
+	if c.keyspaceManager != nil {
+		c.keyspaceManager.SetRegionSplitter(c.ctx, c.coordinator.regionSplitter, c.coordinator.regionScatterer)
+	}
 	c.externalTS, err = c.storage.LoadExternalTS()
 	if err != nil {
 		log.Error("load external timestamp meets error", zap.Error(err))
 	}
 
-	c.wg.Add(8)
+	c.wg.Add(10)
 	go c.runCoordinator()
 	go c.runMetricsCollectionJob()
 	go c.runNodeStateCheckJob()
@@ -286,6 +304,8 @@ func (c *RaftCluster) Start(s Server) error {
 	go c.runReplicationMode()
 	go c.runMinResolvedTSJob()
 	go c.runSyncConfig()
+	go c.runComplianceReportJob()
+	go c.runHotRegionPredictionJob(s.GetHistoryHotRegionStorage())
 	c.running = true
 
 	return nil
@@ -441,6 +461,131 @@ func (c *RaftCluster) runStatsBackgroundJobs() {
 	}
 }
 
+func (c *RaftCluster) runComplianceReportJob() {
+	defer logutil.LogPanic()
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(complianceReportJobInterval)
+	failpoint.Inject("highFrequencyClusterJobs", func() {
+		ticker = time.NewTicker(2 * time.Second)
+	})
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			placementViolationGauge.Reset()
+			log.Info("compliance report job has been stopped")
+			return
+		case <-ticker.C:
+			c.updateComplianceReport()
+		}
+	}
+}
+
+func (c *RaftCluster) runHotRegionPredictionJob(hotRegionStorage *storage.HotRegionStorage) {
+	defer logutil.LogPanic()
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(hotRegionPredictionJobInterval)
+	failpoint.Inject("highFrequencyClusterJobs", func() {
+		ticker = time.NewTicker(2 * time.Second)
+	})
+	defer ticker.Stop()
+
+	// loaded is the end time of the scanned history, zero means nothing is loaded.
+	var loaded time.Time
+	for {
+		select {
+		case <-c.ctx.Done():
+			log.Info("hot region prediction job has been stopped")
+			return
+		case <-ticker.C:
+			if hotRegionStorage == nil || !c.opt.IsHotRegionPredictionEnabled() {
+				if !loaded.IsZero() {
+					c.hotStat.ResetHotPeriods()
+					loaded = time.Time{}
+				}
+				continue
+			}
+			now := time.Now()
+			// The history is flushed every HotRegionsWriteInterval and keyed by the update
+			// time, so the records of the last flush interval and the heartbeat lag may be
+			// written after they are scanned. Re-scan them, which is harmless since only the
+			// max loads are kept.
+			start := loaded.Add(-c.opt.GetHotRegionsWriteInterval() - statistics.RegionHeartBeatReportInterval*time.Second)
+			if loaded.IsZero() {
+				start = now.Add(-statistics.HotPeriodWindow)
+			}
+			if err := c.loadHotPeriods(hotRegionStorage, start, now); err != nil {
+				log.Warn("failed to load hot regions history", errs.ZapError(err))
+				continue
+			}
+			c.hotStat.GCHotPeriods(now)
+			loaded = now
+		}
+	}
+}
+
+// loadHotPeriods feeds the hot regions history in [start, end) to the hot period predictor.
+func (c *RaftCluster) loadHotPeriods(hotRegionStorage *storage.HotRegionStorage, start, end time.Time) error {
+	iter := hotRegionStorage.NewIterator(storage.HotRegionTypes,
+		start.UnixNano()/int64(time.Millisecond), end.UnixNano()/int64(time.Millisecond)-1)
+	for {
+		r, err := iter.Next()
+		if err != nil {
+			return err
+		}
+		if r == nil {
+			return nil
+		}
+		rwTy := statistics.Write
+		if r.HotRegionType == storage.ReadType.String() {
+			rwTy = statistics.Read
+		}
+		loads := make([]float64, statistics.DimLen)
+		loads[statistics.ByteDim] = r.FlowBytes
+		loads[statistics.KeyDim] = r.KeyRate
+		loads[statistics.QueryDim] = r.QueryRate
+		c.hotStat.ObserveHotPeriod(r.RegionID, rwTy, time.Unix(0, r.UpdateTime*int64(time.Millisecond)), loads)
+	}
+}
+
+// updateComplianceReport checks all regions against the placement rules and
+// replaces the compliance report.
+func (c *RaftCluster) updateComplianceReport() {
+	if !c.opt.IsPlacementRulesEnabled() {
+		c.complianceReport.Store((*placement.ComplianceReport)(nil))
+		placementViolationGauge.Reset()
+		return
+	}
+	start := time.Now()
+	report := placement.NewComplianceReport()
+	for _, region := range c.GetRegions() {
+		report.Observe(region, c.ruleManager.FitRegion(c, region))
+	}
+	c.complianceReport.Store(report)
+
+	placementViolationGauge.Reset()
+	for _, rc := range report.Rules {
+		for v, stats := range rc.Violations {
+			placementViolationGauge.WithLabelValues(rc.GroupID, rc.ID, string(v)).Set(float64(stats.Count))
+		}
+	}
+	placementViolationGauge.WithLabelValues("", "", string(placement.ViolationOrphanPeer)).Set(float64(report.OrphanPeers.Count))
+	log.Debug("compliance report is updated",
+		zap.Int("region-count", report.RegionCount),
+		zap.Int("non-compliant", report.NonCompliant.Count),
+		zap.Duration("cost", time.Since(start)))
+}
+
+// GetComplianceReport returns the latest compliance report of placement rules.
+// It returns nil if the report is not generated yet.
+func (c *RaftCluster) GetComplianceReport() *placement.ComplianceReport {
+	report, _ := c.complianceReport.Load().(*placement.ComplianceReport)
+	return report
+}
+
 func (c *RaftCluster) runCoordinator() {
 	defer logutil.LogPanic()
 	defer c.wg.Done()
@@ -555,6 +700,41 @@ func (c *RaftCluster) IsSchedulerPaused(name string) (bool, error) {
 	return c.coordinator.isSchedulerPaused(name)
 }
 
+// SetSchedulerDryRun enables or disables the dry-run mode of a scheduler.
+func (c *RaftCluster) SetSchedulerDryRun(name string, enable bool) error {
+	return c.coordinator.setSchedulerDryRun(name, enable)
+}
+
+// IsSchedulerDryRun checks if a scheduler is in dry-run mode.
+func (c *RaftCluster) IsSchedulerDryRun(name string) (bool, error) {
+	return c.coordinator.isSchedulerDryRun(name)
+}
+
+// GetSchedulerDryRunResult returns the operators recorded by a scheduler in dry-run mode.
+func (c *RaftCluster) GetSchedulerDryRunResult(name string) (*DryRunResult, error) {
+	return c.coordinator.getSchedulerDryRunResult(name)
+}
+
+// SetSchedulingWindows sets the scheduling windows of a scheduler or checker.
+func (c *RaftCluster) SetSchedulingWindows(name string, policy *window.Policy) error {
+	return c.coordinator.setSchedulingWindows(name, policy)
+}
+
+// GetSchedulingWindows returns the scheduling windows of a scheduler or checker.
+func (c *RaftCluster) GetSchedulingWindows(name string) (*window.Policy, error) {
+	return c.coordinator.getSchedulingWindows(name)
+}
+
+// GetAllSchedulingWindows returns the scheduling windows of all schedulers and checkers.
+func (c *RaftCluster) GetAllSchedulingWindows() map[string]*window.Policy {
+	return c.coordinator.windowManager.GetPolicies()
+}
+
+// GetSchedulingWindowStatus returns the current scheduling window status of a scheduler or checker.
+func (c *RaftCluster) GetSchedulingWindowStatus(name string) (*window.Status, error) {
+	return c.coordinator.getSchedulingWindowStatus(name)
+}
+
 // IsSchedulerDisabled checks if a scheduler is disabled.
 func (c *RaftCluster) IsSchedulerDisabled(name string) (bool, error) {
 	return c.coordinator.isSchedulerDisabled(name)
@@ -702,8 +882,13 @@ func (c *RaftCluster) HandleStoreHeartbeat(stats *pdpb.StoreStats) error {
 	interval := reportInterval.GetEndTimestamp() - reportInterval.GetStartTimestamp()
 
 	// c.limiter is nil before "start" is called
-	if c.limiter != nil && c.opt.GetStoreLimitMode() == "auto" {
-		c.limiter.Collect(newStore.GetStoreStats())
+	if c.limiter != nil {
+		switch c.opt.GetStoreLimitMode() {
+		case "auto":
+			c.limiter.Collect(newStore.GetStoreStats())
+		case "snapshot":
+			c.coordinator.opController.CollectSnapshotStats(newStore.GetStoreStats())
+		}
 	}
 
 	regions := make(map[uint64]*core.RegionInfo, len(stats.GetPeerStats()))
@@ -733,7 +918,7 @@ func (c *RaftCluster) HandleStoreHeartbeat(stats *pdpb.StoreStats) error {
 			statistics.RegionWriteKeys:     0,
 			statistics.RegionWriteQueryNum: 0,
 		}
-		peerInfo := core.NewPeerInfo(peer, loads, interval)
+		peerInfo := core.NewPeerInfo(peer, loads, interval).WithEngine(newStore.GetEngine())
 		c.hotStat.CheckReadAsync(statistics.NewCheckPeerTask(peerInfo, region))
 	}
 	// Here we will compare the reported regions with the previous hot peers to decide if it is still hot.
@@ -791,6 +976,9 @@ func (c *RaftCluster) processRegionHeartbeat(region *core.RegionInfo) error {
 	interval := reportInterval.GetEndTimestamp() - reportInterval.GetStartTimestamp()
 	for _, peer := range region.GetPeers() {
 		peerInfo := core.NewPeerInfo(peer, region.GetWriteLoads(), interval)
+		if store := c.GetStore(peer.GetStoreId()); store != nil {
+			peerInfo.WithEngine(store.GetEngine())
+		}
 		c.hotStat.CheckWriteAsync(statistics.NewCheckPeerTask(peerInfo, region))
 	}
 
@@ -827,6 +1015,9 @@ func (c *RaftCluster) processRegionHeartbeat(region *core.RegionInfo) error {
 			}
 			c.labelLevelStats.ClearDefunctRegion(item.GetID())
 		}
+		if c.keyspaceManager != nil {
+			c.keyspaceManager.ObserveRegion(region, overlaps)
+		}
 
 		// Update related stores.
 		storeMap := make(map[uint64]struct{})
@@ -1322,6 +1513,9 @@ func (c *RaftCluster) BuryStore(storeID uint64, forceBury bool) error {
 		c.RemoveStoreLimit(storeID)
 		c.resetProgress(storeID, store.GetAddress())
 		c.hotStat.RemoveRollingStoreStats(storeID)
+		if c.coordinator != nil {
+			c.coordinator.opController.RemoveSnapshotStats(storeID)
+		}
 	}
 	return err
 }
@@ -1612,7 +1806,9 @@ func (c *RaftCluster) calculateRange(stores []*core.StoreInfo, store *core.Store
 	var storeSize float64
 	rules := c.ruleManager.GetRulesForApplyRange(startKey, endKey)
 	for _, rule := range rules {
-		if !placement.MatchLabelConstraints(store, rule.LabelConstraints) {
+		// the constraints referencing the leader are ignored since there is no specific region.
+		constraints := placement.ResolveLabelConstraints(rule.LabelConstraints, nil)
+		if !placement.MatchLabelConstraints(store, constraints) {
 			continue
 		}
 
@@ -1621,7 +1817,7 @@ func (c *RaftCluster) calculateRange(stores []*core.StoreInfo, store *core.Store
 			if s.IsRemoving() || s.IsRemoved() {
 				continue
 			}
-			if placement.MatchLabelConstraints(s, rule.LabelConstraints) {
+			if placement.MatchLabelConstraints(s, constraints) {
 				matchStores = append(matchStores, s)
 			}
 		}
@@ -2022,6 +2218,21 @@ func (c *RaftCluster) GetRegionStats(startKey, endKey []byte) *statistics.Region
 	return statistics.GetRegionStats(c.core.ScanRange(startKey, endKey, -1))
 }
 
+// GetRegionsByLabel returns the regions labeled with the key and the value by the
+// region labeler, sorted by the start key.
+func (c *RaftCluster) GetRegionsByLabel(key, value string) []*core.RegionInfo {
+	var regions []*core.RegionInfo
+	for _, r := range c.regionLabeler.GetLabelKeyRanges(key, value) {
+		for _, region := range c.core.ScanRange(r.StartKey, r.EndKey, -1) {
+			// the region across the boundary of the range is not labeled.
+			if c.regionLabeler.GetRegionLabel(region, key) == value {
+				regions = append(regions, region)
+			}
+		}
+	}
+	return regions
+}
+
 // GetRangeCount returns the number of regions in the range.
 func (c *RaftCluster) GetRangeCount(startKey, endKey []byte) *statistics.RegionStats {
 	stats := &statistics.RegionStats{}
@@ -2076,6 +2287,11 @@ func (c *RaftCluster) RegionWriteStats() map[uint64][]*statistics.HotPeerStat {
 	return c.hotStat.RegionStats(statistics.Write, c.GetOpts().GetHotRegionCacheHitsThreshold())
 }
 
+// PredictedRegionStats returns the stats of the peers which are predicted to be hot soon.
+func (c *RaftCluster) PredictedRegionStats(rw statistics.RWType) map[uint64][]*statistics.HotPeerStat {
+	return c.hotStat.PredictedPeerStats(rw, time.Now(), c.GetRegion)
+}
+
 // TODO: remove me.
 // only used in test.
 func (c *RaftCluster) putRegion(region *core.RegionInfo) error {
@@ -2471,6 +2687,7 @@ func IsClientURL(addr string, etcdClient *clientv3.Client) bool {
 type cacheCluster struct {
 	*RaftCluster
 	stores []*core.StoreInfo
+	opts   *config.PersistOptions
 }
 
 // GetStores returns store infos from cache
@@ -2478,11 +2695,18 @@ func (c *cacheCluster) GetStores() []*core.StoreInfo {
 	return c.stores
 }
 
-// newCacheCluster constructor for cache
-func newCacheCluster(c *RaftCluster) *cacheCluster {
+// GetOpts returns the options with the schedule limits of the scheduling window applied.
+func (c *cacheCluster) GetOpts() *config.PersistOptions {
+	return c.opts
+}
+
+// newCacheCluster constructor for cache, the schedule limits are overridden by the given limits
+// of the active scheduling window of the scheduler if any.
+func newCacheCluster(c *RaftCluster, windowLimits map[string]uint64) *cacheCluster {
 	return &cacheCluster{
 		RaftCluster: c,
 		stores:      c.GetStores(),
+		opts:        c.GetOpts().WithWindowLimits(windowLimits),
 	}
 }
 
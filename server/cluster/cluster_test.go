@@ -1607,6 +1607,48 @@ func TestCalculateStoreSize2(t *testing.T) {
 	re.Equal(3000.0, cluster.getThreshold(stores, store))
 }
 
+func TestComplianceReport(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, opt, err := newTestScheduleConfig()
+	re.NoError(err)
+	cfg := opt.GetReplicationConfig()
+	cfg.EnablePlacementRules = true
+	opt.SetReplicationConfig(cfg)
+	opt.SetMaxReplicas(3)
+	cluster := newTestRaftCluster(ctx, mockid.NewIDAllocator(), opt, storage.NewStorageWithMemoryBackend(), core.NewBasicCluster())
+	re.Nil(cluster.GetComplianceReport())
+
+	for _, store := range newTestStores(5, "6.0.0") {
+		re.NoError(cluster.PutStore(store.GetMeta()))
+	}
+	for _, region := range newTestRegions(10, 5, 3) {
+		// there is no store 0.
+		region = region.Clone(core.WithReplacePeerStore(0, 5))
+		if region.GetID() == 9 {
+			region = region.Clone(core.WithRemoveStorePeer(1))
+		}
+		re.NoError(cluster.putRegion(region))
+	}
+
+	cluster.updateComplianceReport()
+	report := cluster.GetComplianceReport()
+	re.NotNil(report)
+	re.Equal(10, report.RegionCount)
+	re.Equal(1, report.NonCompliant.Count)
+	re.Len(report.Rules, 1)
+	re.Equal(&placement.ComplianceStats{Count: 1, Samples: []uint64{9}}, report.Rules[0].Violations[placement.ViolationMissPeer])
+	re.Zero(report.OrphanPeers.Count)
+
+	// the report is cleared if placement rules are disabled.
+	cfg.EnablePlacementRules = false
+	opt.SetReplicationConfig(cfg)
+	cluster.updateComplianceReport()
+	re.Nil(cluster.GetComplianceReport())
+}
+
 func TestStores(t *testing.T) {
 	re := require.New(t)
 	n := uint64(10)
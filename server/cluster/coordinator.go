@@ -37,6 +37,7 @@ import (
 	"github.com/tikv/pd/server/schedule/hbstream"
 	"github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/schedule/plan"
+	"github.com/tikv/pd/server/schedule/window"
 	"github.com/tikv/pd/server/statistics"
 	"github.com/tikv/pd/server/storage"
 	"go.uber.org/zap"
@@ -49,6 +50,8 @@ const (
 	collectTimeout             = 5 * time.Minute
 	maxScheduleRetries         = 10
 	maxLoadConfigRetries       = 10
+	schedulingWindowInterval   = 10 * time.Second
+	checkerDiagnoseInterval    = 5 * time.Second
 
 	patrolScanRegionLimit = 128 // It takes about 14 minutes to iterate 1 million regions.
 	// PluginLoad means action for load plugin
@@ -74,6 +77,7 @@ type coordinator struct {
 	hbStreams         *hbstream.HeartbeatStreams
 	pluginInterface   *schedule.PluginInterface
 	diagnosticManager *diagnosticManager
+	windowManager     *window.Manager
 }
 
 // newCoordinator creates a new coordinator.
@@ -94,6 +98,7 @@ func newCoordinator(ctx context.Context, cluster *RaftCluster, hbStreams *hbstre
 		hbStreams:         hbStreams,
 		pluginInterface:   schedule.NewPluginInterface(),
 		diagnosticManager: newDiagnosticManager(cluster),
+		windowManager:     window.NewManager(cluster.storage),
 	}
 }
 
@@ -119,6 +124,8 @@ func (c *coordinator) patrolRegions() {
 	var (
 		key     []byte
 		regions []*core.RegionInfo
+		// diagnoseStart is the time when the checkers start to be diagnosed, zero means not diagnosing.
+		diagnoseStart time.Time
 	)
 	for {
 		select {
@@ -132,6 +139,9 @@ func (c *coordinator) patrolRegions() {
 			// Skip patrolling regions during unsafe recovery.
 			continue
 		}
+		if diagnoseStart.IsZero() && c.startDiagnoseCheckers() {
+			diagnoseStart = time.Now()
+		}
 
 		// Check priority regions first.
 		c.checkPriorityRegions()
@@ -141,6 +151,11 @@ func (c *coordinator) patrolRegions() {
 		c.checkWaitingRegions()
 
 		key, regions = c.checkRegions(key)
+		// Record the diagnostic results when a round of patrol finishes or it lasts long enough.
+		if !diagnoseStart.IsZero() && (len(key) == 0 || time.Since(diagnoseStart) >= checkerDiagnoseInterval) {
+			c.finishDiagnoseCheckers()
+			diagnoseStart = time.Time{}
+		}
 		if len(regions) == 0 {
 			continue
 		}
@@ -156,6 +171,39 @@ func (c *coordinator) patrolRegions() {
 	}
 }
 
+// startDiagnoseCheckers starts to collect the plans of checkers if the diagnostic is allowed.
+func (c *coordinator) startDiagnoseCheckers() bool {
+	if !c.cluster.GetOpts().IsDiagnosticAllowed() {
+		return false
+	}
+	c.checkers.GetRuleChecker().StartDiagnose()
+	c.checkers.GetMergeChecker().StartDiagnose()
+	return true
+}
+
+// finishDiagnoseCheckers records the diagnostic results of checkers with the collected plans.
+func (c *coordinator) finishDiagnoseCheckers() {
+	ruleChecker := c.checkers.GetRuleChecker()
+	ruleRecorder := c.diagnosticManager.getRecorder(checker.RuleCheckerName)
+	if plans := ruleChecker.FinishDiagnose(); ruleChecker.IsPaused() {
+		ruleRecorder.setResultFromStatus(paused)
+	} else {
+		ruleRecorder.setResultFromPlans(nil, plans)
+	}
+
+	mergeChecker := c.checkers.GetMergeChecker()
+	mergeRecorder := c.diagnosticManager.getRecorder(checker.MergeCheckerName)
+	plans := mergeChecker.FinishDiagnose()
+	switch {
+	case mergeChecker.IsPaused():
+		mergeRecorder.setResultFromStatus(paused)
+	case len(plans) == 0 && c.opController.OperatorCount(operator.OpMerge) >= c.cluster.GetOpts().GetMergeScheduleLimit():
+		mergeRecorder.setResultFromStatus(pending)
+	default:
+		mergeRecorder.setResultFromPlans(nil, plans)
+	}
+}
+
 func (c *coordinator) checkRegions(startKey []byte) (key []byte, regions []*core.RegionInfo) {
 	regions = c.cluster.ScanRegions(startKey, nil, patrolScanRegionLimit)
 	if len(regions) == 0 {
@@ -409,12 +457,21 @@ func (c *coordinator) run() {
 		log.Error("cannot persist schedule config", errs.ZapError(err))
 	}
 
-	c.wg.Add(3)
+	if err := c.windowManager.Load(); err != nil {
+		log.Error("cannot load scheduling windows", errs.ZapError(err))
+	}
+	if err := c.loadSchedulerDryRun(); err != nil {
+		log.Error("cannot load the dry-run mode of schedulers", errs.ZapError(err))
+	}
+
+	c.wg.Add(4)
 	// Starts to patrol regions.
 	go c.patrolRegions()
 	// Checks suspect key ranges
 	go c.checkSuspectRanges()
 	go c.drivePushOperator()
+	// Enforces the scheduling windows.
+	go c.runSchedulingWindows()
 }
 
 // LoadPlugin load user plugin
@@ -617,6 +674,9 @@ func (c *coordinator) addScheduler(scheduler schedule.Scheduler, args ...string)
 		return err
 	}
 
+	now := time.Now()
+	s.setOutOfWindow(!c.windowManager.GetStatus(s.GetName(), now).Active)
+	s.setWindowLimits(c.windowManager.GetActiveLimits(s.GetName(), now))
 	c.wg.Add(1)
 	go c.runScheduler(s)
 	c.schedulers[s.GetName()] = s
@@ -651,6 +711,13 @@ func (c *coordinator) removeScheduler(name string) error {
 		return err
 	}
 
+	if err := c.windowManager.DeletePolicy(name); err != nil {
+		log.Error("can not remove the scheduling windows", zap.String("scheduler-name", name), errs.ZapError(err))
+	}
+	if err := c.cluster.storage.RemoveSchedulerDryRun(name); err != nil {
+		log.Error("can not remove the dry-run mode of scheduler", zap.String("scheduler-name", name), errs.ZapError(err))
+	}
+
 	s.Stop()
 	schedulerStatusGauge.DeleteLabelValues(name, "allow")
 	delete(c.schedulers, name)
@@ -712,6 +779,88 @@ func (c *coordinator) pauseOrResumeScheduler(name string, t int64) error {
 	return err
 }
 
+// setSchedulerDryRun enables or disables the dry-run mode of the given scheduler or all schedulers.
+// A scheduler in dry-run mode keeps scheduling but records the operators into a shadow log instead of dispatching them.
+func (c *coordinator) setSchedulerDryRun(name string, enable bool) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.cluster == nil {
+		return errs.ErrNotBootstrapped.FastGenByArgs()
+	}
+	if name != "all" {
+		sc, ok := c.schedulers[name]
+		if !ok {
+			return errs.ErrSchedulerNotFound.FastGenByArgs()
+		}
+		return c.persistSchedulerDryRun(sc, enable)
+	}
+	for _, sc := range c.schedulers {
+		if err := c.persistSchedulerDryRun(sc, enable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// persistSchedulerDryRun persists the dry-run mode of the scheduler before applying it,
+// so that the mode is kept after the leader changes.
+func (c *coordinator) persistSchedulerDryRun(sc *scheduleController, enable bool) error {
+	var err error
+	if enable {
+		err = c.cluster.storage.SaveSchedulerDryRun(sc.GetName())
+	} else {
+		err = c.cluster.storage.RemoveSchedulerDryRun(sc.GetName())
+	}
+	if err != nil {
+		log.Error("can not persist the dry-run mode of scheduler", zap.String("scheduler-name", sc.GetName()), zap.Bool("dry-run", enable), errs.ZapError(err))
+		return err
+	}
+	sc.SetDryRun(enable)
+	return nil
+}
+
+// loadSchedulerDryRun restores the dry-run mode of the schedulers.
+func (c *coordinator) loadSchedulerDryRun() error {
+	names, err := c.cluster.storage.LoadAllSchedulerDryRun()
+	if err != nil {
+		return err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	for _, name := range names {
+		if sc, ok := c.schedulers[name]; ok {
+			sc.SetDryRun(true)
+		}
+	}
+	return nil
+}
+
+func (c *coordinator) isSchedulerDryRun(name string) (bool, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if c.cluster == nil {
+		return false, errs.ErrNotBootstrapped.FastGenByArgs()
+	}
+	s, ok := c.schedulers[name]
+	if !ok {
+		return false, errs.ErrSchedulerNotFound.FastGenByArgs()
+	}
+	return s.IsDryRun(), nil
+}
+
+func (c *coordinator) getSchedulerDryRunResult(name string) (*DryRunResult, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if c.cluster == nil {
+		return nil, errs.ErrNotBootstrapped.FastGenByArgs()
+	}
+	s, ok := c.schedulers[name]
+	if !ok {
+		return nil, errs.ErrSchedulerNotFound.FastGenByArgs()
+	}
+	return s.dryRunLog.getResult(name, s.IsDryRun()), nil
+}
+
 // isSchedulerAllowed returns whether a scheduler is allowed to schedule, a scheduler is not allowed to schedule if it is paused or blocked by unsafe recovery.
 func (c *coordinator) isSchedulerAllowed(name string) (bool, error) {
 	c.RLock()
@@ -828,6 +977,105 @@ func (c *coordinator) isCheckerPaused(name string) (bool, error) {
 	return p.IsPaused(), nil
 }
 
+// runSchedulingWindows periodically enforces the scheduling windows.
+func (c *coordinator) runSchedulingWindows() {
+	defer logutil.LogPanic()
+	defer c.wg.Done()
+
+	c.applySchedulingWindows(time.Now())
+	ticker := time.NewTicker(schedulingWindowInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			c.clearSchedulingWindows()
+			log.Info("scheduling windows enforcement has been stopped")
+			return
+		case now := <-ticker.C:
+			c.applySchedulingWindows(now)
+		}
+	}
+}
+
+// applySchedulingWindows pauses the schedulers and checkers out of their windows at the given
+// time, resumes those within their windows and applies the limits of the active windows to them.
+func (c *coordinator) applySchedulingWindows(now time.Time) {
+	c.RLock()
+	for name, s := range c.schedulers {
+		s.setOutOfWindow(!c.windowManager.GetStatus(name, now).Active)
+		s.setWindowLimits(c.windowManager.GetActiveLimits(name, now))
+	}
+	c.RUnlock()
+	for _, name := range checker.PausableCheckers {
+		if p, err := c.checkers.GetPauseController(name); err == nil {
+			p.SetOutOfWindow(!c.windowManager.GetStatus(name, now).Active)
+			p.SetWindowLimits(c.windowManager.GetActiveLimits(name, now))
+		}
+	}
+}
+
+// clearSchedulingWindows lifts the restrictions of the scheduling windows once the coordinator stops.
+func (c *coordinator) clearSchedulingWindows() {
+	c.RLock()
+	for _, s := range c.schedulers {
+		s.setOutOfWindow(false)
+		s.setWindowLimits(nil)
+	}
+	c.RUnlock()
+	for _, name := range checker.PausableCheckers {
+		if p, err := c.checkers.GetPauseController(name); err == nil {
+			p.SetOutOfWindow(false)
+			p.SetWindowLimits(nil)
+		}
+	}
+}
+
+// setSchedulingWindows sets the scheduling windows of the given scheduler or checker.
+func (c *coordinator) setSchedulingWindows(name string, policy *window.Policy) error {
+	if err := c.checkSchedulingWindowsTarget(name); err != nil {
+		return err
+	}
+	if err := c.windowManager.SetPolicy(name, policy); err != nil {
+		return err
+	}
+	c.applySchedulingWindows(time.Now())
+	return nil
+}
+
+func (c *coordinator) getSchedulingWindows(name string) (*window.Policy, error) {
+	if err := c.checkSchedulingWindowsTarget(name); err != nil {
+		return nil, err
+	}
+	policy := c.windowManager.GetPolicy(name)
+	if policy == nil {
+		policy = &window.Policy{Windows: []*window.Window{}}
+	}
+	return policy, nil
+}
+
+func (c *coordinator) getSchedulingWindowStatus(name string) (*window.Status, error) {
+	if err := c.checkSchedulingWindowsTarget(name); err != nil {
+		return nil, err
+	}
+	return c.windowManager.GetStatus(name, time.Now()), nil
+}
+
+// checkSchedulingWindowsTarget checks if the given name is an existing scheduler or checker.
+func (c *coordinator) checkSchedulingWindowsTarget(name string) error {
+	c.RLock()
+	defer c.RUnlock()
+	if c.cluster == nil {
+		return errs.ErrNotBootstrapped.FastGenByArgs()
+	}
+	if _, ok := c.schedulers[name]; ok {
+		return nil
+	}
+	if _, err := c.checkers.GetPauseController(name); err == nil {
+		return nil
+	}
+	return errs.ErrSchedulerNotFound.FastGenByArgs()
+}
+
 func (c *coordinator) GetDiagnosticResult(name string) (*DiagnosticResult, error) {
 	return c.diagnosticManager.getDiagnosticResult(name)
 }
@@ -843,6 +1091,13 @@ type scheduleController struct {
 	delayAt            int64
 	delayUntil         int64
 	diagnosticRecorder *diagnosticRecorder
+	// dryRun is 1 if the operators generated by the scheduler are only recorded into dryRunLog.
+	dryRun    int32
+	dryRunLog *dryRunLog
+	// outOfWindow is 1 if the scheduler is out of its scheduling windows.
+	outOfWindow int32
+	// windowLimits is the schedule limits overridden by the active scheduling window of the scheduler.
+	windowLimits atomic.Value
 }
 
 // newScheduleController creates a new scheduleController.
@@ -856,6 +1111,7 @@ func newScheduleController(c *coordinator, s schedule.Scheduler) *scheduleContro
 		ctx:                ctx,
 		cancel:             cancel,
 		diagnosticRecorder: c.diagnosticManager.getRecorder(s.GetName()),
+		dryRunLog:          newDryRunLog(c.cluster),
 	}
 }
 
@@ -875,14 +1131,29 @@ func (s *scheduleController) Schedule(diagnosable bool) []*operator.Operator {
 			return nil
 		default:
 		}
-		cacheCluster := newCacheCluster(s.cluster)
+		cacheCluster := newCacheCluster(s.cluster, s.getWindowLimits())
 		// we need only process diagnostic once in the retry loop
 		diagnosable = diagnosable && i == 0
-		ops, plans := s.Scheduler.Schedule(cacheCluster, diagnosable)
+		// plans are always collected in dry-run mode to be recorded with the operators
+		dryRun := s.IsDryRun()
+		ops, plans := s.Scheduler.Schedule(cacheCluster, diagnosable || dryRun)
 		if diagnosable {
 			s.diagnosticRecorder.setResultFromPlans(ops, plans)
 		}
 		if len(ops) > 0 {
+			for _, op := range ops {
+				op.SetSchedulerName(s.GetName())
+			}
+			if dryRun {
+				// The operators are never dispatched in dry-run mode, so the scheduler keeps
+				// generating the same ones. Only the new operators count as a schedule to keep
+				// the normal backoff.
+				if s.dryRunLog.record(ops, plans) > 0 {
+					s.nextInterval = s.Scheduler.GetMinInterval()
+					return nil
+				}
+				break
+			}
 			// If we have schedule, reset interval to the minimal interval.
 			s.nextInterval = s.Scheduler.GetMinInterval()
 			return ops
@@ -893,7 +1164,7 @@ func (s *scheduleController) Schedule(diagnosable bool) []*operator.Operator {
 }
 
 func (s *scheduleController) DiagnoseDryRun() ([]*operator.Operator, []plan.Plan) {
-	cacheCluster := newCacheCluster(s.cluster)
+	cacheCluster := newCacheCluster(s.cluster, s.getWindowLimits())
 	return s.Scheduler.Schedule(cacheCluster, true)
 }
 
@@ -904,13 +1175,13 @@ func (s *scheduleController) GetInterval() time.Duration {
 
 // AllowSchedule returns if a scheduler is allowed to schedule.
 func (s *scheduleController) AllowSchedule(diagnosable bool) bool {
-	if !s.Scheduler.IsScheduleAllowed(s.cluster) {
+	if !s.Scheduler.IsScheduleAllowed(newCacheCluster(s.cluster, s.getWindowLimits())) {
 		if diagnosable {
 			s.diagnosticRecorder.setResultFromStatus(pending)
 		}
 		return false
 	}
-	if s.IsPaused() || s.cluster.GetUnsafeRecoveryController().IsRunning() {
+	if s.IsPaused() || s.IsOutOfWindow() || s.cluster.GetUnsafeRecoveryController().IsRunning() {
 		if diagnosable {
 			s.diagnosticRecorder.setResultFromStatus(paused)
 		}
@@ -925,6 +1196,45 @@ func (s *scheduleController) IsPaused() bool {
 	return time.Now().Unix() < delayUntil
 }
 
+// IsOutOfWindow returns if a scheduler is out of its scheduling windows.
+func (s *scheduleController) IsOutOfWindow() bool {
+	return atomic.LoadInt32(&s.outOfWindow) == 1
+}
+
+func (s *scheduleController) setOutOfWindow(outOfWindow bool) {
+	var v int32
+	if outOfWindow {
+		v = 1
+	}
+	atomic.StoreInt32(&s.outOfWindow, v)
+}
+
+func (s *scheduleController) getWindowLimits() map[string]uint64 {
+	limits, _ := s.windowLimits.Load().(map[string]uint64)
+	return limits
+}
+
+func (s *scheduleController) setWindowLimits(limits map[string]uint64) {
+	s.windowLimits.Store(limits)
+}
+
+// IsDryRun returns if a scheduler is in dry-run mode.
+func (s *scheduleController) IsDryRun() bool {
+	return atomic.LoadInt32(&s.dryRun) == 1
+}
+
+// SetDryRun enables or disables the dry-run mode of a scheduler. The shadow log
+// is reset when the dry-run mode is enabled.
+func (s *scheduleController) SetDryRun(enable bool) {
+	if !enable {
+		atomic.StoreInt32(&s.dryRun, 0)
+		return
+	}
+	if atomic.CompareAndSwapInt32(&s.dryRun, 0, 1) {
+		s.dryRunLog.reset()
+	}
+}
+
 // GetPausedSchedulerDelayAt returns paused timestamp of a paused scheduler
 func (s *scheduleController) GetDelayAt() int64 {
 	if s.IsPaused() {
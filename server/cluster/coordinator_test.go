@@ -35,9 +35,11 @@ import (
 	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/core/storelimit"
 	"github.com/tikv/pd/server/schedule"
+	"github.com/tikv/pd/server/schedule/checker"
 	"github.com/tikv/pd/server/schedule/hbstream"
 	"github.com/tikv/pd/server/schedule/labeler"
 	"github.com/tikv/pd/server/schedule/operator"
+	"github.com/tikv/pd/server/schedule/window"
 	"github.com/tikv/pd/server/schedulers"
 	"github.com/tikv/pd/server/statistics"
 	"github.com/tikv/pd/server/storage"
@@ -318,6 +320,7 @@ func TestCheckRegion(t *testing.T) {
 	re.NoError(tc.addLeaderRegion(1, 2, 3))
 	checkRegionAndOperator(re, tc, co, 1, 1)
 	testutil.CheckAddPeer(re, co.opController.GetOperator(1), operator.OpReplica, 1)
+	re.Equal(checker.RuleCheckerName, co.opController.GetOperator(1).GetCheckerName())
 	checkRegionAndOperator(re, tc, co, 1, 0)
 
 	r := tc.GetRegion(1)
@@ -1350,3 +1353,130 @@ func waitNoResponse(re *require.Assertions, stream mockhbstream.HeartbeatStream)
 		return res == nil
 	})
 }
+
+func TestDryRunScheduler(t *testing.T) {
+	re := require.New(t)
+
+	tc, co, cleanup := prepare(nil, nil, nil, re)
+	defer cleanup()
+	oc := co.opController
+
+	re.NoError(tc.addLeaderStore(1, 10))
+	re.NoError(tc.addLeaderStore(2, 0))
+	re.NoError(tc.addLeaderRegion(1, 1, 2))
+	scheduler, err := schedule.CreateScheduler(schedulers.GrantLeaderType, oc, storage.NewStorageWithMemoryBackend(), schedule.ConfigSliceDecoder(schedulers.GrantLeaderType, []string{"2"}))
+	re.NoError(err)
+	name := scheduler.GetName()
+	sc := newScheduleController(co, scheduler)
+	co.schedulers[name] = sc
+
+	re.NoError(co.setSchedulerDryRun(name, true))
+	dryRun, err := co.isSchedulerDryRun(name)
+	re.NoError(err)
+	re.True(dryRun)
+	// The operators are recorded instead of being returned to be dispatched.
+	re.Empty(sc.Schedule(false))
+	re.Zero(oc.OperatorCount(operator.OpLeader))
+	result, err := co.getSchedulerDryRunResult(name)
+	re.NoError(err)
+	re.True(result.DryRun)
+	re.Len(result.Operators, 1)
+	re.Equal(uint64(1), result.Operators[0].RegionID)
+	re.Equal(int64(-1), result.Influence[1].LeaderCount)
+	re.Equal(int64(1), result.Influence[2].LeaderCount)
+
+	// The repeated operators are skipped and the scheduler backs off as there is no new schedule.
+	interval := sc.GetInterval()
+	re.Empty(sc.Schedule(false))
+	re.Greater(sc.GetInterval(), interval)
+	result, err = co.getSchedulerDryRunResult(name)
+	re.NoError(err)
+	re.Len(result.Operators, 1)
+
+	// The dry-run mode is restored from the storage, e.g. after the leader changes.
+	newSc := newScheduleController(co, scheduler)
+	co.schedulers[name] = newSc
+	re.NoError(co.loadSchedulerDryRun())
+	re.True(newSc.IsDryRun())
+	co.schedulers[name] = sc
+
+	// The shadow log is kept after disabling dry-run and reset after enabling it again.
+	re.NoError(co.setSchedulerDryRun("all", false))
+	re.NotEmpty(sc.Schedule(false))
+	newSc.SetDryRun(false)
+	re.NoError(co.loadSchedulerDryRun())
+	re.False(newSc.IsDryRun())
+	result, err = co.getSchedulerDryRunResult(name)
+	re.NoError(err)
+	re.False(result.DryRun)
+	re.Len(result.Operators, 1)
+	re.NoError(co.setSchedulerDryRun(name, true))
+	result, err = co.getSchedulerDryRunResult(name)
+	re.NoError(err)
+	re.Empty(result.Operators)
+
+	re.Error(co.setSchedulerDryRun("test", true))
+	_, err = co.getSchedulerDryRunResult("test")
+	re.Error(err)
+}
+
+func TestSchedulingWindows(t *testing.T) {
+	re := require.New(t)
+
+	tc, co, cleanup := prepare(nil, nil, nil, re)
+	defer cleanup()
+
+	re.NoError(tc.addLeaderStore(1, 1))
+	scheduler, err := schedule.CreateScheduler(schedulers.BalanceRegionType, co.opController, storage.NewStorageWithMemoryBackend(), schedule.ConfigSliceDecoder(schedulers.BalanceRegionType, []string{"", ""}))
+	re.NoError(err)
+	re.NoError(co.addScheduler(scheduler))
+	name := scheduler.GetName()
+	sc := co.schedulers[name]
+	mergeChecker, err := co.checkers.GetPauseController("merge")
+	re.NoError(err)
+
+	// The window is active in the first minute of a year.
+	policy := &window.Policy{Windows: []*window.Window{{
+		Cron:     "0 0 1 1 *",
+		Duration: typeutil.NewDuration(time.Minute),
+		Limits:   map[string]uint64{"region-schedule-limit": 100},
+	}}}
+	re.NoError(co.setSchedulingWindows(name, policy))
+	re.NoError(co.setSchedulingWindows("merge", policy))
+	re.Error(co.setSchedulingWindows("test", policy))
+	re.Error(co.setSchedulingWindows(name, &window.Policy{Windows: []*window.Window{{Cron: "0 0 1 1"}}}))
+	got, err := co.getSchedulingWindows(name)
+	re.NoError(err)
+	re.Equal(policy, got)
+
+	inWindow := time.Date(2022, 1, 1, 0, 0, 30, 0, time.Local)
+	co.applySchedulingWindows(inWindow)
+	re.False(sc.IsOutOfWindow())
+	re.True(sc.AllowSchedule(false))
+	re.False(mergeChecker.IsPaused())
+	// The limits only apply to the scheduler or checker owning the window.
+	defaultLimit := tc.GetOpts().GetScheduleConfig().RegionScheduleLimit
+	re.Equal(uint64(100), newCacheCluster(tc.RaftCluster, sc.getWindowLimits()).GetOpts().GetRegionScheduleLimit())
+	re.Equal(uint64(100), tc.GetOpts().WithWindowLimits(mergeChecker.GetWindowLimits()).GetRegionScheduleLimit())
+	re.Equal(defaultLimit, tc.GetOpts().GetRegionScheduleLimit())
+
+	co.applySchedulingWindows(inWindow.Add(time.Hour))
+	re.True(sc.IsOutOfWindow())
+	re.False(sc.AllowSchedule(false))
+	re.True(mergeChecker.IsPaused())
+	re.Equal(defaultLimit, newCacheCluster(tc.RaftCluster, sc.getWindowLimits()).GetOpts().GetRegionScheduleLimit())
+
+	// The restrictions are lifted once the coordinator stops.
+	co.applySchedulingWindows(inWindow)
+	co.clearSchedulingWindows()
+	re.False(sc.IsOutOfWindow())
+	re.Empty(sc.getWindowLimits())
+	re.Empty(mergeChecker.GetWindowLimits())
+	co.applySchedulingWindows(inWindow.Add(time.Hour))
+
+	// Removing the windows lifts the restriction, and removing the scheduler removes its windows.
+	re.NoError(co.setSchedulingWindows("merge", &window.Policy{}))
+	re.False(mergeChecker.IsPaused())
+	re.NoError(co.removeScheduler(name))
+	re.Empty(co.windowManager.GetPolicies())
+}
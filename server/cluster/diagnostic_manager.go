@@ -22,6 +22,7 @@ import (
 	"github.com/tikv/pd/pkg/cache"
 	"github.com/tikv/pd/pkg/errs"
 	"github.com/tikv/pd/pkg/movingaverage"
+	"github.com/tikv/pd/server/schedule/checker"
 	"github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/schedule/plan"
 	"github.com/tikv/pd/server/schedulers"
@@ -50,6 +51,16 @@ const (
 var DiagnosableSummaryFunc = map[string]plan.Summary{
 	schedulers.BalanceRegionName: schedulers.BalancePlanSummary,
 	schedulers.BalanceLeaderName: schedulers.BalancePlanSummary,
+	schedulers.HotRegionName:     schedulers.HotRegionPlanSummary,
+	schedulers.EvictLeaderName:   schedulers.EvictLeaderPlanSummary,
+	schedulers.GrantLeaderName:   schedulers.GrantLeaderPlanSummary,
+	checker.RuleCheckerName:      checker.RuleCheckerPlanSummary,
+	checker.MergeCheckerName:     checker.MergeCheckerPlanSummary,
+}
+
+// isChecker returns true if the diagnosable name is a checker.
+func isChecker(name string) bool {
+	return name == checker.RuleCheckerName || name == checker.MergeCheckerName
 }
 
 type diagnosticManager struct {
@@ -73,9 +84,18 @@ func (d *diagnosticManager) getDiagnosticResult(name string) (*DiagnosticResult,
 		return nil, errs.ErrDiagnosticDisabled
 	}
 
-	isSchedulerExisted, _ := d.cluster.IsSchedulerExisted(name)
-	isDisabled, _ := d.cluster.IsSchedulerDisabled(name)
-	if !isSchedulerExisted || isDisabled {
+	var isDisabled bool
+	switch name {
+	case checker.RuleCheckerName:
+		isDisabled = !d.cluster.opt.IsPlacementRulesEnabled()
+	case checker.MergeCheckerName:
+		isDisabled = d.cluster.opt.GetMergeScheduleLimit() == 0
+	default:
+		isSchedulerExisted, _ := d.cluster.IsSchedulerExisted(name)
+		isSchedulerDisabled, _ := d.cluster.IsSchedulerDisabled(name)
+		isDisabled = !isSchedulerExisted || isSchedulerDisabled
+	}
+	if isDisabled {
 		ts := uint64(time.Now().Unix())
 		res := &DiagnosticResult{Name: name, Timestamp: ts, Status: disabled}
 		return res, nil
@@ -142,6 +162,7 @@ func (d *diagnosticRecorder) getLastResult() *DiagnosticResult {
 	}
 
 	var resStr string
+	var storeReasons map[uint64]string
 	firstStatus := items[0].Value.(*DiagnosticResult).Status
 	if firstStatus == pending || firstStatus == normal {
 		wa := movingaverage.NewWeightAllocator(length, 3)
@@ -157,7 +178,8 @@ func (d *diagnosticRecorder) getLastResult() *DiagnosticResult {
 			}
 		}
 		statusCounter := make(map[plan.Status]uint64)
-		for _, store := range counter {
+		storeReasons = make(map[uint64]string, len(counter))
+		for storeID, store := range counter {
 			max := 0.
 			curStat := *plan.NewStatus(plan.StatusOK)
 			for stat, c := range store {
@@ -167,6 +189,7 @@ func (d *diagnosticRecorder) getLastResult() *DiagnosticResult {
 				}
 			}
 			statusCounter[curStat] += 1
+			storeReasons[storeID] = curStat.String()
 		}
 		if len(statusCounter) > 0 {
 			for k, v := range statusCounter {
@@ -178,10 +201,11 @@ func (d *diagnosticRecorder) getLastResult() *DiagnosticResult {
 		}
 	}
 	return &DiagnosticResult{
-		Name:      d.schedulerName,
-		Status:    firstStatus,
-		Summary:   resStr,
-		Timestamp: uint64(time.Now().Unix()),
+		Name:         d.schedulerName,
+		Status:       firstStatus,
+		Summary:      resStr,
+		Timestamp:    uint64(time.Now().Unix()),
+		StoreReasons: storeReasons,
 	}
 }
 
@@ -203,26 +227,28 @@ func (d *diagnosticRecorder) setResultFromPlans(ops []*operator.Operator, plans
 
 func (d *diagnosticRecorder) analyze(ops []*operator.Operator, plans []plan.Plan, ts uint64) *DiagnosticResult {
 	res := &DiagnosticResult{Name: d.schedulerName, Timestamp: ts, Status: normal}
-	name := d.schedulerName
-	// TODO: support more schedulers and checkers
-	switch name {
-	case schedulers.BalanceRegionName, schedulers.BalanceLeaderName:
-		if len(ops) != 0 {
-			res.Status = scheduling
-			return res
-		}
-		res.Status = pending
-		if d.summaryFunc != nil {
-			isAllNormal := false
-			res.StoreStatus, isAllNormal, _ = d.summaryFunc(plans)
-			if isAllNormal {
-				res.Status = normal
+	if len(ops) != 0 {
+		res.Status = scheduling
+		return res
+	}
+	// The checkers do not return operators to the recorder, but collect the plans
+	// with OK status for the checked regions which operators are created for.
+	if isChecker(d.schedulerName) {
+		for _, p := range plans {
+			if p.GetStatus().IsOK() {
+				res.Status = scheduling
+				return res
 			}
 		}
-		return res
-	default:
 	}
-	// TODO: save plan into result
+	res.Status = pending
+	if d.summaryFunc != nil {
+		isAllNormal := false
+		res.StoreStatus, isAllNormal, _ = d.summaryFunc(plans)
+		if isAllNormal {
+			res.Status = normal
+		}
+	}
 	return res
 }
 
@@ -232,6 +258,8 @@ type DiagnosticResult struct {
 	Status    string `json:"status"`
 	Summary   string `json:"summary"`
 	Timestamp uint64 `json:"timestamp"`
+	// StoreReasons explains why each store cannot be scheduled.
+	StoreReasons map[uint64]string `json:"store-reasons,omitempty"`
 
 	StoreStatus map[uint64]plan.Status `json:"-"`
 }
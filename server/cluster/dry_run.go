@@ -0,0 +1,200 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tikv/pd/pkg/syncutil"
+	"github.com/tikv/pd/server/schedule/operator"
+	"github.com/tikv/pd/server/schedule/plan"
+)
+
+// maxDryRunOperatorNum is the max number of operators kept in the shadow log of a scheduler.
+const maxDryRunOperatorNum = 1000
+
+// DryRunInfluence is the estimated influence of operators on a store.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type DryRunInfluence struct {
+	RegionSize  int64 `json:"region_size"`
+	RegionCount int64 `json:"region_count"`
+	LeaderSize  int64 `json:"leader_size"`
+	LeaderCount int64 `json:"leader_count"`
+}
+
+func (i *DryRunInfluence) add(other *DryRunInfluence) {
+	i.RegionSize += other.RegionSize
+	i.RegionCount += other.RegionCount
+	i.LeaderSize += other.LeaderSize
+	i.LeaderCount += other.LeaderCount
+}
+
+// DryRunOperator is an operator generated by a scheduler in dry-run mode, which is never dispatched.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type DryRunOperator struct {
+	CreateTime time.Time `json:"create_time"`
+	RegionID   uint64    `json:"region_id"`
+	Desc       string    `json:"desc"`
+	Kind       string    `json:"kind"`
+	// Operator is the description of the operator including its steps.
+	Operator string `json:"operator"`
+	// Plans counts the plans of the scheduling round generating the operator by status.
+	// It is only available for the schedulers collecting plans.
+	Plans map[string]int `json:"plans,omitempty"`
+	// Influence is the estimated influence of the operator on each store.
+	Influence map[uint64]*DryRunInfluence `json:"influence"`
+
+	key string
+}
+
+// DryRunResult is the shadow log of a scheduler in dry-run mode.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type DryRunResult struct {
+	Name   string `json:"name"`
+	DryRun bool   `json:"dry_run"`
+	// Operators are the operators in the shadow log, from the oldest to the newest.
+	Operators []*DryRunOperator `json:"operators"`
+	// Influence summarizes the estimated influence of all the operators in the shadow log on each store.
+	Influence map[uint64]*DryRunInfluence `json:"influence"`
+}
+
+// dryRunLog is a bounded shadow log of the operators generated by a scheduler in dry-run mode.
+type dryRunLog struct {
+	syncutil.RWMutex
+	cluster   *RaftCluster
+	operators []*DryRunOperator
+	// keys are the region and steps of the operators in the log, to skip the repeated ones.
+	keys map[string]struct{}
+	// next is the position to put the next operator once the log is full.
+	next int
+}
+
+func newDryRunLog(cluster *RaftCluster) *dryRunLog {
+	return &dryRunLog{cluster: cluster, keys: make(map[string]struct{})}
+}
+
+// record puts the given operators into the shadow log, evicting the oldest ones if the log is full.
+// The operators with the same region and steps as one in the log are skipped. It returns the
+// number of the recorded operators.
+func (l *dryRunLog) record(ops []*operator.Operator, plans []plan.Plan) int {
+	var planSummary map[string]int
+	if len(plans) > 0 {
+		planSummary = make(map[string]int)
+		for _, p := range plans {
+			planSummary[p.GetStatus().String()]++
+		}
+	}
+	l.Lock()
+	defer l.Unlock()
+	recorded := 0
+	for _, op := range ops {
+		key := dryRunOperatorKey(op)
+		if _, ok := l.keys[key]; ok {
+			continue
+		}
+		recorded++
+		l.put(key, &DryRunOperator{
+			CreateTime: op.GetCreateTime(),
+			RegionID:   op.RegionID(),
+			Desc:       op.Desc(),
+			Kind:       op.Kind().String(),
+			Operator:   op.String(),
+			Plans:      planSummary,
+			Influence:  l.estimateInfluence(op),
+			key:        key,
+		})
+	}
+	return recorded
+}
+
+func dryRunOperatorKey(op *operator.Operator) string {
+	var key strings.Builder
+	key.WriteString(strconv.FormatUint(op.RegionID(), 10))
+	for i := 0; i < op.Len(); i++ {
+		key.WriteString("|")
+		key.WriteString(op.Step(i).String())
+	}
+	return key.String()
+}
+
+func (l *dryRunLog) put(key string, op *DryRunOperator) {
+	l.keys[key] = struct{}{}
+	if len(l.operators) < maxDryRunOperatorNum {
+		l.operators = append(l.operators, op)
+		return
+	}
+	delete(l.keys, l.operators[l.next].key)
+	l.operators[l.next] = op
+	l.next = (l.next + 1) % maxDryRunOperatorNum
+}
+
+func (l *dryRunLog) estimateInfluence(op *operator.Operator) map[uint64]*DryRunInfluence {
+	influence := make(map[uint64]*DryRunInfluence)
+	region := l.cluster.GetRegion(op.RegionID())
+	if region == nil {
+		return influence
+	}
+	opInfluence := operator.OpInfluence{StoresInfluence: make(map[uint64]*operator.StoreInfluence)}
+	op.TotalInfluence(opInfluence, region)
+	for storeID, storeInfluence := range opInfluence.StoresInfluence {
+		influence[storeID] = &DryRunInfluence{
+			RegionSize:  storeInfluence.RegionSize,
+			RegionCount: storeInfluence.RegionCount,
+			LeaderSize:  storeInfluence.LeaderSize,
+			LeaderCount: storeInfluence.LeaderCount,
+		}
+	}
+	return influence
+}
+
+// reset clears the shadow log.
+func (l *dryRunLog) reset() {
+	l.Lock()
+	defer l.Unlock()
+	l.operators = nil
+	l.keys = make(map[string]struct{})
+	l.next = 0
+}
+
+// getOperators returns the operators in the shadow log from the oldest to the newest.
+func (l *dryRunLog) getOperators() []*DryRunOperator {
+	l.RLock()
+	defer l.RUnlock()
+	operators := make([]*DryRunOperator, 0, len(l.operators))
+	operators = append(operators, l.operators[l.next:]...)
+	return append(operators, l.operators[:l.next]...)
+}
+
+func (l *dryRunLog) getResult(name string, dryRun bool) *DryRunResult {
+	result := &DryRunResult{
+		Name:      name,
+		DryRun:    dryRun,
+		Operators: l.getOperators(),
+		Influence: make(map[uint64]*DryRunInfluence),
+	}
+	for _, op := range result.Operators {
+		for storeID, influence := range op.Influence {
+			total, ok := result.Influence[storeID]
+			if !ok {
+				total = &DryRunInfluence{}
+				result.Influence[storeID] = total
+			}
+			total.add(influence)
+		}
+	}
+	return result
+}
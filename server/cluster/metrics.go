@@ -120,6 +120,14 @@ var (
 			Help:      "The ETA of corresponding action",
 		}, []string{"address", "store", "action"})
 
+	placementViolationGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "cluster",
+			Name:      "placement_violation_regions",
+			Help:      "The count of regions violating placement rules.",
+		}, []string{"group", "rule", "type"})
+
 	storeSyncConfigEvent = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "pd",
@@ -143,4 +151,5 @@ func init() {
 	prometheus.MustRegister(storesSpeedGauge)
 	prometheus.MustRegister(storesETAGauge)
 	prometheus.MustRegister(storeSyncConfigEvent)
+	prometheus.MustRegister(placementViolationGauge)
 }
@@ -165,6 +165,8 @@ type Config struct {
 	Dashboard DashboardConfig `toml:"dashboard" json:"dashboard"`
 
 	ReplicationMode ReplicationModeConfig `toml:"replication-mode" json:"replication-mode"`
+
+	Keyspace KeyspaceConfig `toml:"keyspace" json:"keyspace"`
 }
 
 // NewConfig creates a new config.
@@ -242,6 +244,8 @@ const (
 	// DefaultMinResolvedTSPersistenceInterval is the default value of min resolved ts persistent interval.
 	DefaultMinResolvedTSPersistenceInterval = time.Second
 
+	defaultServiceGCSafePointLagThreshold = 24 * time.Hour
+
 	defaultStrictlyMatchLabel   = false
 	defaultEnablePlacementRules = true
 	defaultEnableGRPCGateway    = true
@@ -260,6 +264,10 @@ const (
 	defaultLogFormat = "text"
 
 	defaultMaxMovableHotPeerSize = int64(512)
+
+	defaultKeyspaceMinDisabledDuration    = time.Hour
+	defaultKeyspaceMinArchivedDuration    = 24 * time.Hour
+	defaultKeyspaceLifecycleCheckInterval = time.Minute
 )
 
 // Special keys for Labels
@@ -601,6 +609,8 @@ func (c *Config) Adjust(meta *toml.MetaData, reloading bool) error {
 
 	c.ReplicationMode.adjust(configMetaData.Child("replication-mode"))
 
+	c.Keyspace.adjust(configMetaData.Child("keyspace"))
+
 	c.Security.Encryption.Adjust()
 
 	if len(c.Log.Format) == 0 {
@@ -665,7 +675,8 @@ type ScheduleConfig struct {
 	MaxStorePreparingTime typeutil.Duration `toml:"max-store-preparing-time" json:"max-store-preparing-time"`
 	// LeaderScheduleLimit is the max coexist leader schedules.
 	LeaderScheduleLimit uint64 `toml:"leader-schedule-limit" json:"leader-schedule-limit"`
-	// LeaderSchedulePolicy is the option to balance leader, there are some policies supported: ["count", "size"], default: "count"
+	// LeaderSchedulePolicy is the option to balance leader, there are some policies supported:
+	// ["count", "size", "read-query", "write-query", "read-byte", "write-byte"], default: "count"
 	LeaderSchedulePolicy string `toml:"leader-schedule-policy" json:"leader-schedule-policy"`
 	// RegionScheduleLimit is the max coexist region schedules.
 	RegionScheduleLimit uint64 `toml:"region-schedule-limit" json:"region-schedule-limit"`
@@ -702,6 +713,9 @@ type ScheduleConfig struct {
 	RegionScoreFormulaVersion string `toml:"region-score-formula-version" json:"region-score-formula-version"`
 	// SchedulerMaxWaitingOperator is the max coexist operators for each scheduler.
 	SchedulerMaxWaitingOperator uint64 `toml:"scheduler-max-waiting-operator" json:"scheduler-max-waiting-operator"`
+	// OperatorQuotas customizes the quota of waiting operators for each source,
+	// which is the name of the scheduler or the checker creating the operators, e.g. "rule-checker".
+	OperatorQuotas map[string]OperatorQuotaConfig `toml:"operator-quotas" json:"operator-quotas"`
 	// WARN: DisableLearner is deprecated.
 	// DisableLearner is the option to disable using AddLearnerNode instead of AddNode.
 	DisableLearner bool `toml:"disable-raft-learner" json:"disable-raft-learner,string,omitempty"`
@@ -755,6 +769,9 @@ type ScheduleConfig struct {
 	// the load state of the cluster dynamically. User can
 	// overwrite the auto-tuned value by pd-ctl, when the value
 	// is overwritten, the value is fixed until it is deleted.
+	// When set to snapshot, PD adjusts the limit of each store
+	// according to the snapshot activity of the store, and the
+	// configured values are used as the upper bound.
 	// Default: manual
 	StoreLimitMode string `toml:"store-limit-mode" json:"store-limit-mode"`
 
@@ -764,12 +781,23 @@ type ScheduleConfig struct {
 	// The day of hot regions data to be reserved. 0 means close.
 	HotRegionsReservedDays uint64 `toml:"hot-regions-reserved-days" json:"hot-regions-reserved-days"`
 
+	// The day of finished operators history to be reserved. 0 means close.
+	OperatorHistoryReservedDays uint64 `toml:"operator-history-reserved-days" json:"operator-history-reserved-days"`
+
+	// The number of placement rule versions to be reserved. 0 means close.
+	PlacementRuleReservedVersions uint64 `toml:"placement-rule-reserved-versions" json:"placement-rule-reserved-versions"`
+
 	// MaxMovableHotPeerSize is the threshold of region size for balance hot region and split bucket scheduler.
 	// Hot region must be split before moved if it's region size is greater than MaxMovableHotPeerSize.
 	MaxMovableHotPeerSize int64 `toml:"max-movable-hot-peer-size" json:"max-movable-hot-peer-size,omitempty"`
 
 	// EnableDiagnostic is the the option to enable using diagnostic
 	EnableDiagnostic bool `toml:"enable-diagnostic" json:"enable-diagnostic,string"`
+
+	// EnableHotRegionPrediction is the option to enable predicting the recurring hot regions
+	// from the hot regions history, so that they can be scheduled before becoming hot.
+	// It requires the hot regions history, see HotRegionsReservedDays.
+	EnableHotRegionPrediction bool `toml:"enable-hot-region-prediction" json:"enable-hot-region-prediction,string"`
 }
 
 // Clone returns a cloned scheduling configuration.
@@ -782,8 +810,16 @@ func (c *ScheduleConfig) Clone() *ScheduleConfig {
 			storeLimit[k] = v
 		}
 	}
+	var operatorQuotas map[string]OperatorQuotaConfig
+	if c.OperatorQuotas != nil {
+		operatorQuotas = make(map[string]OperatorQuotaConfig, len(c.OperatorQuotas))
+		for k, v := range c.OperatorQuotas {
+			operatorQuotas[k] = v
+		}
+	}
 	cfg := *c
 	cfg.StoreLimit = storeLimit
+	cfg.OperatorQuotas = operatorQuotas
 	cfg.Schedulers = schedulers
 	cfg.SchedulersPayload = nil
 	return &cfg
@@ -796,6 +832,7 @@ const (
 	defaultMaxMergeRegionSize        = 20
 	defaultSplitMergeInterval        = time.Hour
 	defaultEnableDiagnostic          = false
+	defaultEnableHotRegionPrediction = false
 	defaultPatrolRegionInterval      = 10 * time.Millisecond
 	defaultMaxStoreDownTime          = 30 * time.Minute
 	defaultLeaderScheduleLimit       = 4
@@ -818,6 +855,9 @@ const (
 	defaultEnableCrossTableMerge       = true
 	defaultHotRegionsWriteInterval     = 10 * time.Minute
 	defaultHotRegionsReservedDays      = 7
+	defaultOperatorHistoryReservedDays = 7
+	// DefaultPlacementRuleReservedVersions is the default number of placement rule versions to be reserved.
+	DefaultPlacementRuleReservedVersions = 100
 	// It means we skip the preparing stage after the 48 hours no matter if the store has finished preparing stage.
 	defaultMaxStorePreparingTime = 48 * time.Hour
 )
@@ -881,6 +921,9 @@ func (c *ScheduleConfig) adjust(meta *configMetaData, reloading bool) error {
 	if !meta.IsDefined("enable-diagnostic") {
 		c.EnableDiagnostic = defaultEnableDiagnostic
 	}
+	if !meta.IsDefined("enable-hot-region-prediction") {
+		c.EnableHotRegionPrediction = defaultEnableHotRegionPrediction
+	}
 
 	// new cluster:v2, old cluster:v1
 	if !meta.IsDefined("region-score-formula-version") && !reloading {
@@ -910,6 +953,14 @@ func (c *ScheduleConfig) adjust(meta *configMetaData, reloading bool) error {
 		adjustUint64(&c.HotRegionsReservedDays, defaultHotRegionsReservedDays)
 	}
 
+	if !meta.IsDefined("operator-history-reserved-days") {
+		adjustUint64(&c.OperatorHistoryReservedDays, defaultOperatorHistoryReservedDays)
+	}
+
+	if !meta.IsDefined("placement-rule-reserved-versions") {
+		adjustUint64(&c.PlacementRuleReservedVersions, DefaultPlacementRuleReservedVersions)
+	}
+
 	return c.Validate()
 }
 
@@ -980,9 +1031,21 @@ func (c *ScheduleConfig) Validate() error {
 	if c.LowSpaceRatio <= c.HighSpaceRatio {
 		return errors.New("low-space-ratio should be larger than high-space-ratio")
 	}
-	if c.LeaderSchedulePolicy != "count" && c.LeaderSchedulePolicy != "size" {
+	switch c.LeaderSchedulePolicy {
+	case "count", "size", "read-query", "write-query", "read-byte", "write-byte":
+	default:
 		return errors.Errorf("leader-schedule-policy %v is invalid", c.LeaderSchedulePolicy)
 	}
+	switch c.StoreLimitMode {
+	case "auto", "manual", "snapshot":
+	default:
+		return errors.Errorf("store-limit-mode %v is invalid", c.StoreLimitMode)
+	}
+	for source, quota := range c.OperatorQuotas {
+		if quota.Weight < 0 {
+			return errors.Errorf("weight of operator quota %v should be non-negative", source)
+		}
+	}
 	for _, scheduleConfig := range c.Schedulers {
 		if !IsSchedulerRegistered(scheduleConfig.Type) {
 			return errors.Errorf("create func of %v is not registered, maybe misspelled", scheduleConfig.Type)
@@ -1023,6 +1086,16 @@ type StoreLimitConfig struct {
 	RemovePeer float64 `toml:"remove-peer" json:"remove-peer"`
 }
 
+// OperatorQuotaConfig is the quota of waiting operators from a source.
+type OperatorQuotaConfig struct {
+	// MaxWaiting is the max count of waiting operators from the source.
+	// 0 means using scheduler-max-waiting-operator.
+	MaxWaiting uint64 `toml:"max-waiting" json:"max-waiting"`
+	// Weight is the relative share of the source when promoting waiting operators
+	// with the same priority. 0 means 1.
+	Weight float64 `toml:"weight" json:"weight"`
+}
+
 // SchedulerConfigs is a slice of customized scheduler configuration.
 type SchedulerConfigs []SchedulerConfig
 
@@ -1150,6 +1223,9 @@ type PDServerConfig struct {
 	FlowRoundByDigit int `toml:"flow-round-by-digit" json:"flow-round-by-digit"`
 	// MinResolvedTSPersistenceInterval is the interval to save the min resolved ts.
 	MinResolvedTSPersistenceInterval typeutil.Duration `toml:"min-resolved-ts-persistence-interval" json:"min-resolved-ts-persistence-interval"`
+	// ServiceGCSafePointLagThreshold is the age beyond which a service GC safe point is considered lagging.
+	// 0 means disabling the check.
+	ServiceGCSafePointLagThreshold typeutil.Duration `toml:"service-gc-safepoint-lag-threshold" json:"service-gc-safepoint-lag-threshold"`
 }
 
 func (c *PDServerConfig) adjust(meta *configMetaData) error {
@@ -1175,6 +1251,9 @@ func (c *PDServerConfig) adjust(meta *configMetaData) error {
 	if !meta.IsDefined("min-resolved-ts-persistence-interval") {
 		adjustDuration(&c.MinResolvedTSPersistenceInterval, DefaultMinResolvedTSPersistenceInterval)
 	}
+	if !meta.IsDefined("service-gc-safepoint-lag-threshold") {
+		adjustDuration(&c.ServiceGCSafePointLagThreshold, defaultServiceGCSafePointLagThreshold)
+	}
 	c.migrateConfigurationFromFile(meta)
 	return c.Validate()
 }
@@ -1438,3 +1517,40 @@ type SecurityConfig struct {
 	RedactInfoLog bool              `toml:"redact-info-log" json:"redact-info-log"`
 	Encryption    encryption.Config `toml:"encryption" json:"encryption"`
 }
+
+// KeyspaceConfig is the configuration for keyspace management.
+type KeyspaceConfig struct {
+	// MinDisabledDuration is the minimum duration a keyspace must stay DISABLED before it can be archived.
+	MinDisabledDuration typeutil.Duration `toml:"min-disabled-duration" json:"min-disabled-duration"`
+	// MinArchivedDuration is the minimum duration a keyspace must stay ARCHIVED before its data
+	// is considered to be garbage collected and the keyspace can be tombstoned.
+	MinArchivedDuration typeutil.Duration `toml:"min-archived-duration" json:"min-archived-duration"`
+	// LifecycleCheckInterval is the interval to check the lifecycle of archived keyspaces.
+	LifecycleCheckInterval typeutil.Duration `toml:"lifecycle-check-interval" json:"lifecycle-check-interval"`
+}
+
+func (c *KeyspaceConfig) adjust(meta *configMetaData) {
+	// A zero dwell time is legal, so only fill in the default values when they are not configured.
+	if !meta.IsDefined("min-disabled-duration") {
+		adjustDuration(&c.MinDisabledDuration, defaultKeyspaceMinDisabledDuration)
+	}
+	if !meta.IsDefined("min-archived-duration") {
+		adjustDuration(&c.MinArchivedDuration, defaultKeyspaceMinArchivedDuration)
+	}
+	adjustDuration(&c.LifecycleCheckInterval, defaultKeyspaceLifecycleCheckInterval)
+}
+
+// GetMinDisabledDuration returns the minimum duration a keyspace must stay DISABLED before archived.
+func (c *KeyspaceConfig) GetMinDisabledDuration() time.Duration {
+	return c.MinDisabledDuration.Duration
+}
+
+// GetMinArchivedDuration returns the minimum duration a keyspace must stay ARCHIVED before tombstoned.
+func (c *KeyspaceConfig) GetMinArchivedDuration() time.Duration {
+	return c.MinArchivedDuration.Duration
+}
+
+// GetLifecycleCheckInterval returns the interval to check the lifecycle of archived keyspaces.
+func (c *KeyspaceConfig) GetLifecycleCheckInterval() time.Duration {
+	return c.LifecycleCheckInterval.Duration
+}
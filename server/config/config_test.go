@@ -151,6 +151,11 @@ func TestValidation(t *testing.T) {
 	re.NoError(cfg.Schedule.Validate())
 	cfg.Schedule.TolerantSizeRatio = -0.6
 	re.Error(cfg.Schedule.Validate())
+	cfg.Schedule.TolerantSizeRatio = 0
+	cfg.Schedule.StoreLimitMode = "snapshot"
+	re.NoError(cfg.Schedule.Validate())
+	cfg.Schedule.StoreLimitMode = "snap"
+	re.Error(cfg.Schedule.Validate())
 	// check quota
 	re.Equal(defaultQuotaBackendBytes, cfg.QuotaBackendBytes)
 	// check request bytes
@@ -50,6 +50,9 @@ type PersistOptions struct {
 	replicationMode atomic.Value
 	labelProperty   atomic.Value
 	clusterVersion  unsafe.Pointer
+	// windowLimits is the schedule limits overridden by the active scheduling window of a
+	// scheduler or checker, it is only set in the view returned by WithWindowLimits.
+	windowLimits map[string]uint64
 }
 
 // NewPersistOptions creates a new PersistOptions instance.
@@ -284,6 +287,18 @@ func (o *PersistOptions) SetEnableDiagnostic(enable bool) {
 	o.SetScheduleConfig(v)
 }
 
+// IsHotRegionPredictionEnabled returns whether the recurring hot regions are predicted from the history.
+func (o *PersistOptions) IsHotRegionPredictionEnabled() bool {
+	return o.GetScheduleConfig().EnableHotRegionPrediction
+}
+
+// SetEnableHotRegionPrediction to set the option for hot region prediction. It's only used to test.
+func (o *PersistOptions) SetEnableHotRegionPrediction(enable bool) {
+	v := o.GetScheduleConfig().Clone()
+	v.EnableHotRegionPrediction = enable
+	o.SetScheduleConfig(v)
+}
+
 // SetMaxMergeRegionSize sets the max merge region size.
 func (o *PersistOptions) SetMaxMergeRegionSize(maxMergeRegionSize uint64) {
 	v := o.GetScheduleConfig().Clone()
@@ -371,27 +386,27 @@ func (o *PersistOptions) GetMaxStorePreparingTime() time.Duration {
 
 // GetLeaderScheduleLimit returns the limit for leader schedule.
 func (o *PersistOptions) GetLeaderScheduleLimit() uint64 {
-	return o.getTTLUintOr(leaderScheduleLimitKey, o.GetScheduleConfig().LeaderScheduleLimit)
+	return o.getTTLUintOr(leaderScheduleLimitKey, o.getWindowLimitOr(leaderScheduleLimitKey, o.GetScheduleConfig().LeaderScheduleLimit))
 }
 
 // GetRegionScheduleLimit returns the limit for region schedule.
 func (o *PersistOptions) GetRegionScheduleLimit() uint64 {
-	return o.getTTLUintOr(regionScheduleLimitKey, o.GetScheduleConfig().RegionScheduleLimit)
+	return o.getTTLUintOr(regionScheduleLimitKey, o.getWindowLimitOr(regionScheduleLimitKey, o.GetScheduleConfig().RegionScheduleLimit))
 }
 
 // GetReplicaScheduleLimit returns the limit for replica schedule.
 func (o *PersistOptions) GetReplicaScheduleLimit() uint64 {
-	return o.getTTLUintOr(replicaRescheduleLimitKey, o.GetScheduleConfig().ReplicaScheduleLimit)
+	return o.getTTLUintOr(replicaRescheduleLimitKey, o.getWindowLimitOr(replicaRescheduleLimitKey, o.GetScheduleConfig().ReplicaScheduleLimit))
 }
 
 // GetMergeScheduleLimit returns the limit for merge schedule.
 func (o *PersistOptions) GetMergeScheduleLimit() uint64 {
-	return o.getTTLUintOr(mergeScheduleLimitKey, o.GetScheduleConfig().MergeScheduleLimit)
+	return o.getTTLUintOr(mergeScheduleLimitKey, o.getWindowLimitOr(mergeScheduleLimitKey, o.GetScheduleConfig().MergeScheduleLimit))
 }
 
 // GetHotRegionScheduleLimit returns the limit for hot region schedule.
 func (o *PersistOptions) GetHotRegionScheduleLimit() uint64 {
-	return o.getTTLUintOr(hotRegionScheduleLimitKey, o.GetScheduleConfig().HotRegionScheduleLimit)
+	return o.getTTLUintOr(hotRegionScheduleLimitKey, o.getWindowLimitOr(hotRegionScheduleLimitKey, o.GetScheduleConfig().HotRegionScheduleLimit))
 }
 
 // GetStoreLimit returns the limit of a store.
@@ -489,6 +504,19 @@ func (o *PersistOptions) GetSchedulerMaxWaitingOperator() uint64 {
 	return o.getTTLUintOr(schedulerMaxWaitingOperatorKey, o.GetScheduleConfig().SchedulerMaxWaitingOperator)
 }
 
+// GetOperatorQuota returns the quota of waiting operators from the source.
+// The unset fields are filled with the default values.
+func (o *PersistOptions) GetOperatorQuota(source string) OperatorQuotaConfig {
+	quota := o.GetScheduleConfig().OperatorQuotas[source]
+	if quota.MaxWaiting == 0 {
+		quota.MaxWaiting = o.GetSchedulerMaxWaitingOperator()
+	}
+	if quota.Weight <= 0 {
+		quota.Weight = 1
+	}
+	return quota
+}
+
 // GetLeaderSchedulePolicy is to get leader schedule policy.
 func (o *PersistOptions) GetLeaderSchedulePolicy() core.SchedulePolicy {
 	return core.StringToSchedulePolicy(o.GetScheduleConfig().LeaderSchedulePolicy)
@@ -594,6 +622,16 @@ func (o *PersistOptions) GetHotRegionsReservedDays() uint64 {
 	return o.GetScheduleConfig().HotRegionsReservedDays
 }
 
+// GetOperatorHistoryReservedDays gets days the history of finished operators is kept.
+func (o *PersistOptions) GetOperatorHistoryReservedDays() uint64 {
+	return o.GetScheduleConfig().OperatorHistoryReservedDays
+}
+
+// GetPlacementRuleReservedVersions gets the number of placement rule versions to be kept.
+func (o *PersistOptions) GetPlacementRuleReservedVersions() uint64 {
+	return o.GetScheduleConfig().PlacementRuleReservedVersions
+}
+
 // AddSchedulerCfg adds the scheduler configurations.
 func (o *PersistOptions) AddSchedulerCfg(tp string, args []string) {
 	v := o.GetScheduleConfig().Clone()
@@ -715,6 +753,64 @@ func (o *PersistOptions) GetMinResolvedTSPersistenceInterval() time.Duration {
 	return o.GetPDServerConfig().MinResolvedTSPersistenceInterval.Duration
 }
 
+// GetServiceGCSafePointLagThreshold gets the age beyond which a service GC safe point is considered lagging.
+func (o *PersistOptions) GetServiceGCSafePointLagThreshold() time.Duration {
+	return o.GetPDServerConfig().ServiceGCSafePointLagThreshold.Duration
+}
+
+// windowLimitKeys are the keys of the schedule limits which can be overridden by the scheduling windows.
+var windowLimitKeys = []string{
+	leaderScheduleLimitKey,
+	regionScheduleLimitKey,
+	replicaRescheduleLimitKey,
+	mergeScheduleLimitKey,
+	hotRegionScheduleLimitKey,
+}
+
+// windowLimitName returns the name of the limit in the scheduling windows, which is the config key without the "schedule." prefix.
+func windowLimitName(key string) string {
+	return strings.TrimPrefix(key, "schedule.")
+}
+
+// IsWindowLimit returns whether the schedule limit with the given name, e.g. "region-schedule-limit",
+// can be overridden by the scheduling windows.
+func IsWindowLimit(name string) bool {
+	for _, key := range windowLimitKeys {
+		if windowLimitName(key) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WithWindowLimits returns a read-only view of the options whose schedule limits are overridden by the
+// limits of the active scheduling window of a scheduler or checker. The limits take precedence over the
+// config but not over the temporary configuration. The view is a snapshot and the changes made through
+// it are not applied to the options.
+func (o *PersistOptions) WithWindowLimits(limits map[string]uint64) *PersistOptions {
+	if len(limits) == 0 {
+		return o
+	}
+	v := &PersistOptions{
+		ttl:            o.ttl,
+		clusterVersion: atomic.LoadPointer(&o.clusterVersion),
+		windowLimits:   limits,
+	}
+	v.schedule.Store(o.schedule.Load())
+	v.replication.Store(o.replication.Load())
+	v.pdServerConfig.Store(o.pdServerConfig.Load())
+	v.replicationMode.Store(o.replicationMode.Load())
+	v.labelProperty.Store(o.labelProperty.Load())
+	return v
+}
+
+func (o *PersistOptions) getWindowLimitOr(key string, defaultValue uint64) uint64 {
+	if v, ok := o.windowLimits[windowLimitName(key)]; ok {
+		return v
+	}
+	return defaultValue
+}
+
 const ttlConfigPrefix = "/config/ttl"
 
 // SetTTLData set temporary configuration
@@ -68,6 +68,14 @@ const (
 	ByCount SchedulePolicy = iota
 	// BySize indicates that balance by size
 	BySize
+	// ByReadQuery indicates that balance by the read query rate of leaders
+	ByReadQuery
+	// ByWriteQuery indicates that balance by the write query rate of leaders
+	ByWriteQuery
+	// ByReadByte indicates that balance by the read byte rate of leaders
+	ByReadByte
+	// ByWriteByte indicates that balance by the write byte rate of leaders
+	ByWriteByte
 )
 
 func (k SchedulePolicy) String() string {
@@ -76,11 +84,29 @@ func (k SchedulePolicy) String() string {
 		return "count"
 	case BySize:
 		return "size"
+	case ByReadQuery:
+		return "read-query"
+	case ByWriteQuery:
+		return "write-query"
+	case ByReadByte:
+		return "read-byte"
+	case ByWriteByte:
+		return "write-byte"
 	default:
 		return "unknown"
 	}
 }
 
+// IsLoadPolicy returns whether the policy balances on the load of stores.
+func (k SchedulePolicy) IsLoadPolicy() bool {
+	switch k {
+	case ByReadQuery, ByWriteQuery, ByReadByte, ByWriteByte:
+		return true
+	default:
+		return false
+	}
+}
+
 // StringToSchedulePolicy creates a schedule policy with string.
 func StringToSchedulePolicy(input string) SchedulePolicy {
 	switch input {
@@ -88,6 +114,14 @@ func StringToSchedulePolicy(input string) SchedulePolicy {
 		return BySize
 	case ByCount.String():
 		return ByCount
+	case ByReadQuery.String():
+		return ByReadQuery
+	case ByWriteQuery.String():
+		return ByWriteQuery
+	case ByReadByte.String():
+		return ByReadByte
+	case ByWriteByte.String():
+		return ByWriteByte
 	default:
 		panic("invalid schedule policy: " + input)
 	}
@@ -83,6 +83,8 @@ type PeerInfo struct {
 	*metapb.Peer
 	loads    []float64
 	interval uint64
+	// engine is the engine of the store where the peer is located.
+	engine string
 }
 
 // NewPeerInfo creates PeerInfo
@@ -113,3 +115,17 @@ func (p *PeerInfo) GetPeerID() uint64 {
 func (p *PeerInfo) GetInterval() uint64 {
 	return p.interval
 }
+
+// WithEngine sets the engine of the store where the peer is located.
+func (p *PeerInfo) WithEngine(engine string) *PeerInfo {
+	p.engine = engine
+	return p
+}
+
+// GetEngine returns the engine of the store where the peer is located.
+func (p *PeerInfo) GetEngine() string {
+	if p.engine == "" {
+		return EngineTiKV
+	}
+	return p.engine
+}
@@ -539,6 +539,28 @@ func (r *RegionInfo) GetWriteRate() (bytesRate, keysRate float64) {
 	return 0, 0
 }
 
+// GetLeaderLoadRate returns the rate of the load which the load-based leader schedule
+// policy balances on, it returns 0 for the other policies.
+func (r *RegionInfo) GetLeaderLoadRate(policy SchedulePolicy) int64 {
+	reportInterval := r.GetInterval()
+	interval := reportInterval.GetEndTimestamp() - reportInterval.GetStartTimestamp()
+	if interval < statsReportMinInterval || interval > statsReportMaxInterval {
+		return 0
+	}
+	var load uint64
+	switch policy {
+	case ByReadQuery:
+		load = r.GetReadQueryNum()
+	case ByWriteQuery:
+		load = r.GetWriteQueryNum()
+	case ByReadByte:
+		load = r.GetBytesRead()
+	case ByWriteByte:
+		load = r.GetBytesWritten()
+	}
+	return int64(float64(load) / float64(interval))
+}
+
 // GetLeader returns the leader of the region.
 func (r *RegionInfo) GetLeader() *metapb.Peer {
 	return r.leader
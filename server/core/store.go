@@ -126,6 +126,16 @@ func (s *StoreInfo) IsTiFlash() bool {
 	return IsStoreContainLabel(s.GetMeta(), EngineKey, EngineTiFlash)
 }
 
+// GetEngine returns the engine of the store. The store without the engine label is tikv.
+func (s *StoreInfo) GetEngine() string {
+	for _, l := range s.GetLabels() {
+		if l.GetKey() == EngineKey && l.GetValue() != "" {
+			return l.GetValue()
+		}
+	}
+	return EngineTiKV
+}
+
 // IsUp returns true if store is serving or preparing.
 func (s *StoreInfo) IsUp() bool {
 	return s.IsServing() || s.IsPreparing()
@@ -287,6 +297,12 @@ func (s *StoreInfo) LeaderScore(policy SchedulePolicy, delta int64) float64 {
 	}
 }
 
+// LeaderLoadScore returns the store's leader score with the given load, which is used
+// by the load-based leader schedule policies.
+func (s *StoreInfo) LeaderLoadScore(load float64, delta int64) float64 {
+	return (load + float64(delta)) / math.Max(s.GetLeaderWeight(), minWeight)
+}
+
 // RegionScore returns the store's region score.
 // Deviation It is used to control the direction of the deviation considered
 // when calculating the region score. It is set to -1 when it is the source
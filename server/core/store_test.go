@@ -142,6 +142,18 @@ func TestLowSpaceRatio(t *testing.T) {
 	re.False(store.IsLowSpace(0.8))
 }
 
+func TestGetEngine(t *testing.T) {
+	re := require.New(t)
+	re.Equal(EngineTiKV, NewStoreInfoWithLabel(1, 20, nil).GetEngine())
+	re.Equal(EngineTiKV, NewStoreInfoWithLabel(1, 20, map[string]string{EngineKey: ""}).GetEngine())
+	store := NewStoreInfoWithLabel(1, 20, map[string]string{EngineKey: EngineTiFlash})
+	re.Equal(EngineTiFlash, store.GetEngine())
+	re.True(store.IsTiFlash())
+	store = NewStoreInfoWithLabel(1, 20, map[string]string{EngineKey: "columnar"})
+	re.Equal("columnar", store.GetEngine())
+	re.False(store.IsTiFlash())
+}
+
 func TestLowSpaceScoreV2(t *testing.T) {
 	re := require.New(t)
 	testdata := []struct {
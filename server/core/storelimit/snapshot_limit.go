@@ -0,0 +1,135 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storelimit
+
+import (
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/tikv/pd/pkg/movingaverage"
+	"github.com/tikv/pd/pkg/syncutil"
+)
+
+const (
+	// snapshotCongestedCount is the count of snapshots in progress from which a store is regarded as congested.
+	snapshotCongestedCount = 3
+	// snapshotSlowStepDuration is the average duration of the snapshot steps from which a store is regarded as congested.
+	snapshotSlowStepDuration = 2 * time.Minute
+	// minSnapshotRatio is the min ratio of the effective rate to the configured rate.
+	minSnapshotRatio = 0.1
+	// snapshotBackoffRatio is multiplied to the ratio when the store is congested.
+	snapshotBackoffRatio = 0.5
+	// snapshotRampUpStep is added to the ratio when the store is idle.
+	snapshotRampUpStep = 0.1
+)
+
+// SnapshotController adjusts the store limits according to the snapshot activity of stores.
+// The effective rate of a store limit is the configured rate multiplied by a ratio in
+// [minSnapshotRatio, 1]. The ratio backs off when the store is congested with snapshots,
+// and ramps up when the store is idle.
+//
+// The add-peer limit follows the snapshots received and applied by the store, and the
+// store is also regarded as congested if the steps adding peers to it are slow while
+// there are snapshots in progress. The remove-peer limit follows the snapshots sent by the store.
+type SnapshotController struct {
+	mu            syncutil.RWMutex
+	ratios        map[uint64]map[Type]float64
+	stepDurations map[uint64]*movingaverage.EMA
+}
+
+// NewSnapshotController creates a SnapshotController.
+func NewSnapshotController() *SnapshotController {
+	return &SnapshotController{
+		ratios:        make(map[uint64]map[Type]float64),
+		stepDurations: make(map[uint64]*movingaverage.EMA),
+	}
+}
+
+// ObserveStepDuration observes the duration of a step which adds a peer to the store.
+func (c *SnapshotController) ObserveStepDuration(storeID uint64, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ema, ok := c.stepDurations[storeID]
+	if !ok {
+		ema = movingaverage.NewEMA()
+		c.stepDurations[storeID] = ema
+	}
+	ema.Add(d.Seconds())
+}
+
+// Collect adjusts the ratios of the store with the snapshot statistics in the store heartbeat.
+func (c *SnapshotController) Collect(stats *pdpb.StoreStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	storeID := stats.GetStoreId()
+	ratios, ok := c.ratios[storeID]
+	if !ok {
+		ratios = map[Type]float64{AddPeer: 1, RemovePeer: 1}
+		c.ratios[storeID] = ratios
+	}
+
+	var stepDuration time.Duration
+	if ema, ok := c.stepDurations[storeID]; ok {
+		stepDuration = time.Duration(ema.Get() * float64(time.Second))
+	}
+	incoming := stats.GetReceivingSnapCount() + stats.GetApplyingSnapCount()
+	switch {
+	case incoming >= snapshotCongestedCount || (incoming > 0 && stepDuration >= snapshotSlowStepDuration):
+		ratios[AddPeer] = backoffRatio(ratios[AddPeer])
+	case incoming == 0:
+		ratios[AddPeer] = rampUpRatio(ratios[AddPeer])
+	}
+
+	switch outgoing := stats.GetSendingSnapCount(); {
+	case outgoing >= snapshotCongestedCount:
+		ratios[RemovePeer] = backoffRatio(ratios[RemovePeer])
+	case outgoing == 0:
+		ratios[RemovePeer] = rampUpRatio(ratios[RemovePeer])
+	}
+}
+
+// RemoveStore removes the statistics of the store which no longer exists.
+func (c *SnapshotController) RemoveStore(storeID uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.ratios, storeID)
+	delete(c.stepDurations, storeID)
+}
+
+// Ratio returns the ratio of the effective rate to the configured rate of the store limit.
+func (c *SnapshotController) Ratio(storeID uint64, typ Type) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if ratio, ok := c.ratios[storeID][typ]; ok {
+		return ratio
+	}
+	return 1
+}
+
+func backoffRatio(ratio float64) float64 {
+	ratio *= snapshotBackoffRatio
+	if ratio < minSnapshotRatio {
+		return minSnapshotRatio
+	}
+	return ratio
+}
+
+func rampUpRatio(ratio float64) float64 {
+	ratio += snapshotRampUpStep
+	if ratio > 1 {
+		return 1
+	}
+	return ratio
+}
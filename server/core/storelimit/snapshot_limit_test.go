@@ -0,0 +1,88 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotController(t *testing.T) {
+	re := require.New(t)
+	c := NewSnapshotController()
+	re.Equal(1.0, c.Ratio(1, AddPeer))
+
+	// back off on the congested store.
+	congested := &pdpb.StoreStats{StoreId: 1, ReceivingSnapCount: 2, ApplyingSnapCount: 1}
+	c.Collect(congested)
+	re.Equal(0.5, c.Ratio(1, AddPeer))
+	re.Equal(1.0, c.Ratio(1, RemovePeer))
+	for i := 0; i < 10; i++ {
+		c.Collect(congested)
+	}
+	re.Equal(minSnapshotRatio, c.Ratio(1, AddPeer))
+	// the add-peer limit ramps up since the store does not receive snapshots.
+	c.Collect(&pdpb.StoreStats{StoreId: 1, SendingSnapCount: 3})
+	re.InDelta(0.2, c.Ratio(1, AddPeer), 1e-9)
+	re.Equal(0.5, c.Ratio(1, RemovePeer))
+	// the other stores are not affected.
+	re.Equal(1.0, c.Ratio(2, AddPeer))
+
+	// keep the ratio if the store is busy but not congested.
+	c.Collect(&pdpb.StoreStats{StoreId: 1, ReceivingSnapCount: 1, SendingSnapCount: 1})
+	re.InDelta(0.2, c.Ratio(1, AddPeer), 1e-9)
+	re.Equal(0.5, c.Ratio(1, RemovePeer))
+
+	// ramp up on the idle store.
+	idle := &pdpb.StoreStats{StoreId: 1}
+	c.Collect(idle)
+	re.InDelta(0.3, c.Ratio(1, AddPeer), 1e-9)
+	re.InDelta(0.6, c.Ratio(1, RemovePeer), 1e-9)
+	for i := 0; i < 10; i++ {
+		c.Collect(idle)
+	}
+	re.Equal(1.0, c.Ratio(1, AddPeer))
+	re.Equal(1.0, c.Ratio(1, RemovePeer))
+
+	// the store is congested if the steps adding peers are slow while receiving snapshots.
+	c.ObserveStepDuration(1, 3*time.Minute)
+	c.Collect(idle)
+	re.Equal(1.0, c.Ratio(1, AddPeer))
+	c.Collect(&pdpb.StoreStats{StoreId: 1, ApplyingSnapCount: 1})
+	re.Equal(0.5, c.Ratio(1, AddPeer))
+
+	// the statistics are removed with the store.
+	c.RemoveStore(1)
+	re.Equal(1.0, c.Ratio(1, AddPeer))
+	re.Empty(c.ratios)
+	re.Empty(c.stepDurations)
+}
+
+func TestStoreLimitSetRate(t *testing.T) {
+	re := require.New(t)
+	limit := NewStoreLimit(1, RegionInfluence[AddPeer])
+	re.True(limit.Available(RegionInfluence[AddPeer]))
+	limit.Take(RegionInfluence[AddPeer])
+	re.False(limit.Available(RegionInfluence[AddPeer]))
+	// adjusting the rate does not refill the bucket.
+	limit.SetRate(2)
+	re.Equal(2.0, limit.Rate())
+	re.False(limit.Available(RegionInfluence[AddPeer]))
+	limit.SetRate(Unlimited)
+	re.Equal(Unlimited, limit.Rate())
+}
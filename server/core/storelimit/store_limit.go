@@ -15,7 +15,11 @@
 package storelimit
 
 import (
+	"math"
+	"sync/atomic"
+
 	"github.com/tikv/pd/pkg/ratelimit"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -68,11 +72,22 @@ func (t Type) String() string {
 type StoreLimit struct {
 	limiter         *ratelimit.RateLimiter
 	regionInfluence int64
-	ratePerSec      float64
+	// ratePerSec is the bits of the float64 rate, which can be adjusted concurrently.
+	ratePerSec uint64
 }
 
 // NewStoreLimit returns a StoreLimit object
 func NewStoreLimit(ratePerSec float64, regionInfluence int64) *StoreLimit {
+	fillRate, capacity := limiterConfig(ratePerSec, regionInfluence)
+	return &StoreLimit{
+		limiter:         ratelimit.NewRateLimiter(fillRate, int(capacity)),
+		regionInfluence: regionInfluence,
+		ratePerSec:      math.Float64bits(ratePerSec),
+	}
+}
+
+// limiterConfig returns the fill rate and the capacity of the token bucket for the rate.
+func limiterConfig(ratePerSec float64, regionInfluence int64) (float64, int64) {
 	capacity := regionInfluence
 	rate := ratePerSec
 	// unlimited
@@ -84,11 +99,16 @@ func NewStoreLimit(ratePerSec float64, regionInfluence int64) *StoreLimit {
 	} else {
 		ratePerSec *= float64(regionInfluence)
 	}
-	return &StoreLimit{
-		limiter:         ratelimit.NewRateLimiter(ratePerSec, int(capacity)),
-		regionInfluence: regionInfluence,
-		ratePerSec:      rate,
-	}
+	return ratePerSec, capacity
+}
+
+// SetRate adjusts the rate of the store limit. Unlike creating a new store limit, the
+// bucket is not refilled, so the tokens taken before are still counted.
+func (l *StoreLimit) SetRate(ratePerSec float64) {
+	fillRate, capacity := limiterConfig(ratePerSec, l.regionInfluence)
+	l.limiter.SetLimit(rate.Limit(fillRate))
+	l.limiter.SetBurst(int(capacity))
+	atomic.StoreUint64(&l.ratePerSec, math.Float64bits(ratePerSec))
 }
 
 // Available returns the number of available tokens
@@ -99,7 +119,7 @@ func (l *StoreLimit) Available(n int64) bool {
 
 // Rate returns the fill rate of the bucket, in tokens per second.
 func (l *StoreLimit) Rate() float64 {
-	return l.ratePerSec
+	return math.Float64frombits(atomic.LoadUint64(&l.ratePerSec))
 }
 
 // Take takes count tokens from the bucket without blocking.
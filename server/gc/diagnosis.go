@@ -0,0 +1,185 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/logutil"
+	"github.com/tikv/pd/pkg/tsoutil"
+	"github.com/tikv/pd/pkg/typeutil"
+)
+
+const metricsUpdateInterval = 30 * time.Second
+
+// Config is the config needed by the GC safe point diagnosis.
+type Config interface {
+	// GetServiceGCSafePointLagThreshold returns the age beyond which a service safe point is considered lagging.
+	GetServiceGCSafePointLagThreshold() time.Duration
+}
+
+// ServiceSafePointDiagnosis is the diagnosis of a service safe point.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type ServiceSafePointDiagnosis struct {
+	ServiceID string `json:"service_id"`
+	SafePoint uint64 `json:"safe_point"`
+	// ExpiredAt is the unix time when the service safe point expires.
+	ExpiredAt int64 `json:"expired_at"`
+	// AgeSeconds is how long the safe point lags behind the current time.
+	AgeSeconds float64 `json:"age_seconds"`
+	// TTLLeftSeconds is the time left before the safe point expires, -1 means it never expires.
+	TTLLeftSeconds int64 `json:"ttl_left_seconds"`
+	// GapToGCSafePointSeconds is how far the safe point is ahead of the GC safe point.
+	GapToGCSafePointSeconds float64 `json:"gap_to_gc_safe_point_seconds"`
+	Expired                 bool    `json:"expired"`
+	// Lagging indicates that the age of the safe point exceeds the lag threshold.
+	Lagging bool `json:"lagging"`
+	// Blocking indicates that the safe point is the minimum one, which holds back the GC.
+	Blocking bool `json:"blocking"`
+}
+
+// GCBlockerDiagnosis is the diagnosis of which service safe points are holding back the GC.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type GCBlockerDiagnosis struct {
+	GCSafePoint           uint64            `json:"gc_safe_point"`
+	GCSafePointAgeSeconds float64           `json:"gc_safe_point_age_seconds"`
+	LagThreshold          typeutil.Duration `json:"lag_threshold"`
+	// BlockerServiceID is the id of the service whose safe point is the minimum one.
+	BlockerServiceID  string                       `json:"blocker_service_id"`
+	ServiceSafePoints []*ServiceSafePointDiagnosis `json:"service_gc_safe_points"`
+}
+
+// DiagnoseServiceGCSafePoints diagnoses all service safe points against the given current time.
+// A service safe point is lagging if it lags behind now beyond lagThreshold, a non-positive
+// lagThreshold disables the lagging check. The service safe points are sorted by safe point.
+func (manager *SafePointManager) DiagnoseServiceGCSafePoints(now time.Time, lagThreshold time.Duration) (*GCBlockerDiagnosis, error) {
+	manager.serviceGCLock.Lock()
+	gcSafePoint, err := manager.store.LoadGCSafePoint()
+	if err != nil {
+		manager.serviceGCLock.Unlock()
+		return nil, err
+	}
+	ssps, err := manager.store.LoadAllServiceGCSafePoints()
+	manager.serviceGCLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	gcSafePointTime, _ := tsoutil.ParseTS(gcSafePoint)
+	diagnosis := &GCBlockerDiagnosis{
+		GCSafePoint:           gcSafePoint,
+		GCSafePointAgeSeconds: now.Sub(gcSafePointTime).Seconds(),
+		LagThreshold:          typeutil.NewDuration(lagThreshold),
+		ServiceSafePoints:     make([]*ServiceSafePointDiagnosis, 0, len(ssps)),
+	}
+	var blocker *ServiceSafePointDiagnosis
+	for _, ssp := range ssps {
+		safePointTime, _ := tsoutil.ParseTS(ssp.SafePoint)
+		d := &ServiceSafePointDiagnosis{
+			ServiceID:               ssp.ServiceID,
+			SafePoint:               ssp.SafePoint,
+			ExpiredAt:               ssp.ExpiredAt,
+			AgeSeconds:              now.Sub(safePointTime).Seconds(),
+			TTLLeftSeconds:          ssp.ExpiredAt - now.Unix(),
+			GapToGCSafePointSeconds: safePointTime.Sub(gcSafePointTime).Seconds(),
+			Expired:                 ssp.ExpiredAt < now.Unix(),
+		}
+		if ssp.ExpiredAt == math.MaxInt64 {
+			d.TTLLeftSeconds = -1
+		}
+		if d.Expired {
+			d.TTLLeftSeconds = 0
+		} else {
+			d.Lagging = lagThreshold > 0 && now.Sub(safePointTime) > lagThreshold
+			if blocker == nil || d.SafePoint < blocker.SafePoint {
+				blocker = d
+			}
+		}
+		diagnosis.ServiceSafePoints = append(diagnosis.ServiceSafePoints, d)
+	}
+	if blocker != nil {
+		blocker.Blocking = true
+		diagnosis.BlockerServiceID = blocker.ServiceID
+	}
+	sort.SliceStable(diagnosis.ServiceSafePoints, func(i, j int) bool {
+		return diagnosis.ServiceSafePoints[i].SafePoint < diagnosis.ServiceSafePoints[j].SafePoint
+	})
+	return diagnosis, nil
+}
+
+// RunMetricsJob periodically exports the diagnosis of service safe points as metrics.
+// The current time is derived from the TSO returned by getTS, the same time source as
+// the safe points and the diagnosis API, so that the ages are not skewed by the local clock.
+// It should only be run on the PD leader and exits when ctx is canceled.
+func (manager *SafePointManager) RunMetricsJob(ctx context.Context, cfg Config, getTS func() (uint64, error)) {
+	defer logutil.LogPanic()
+	defer resetMetrics()
+
+	ticker := time.NewTicker(metricsUpdateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("service safe point metrics job has been stopped")
+			return
+		case <-ticker.C:
+			nowTS, err := getTS()
+			if err != nil {
+				log.Warn("failed to get the current time to diagnose service safe points", errs.ZapError(err))
+				continue
+			}
+			now, _ := tsoutil.ParseTS(nowTS)
+			diagnosis, err := manager.DiagnoseServiceGCSafePoints(now, cfg.GetServiceGCSafePointLagThreshold())
+			if err != nil {
+				log.Warn("failed to diagnose service safe points", errs.ZapError(err))
+				continue
+			}
+			diagnosis.updateMetrics()
+		}
+	}
+}
+
+func (d *GCBlockerDiagnosis) updateMetrics() {
+	resetMetrics()
+	gcSafePointAge.Set(d.GCSafePointAgeSeconds)
+	serviceSafePointLagThreshold.Set(d.LagThreshold.Seconds())
+	for _, ssp := range d.ServiceSafePoints {
+		if ssp.Expired {
+			continue
+		}
+		serviceSafePointAge.WithLabelValues(ssp.ServiceID).Set(ssp.AgeSeconds)
+		serviceSafePointGap.WithLabelValues(ssp.ServiceID).Set(ssp.GapToGCSafePointSeconds)
+		serviceSafePointTTLLeft.WithLabelValues(ssp.ServiceID).Set(float64(ssp.TTLLeftSeconds))
+		lagging := 0.0
+		if ssp.Lagging {
+			lagging = 1
+		}
+		serviceSafePointLagging.WithLabelValues(ssp.ServiceID).Set(lagging)
+	}
+}
+
+func resetMetrics() {
+	gcSafePointAge.Set(0)
+	serviceSafePointLagThreshold.Set(0)
+	serviceSafePointAge.Reset()
+	serviceSafePointGap.Reset()
+	serviceSafePointTTLLeft.Reset()
+	serviceSafePointLagging.Reset()
+}
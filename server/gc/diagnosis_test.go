@@ -0,0 +1,77 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/tsoutil"
+)
+
+func TestDiagnoseServiceGCSafePoints(t *testing.T) {
+	re := require.New(t)
+	manager := NewSafePointManager(newGCStorage())
+	now := time.Now()
+	toTS := func(t time.Time) uint64 {
+		return tsoutil.GenerateTS(tsoutil.GenerateTimestamp(t, 0))
+	}
+
+	_, err := manager.UpdateGCSafePoint(toTS(now.Add(-3 * time.Hour)))
+	re.NoError(err)
+	_, _, err = manager.UpdateServiceGCSafePoint("gc_worker", toTS(now.Add(-2*time.Hour)), math.MaxInt64, now)
+	re.NoError(err)
+	_, _, err = manager.UpdateServiceGCSafePoint("br", toTS(now.Add(-time.Hour)), 600, now)
+	re.NoError(err)
+	_, _, err = manager.UpdateServiceGCSafePoint("cdc", toTS(now.Add(-30*time.Minute)), 60, now)
+	re.NoError(err)
+
+	// Move the time forward, so that cdc has expired.
+	now = now.Add(2 * time.Minute)
+	diagnosis, err := manager.DiagnoseServiceGCSafePoints(now, 90*time.Minute)
+	re.NoError(err)
+	re.InDelta((3*time.Hour + 2*time.Minute).Seconds(), diagnosis.GCSafePointAgeSeconds, 1)
+	re.Equal("gc_worker", diagnosis.BlockerServiceID)
+	re.Len(diagnosis.ServiceSafePoints, 3)
+
+	gcWorker, br, cdc := diagnosis.ServiceSafePoints[0], diagnosis.ServiceSafePoints[1], diagnosis.ServiceSafePoints[2]
+	re.Equal("gc_worker", gcWorker.ServiceID)
+	re.True(gcWorker.Blocking)
+	re.True(gcWorker.Lagging)
+	re.Equal(int64(-1), gcWorker.TTLLeftSeconds)
+	re.InDelta(time.Hour.Seconds(), gcWorker.GapToGCSafePointSeconds, 1)
+
+	re.Equal("br", br.ServiceID)
+	re.False(br.Blocking)
+	re.False(br.Lagging)
+	re.False(br.Expired)
+	re.Equal(int64(480), br.TTLLeftSeconds)
+	re.InDelta((time.Hour + 2*time.Minute).Seconds(), br.AgeSeconds, 1)
+	re.InDelta((2 * time.Hour).Seconds(), br.GapToGCSafePointSeconds, 1)
+
+	re.Equal("cdc", cdc.ServiceID)
+	re.True(cdc.Expired)
+	re.False(cdc.Blocking)
+	re.Equal(int64(0), cdc.TTLLeftSeconds)
+
+	// Non-positive threshold disables the lagging check.
+	diagnosis, err = manager.DiagnoseServiceGCSafePoints(now, 0)
+	re.NoError(err)
+	for _, ssp := range diagnosis.ServiceSafePoints {
+		re.False(ssp.Lagging)
+	}
+}
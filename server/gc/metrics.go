@@ -0,0 +1,78 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const serviceLabel = "service"
+
+var (
+	gcSafePointAge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "gc",
+			Name:      "safepoint_age_seconds",
+			Help:      "How long the GC safe point lags behind the current time.",
+		})
+
+	serviceSafePointAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "gc",
+			Name:      "service_safepoint_age_seconds",
+			Help:      "How long the service safe point lags behind the current time.",
+		}, []string{serviceLabel})
+
+	serviceSafePointGap = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "gc",
+			Name:      "service_safepoint_gap_seconds",
+			Help:      "The gap between the service safe point and the GC safe point.",
+		}, []string{serviceLabel})
+
+	serviceSafePointTTLLeft = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "gc",
+			Name:      "service_safepoint_ttl_left_seconds",
+			Help:      "The time left before the service safe point expires, -1 means never expire.",
+		}, []string{serviceLabel})
+
+	serviceSafePointLagging = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "gc",
+			Name:      "service_safepoint_lagging",
+			Help:      "Whether the service safe point lags behind the current time beyond the threshold, 1 means lagging.",
+		}, []string{serviceLabel})
+
+	serviceSafePointLagThreshold = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "gc",
+			Name:      "service_safepoint_lag_threshold_seconds",
+			Help:      "The threshold of the service safe point age to be considered as lagging.",
+		})
+)
+
+func init() {
+	prometheus.MustRegister(gcSafePointAge)
+	prometheus.MustRegister(serviceSafePointAge)
+	prometheus.MustRegister(serviceSafePointGap)
+	prometheus.MustRegister(serviceSafePointTTLLeft)
+	prometheus.MustRegister(serviceSafePointLagging)
+	prometheus.MustRegister(serviceSafePointLagThreshold)
+}
@@ -16,21 +16,32 @@ package gc
 
 import (
 	"math"
+	"strconv"
 	"time"
 
 	"github.com/tikv/pd/pkg/syncutil"
 	"github.com/tikv/pd/server/storage/endpoint"
 )
 
+// Storage is the storage needed by SafePointManager, including both the
+// cluster-wide safe points and the keyspace-level safe points.
+type Storage interface {
+	endpoint.GCSafePointStorage
+	endpoint.KeySpaceGCSafePointStorage
+}
+
 // SafePointManager is the manager for safePoint of GC and services.
 type SafePointManager struct {
 	gcLock        syncutil.Mutex
 	serviceGCLock syncutil.Mutex
-	store         endpoint.GCSafePointStorage
+	// keyspaceGCLock protects both the GC safe points and the service safe points of keyspaces,
+	// so that a GC safe point never exceeds the min service safe point of the same keyspace.
+	keyspaceGCLock syncutil.Mutex
+	store          Storage
 }
 
 // NewSafePointManager creates a SafePointManager of GC and services.
-func NewSafePointManager(store endpoint.GCSafePointStorage) *SafePointManager {
+func NewSafePointManager(store Storage) *SafePointManager {
 	return &SafePointManager{store: store}
 }
 
@@ -83,3 +94,98 @@ func (manager *SafePointManager) UpdateServiceGCSafePoint(serviceID string, newS
 	}
 	return minServiceSafePoint, true, err
 }
+
+// LoadKeyspaceGCSafePoint loads the GC safe point of the given keyspace from storage.
+// It returns 0 if the GC safe point of the keyspace has never been set.
+func (manager *SafePointManager) LoadKeyspaceGCSafePoint(keyspaceID uint32) (uint64, error) {
+	return manager.store.LoadKeySpaceGCSafePoint(encodeKeyspaceID(keyspaceID))
+}
+
+// LoadAllKeyspaceGCSafePoints loads the GC safe points of all keyspaces that have one.
+// If withGCSafePoint is false, the returned safe points will be 0.
+func (manager *SafePointManager) LoadAllKeyspaceGCSafePoints(withGCSafePoint bool) ([]*endpoint.KeySpaceGCSafePoint, error) {
+	return manager.store.LoadAllKeySpaceGCSafePoints(withGCSafePoint)
+}
+
+// LoadKeyspaceMinServiceGCSafePoint returns the min service safe point of the given keyspace.
+// It returns nil if there is no valid service safe point in the keyspace.
+func (manager *SafePointManager) LoadKeyspaceMinServiceGCSafePoint(keyspaceID uint32, now time.Time) (*endpoint.ServiceSafePoint, error) {
+	manager.keyspaceGCLock.Lock()
+	defer manager.keyspaceGCLock.Unlock()
+	return manager.store.LoadMinServiceSafePoint(encodeKeyspaceID(keyspaceID), now)
+}
+
+// UpdateKeyspaceGCSafePoint updates the GC safe point of the given keyspace if it is greater than
+// the previous one and not greater than the min service safe point of the keyspace.
+// It returns the old GC safe point in the storage and whether the update succeeded.
+func (manager *SafePointManager) UpdateKeyspaceGCSafePoint(keyspaceID uint32, newSafePoint uint64, now time.Time) (oldSafePoint uint64, updated bool, err error) {
+	manager.keyspaceGCLock.Lock()
+	defer manager.keyspaceGCLock.Unlock()
+	spaceID := encodeKeyspaceID(keyspaceID)
+	oldSafePoint, err = manager.store.LoadKeySpaceGCSafePoint(spaceID)
+	if err != nil || oldSafePoint >= newSafePoint {
+		return oldSafePoint, false, err
+	}
+	minServiceSafePoint, err := manager.store.LoadMinServiceSafePoint(spaceID, now)
+	if err != nil {
+		return oldSafePoint, false, err
+	}
+	if minServiceSafePoint != nil && newSafePoint > minServiceSafePoint.SafePoint {
+		return oldSafePoint, false, nil
+	}
+	if err = manager.store.SaveKeySpaceGCSafePoint(spaceID, newSafePoint); err != nil {
+		return oldSafePoint, false, err
+	}
+	return oldSafePoint, true, nil
+}
+
+// UpdateKeyspaceServiceGCSafePoint updates the safepoint for a specific service in the given keyspace.
+// The TTL semantics are the same as UpdateServiceGCSafePoint, and the new safe point should not be
+// less than the GC safe point of the keyspace.
+// It returns the GC safe point of the keyspace, the previous service safe point which is nil if
+// it did not exist or had expired, and whether the update succeeded.
+func (manager *SafePointManager) UpdateKeyspaceServiceGCSafePoint(keyspaceID uint32, serviceID string, newSafePoint uint64, ttl int64, now time.Time) (
+	gcSafePoint uint64, oldServiceSafePoint *endpoint.ServiceSafePoint, updated bool, err error) {
+	manager.keyspaceGCLock.Lock()
+	defer manager.keyspaceGCLock.Unlock()
+	spaceID := encodeKeyspaceID(keyspaceID)
+	gcSafePoint, err = manager.store.LoadKeySpaceGCSafePoint(spaceID)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	oldServiceSafePoint, err = manager.store.LoadServiceSafePoint(spaceID, serviceID)
+	if err != nil || ttl <= 0 || newSafePoint < gcSafePoint {
+		return gcSafePoint, oldServiceSafePoint, false, err
+	}
+
+	ssp := &endpoint.ServiceSafePoint{
+		ServiceID: serviceID,
+		ExpiredAt: now.Unix() + ttl,
+		SafePoint: newSafePoint,
+	}
+	if math.MaxInt64-now.Unix() <= ttl {
+		ssp.ExpiredAt = math.MaxInt64
+	}
+	if err = manager.store.SaveServiceSafePoint(spaceID, ssp); err != nil {
+		return gcSafePoint, oldServiceSafePoint, false, err
+	}
+	return gcSafePoint, oldServiceSafePoint, true, nil
+}
+
+// RemoveKeyspaceServiceGCSafePoint removes the safepoint for a specific service in the given keyspace.
+func (manager *SafePointManager) RemoveKeyspaceServiceGCSafePoint(keyspaceID uint32, serviceID string) error {
+	manager.keyspaceGCLock.Lock()
+	defer manager.keyspaceGCLock.Unlock()
+	return manager.store.RemoveServiceSafePoint(encodeKeyspaceID(keyspaceID), serviceID)
+}
+
+// encodeKeyspaceID encodes the keyspace id into the space id used by the keyspace safe point storage.
+func encodeKeyspaceID(keyspaceID uint32) string {
+	return strconv.FormatUint(uint64(keyspaceID), 10)
+}
+
+// DecodeKeyspaceID decodes the space id used by the keyspace safe point storage into the keyspace id.
+func DecodeKeyspaceID(spaceID string) (uint32, error) {
+	keyspaceID, err := strconv.ParseUint(spaceID, 10, 32)
+	return uint32(keyspaceID), err
+}
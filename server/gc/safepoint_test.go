@@ -25,7 +25,7 @@ import (
 	"github.com/tikv/pd/server/storage/kv"
 )
 
-func newGCStorage() endpoint.GCSafePointStorage {
+func newGCStorage() Storage {
 	return endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
 }
 
@@ -162,3 +162,80 @@ func TestServiceGCSafePointUpdate(t *testing.T) {
 	re.NoError(err)
 	re.True(updated)
 }
+
+func TestKeyspaceGCSafePointUpdate(t *testing.T) {
+	re := require.New(t)
+	manager := NewSafePointManager(newGCStorage())
+	keyspaceID := uint32(100)
+	now := time.Now()
+
+	// No service safe point, GC safe point can be updated freely.
+	min, err := manager.LoadKeyspaceMinServiceGCSafePoint(keyspaceID, now)
+	re.NoError(err)
+	re.Nil(min)
+	oldSafePoint, updated, err := manager.UpdateKeyspaceGCSafePoint(keyspaceID, 10, now)
+	re.NoError(err)
+	re.True(updated)
+	re.Equal(uint64(0), oldSafePoint)
+	safePoint, err := manager.LoadKeyspaceGCSafePoint(keyspaceID)
+	re.NoError(err)
+	re.Equal(uint64(10), safePoint)
+	// Other keyspaces should not be affected.
+	safePoint, err = manager.LoadKeyspaceGCSafePoint(keyspaceID + 1)
+	re.NoError(err)
+	re.Equal(uint64(0), safePoint)
+
+	// GC safe point cannot go backwards.
+	oldSafePoint, updated, err = manager.UpdateKeyspaceGCSafePoint(keyspaceID, 5, now)
+	re.NoError(err)
+	re.False(updated)
+	re.Equal(uint64(10), oldSafePoint)
+
+	// Service safe point less than the GC safe point should be rejected.
+	gcSafePoint, old, updated, err := manager.UpdateKeyspaceServiceGCSafePoint(keyspaceID, "cdc", 5, 100, now)
+	re.NoError(err)
+	re.False(updated)
+	re.Nil(old)
+	re.Equal(uint64(10), gcSafePoint)
+	_, _, updated, err = manager.UpdateKeyspaceServiceGCSafePoint(keyspaceID, "cdc", 20, 100, now)
+	re.NoError(err)
+	re.True(updated)
+	// Non-positive TTL should not update the service safe point.
+	_, old, updated, err = manager.UpdateKeyspaceServiceGCSafePoint(keyspaceID, "cdc", 30, 0, now)
+	re.NoError(err)
+	re.False(updated)
+	re.Equal(uint64(20), old.SafePoint)
+	_, _, updated, err = manager.UpdateKeyspaceServiceGCSafePoint(keyspaceID, "br", 15, math.MaxInt64, now)
+	re.NoError(err)
+	re.True(updated)
+	min, err = manager.LoadKeyspaceMinServiceGCSafePoint(keyspaceID, now)
+	re.NoError(err)
+	re.Equal("br", min.ServiceID)
+	re.Equal(int64(math.MaxInt64), min.ExpiredAt)
+
+	// GC safe point cannot exceed the min service safe point.
+	_, updated, err = manager.UpdateKeyspaceGCSafePoint(keyspaceID, 16, now)
+	re.NoError(err)
+	re.False(updated)
+	_, updated, err = manager.UpdateKeyspaceGCSafePoint(keyspaceID, 15, now)
+	re.NoError(err)
+	re.True(updated)
+
+	// After br is removed, cdc becomes the min service safe point.
+	re.NoError(manager.RemoveKeyspaceServiceGCSafePoint(keyspaceID, "br"))
+	min, err = manager.LoadKeyspaceMinServiceGCSafePoint(keyspaceID, now)
+	re.NoError(err)
+	re.Equal("cdc", min.ServiceID)
+	// After cdc expires, there is no service safe point.
+	min, err = manager.LoadKeyspaceMinServiceGCSafePoint(keyspaceID, now.Add(time.Hour))
+	re.NoError(err)
+	re.Nil(min)
+
+	safePoints, err := manager.LoadAllKeyspaceGCSafePoints(true)
+	re.NoError(err)
+	re.Len(safePoints, 1)
+	spaceID, err := DecodeKeyspaceID(safePoints[0].SpaceID)
+	re.NoError(err)
+	re.Equal(keyspaceID, spaceID)
+	re.Equal(uint64(15), safePoints[0].SafePoint)
+}
@@ -0,0 +1,298 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/gcpb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/grpcutil"
+	"github.com/tikv/pd/pkg/tsoutil"
+	"github.com/tikv/pd/server/gc"
+	"github.com/tikv/pd/server/tso"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GCServer wraps GrpcServer to provide the keyspace-level GC safe point service.
+// The space id in the requests is the decimal string of the keyspace id.
+type GCServer struct {
+	*GrpcServer
+}
+
+// validateRequest checks if Server is leader and clusterID is matched.
+func (s *GCServer) validateRequest(header *gcpb.RequestHeader) error {
+	if s.IsClosed() || !s.member.IsLeader() {
+		return ErrNotLeader
+	}
+	if header.GetClusterId() != s.clusterID {
+		return status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.clusterID, header.GetClusterId())
+	}
+	return nil
+}
+
+// unaryMiddleware forwards the request to the leader if it is forwarded by a follower,
+// otherwise it validates the request. The returned response is nil if the request
+// should be handled locally.
+func (s *GCServer) unaryMiddleware(ctx context.Context, header *gcpb.RequestHeader, fn forwardFn) (rsp interface{}, err error) {
+	forwardedHost := getForwardedHost(ctx)
+	if !s.isLocalRequest(forwardedHost) {
+		client, err := s.getDelegateClient(ctx, forwardedHost)
+		if err != nil {
+			return nil, err
+		}
+		ctx = grpcutil.ResetForwardContext(ctx)
+		return fn(ctx, client)
+	}
+	if err := s.validateRequest(header); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *GCServer) header() *gcpb.ResponseHeader {
+	if s.clusterID == 0 {
+		return s.wrapErrorToHeader(gcpb.ErrorType_NOT_BOOTSTRAPPED, "cluster id is not ready")
+	}
+	return &gcpb.ResponseHeader{ClusterId: s.clusterID}
+}
+
+func (s *GCServer) wrapErrorToHeader(errorType gcpb.ErrorType, message string) *gcpb.ResponseHeader {
+	return &gcpb.ResponseHeader{
+		ClusterId: s.clusterID,
+		Error: &gcpb.Error{
+			Type:    errorType,
+			Message: message,
+		},
+	}
+}
+
+func (s *GCServer) notBootstrappedHeader() *gcpb.ResponseHeader {
+	return s.wrapErrorToHeader(gcpb.ErrorType_NOT_BOOTSTRAPPED, "cluster is not bootstrapped")
+}
+
+// getNow returns the current time of the PD leader, derived from a global TSO.
+func (s *GCServer) getNow() (time.Time, uint64, error) {
+	nowTSO, err := s.tsoAllocatorManager.HandleTSORequest(tso.GlobalDCLocation, 1)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	now, _ := tsoutil.ParseTimestamp(nowTSO)
+	return now, tsoutil.GenerateTS(&nowTSO), nil
+}
+
+// checkKeyspace returns an error header if the given keyspace does not exist,
+// so that no safe point is stored for an unknown keyspace.
+func (s *GCServer) checkKeyspace(keyspaceID uint32) *gcpb.ResponseHeader {
+	if _, err := s.GetKeyspaceManager().LoadKeyspaceByID(keyspaceID); err != nil {
+		return s.wrapErrorToHeader(gcpb.ErrorType_UNKNOWN, err.Error())
+	}
+	return nil
+}
+
+// ListKeySpaces returns all keyspaces that have a GC safe point.
+func (s *GCServer) ListKeySpaces(ctx context.Context, request *gcpb.ListKeySpacesRequest) (*gcpb.ListKeySpacesResponse, error) {
+	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
+		return gcpb.NewGCClient(client).ListKeySpaces(ctx, request)
+	}
+	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
+		return nil, err
+	} else if rsp != nil {
+		return rsp.(*gcpb.ListKeySpacesResponse), nil
+	}
+	rc := s.GetRaftCluster()
+	if rc == nil {
+		return &gcpb.ListKeySpacesResponse{Header: s.notBootstrappedHeader()}, nil
+	}
+
+	safePoints, err := s.gcSafePointManager.LoadAllKeyspaceGCSafePoints(request.GetWithGcSafePoint())
+	if err != nil {
+		return &gcpb.ListKeySpacesResponse{Header: s.wrapErrorToHeader(gcpb.ErrorType_UNKNOWN, err.Error())}, nil
+	}
+	keyspaces := make([]*gcpb.KeySpace, 0, len(safePoints))
+	for _, safePoint := range safePoints {
+		keyspaces = append(keyspaces, &gcpb.KeySpace{
+			SpaceId:     []byte(safePoint.SpaceID),
+			GcSafePoint: safePoint.SafePoint,
+		})
+	}
+	return &gcpb.ListKeySpacesResponse{
+		Header:    s.header(),
+		KeySpaces: keyspaces,
+	}, nil
+}
+
+// GetMinServiceSafePoint returns the min service safe point of the given keyspace.
+// If there is no valid service safe point in the keyspace, the current TSO is returned,
+// which means the GC of the keyspace is not blocked by any service.
+// The returned revision is always 0, because UpdateGCSafePoint checks the new GC safe point
+// against the min service safe point atomically and does not rely on the revision.
+func (s *GCServer) GetMinServiceSafePoint(ctx context.Context, request *gcpb.GetMinServiceSafePointRequest) (*gcpb.GetMinServiceSafePointResponse, error) {
+	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
+		return gcpb.NewGCClient(client).GetMinServiceSafePoint(ctx, request)
+	}
+	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
+		return nil, err
+	} else if rsp != nil {
+		return rsp.(*gcpb.GetMinServiceSafePointResponse), nil
+	}
+	rc := s.GetRaftCluster()
+	if rc == nil {
+		return &gcpb.GetMinServiceSafePointResponse{Header: s.notBootstrappedHeader()}, nil
+	}
+
+	keyspaceID, err := gc.DecodeKeyspaceID(string(request.GetSpaceId()))
+	if err != nil {
+		return &gcpb.GetMinServiceSafePointResponse{Header: s.wrapErrorToHeader(gcpb.ErrorType_UNKNOWN, err.Error())}, nil
+	}
+	now, nowTS, err := s.getNow()
+	if err != nil {
+		return nil, err
+	}
+	min, err := s.gcSafePointManager.LoadKeyspaceMinServiceGCSafePoint(keyspaceID, now)
+	if err != nil {
+		return &gcpb.GetMinServiceSafePointResponse{Header: s.wrapErrorToHeader(gcpb.ErrorType_UNKNOWN, err.Error())}, nil
+	}
+	safePoint := nowTS
+	if min != nil {
+		safePoint = min.SafePoint
+	}
+	return &gcpb.GetMinServiceSafePointResponse{
+		Header:    s.header(),
+		SafePoint: safePoint,
+	}, nil
+}
+
+// UpdateGCSafePoint updates the GC safe point of the given keyspace.
+// The update succeeds only if the new GC safe point is greater than the old one
+// and not greater than the min service safe point of the keyspace. Otherwise the
+// current GC safe point is returned, so a zero safe point loads it without any update.
+func (s *GCServer) UpdateGCSafePoint(ctx context.Context, request *gcpb.UpdateGCSafePointRequest) (*gcpb.UpdateGCSafePointResponse, error) {
+	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
+		return gcpb.NewGCClient(client).UpdateGCSafePoint(ctx, request)
+	}
+	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
+		return nil, err
+	} else if rsp != nil {
+		return rsp.(*gcpb.UpdateGCSafePointResponse), nil
+	}
+	rc := s.GetRaftCluster()
+	if rc == nil {
+		return &gcpb.UpdateGCSafePointResponse{Header: s.notBootstrappedHeader()}, nil
+	}
+
+	keyspaceID, err := gc.DecodeKeyspaceID(string(request.GetSpaceId()))
+	if err != nil {
+		return &gcpb.UpdateGCSafePointResponse{Header: s.wrapErrorToHeader(gcpb.ErrorType_UNKNOWN, err.Error())}, nil
+	}
+	if header := s.checkKeyspace(keyspaceID); header != nil {
+		return &gcpb.UpdateGCSafePointResponse{Header: header}, nil
+	}
+	now, _, err := s.getNow()
+	if err != nil {
+		return nil, err
+	}
+	newSafePoint := request.GetSafePoint()
+	oldSafePoint, updated, err := s.gcSafePointManager.UpdateKeyspaceGCSafePoint(keyspaceID, newSafePoint, now)
+	if err != nil {
+		return &gcpb.UpdateGCSafePointResponse{Header: s.wrapErrorToHeader(gcpb.ErrorType_UNKNOWN, err.Error())}, nil
+	}
+	if updated {
+		log.Info("updated keyspace gc safe point",
+			zap.Uint32("keyspace-id", keyspaceID),
+			zap.Uint64("safe-point", newSafePoint))
+	} else if newSafePoint != oldSafePoint {
+		// A zero safe point is used to load the current GC safe point, which is not worth a warning.
+		if newSafePoint != 0 {
+			log.Warn("trying to update keyspace gc safe point",
+				zap.Uint32("keyspace-id", keyspaceID),
+				zap.Uint64("old-safe-point", oldSafePoint),
+				zap.Uint64("new-safe-point", newSafePoint))
+		}
+		newSafePoint = oldSafePoint
+	}
+	return &gcpb.UpdateGCSafePointResponse{
+		Header:       s.header(),
+		Succeeded:    updated,
+		NewSafePoint: newSafePoint,
+	}, nil
+}
+
+// UpdateServiceSafePoint updates the safe point of a specific service in the given keyspace.
+// A TTL not greater than 0 removes the service safe point. The update fails if the new
+// safe point is less than the GC safe point of the keyspace.
+func (s *GCServer) UpdateServiceSafePoint(ctx context.Context, request *gcpb.UpdateServiceSafePointRequest) (*gcpb.UpdateServiceSafePointResponse, error) {
+	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
+		return gcpb.NewGCClient(client).UpdateServiceSafePoint(ctx, request)
+	}
+	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
+		return nil, err
+	} else if rsp != nil {
+		return rsp.(*gcpb.UpdateServiceSafePointResponse), nil
+	}
+	rc := s.GetRaftCluster()
+	if rc == nil {
+		return &gcpb.UpdateServiceSafePointResponse{Header: s.notBootstrappedHeader()}, nil
+	}
+
+	keyspaceID, err := gc.DecodeKeyspaceID(string(request.GetSpaceId()))
+	if err != nil {
+		return &gcpb.UpdateServiceSafePointResponse{Header: s.wrapErrorToHeader(gcpb.ErrorType_UNKNOWN, err.Error())}, nil
+	}
+	serviceID := string(request.GetServiceId())
+	if request.GetTTL() <= 0 {
+		if err = s.gcSafePointManager.RemoveKeyspaceServiceGCSafePoint(keyspaceID, serviceID); err != nil {
+			return &gcpb.UpdateServiceSafePointResponse{Header: s.wrapErrorToHeader(gcpb.ErrorType_UNKNOWN, err.Error())}, nil
+		}
+		return &gcpb.UpdateServiceSafePointResponse{
+			Header:    s.header(),
+			Succeeded: true,
+		}, nil
+	}
+
+	if header := s.checkKeyspace(keyspaceID); header != nil {
+		return &gcpb.UpdateServiceSafePointResponse{Header: header}, nil
+	}
+	now, _, err := s.getNow()
+	if err != nil {
+		return nil, err
+	}
+	gcSafePoint, old, updated, err := s.gcSafePointManager.UpdateKeyspaceServiceGCSafePoint(keyspaceID, serviceID, request.GetSafePoint(), request.GetTTL(), now)
+	if err != nil {
+		return &gcpb.UpdateServiceSafePointResponse{Header: s.wrapErrorToHeader(gcpb.ErrorType_UNKNOWN, err.Error())}, nil
+	}
+	resp := &gcpb.UpdateServiceSafePointResponse{
+		Header:      s.header(),
+		Succeeded:   updated,
+		GcSafePoint: gcSafePoint,
+	}
+	if old != nil {
+		resp.OldSafePoint = old.SafePoint
+		resp.NewSafePoint = old.SafePoint
+	}
+	if updated {
+		resp.NewSafePoint = request.GetSafePoint()
+		log.Info("update keyspace service GC safe point",
+			zap.Uint32("keyspace-id", keyspaceID),
+			zap.String("service-id", serviceID),
+			zap.Int64("expire-at", now.Unix()+request.GetTTL()),
+			zap.Uint64("safepoint", request.GetSafePoint()))
+	}
+	return resp, nil
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/tikv/pd/server/schedule/filter"
 	"github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/server/schedule/window"
 	"github.com/tikv/pd/server/schedulers"
 	"github.com/tikv/pd/server/statistics"
 	"github.com/tikv/pd/server/storage"
@@ -119,6 +120,15 @@ func (h *Handler) IsSchedulerPaused(name string) (bool, error) {
 	return rc.IsSchedulerPaused(name)
 }
 
+// IsSchedulerDryRun returns whether scheduler is in dry-run mode.
+func (h *Handler) IsSchedulerDryRun(name string) (bool, error) {
+	rc, err := h.GetRaftCluster()
+	if err != nil {
+		return false, err
+	}
+	return rc.IsSchedulerDryRun(name)
+}
+
 // IsSchedulerDisabled returns whether scheduler is disabled.
 func (h *Handler) IsSchedulerDisabled(name string) (bool, error) {
 	rc, err := h.GetRaftCluster()
@@ -207,6 +217,11 @@ func (h *Handler) GetHotRegionsReservedDays() uint64 {
 	return h.opt.GetHotRegionsReservedDays()
 }
 
+// GetOperatorHistoryReservedDays gets days the history of finished operators is kept.
+func (h *Handler) GetOperatorHistoryReservedDays() uint64 {
+	return h.opt.GetOperatorHistoryReservedDays()
+}
+
 // GetStoresLoads gets all hot write stores stats.
 func (h *Handler) GetStoresLoads() map[uint64][]float64 {
 	rc := h.s.GetRaftCluster()
@@ -349,6 +364,11 @@ func (h *Handler) AddEvictSlowStoreScheduler() error {
 	return h.AddScheduler(schedulers.EvictSlowStoreType)
 }
 
+// AddBalanceKeyspaceScheduler adds a balance-keyspace-scheduler.
+func (h *Handler) AddBalanceKeyspaceScheduler() error {
+	return h.AddScheduler(schedulers.BalanceKeyspaceType)
+}
+
 // AddSplitBucketScheduler adds a split-bucket-scheduler.
 func (h *Handler) AddSplitBucketScheduler() error {
 	return h.AddScheduler(schedulers.SplitBucketType)
@@ -410,6 +430,16 @@ func (h *Handler) RemoveOperator(regionID uint64) error {
 	return nil
 }
 
+// RemoveOperators removes the running operators selected by the filter.
+// If dryRun is true, the selected operators are returned without being removed.
+func (h *Handler) RemoveOperators(filter *schedule.OperatorFilter, dryRun bool) ([]*operator.Operator, error) {
+	c, err := h.GetOperatorController()
+	if err != nil {
+		return nil, err
+	}
+	return c.RemoveOperators(filter, dryRun), nil
+}
+
 // GetOperators returns the running operators.
 func (h *Handler) GetOperators() ([]*operator.Operator, error) {
 	c, err := h.GetOperatorController()
@@ -1062,6 +1092,41 @@ func (h *Handler) GetHistoryHotRegionIter(
 	return iter
 }
 
+// PackHistoryOperators gets the operators finished since the last call in HistoryOperator form.
+func (h *Handler) PackHistoryOperators() ([]storage.HistoryOperator, error) {
+	rc := h.s.GetRaftCluster()
+	if rc == nil {
+		return nil, nil
+	}
+	finished := rc.GetOperatorController().TakeFinishedOperators()
+	historyOperators := make([]storage.HistoryOperator, 0, len(finished))
+	for _, op := range finished {
+		steps := make([]string, 0, op.Len())
+		for i := 0; i < op.Len(); i++ {
+			steps = append(steps, op.Step(i).String())
+		}
+		historyOperators = append(historyOperators, storage.HistoryOperator{
+			// store in ms.
+			FinishTime:     op.FinishTime.UnixNano() / int64(time.Millisecond),
+			CreateTime:     op.GetCreateTime().UnixNano() / int64(time.Millisecond),
+			RegionID:       op.RegionID(),
+			Desc:           op.Desc(),
+			Kind:           op.Kind().String(),
+			SchedulerName:  op.GetSchedulerName(),
+			Status:         operator.OpStatusToString(op.Operator.Status()),
+			StoreIDs:       op.RelatedStores(),
+			Steps:          steps,
+			AdditionalInfo: op.GetAdditionalInfo(),
+		})
+	}
+	return historyOperators, nil
+}
+
+// GetHistoryOperatorIter returns an iterator of the operators finished in [startTime, endTime].
+func (h *Handler) GetHistoryOperatorIter(startTime, endTime int64) storage.OperatorHistoryIterator {
+	return h.s.operatorHistoryStorage.NewIterator(startTime, endTime)
+}
+
 func checkStoreState(rc *cluster.RaftCluster, storeID uint64) error {
 	store := rc.GetStore(storeID)
 	if store == nil {
@@ -1113,6 +1178,71 @@ func (h *Handler) AddEvictOrGrant(storeID float64, name string) error {
 	return nil
 }
 
+// SetSchedulerDryRun enables or disables the dry-run mode of a scheduler.
+// A scheduler in dry-run mode records its operators into a shadow log instead of dispatching them.
+func (h *Handler) SetSchedulerDryRun(name string, enable bool) error {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return err
+	}
+	if err = c.SetSchedulerDryRun(name, enable); err != nil {
+		log.Error("can not set scheduler dry-run", zap.String("scheduler-name", name), zap.Bool("enable", enable), errs.ZapError(err))
+	} else {
+		log.Info("set scheduler dry-run successfully", zap.String("scheduler-name", name), zap.Bool("enable", enable))
+	}
+	return err
+}
+
+// GetSchedulerDryRunResult returns the operators recorded by a scheduler in dry-run mode.
+func (h *Handler) GetSchedulerDryRunResult(name string) (*cluster.DryRunResult, error) {
+	rc, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	return rc.GetSchedulerDryRunResult(name)
+}
+
+// SetSchedulingWindows sets the scheduling windows of a scheduler or checker.
+func (h *Handler) SetSchedulingWindows(name string, policy *window.Policy) error {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return err
+	}
+	if err = c.SetSchedulingWindows(name, policy); err != nil {
+		log.Error("can not set scheduling windows", zap.String("name", name), errs.ZapError(err))
+	} else {
+		log.Info("set scheduling windows successfully", zap.String("name", name), zap.Int("windows", len(policy.Windows)))
+	}
+	return err
+}
+
+// GetSchedulingWindows returns the scheduling windows of a scheduler or checker.
+func (h *Handler) GetSchedulingWindows(name string) (*window.Policy, error) {
+	rc, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	return rc.GetSchedulingWindows(name)
+}
+
+// GetAllSchedulingWindows returns the scheduling windows of all schedulers and checkers.
+func (h *Handler) GetAllSchedulingWindows() (map[string]*window.Policy, error) {
+	rc, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	return rc.GetAllSchedulingWindows(), nil
+}
+
+// GetSchedulingWindowStatus returns the current scheduling window status of a scheduler or checker.
+func (h *Handler) GetSchedulingWindowStatus(name string) (*window.Status, error) {
+	rc, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	return rc.GetSchedulingWindowStatus(name)
+}
+
 // GetPausedSchedulerDelayAt returns paused unix timestamp when a scheduler is paused
 func (h *Handler) GetPausedSchedulerDelayAt(name string) (int64, error) {
 	rc, err := h.GetRaftCluster()
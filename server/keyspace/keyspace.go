@@ -19,7 +19,9 @@ import (
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/tikv/pd/pkg/progress"
 	"github.com/tikv/pd/pkg/syncutil"
+	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/id"
 	"github.com/tikv/pd/server/storage/endpoint"
 )
@@ -47,6 +49,31 @@ type Manager struct {
 	idAllocator id.Allocator
 	// store is the storage for keyspace related information.
 	store endpoint.KeyspaceStorage
+	// cluster is used to inspect the regions of keyspaces.
+	cluster RegionScanner
+	// regionStats is the stats of the regions of keyspaces.
+	regionStats *regionStatistics
+	// quotas is the cache of the quota config of keyspaces.
+	quotas *quotaCache
+	// splits tracks the jobs splitting the regions of new keyspaces.
+	splits *splitJobs
+	// config is the configurations of the manager.
+	config Config
+	// progressManager tracks the data cleanup of archived keyspaces.
+	progressManager *progress.Manager
+}
+
+// Config is the interface for keyspace config.
+type Config interface {
+	GetMinDisabledDuration() time.Duration
+	GetMinArchivedDuration() time.Duration
+	GetLifecycleCheckInterval() time.Duration
+}
+
+// RegionScanner is the interface to inspect the regions within a key range.
+type RegionScanner interface {
+	ScanRange(startKey, endKey []byte, limit int) []*core.RegionInfo
+	GetRegionByKey(regionKey []byte) *core.RegionInfo
 }
 
 // CreateKeyspaceRequest represents necessary arguments to create a keyspace.
@@ -57,14 +84,23 @@ type CreateKeyspaceRequest struct {
 	Config map[string]string
 	// Now is the timestamp used to record creation time.
 	Now int64
+	// SplitCount is the number of regions each key range of the keyspace is pre-split into.
+	// The keyspace is always split at its boundaries, 0 or 1 means no further pre-split.
+	SplitCount int
 }
 
 // NewKeyspaceManager creates a Manager of keyspace related data.
-func NewKeyspaceManager(store endpoint.KeyspaceStorage, idAllocator id.Allocator) (*Manager, error) {
+func NewKeyspaceManager(store endpoint.KeyspaceStorage, cluster RegionScanner, idAllocator id.Allocator, config Config) (*Manager, error) {
 	manager := &Manager{
-		store:       store,
-		idAllocator: idAllocator,
-		metaLock:    syncutil.NewLockGroup(syncutil.WithHash(SpaceIDHash)),
+		store:           store,
+		cluster:         cluster,
+		regionStats:     newRegionStatistics(),
+		quotas:          newQuotaCache(),
+		splits:          newSplitJobs(),
+		idAllocator:     idAllocator,
+		config:          config,
+		metaLock:        syncutil.NewLockGroup(syncutil.WithHash(SpaceIDHash)),
+		progressManager: progress.NewManager(),
 	}
 	// If default keyspace already exists, skip initialization.
 	defaultExist, _, err := manager.store.LoadKeyspaceIDByName(DefaultKeyspaceName)
@@ -96,6 +132,16 @@ func (manager *Manager) CreateKeyspace(request *CreateKeyspaceRequest) (*keyspac
 	if err := validateName(request.Name); err != nil {
 		return nil, err
 	}
+	// The quota state is maintained by PD.
+	if _, ok := request.Config[QuotaStateKey]; ok {
+		return nil, errModifyQuotaState
+	}
+	if err := validateQuotaConfig(request.Config); err != nil {
+		return nil, err
+	}
+	if err := ValidateSplitCount(request.SplitCount); err != nil {
+		return nil, err
+	}
 	// Allocate new keyspaceID.
 	newID, err := manager.allocID()
 	if err != nil {
@@ -110,7 +156,13 @@ func (manager *Manager) CreateKeyspace(request *CreateKeyspaceRequest) (*keyspac
 		StateChangedAt: request.Now,
 		Config:         request.Config,
 	}
-	return manager.saveNewKeyspace(keyspace)
+	created, err := manager.saveNewKeyspace(keyspace)
+	if err != nil {
+		return nil, err
+	}
+	// Failing to split the keyspace does not fail the creation, so the split runs in the background.
+	manager.startSplitJob(created, request.SplitCount)
+	return created, nil
 }
 
 func (manager *Manager) saveNewKeyspace(keyspace *keyspacepb.KeyspaceMeta) (*keyspacepb.KeyspaceMeta, error) {
@@ -147,6 +199,7 @@ func (manager *Manager) saveNewKeyspace(keyspace *keyspacepb.KeyspaceMeta) (*key
 		}
 		return nil, err
 	}
+	manager.quotas.update(keyspace)
 
 	return keyspace, nil
 }
@@ -165,6 +218,12 @@ func (manager *Manager) LoadKeyspace(name string) (*keyspacepb.KeyspaceMeta, err
 	return manager.loadKeyspaceByID(spaceID)
 }
 
+// LoadKeyspaceByID returns the keyspace specified by spaceID.
+// It returns error if loading or unmarshalling met error or if keyspace does not exist.
+func (manager *Manager) LoadKeyspaceByID(spaceID uint32) (*keyspacepb.KeyspaceMeta, error) {
+	return manager.loadKeyspaceByID(spaceID)
+}
+
 func (manager *Manager) loadKeyspaceByID(spaceID uint32) (*keyspacepb.KeyspaceMeta, error) {
 	// Load the keyspace with target ID.
 	keyspace := &keyspacepb.KeyspaceMeta{}
@@ -226,6 +285,10 @@ func (manager *Manager) UpdateKeyspaceConfig(name string, mutations []*Mutation)
 	}
 	// Update keyspace config according to mutations.
 	for _, mutation := range mutations {
+		// The quota state is maintained by PD.
+		if mutation.Key == QuotaStateKey {
+			return nil, errModifyQuotaState
+		}
 		switch mutation.Op {
 		case OpPut:
 			keyspace.Config[mutation.Key] = mutation.Value
@@ -235,10 +298,14 @@ func (manager *Manager) UpdateKeyspaceConfig(name string, mutations []*Mutation)
 			return nil, errIllegalOperation
 		}
 	}
+	if err = validateQuotaConfig(keyspace.GetConfig()); err != nil {
+		return nil, err
+	}
 	// Save the updated keyspace.
 	if err = manager.store.SaveKeyspace(keyspace); err != nil {
 		return nil, err
 	}
+	manager.quotas.update(keyspace)
 	return keyspace, nil
 }
 
@@ -276,6 +343,11 @@ func (manager *Manager) UpdateKeyspaceState(name string, newState keyspacepb.Key
 	if keyspace.GetState() == keyspacepb.KeyspaceState_ENABLED && newState == keyspacepb.KeyspaceState_ARCHIVED {
 		return nil, errArchiveEnabled
 	}
+	// Archiving a keyspace that has not stayed DISABLED long enough is not allowed.
+	if newState == keyspacepb.KeyspaceState_ARCHIVED &&
+		time.Duration(now-keyspace.GetStateChangedAt())*time.Second < manager.config.GetMinDisabledDuration() {
+		return nil, errDisabledTooShort
+	}
 	// Change keyspace state and record change time.
 	keyspace.StateChangedAt = now
 	keyspace.State = newState
@@ -283,6 +355,7 @@ func (manager *Manager) UpdateKeyspaceState(name string, newState keyspacepb.Key
 	if err = manager.store.SaveKeyspace(keyspace); err != nil {
 		return nil, err
 	}
+	manager.quotas.update(keyspace)
 	return keyspace, nil
 }
 
@@ -290,7 +363,7 @@ func (manager *Manager) UpdateKeyspaceState(name string, newState keyspacepb.Key
 func (manager *Manager) LoadRangeKeyspace(startID uint32, limit int) ([]*keyspacepb.KeyspaceMeta, error) {
 	// Load Start should fall within acceptable ID range.
 	if startID > spaceIDMax {
-		return nil, errors.Errorf("startID of the scan %d exceeds spaceID Max %d", startID, spaceIDMax)
+		return nil, errors.Wrapf(errIllegalID, "startID of the scan %d exceeds spaceID Max %d", startID, spaceIDMax)
 	}
 	return manager.store.LoadRangeKeyspace(startID, limit)
 }
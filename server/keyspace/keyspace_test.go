@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/keyspacepb"
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/pd/pkg/mock/mockid"
+	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/storage/endpoint"
 	"github.com/tikv/pd/server/storage/kv"
 )
@@ -35,10 +36,24 @@ const (
 	testConfig2 = "config_entry_2"
 )
 
+type mockConfig struct {
+	minDisabledDuration    time.Duration
+	minArchivedDuration    time.Duration
+	lifecycleCheckInterval time.Duration
+}
+
+func (c *mockConfig) GetMinDisabledDuration() time.Duration    { return c.minDisabledDuration }
+func (c *mockConfig) GetMinArchivedDuration() time.Duration    { return c.minArchivedDuration }
+func (c *mockConfig) GetLifecycleCheckInterval() time.Duration { return c.lifecycleCheckInterval }
+
 func mustNewKeyspaceManager(re *require.Assertions) *Manager {
+	return mustNewKeyspaceManagerWithConfig(re, core.NewBasicCluster(), &mockConfig{lifecycleCheckInterval: time.Minute})
+}
+
+func mustNewKeyspaceManagerWithConfig(re *require.Assertions, cluster RegionScanner, config Config) *Manager {
 	store := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
 	allocator := mockid.NewIDAllocator()
-	manager, err := NewKeyspaceManager(store, allocator)
+	manager, err := NewKeyspaceManager(store, cluster, allocator, config)
 	re.NoError(err)
 	return manager
 }
@@ -166,6 +181,27 @@ func TestUpdateKeyspaceState(t *testing.T) {
 	}
 }
 
+func TestArchiveDwellTime(t *testing.T) {
+	re := require.New(t)
+	manager := mustNewKeyspaceManagerWithConfig(re, core.NewBasicCluster(), &mockConfig{
+		minDisabledDuration:    time.Hour,
+		lifecycleCheckInterval: time.Minute,
+	})
+	request := makeCreateKeyspaceRequests(1)[0]
+	_, err := manager.CreateKeyspace(request)
+	re.NoError(err)
+	disabledAt := time.Now().Unix()
+	_, err = manager.UpdateKeyspaceState(request.Name, keyspacepb.KeyspaceState_DISABLED, disabledAt)
+	re.NoError(err)
+	// Archiving a keyspace that has not been DISABLED long enough is not allowed.
+	_, err = manager.UpdateKeyspaceState(request.Name, keyspacepb.KeyspaceState_ARCHIVED, disabledAt+60)
+	re.ErrorIs(err, errDisabledTooShort)
+	// Archiving is allowed once the keyspace stays DISABLED long enough.
+	archived, err := manager.UpdateKeyspaceState(request.Name, keyspacepb.KeyspaceState_ARCHIVED, disabledAt+3600)
+	re.NoError(err)
+	re.Equal(keyspacepb.KeyspaceState_ARCHIVED, archived.State)
+}
+
 func TestLoadRangeKeyspace(t *testing.T) {
 	re := require.New(t)
 	manager := mustNewKeyspaceManager(re)
@@ -0,0 +1,195 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/logutil"
+	"github.com/tikv/pd/server/core"
+	"go.uber.org/zap"
+)
+
+// The lifecycle of a keyspace is:
+//
+//	ENABLED <-> DISABLED -> ARCHIVED -> tombstone
+//
+// Transitions between ENABLED, DISABLED and ARCHIVED are requested by users via UpdateKeyspaceState,
+// archiving a keyspace requires it to stay DISABLED for at least `min-disabled-duration`.
+// Once ARCHIVED, the keyspace is visible to the GC workers through WatchKeyspaces, which clean up its data.
+// The lifecycle job then waits for at least `min-archived-duration` and for all regions within the keyspace
+// to become empty before tombstoning it. Since there is no dedicated tombstone state in keyspacepb,
+// a tombstoned keyspace is an ARCHIVED keyspace whose config is cleaned up and whose name is released,
+// so that the name can be used by a new keyspace. Keyspace id is never reused.
+
+// RunLifecycleJob periodically checks archived keyspaces and tombstones the ones whose data has been cleaned up.
+// It also updates the quota state of the keyspaces with storage quota.
+// It should only be run on the PD leader and exits when ctx is canceled.
+func (manager *Manager) RunLifecycleJob(ctx context.Context) {
+	defer logutil.LogPanic()
+
+	// The stats and the quota cache may be stale since the last leader term,
+	// rebuild them before checking any keyspace.
+	manager.loadRegionStats()
+	manager.quotas.reset()
+	ticker := time.NewTicker(manager.config.GetLifecycleCheckInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("keyspace lifecycle job has been stopped")
+			return
+		case <-ticker.C:
+			manager.checkArchivedKeyspaces(time.Now())
+			manager.checkKeyspaceQuotas()
+		}
+	}
+}
+
+// checkArchivedKeyspaces checks all archived keyspaces and tombstones the ones ready for it.
+func (manager *Manager) checkArchivedKeyspaces(now time.Time) {
+	keyspaces, err := manager.store.LoadRangeKeyspace(DefaultKeyspaceID, 0)
+	if err != nil {
+		log.Error("failed to load keyspaces for lifecycle check", errs.ZapError(err))
+		return
+	}
+	for _, meta := range keyspaces {
+		if meta.GetState() != keyspacepb.KeyspaceState_ARCHIVED {
+			continue
+		}
+		if err = manager.checkArchivedKeyspace(meta, now); err != nil {
+			log.Warn("failed to check archived keyspace",
+				zap.Uint32("keyspace-id", meta.GetId()),
+				zap.String("keyspace-name", meta.GetName()),
+				errs.ZapError(err))
+		}
+	}
+}
+
+// checkArchivedKeyspace records the data cleanup progress of the given archived keyspace,
+// and tombstones it if it has stayed ARCHIVED long enough and none of its regions holds data anymore.
+func (manager *Manager) checkArchivedKeyspace(meta *keyspacepb.KeyspaceMeta, now time.Time) error {
+	tombstone, err := manager.isTombstone(meta)
+	if err != nil || tombstone {
+		return err
+	}
+	progressKey := encodeGCProgressKey(meta.GetId())
+	remaining, unknown := manager.getRemainingSize(meta.GetId())
+	// The data is handed off to the GC workers, record the progress of the cleanup.
+	// UpdateProgress does nothing if the progress does not exist, i.e. no data is found from the very beginning.
+	if remaining == 0 || manager.progressManager.AddProgress(progressKey, float64(remaining), float64(remaining), manager.config.GetLifecycleCheckInterval()) {
+		manager.progressManager.UpdateProgress(progressKey, float64(remaining), float64(remaining), false)
+	}
+	archivedAt := time.Unix(meta.GetStateChangedAt(), 0)
+	if remaining > 0 || unknown || now.Sub(archivedAt) < manager.config.GetMinArchivedDuration() {
+		return nil
+	}
+	if err = manager.tombstoneKeyspace(meta.GetId(), now.Unix()); err != nil {
+		return err
+	}
+	manager.progressManager.RemoveProgress(progressKey)
+	return nil
+}
+
+// getRemainingSize returns the total approximate size of the non-empty regions overlapping the given keyspace,
+// and whether there is any overlapping region whose size is still unknown.
+// A region spanning the keyspace boundaries, or not split yet, may still hold the data of the keyspace,
+// so it is counted as well, and it blocks the tombstone until it is split or cleaned up.
+func (manager *Manager) getRemainingSize(spaceID uint32) (remaining int64, unknown bool) {
+	stats := manager.regionStats.get(spaceID)
+	remaining, unknown = stats.remaining, stats.unknown > 0
+	// The regions starting within the keyspace are attributed to it by the stats,
+	// the only other overlapping regions are the ones starting before and crossing its lower bounds.
+	bound := MakeRegionBound(spaceID)
+	for _, left := range [][]byte{bound.RawLeftBound, bound.TxnLeftBound} {
+		region := manager.cluster.GetRegionByKey(left)
+		if region == nil || bytes.Compare(region.GetStartKey(), left) >= 0 {
+			continue
+		}
+		switch size := region.GetApproximateSize(); {
+		case size == 0:
+			unknown = true
+		case size > core.EmptyRegionApproximateSize:
+			remaining += size
+		}
+	}
+	return remaining, unknown
+}
+
+// isTombstone checks if the given archived keyspace has already been tombstoned,
+// that is, its name no longer refers to it.
+func (manager *Manager) isTombstone(meta *keyspacepb.KeyspaceMeta) (bool, error) {
+	loaded, spaceID, err := manager.store.LoadKeyspaceIDByName(meta.GetName())
+	if err != nil {
+		return false, err
+	}
+	return !loaded || spaceID != meta.GetId(), nil
+}
+
+// tombstoneKeyspace cleans up the config of the target archived keyspace and releases its name.
+func (manager *Manager) tombstoneKeyspace(spaceID uint32, now int64) error {
+	manager.idLock.Lock()
+	defer manager.idLock.Unlock()
+	manager.metaLock.Lock(spaceID)
+	defer manager.metaLock.Unlock(spaceID)
+	// Reload the keyspace under lock to make sure it's still the archived one.
+	keyspace, err := manager.loadKeyspaceByID(spaceID)
+	if err != nil {
+		return err
+	}
+	if keyspace.GetState() != keyspacepb.KeyspaceState_ARCHIVED {
+		return errKeyspaceNotArchived
+	}
+	tombstone, err := manager.isTombstone(keyspace)
+	if err != nil || tombstone {
+		return err
+	}
+	// Clean up the config before releasing the name, so that a failure in between
+	// leaves a keyspace that will be tombstoned again in the next round.
+	keyspace.Config = nil
+	keyspace.StateChangedAt = now
+	if err = manager.store.SaveKeyspace(keyspace); err != nil {
+		return err
+	}
+	if err = manager.store.RemoveKeyspaceIDByName(keyspace.GetName()); err != nil {
+		return err
+	}
+	log.Info("keyspace has been tombstoned",
+		zap.Uint32("keyspace-id", spaceID),
+		zap.String("keyspace-name", keyspace.GetName()))
+	return nil
+}
+
+// GetGCProgress returns the data cleanup progress of the given archived keyspace.
+func (manager *Manager) GetGCProgress(name string) (process, leftSeconds, currentSpeed float64, err error) {
+	loaded, spaceID, err := manager.store.LoadKeyspaceIDByName(name)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if !loaded {
+		return 0, 0, 0, ErrKeyspaceNotFound
+	}
+	return manager.progressManager.Status(encodeGCProgressKey(spaceID))
+}
+
+func encodeGCProgressKey(spaceID uint32) string {
+	return fmt.Sprintf("keyspace-%d-gc", spaceID)
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/server/core"
+)
+
+// putKeyspaceRegion puts the region into the cluster and reports it to the manager like a region heartbeat.
+func putKeyspaceRegion(manager *Manager, cluster *core.BasicCluster, regionID uint64, startKey, endKey []byte, size int64) {
+	region := core.NewRegionInfo(&metapb.Region{
+		Id:          regionID,
+		StartKey:    startKey,
+		EndKey:      endKey,
+		RegionEpoch: &metapb.RegionEpoch{Version: 1, ConfVer: 1},
+	}, nil, core.SetApproximateSize(size))
+	manager.ObserveRegion(region, cluster.PutRegion(region))
+}
+
+func TestTombstoneKeyspace(t *testing.T) {
+	re := require.New(t)
+	cluster := core.NewBasicCluster()
+	manager := mustNewKeyspaceManagerWithConfig(re, cluster, &mockConfig{
+		minArchivedDuration:    time.Hour,
+		lifecycleCheckInterval: time.Minute,
+	})
+	request := makeCreateKeyspaceRequests(1)[0]
+	created, err := manager.CreateKeyspace(request)
+	re.NoError(err)
+	bound := MakeRegionBound(created.GetId())
+	putKeyspaceRegion(manager, cluster, 1, bound.TxnLeftBound, bound.TxnRightBound, 100)
+
+	archivedAt := time.Now()
+	_, err = manager.UpdateKeyspaceState(request.Name, keyspacepb.KeyspaceState_DISABLED, archivedAt.Unix())
+	re.NoError(err)
+	_, err = manager.UpdateKeyspaceState(request.Name, keyspacepb.KeyspaceState_ARCHIVED, archivedAt.Unix())
+	re.NoError(err)
+
+	// The keyspace still has data, it should be handed off to GC and its progress recorded.
+	manager.checkArchivedKeyspaces(archivedAt.Add(2 * time.Hour))
+	loaded, err := manager.LoadKeyspace(request.Name)
+	re.NoError(err)
+	re.Equal(keyspacepb.KeyspaceState_ARCHIVED, loaded.State)
+	process, _, _, err := manager.GetGCProgress(request.Name)
+	re.NoError(err)
+	re.Equal(0.0, process)
+
+	// Half of the data has been cleaned up.
+	putKeyspaceRegion(manager, cluster, 1, bound.TxnLeftBound, bound.TxnRightBound, 50)
+	manager.checkArchivedKeyspaces(archivedAt.Add(2 * time.Hour))
+	process, _, _, err = manager.GetGCProgress(request.Name)
+	re.NoError(err)
+	re.Equal(0.5, process)
+
+	// All regions are empty now, but the keyspace has not been ARCHIVED long enough.
+	putKeyspaceRegion(manager, cluster, 1, bound.TxnLeftBound, bound.TxnRightBound, core.EmptyRegionApproximateSize)
+	manager.checkArchivedKeyspaces(archivedAt.Add(time.Minute))
+	_, err = manager.LoadKeyspace(request.Name)
+	re.NoError(err)
+
+	// A region spanning the keyspace boundary may still hold data of the keyspace, so it blocks the tombstone.
+	putKeyspaceRegion(manager, cluster, 2, []byte(""), bound.RawRightBound, 100)
+	manager.checkArchivedKeyspaces(archivedAt.Add(2 * time.Hour))
+	_, err = manager.LoadKeyspace(request.Name)
+	re.NoError(err)
+	remaining, unknown := manager.getRemainingSize(created.GetId())
+	re.Equal(int64(100), remaining)
+	re.False(unknown)
+
+	// The region is split at the keyspace boundary, the part within the keyspace is still loaded from storage
+	// and its size is unknown, which blocks the tombstone as well.
+	putKeyspaceRegion(manager, cluster, 2, []byte(""), bound.RawLeftBound, 100)
+	putKeyspaceRegion(manager, cluster, 3, bound.RawLeftBound, bound.RawRightBound, 0)
+	manager.checkArchivedKeyspaces(archivedAt.Add(2 * time.Hour))
+	_, err = manager.LoadKeyspace(request.Name)
+	re.NoError(err)
+	remaining, unknown = manager.getRemainingSize(created.GetId())
+	re.Equal(int64(0), remaining)
+	re.True(unknown)
+
+	// The stats rebuilt from the cluster should be the same.
+	stats := manager.regionStats.get(created.GetId())
+	re.Equal(1, stats.unknown)
+	manager.loadRegionStats()
+	re.Equal(stats, manager.regionStats.get(created.GetId()))
+
+	// All regions overlapping the keyspace are empty now.
+	putKeyspaceRegion(manager, cluster, 3, bound.RawLeftBound, bound.RawRightBound, core.EmptyRegionApproximateSize)
+	manager.checkArchivedKeyspaces(archivedAt.Add(2 * time.Hour))
+	// Name of a tombstoned keyspace should be released.
+	_, err = manager.LoadKeyspace(request.Name)
+	re.ErrorIs(err, ErrKeyspaceNotFound)
+	tombstone, err := manager.LoadKeyspaceByID(created.GetId())
+	re.NoError(err)
+	re.Equal(keyspacepb.KeyspaceState_ARCHIVED, tombstone.State)
+	re.Empty(tombstone.Config)
+	_, _, _, err = manager.GetGCProgress(request.Name)
+	re.ErrorIs(err, ErrKeyspaceNotFound)
+
+	// The released name can be used by a new keyspace with a different id.
+	recreated, err := manager.CreateKeyspace(request)
+	re.NoError(err)
+	re.NotEqual(created.GetId(), recreated.GetId())
+	loaded, err = manager.LoadKeyspace(request.Name)
+	re.NoError(err)
+	re.Equal(recreated.GetId(), loaded.GetId())
+	// The old tombstone should not affect the new keyspace.
+	manager.checkArchivedKeyspaces(archivedAt.Add(2 * time.Hour))
+	loaded, err = manager.LoadKeyspace(request.Name)
+	re.NoError(err)
+	re.Equal(recreated.GetId(), loaded.GetId())
+	re.Equal(keyspacepb.KeyspaceState_ENABLED, loaded.State)
+}
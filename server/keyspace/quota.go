@@ -0,0 +1,280 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"github.com/docker/go-units"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/syncutil"
+	"github.com/tikv/pd/pkg/typeutil"
+	"go.uber.org/zap"
+)
+
+// Storage quota is configured through the keyspace config, and the quota state is
+// recorded in the keyspace config as well, so that clients watching keyspaces via
+// WatchKeyspaces are notified once a keyspace exceeds or drops back under its quota.
+const (
+	// StorageQuotaKey is the config key of the storage quota of a keyspace, e.g. "100GiB".
+	// A keyspace without this config has no quota.
+	StorageQuotaKey = "storage_quota"
+	// StorageQuotaActionKey is the config key of the quota state a keyspace is changed into
+	// when it exceeds its storage quota, either QuotaStateOverQuota (default) or QuotaStateReadOnly.
+	StorageQuotaActionKey = "storage_quota_action"
+	// QuotaStateKey is the config key of the quota state of a keyspace. It is maintained by PD
+	// and only present when the keyspace exceeds its storage quota.
+	QuotaStateKey = "quota_state"
+	// QuotaStateOverQuota indicates that the keyspace exceeds its storage quota.
+	QuotaStateOverQuota = "over_quota"
+	// QuotaStateReadOnly indicates that the keyspace exceeds its storage quota and should only serve reads.
+	QuotaStateReadOnly = "read_only"
+)
+
+var (
+	errIllegalQuota       = errors.New("illegal keyspace storage quota")
+	errIllegalQuotaAction = errors.New("illegal keyspace storage quota action")
+	errModifyQuotaState   = errors.New("keyspace quota state is maintained by PD and cannot be modified")
+)
+
+// Usage is the approximate storage usage of a keyspace.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Usage struct {
+	// ApproximateSize is the approximate size of the keyspace in MiB.
+	ApproximateSize int64 `json:"approximate_size"`
+	// ApproximateKeys is the approximate number of keys of the keyspace.
+	ApproximateKeys int64 `json:"approximate_keys"`
+	RegionCount     int   `json:"region_count"`
+}
+
+// GetKeyspaceUsage returns the approximate storage usage of the given keyspace,
+// aggregated from the region heartbeats.
+func (manager *Manager) GetKeyspaceUsage(name string) (*Usage, error) {
+	loaded, spaceID, err := manager.store.LoadKeyspaceIDByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if !loaded {
+		return nil, ErrKeyspaceNotFound
+	}
+	return manager.getUsage(spaceID), nil
+}
+
+// getUsage returns the usage of the given keyspace from the region stats.
+// Each region is attributed to the keyspace its start key belongs to, so a region
+// spanning several keyspaces is only counted once.
+func (manager *Manager) getUsage(spaceID uint32) *Usage {
+	stats := manager.regionStats.get(spaceID)
+	return &Usage{
+		ApproximateSize: stats.size,
+		ApproximateKeys: stats.keys,
+		RegionCount:     stats.count,
+	}
+}
+
+// parseStorageQuota returns the storage quota in MiB of the given keyspace config, 0 means no quota.
+func parseStorageQuota(config map[string]string) (uint64, error) {
+	text, ok := config[StorageQuotaKey]
+	if !ok {
+		return 0, nil
+	}
+	var quota typeutil.ByteSize
+	if err := quota.UnmarshalText([]byte(text)); err != nil {
+		return 0, errors.Wrapf(errIllegalQuota, "%s: %v", text, err)
+	}
+	return uint64(quota) / units.MiB, nil
+}
+
+// getQuotaAction returns the quota state of the given keyspace config once it exceeds its storage quota.
+func getQuotaAction(config map[string]string) (string, error) {
+	switch action := config[StorageQuotaActionKey]; action {
+	case "", QuotaStateOverQuota:
+		return QuotaStateOverQuota, nil
+	case QuotaStateReadOnly:
+		return QuotaStateReadOnly, nil
+	default:
+		return "", errors.Wrap(errIllegalQuotaAction, action)
+	}
+}
+
+// validateQuotaConfig checks the quota related entries of a keyspace config provided by users.
+func validateQuotaConfig(config map[string]string) error {
+	if _, err := parseStorageQuota(config); err != nil {
+		return err
+	}
+	_, err := getQuotaAction(config)
+	return err
+}
+
+// quotaConfig is the quota related config of a keyspace.
+type quotaConfig struct {
+	// quota is the storage quota in MiB, 0 means no quota.
+	quota  uint64
+	action string
+	// state is the current quota state of the keyspace.
+	state string
+}
+
+func newQuotaConfig(keyspace *keyspacepb.KeyspaceMeta) (*quotaConfig, error) {
+	quota, err := parseStorageQuota(keyspace.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	action, err := getQuotaAction(keyspace.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &quotaConfig{quota: quota, action: action, state: keyspace.GetConfig()[QuotaStateKey]}, nil
+}
+
+// expectedState returns the quota state of the keyspace with the given usage.
+func (config *quotaConfig) expectedState(usage *Usage) string {
+	if config.quota > 0 && uint64(usage.ApproximateSize) > config.quota {
+		return config.action
+	}
+	return ""
+}
+
+// quotaCache caches the quota config of the keyspaces which have a storage quota or a quota state,
+// so that the quota check does not need to load all keyspaces from the storage on every tick.
+// It is kept up to date by the keyspace manager on the PD leader.
+type quotaCache struct {
+	syncutil.RWMutex
+	// loaded indicates whether the cache has been loaded from the storage in the current leader term.
+	loaded    bool
+	keyspaces map[uint32]*quotaConfig
+}
+
+func newQuotaCache() *quotaCache {
+	return &quotaCache{keyspaces: make(map[uint32]*quotaConfig)}
+}
+
+// update updates the cache with the latest keyspace meta.
+func (c *quotaCache) update(keyspace *keyspacepb.KeyspaceMeta) {
+	config, err := newQuotaConfig(keyspace)
+	c.Lock()
+	defer c.Unlock()
+	if err != nil || keyspace.GetState() == keyspacepb.KeyspaceState_ARCHIVED ||
+		(config.quota == 0 && config.state == "") {
+		delete(c.keyspaces, keyspace.GetId())
+		return
+	}
+	c.keyspaces[keyspace.GetId()] = config
+}
+
+// reset clears the cache, it will be loaded again before the next quota check.
+func (c *quotaCache) reset() {
+	c.Lock()
+	defer c.Unlock()
+	c.loaded = false
+	c.keyspaces = make(map[uint32]*quotaConfig)
+}
+
+// getAll returns a copy of the cached quota configs.
+func (c *quotaCache) getAll() map[uint32]quotaConfig {
+	c.RLock()
+	defer c.RUnlock()
+	configs := make(map[uint32]quotaConfig, len(c.keyspaces))
+	for spaceID, config := range c.keyspaces {
+		configs[spaceID] = *config
+	}
+	return configs
+}
+
+// loadQuotaCache loads the quota config of all keyspaces into the cache if it has not been loaded.
+func (manager *Manager) loadQuotaCache() error {
+	manager.quotas.RLock()
+	loaded := manager.quotas.loaded
+	manager.quotas.RUnlock()
+	if loaded {
+		return nil
+	}
+	keyspaces, err := manager.store.LoadRangeKeyspace(DefaultKeyspaceID, 0)
+	if err != nil {
+		return err
+	}
+	for _, meta := range keyspaces {
+		manager.quotas.update(meta)
+	}
+	manager.quotas.Lock()
+	manager.quotas.loaded = true
+	manager.quotas.Unlock()
+	return nil
+}
+
+// checkKeyspaceQuotas updates the quota state of the keyspaces whose usage crosses their storage quota.
+// The usage comes from the region stats and the quota config from the cache, so only the
+// keyspaces whose quota state changes are loaded from the storage.
+func (manager *Manager) checkKeyspaceQuotas() {
+	if err := manager.loadQuotaCache(); err != nil {
+		log.Error("failed to load keyspaces for quota check", errs.ZapError(err))
+		return
+	}
+	for spaceID, config := range manager.quotas.getAll() {
+		if config.expectedState(manager.getUsage(spaceID)) == config.state {
+			continue
+		}
+		if err := manager.checkKeyspaceQuota(spaceID); err != nil {
+			log.Warn("failed to check keyspace quota",
+				zap.Uint32("keyspace-id", spaceID),
+				errs.ZapError(err))
+		}
+	}
+}
+
+// checkKeyspaceQuota compares the usage of the given keyspace with its storage quota,
+// and saves the keyspace if its quota state changes.
+func (manager *Manager) checkKeyspaceQuota(spaceID uint32) error {
+	manager.metaLock.Lock(spaceID)
+	defer manager.metaLock.Unlock(spaceID)
+	keyspace, err := manager.loadKeyspaceByID(spaceID)
+	if err != nil {
+		return err
+	}
+	// The cached config may be stale, correct it with the loaded one.
+	manager.quotas.update(keyspace)
+	if keyspace.GetState() == keyspacepb.KeyspaceState_ARCHIVED {
+		return nil
+	}
+	config, err := newQuotaConfig(keyspace)
+	if err != nil {
+		return err
+	}
+	usage := manager.getUsage(spaceID)
+	state := config.expectedState(usage)
+	if config.state == state {
+		return nil
+	}
+	if state == "" {
+		delete(keyspace.Config, QuotaStateKey)
+	} else {
+		if keyspace.Config == nil {
+			keyspace.Config = map[string]string{}
+		}
+		keyspace.Config[QuotaStateKey] = state
+	}
+	if err = manager.store.SaveKeyspace(keyspace); err != nil {
+		return err
+	}
+	manager.quotas.update(keyspace)
+	log.Info("keyspace quota state changed",
+		zap.Uint32("keyspace-id", spaceID),
+		zap.String("keyspace-name", keyspace.GetName()),
+		zap.String("quota-state", state),
+		zap.Uint64("quota", config.quota),
+		zap.Int64("approximate-size", usage.ApproximateSize))
+	return nil
+}
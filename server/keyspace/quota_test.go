@@ -0,0 +1,115 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/server/core"
+)
+
+func TestKeyspaceUsage(t *testing.T) {
+	re := require.New(t)
+	cluster := core.NewBasicCluster()
+	manager := mustNewKeyspaceManagerWithConfig(re, cluster, &mockConfig{lifecycleCheckInterval: time.Minute})
+	request := makeCreateKeyspaceRequests(1)[0]
+	created, err := manager.CreateKeyspace(request)
+	re.NoError(err)
+	bound := MakeRegionBound(created.GetId())
+
+	putRegion := func(id uint64, startKey, endKey []byte, size, keys int64) {
+		cluster.PutRegion(core.NewRegionInfo(&metapb.Region{
+			Id:          id,
+			StartKey:    startKey,
+			EndKey:      endKey,
+			RegionEpoch: &metapb.RegionEpoch{Version: 1, ConfVer: 1},
+		}, nil, core.SetApproximateSize(size), core.SetApproximateKeys(keys)))
+	}
+	// The region before the keyspace should not be counted.
+	putRegion(1, []byte(""), bound.RawLeftBound, 100, 1000)
+	putRegion(2, bound.RawLeftBound, bound.RawRightBound, 10, 100)
+	putRegion(3, bound.RawRightBound, bound.TxnLeftBound, 100, 1000)
+	putRegion(4, bound.TxnLeftBound, bound.TxnRightBound, 20, 200)
+	putRegion(5, bound.TxnRightBound, []byte(""), 100, 1000)
+	// The usage is aggregated from the region stats, which are rebuilt from the cluster.
+	manager.loadRegionStats()
+
+	usage, err := manager.GetKeyspaceUsage(request.Name)
+	re.NoError(err)
+	re.Equal(&Usage{ApproximateSize: 30, ApproximateKeys: 300, RegionCount: 2}, usage)
+	_, err = manager.GetKeyspaceUsage("non-existing")
+	re.ErrorIs(err, ErrKeyspaceNotFound)
+}
+
+func TestKeyspaceQuota(t *testing.T) {
+	re := require.New(t)
+	cluster := core.NewBasicCluster()
+	manager := mustNewKeyspaceManagerWithConfig(re, cluster, &mockConfig{lifecycleCheckInterval: time.Minute})
+	request := makeCreateKeyspaceRequests(1)[0]
+	request.Config = map[string]string{StorageQuotaKey: "100MiB"}
+	created, err := manager.CreateKeyspace(request)
+	re.NoError(err)
+	bound := MakeRegionBound(created.GetId())
+
+	// Illegal quota configs should be rejected.
+	_, err = manager.UpdateKeyspaceConfig(request.Name, []*Mutation{{Op: OpPut, Key: StorageQuotaKey, Value: "abc"}})
+	re.Error(err)
+	_, err = manager.UpdateKeyspaceConfig(request.Name, []*Mutation{{Op: OpPut, Key: StorageQuotaActionKey, Value: "abc"}})
+	re.Error(err)
+	_, err = manager.UpdateKeyspaceConfig(request.Name, []*Mutation{{Op: OpPut, Key: QuotaStateKey, Value: QuotaStateReadOnly}})
+	re.ErrorIs(err, errModifyQuotaState)
+
+	// Under quota.
+	putKeyspaceRegion(manager, cluster, 1, bound.TxnLeftBound, bound.TxnRightBound, 50)
+	manager.checkKeyspaceQuotas()
+	loaded, err := manager.LoadKeyspace(request.Name)
+	re.NoError(err)
+	re.NotContains(loaded.GetConfig(), QuotaStateKey)
+
+	// Over quota.
+	putKeyspaceRegion(manager, cluster, 1, bound.TxnLeftBound, bound.TxnRightBound, 150)
+	manager.checkKeyspaceQuotas()
+	loaded, err = manager.LoadKeyspace(request.Name)
+	re.NoError(err)
+	re.Equal(QuotaStateOverQuota, loaded.GetConfig()[QuotaStateKey])
+
+	// Change the quota action to read only.
+	_, err = manager.UpdateKeyspaceConfig(request.Name, []*Mutation{{Op: OpPut, Key: StorageQuotaActionKey, Value: QuotaStateReadOnly}})
+	re.NoError(err)
+	manager.checkKeyspaceQuotas()
+	loaded, err = manager.LoadKeyspace(request.Name)
+	re.NoError(err)
+	re.Equal(QuotaStateReadOnly, loaded.GetConfig()[QuotaStateKey])
+
+	// Only the keyspaces with quota are cached for the quota check.
+	re.Len(manager.quotas.getAll(), 1)
+	re.Equal(quotaConfig{quota: 100, action: QuotaStateReadOnly, state: QuotaStateReadOnly}, manager.quotas.getAll()[created.GetId()])
+	// The cache is reloaded in a new leader term.
+	manager.quotas.reset()
+	manager.checkKeyspaceQuotas()
+	re.Len(manager.quotas.getAll(), 1)
+
+	// Removing the quota should clear the quota state.
+	_, err = manager.UpdateKeyspaceConfig(request.Name, []*Mutation{{Op: OpDel, Key: StorageQuotaKey}})
+	re.NoError(err)
+	manager.checkKeyspaceQuotas()
+	loaded, err = manager.LoadKeyspace(request.Name)
+	re.NoError(err)
+	re.NotContains(loaded.GetConfig(), QuotaStateKey)
+	re.Empty(manager.quotas.getAll())
+}
@@ -0,0 +1,149 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"github.com/tikv/pd/pkg/syncutil"
+	"github.com/tikv/pd/server/core"
+)
+
+// regionStat is the contribution of a region to the stats of the keyspace its start key belongs to.
+type regionStat struct {
+	spaceID uint32
+	size    int64
+	keys    int64
+}
+
+// keyspaceRegionStats is the aggregated stats of the regions starting within a keyspace.
+type keyspaceRegionStats struct {
+	// size and keys are the total approximate size and keys of the regions.
+	size  int64
+	keys  int64
+	count int
+	// remaining is the total approximate size of the non-empty regions.
+	remaining int64
+	// unknown is the count of the regions whose size has not been reported since they were loaded.
+	unknown int
+}
+
+func (stats *keyspaceRegionStats) add(stat regionStat, delta int) {
+	stats.size += int64(delta) * stat.size
+	stats.keys += int64(delta) * stat.keys
+	stats.count += delta
+	switch {
+	case stat.size == 0:
+		stats.unknown += delta
+	case stat.size > core.EmptyRegionApproximateSize:
+		stats.remaining += int64(delta) * stat.size
+	}
+}
+
+// regionStatistics aggregates the regions of each keyspace from the region heartbeats,
+// so that the keyspace checks do not need to scan the regions. Each region is attributed
+// to the keyspace its start key belongs to.
+type regionStatistics struct {
+	syncutil.RWMutex
+	// regions is the region ID -> the contribution of the region.
+	regions map[uint64]regionStat
+	// keyspaces is the keyspace ID -> the aggregated stats of its regions.
+	keyspaces map[uint32]*keyspaceRegionStats
+}
+
+func newRegionStatistics() *regionStatistics {
+	return &regionStatistics{
+		regions:   make(map[uint64]regionStat),
+		keyspaces: make(map[uint32]*keyspaceRegionStats),
+	}
+}
+
+// observe updates the stats with the latest region info.
+func (s *regionStatistics) observe(region *core.RegionInfo) {
+	spaceID, ok := getKeyspaceIDByKey(region.GetStartKey())
+	s.Lock()
+	defer s.Unlock()
+	s.removeLocked(region.GetID())
+	if !ok {
+		return
+	}
+	stat := regionStat{spaceID: spaceID, size: region.GetApproximateSize(), keys: region.GetApproximateKeys()}
+	s.regions[region.GetID()] = stat
+	stats, ok := s.keyspaces[spaceID]
+	if !ok {
+		stats = &keyspaceRegionStats{}
+		s.keyspaces[spaceID] = stats
+	}
+	stats.add(stat, 1)
+}
+
+// remove removes the region which no longer exists from the stats.
+func (s *regionStatistics) remove(regionID uint64) {
+	s.Lock()
+	defer s.Unlock()
+	s.removeLocked(regionID)
+}
+
+func (s *regionStatistics) removeLocked(regionID uint64) {
+	stat, ok := s.regions[regionID]
+	if !ok {
+		return
+	}
+	delete(s.regions, regionID)
+	stats := s.keyspaces[stat.spaceID]
+	stats.add(stat, -1)
+	if *stats == (keyspaceRegionStats{}) {
+		delete(s.keyspaces, stat.spaceID)
+	}
+}
+
+// get returns the stats of the given keyspace.
+func (s *regionStatistics) get(spaceID uint32) keyspaceRegionStats {
+	s.RLock()
+	defer s.RUnlock()
+	if stats, ok := s.keyspaces[spaceID]; ok {
+		return *stats
+	}
+	return keyspaceRegionStats{}
+}
+
+// reset clears the stats.
+func (s *regionStatistics) reset() {
+	s.Lock()
+	defer s.Unlock()
+	s.regions = make(map[uint64]regionStat)
+	s.keyspaces = make(map[uint32]*keyspaceRegionStats)
+}
+
+// ObserveRegion updates the keyspace stats with the region reported by the heartbeat,
+// and the overlapped regions which are replaced by it.
+func (manager *Manager) ObserveRegion(region *core.RegionInfo, overlaps []*core.RegionInfo) {
+	for _, overlap := range overlaps {
+		manager.regionStats.remove(overlap.GetID())
+	}
+	manager.regionStats.observe(region)
+}
+
+// loadRegionStats rebuilds the keyspace stats from the regions in the cluster.
+// It is called once the PD becomes leader, after that the stats are kept up to date
+// by the region heartbeats.
+func (manager *Manager) loadRegionStats() {
+	manager.regionStats.reset()
+	bound := MakeAllKeyspacesBound()
+	for _, region := range manager.cluster.ScanRange(bound.RawLeftBound, bound.RawRightBound, 0) {
+		manager.regionStats.observe(region)
+	}
+	for _, region := range manager.cluster.ScanRange(bound.TxnLeftBound, bound.TxnRightBound, 0) {
+		manager.regionStats.observe(region)
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/codec"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/logutil"
+	"github.com/tikv/pd/pkg/syncutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxSplitCount is the maximum number of regions each key range of a keyspace can be pre-split into.
+	MaxSplitCount = 256
+	// splitRetryLimit is the retry limit of both splitting and scattering keyspace regions.
+	splitRetryLimit = 1
+	// scatterGroupPrefix is the prefix of the scatter group of keyspace regions.
+	scatterGroupPrefix = "keyspace-"
+)
+
+var (
+	errIllegalSplitCount = errors.Errorf("illegal split count, should be between 0 and %d", MaxSplitCount)
+	// ErrSplitStatusNotFound is returned when the keyspace has no split job in the current leader term.
+	ErrSplitStatusNotFound = errors.New("keyspace split status does not exist")
+)
+
+// RegionSplitter is the interface to split regions by the given keys.
+// It is satisfied by schedule.RegionSplitter.
+type RegionSplitter interface {
+	SplitRegions(ctx context.Context, splitKeys [][]byte, retryLimit int) (int, []uint64)
+}
+
+// RegionScatterer is the interface to scatter the given regions.
+// It is satisfied by schedule.RegionScatterer.
+type RegionScatterer interface {
+	ScatterRegionsByID(regionsID []uint64, group string, retryLimit int) (int, map[uint64]error, error)
+}
+
+// SplitStatus is the result of splitting and scattering the regions of a keyspace.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type SplitStatus struct {
+	// SplitKeys is the number of keys the keyspace is split at.
+	SplitKeys int `json:"split_keys"`
+	// SplitFinishedPercentage is the percentage of split keys that have been processed.
+	SplitFinishedPercentage int      `json:"split_finished_percentage"`
+	NewRegionIDs            []uint64 `json:"new_region_ids"`
+	// ScatterFinishedPercentage is the percentage of new regions that have been scattered.
+	ScatterFinishedPercentage int    `json:"scatter_finished_percentage"`
+	ScatterGroup              string `json:"scatter_group"`
+	// FailedRegions records the regions failed to be scattered and the reasons.
+	FailedRegions map[uint64]string `json:"failed_regions,omitempty"`
+	// Error records the error of scattering if any.
+	Error string `json:"error,omitempty"`
+	// Finished indicates whether the split job has finished, the other fields except
+	// SplitKeys and ScatterGroup are only meaningful after that.
+	Finished bool `json:"finished"`
+}
+
+// splitJobs tracks the jobs splitting and scattering the regions of new keyspaces.
+type splitJobs struct {
+	syncutil.RWMutex
+	// ctx cancels the running jobs once the raft cluster which the splitter belongs to is stopped.
+	ctx       context.Context
+	splitter  RegionSplitter
+	scatterer RegionScatterer
+	// statuses is the keyspace id -> the status of the split job of the keyspace.
+	statuses map[uint32]*SplitStatus
+}
+
+func newSplitJobs() *splitJobs {
+	return &splitJobs{statuses: make(map[uint32]*SplitStatus)}
+}
+
+func (jobs *splitJobs) setStatus(spaceID uint32, status *SplitStatus) {
+	jobs.Lock()
+	defer jobs.Unlock()
+	jobs.statuses[spaceID] = status
+}
+
+func (jobs *splitJobs) getStatus(spaceID uint32) (*SplitStatus, bool) {
+	jobs.RLock()
+	defer jobs.RUnlock()
+	status, ok := jobs.statuses[spaceID]
+	if !ok {
+		return nil, false
+	}
+	clone := *status
+	return &clone, true
+}
+
+// ValidateSplitCount checks if the given split count is legal.
+func ValidateSplitCount(splitCount int) error {
+	if splitCount < 0 || splitCount > MaxSplitCount {
+		return errors.Wrapf(errIllegalSplitCount, "%d", splitCount)
+	}
+	return nil
+}
+
+// SetRegionSplitter sets the splitter and scatterer used to split the regions of new keyspaces.
+// It is called once the raft cluster is started, and the running split jobs are canceled with
+// ctx once the raft cluster is stopped. The split statuses of the last leader term are dropped.
+func (manager *Manager) SetRegionSplitter(ctx context.Context, splitter RegionSplitter, scatterer RegionScatterer) {
+	manager.splits.Lock()
+	defer manager.splits.Unlock()
+	manager.splits.ctx, manager.splits.splitter, manager.splits.scatterer = ctx, splitter, scatterer
+	manager.splits.statuses = make(map[uint32]*SplitStatus)
+}
+
+// GetSplitStatus returns the status of the split job of the given keyspace, which is started
+// when the keyspace is created. The status can be polled until it is finished.
+func (manager *Manager) GetSplitStatus(name string) (*SplitStatus, error) {
+	loaded, spaceID, err := manager.store.LoadKeyspaceIDByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if !loaded {
+		return nil, ErrKeyspaceNotFound
+	}
+	status, ok := manager.splits.getStatus(spaceID)
+	if !ok {
+		return nil, ErrSplitStatusNotFound
+	}
+	return status, nil
+}
+
+// startSplitJob starts a job in the background to split the regions at the raw and txn boundaries
+// of the given keyspace, and further pre-split each key range into splitCount regions if splitCount > 1.
+// The new regions are then scattered with the scatter group named after the keyspace.
+// Nothing is done if the raft cluster is not started, since the regions will be split at the
+// keyspace boundaries eventually.
+func (manager *Manager) startSplitJob(keyspace *keyspacepb.KeyspaceMeta, splitCount int) {
+	manager.splits.RLock()
+	ctx, splitter, scatterer := manager.splits.ctx, manager.splits.splitter, manager.splits.scatterer
+	manager.splits.RUnlock()
+	if splitter == nil || scatterer == nil {
+		return
+	}
+	splitKeys := manager.getSplitKeys(keyspace.GetId(), splitCount)
+	status := &SplitStatus{
+		SplitKeys:    len(splitKeys),
+		NewRegionIDs: []uint64{},
+		ScatterGroup: scatterGroupPrefix + keyspace.GetName(),
+	}
+	manager.splits.setStatus(keyspace.GetId(), status)
+	go func(status SplitStatus) {
+		defer logutil.LogPanic()
+		manager.splits.setStatus(keyspace.GetId(), splitKeyspace(ctx, keyspace, splitKeys, splitter, scatterer, &status))
+	}(*status)
+}
+
+// splitKeyspace splits the regions by the given keys and scatters the new regions,
+// and returns the finished status.
+func splitKeyspace(ctx context.Context, keyspace *keyspacepb.KeyspaceMeta, splitKeys [][]byte,
+	splitter RegionSplitter, scatterer RegionScatterer, status *SplitStatus) *SplitStatus {
+	status.Finished = true
+	status.SplitFinishedPercentage, status.ScatterFinishedPercentage = 100, 100
+	if len(splitKeys) == 0 {
+		return status
+	}
+	status.SplitFinishedPercentage, status.NewRegionIDs = splitter.SplitRegions(ctx, splitKeys, splitRetryLimit)
+	if len(status.NewRegionIDs) == 0 {
+		return status
+	}
+	finished, failures, err := scatterer.ScatterRegionsByID(status.NewRegionIDs, status.ScatterGroup, splitRetryLimit)
+	status.ScatterFinishedPercentage = finished
+	if err != nil {
+		status.Error = err.Error()
+	}
+	if len(failures) > 0 {
+		status.FailedRegions = make(map[uint64]string, len(failures))
+		for regionID, failure := range failures {
+			status.FailedRegions[regionID] = failure.Error()
+		}
+	}
+	log.Info("keyspace regions split and scattered",
+		zap.Uint32("keyspace-id", keyspace.GetId()),
+		zap.String("keyspace-name", keyspace.GetName()),
+		zap.Int("split-keys", status.SplitKeys),
+		zap.Int("split-finished-percentage", status.SplitFinishedPercentage),
+		zap.Int("new-regions", len(status.NewRegionIDs)),
+		zap.Int("scatter-finished-percentage", status.ScatterFinishedPercentage),
+		errs.ZapError(err))
+	return status
+}
+
+// getSplitKeys returns the keys the given keyspace should be split at. Keys that
+// are already the start key of a region are skipped.
+func (manager *Manager) getSplitKeys(spaceID uint32, splitCount int) [][]byte {
+	var splitKeys [][]byte
+	for _, mode := range []byte{'r', 'x'} {
+		keys := [][]byte{encodeKeyspacePrefix(mode, spaceID)}
+		keys = append(keys, encodePreSplitKeys(mode, spaceID, splitCount)...)
+		keys = append(keys, encodeKeyspacePrefix(mode, spaceID+1))
+		for _, key := range keys {
+			regions := manager.cluster.ScanRange(key, nil, 1)
+			if len(regions) > 0 && bytes.Equal(regions[0].GetStartKey(), key) {
+				continue
+			}
+			splitKeys = append(splitKeys, key)
+		}
+	}
+	return splitKeys
+}
+
+// encodePreSplitKeys returns the keys evenly dividing the key range of the given
+// mode and keyspace into splitCount parts by the first byte after the keyspace prefix.
+func encodePreSplitKeys(mode byte, spaceID uint32, splitCount int) [][]byte {
+	if splitCount <= 1 {
+		return nil
+	}
+	idBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(idBytes, spaceID)
+	keys := make([][]byte, 0, splitCount-1)
+	for i := 1; i < splitCount; i++ {
+		key := append([]byte{mode}, idBytes[1:]...)
+		key = append(key, byte(i*MaxSplitCount/splitCount))
+		keys = append(keys, codec.EncodeBytes(key))
+	}
+	return keys
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/testutil"
+	"github.com/tikv/pd/server/core"
+)
+
+type mockSplitter struct {
+	splitKeys [][]byte
+}
+
+func (s *mockSplitter) SplitRegions(_ context.Context, splitKeys [][]byte, _ int) (int, []uint64) {
+	s.splitKeys = splitKeys
+	newRegionIDs := make([]uint64, 0, len(splitKeys))
+	for i := range splitKeys {
+		newRegionIDs = append(newRegionIDs, uint64(100+i))
+	}
+	return 100, newRegionIDs
+}
+
+type mockScatterer struct {
+	group     string
+	regionIDs []uint64
+}
+
+func (s *mockScatterer) ScatterRegionsByID(regionsID []uint64, group string, _ int) (int, map[uint64]error, error) {
+	s.group, s.regionIDs = group, regionsID
+	return 50, map[uint64]error{regionsID[0]: errors.New("failed")}, nil
+}
+
+func TestSplitKeyspace(t *testing.T) {
+	re := require.New(t)
+	cluster := core.NewBasicCluster()
+	manager := mustNewKeyspaceManagerWithConfig(re, cluster, &mockConfig{lifecycleCheckInterval: time.Minute})
+	requests := makeCreateKeyspaceRequests(4)
+
+	// Nothing is split before the raft cluster is started.
+	_, err := manager.CreateKeyspace(requests[0])
+	re.NoError(err)
+	_, err = manager.GetSplitStatus(requests[0].Name)
+	re.ErrorIs(err, ErrSplitStatusNotFound)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	splitter, scatterer := &mockSplitter{}, &mockScatterer{}
+	manager.SetRegionSplitter(ctx, splitter, scatterer)
+	waitSplitFinished := func(name string) *SplitStatus {
+		var status *SplitStatus
+		testutil.Eventually(re, func() bool {
+			status, err = manager.GetSplitStatus(name)
+			re.NoError(err)
+			return status.Finished
+		})
+		return status
+	}
+
+	// Split at the keyspace boundaries only.
+	created, err := manager.CreateKeyspace(requests[1])
+	re.NoError(err)
+	bound := MakeRegionBound(created.GetId())
+	status := waitSplitFinished(requests[1].Name)
+	re.Equal([][]byte{bound.RawLeftBound, bound.RawRightBound, bound.TxnLeftBound, bound.TxnRightBound}, splitter.splitKeys)
+	re.Equal(4, status.SplitKeys)
+	re.Equal(100, status.SplitFinishedPercentage)
+	re.Len(status.NewRegionIDs, 4)
+	re.Equal("keyspace-"+requests[1].Name, scatterer.group)
+	re.Equal(status.NewRegionIDs, scatterer.regionIDs)
+	re.Equal(50, status.ScatterFinishedPercentage)
+	re.Equal(map[uint64]string{100: "failed"}, status.FailedRegions)
+
+	// Keys which are already region boundaries are skipped.
+	bound = MakeRegionBound(created.GetId() + 1)
+	putKeyspaceRegion(manager, cluster, 1, bound.RawLeftBound, bound.RawRightBound, 0)
+	created, err = manager.CreateKeyspace(requests[2])
+	re.NoError(err)
+	re.Equal(bound, MakeRegionBound(created.GetId()))
+	waitSplitFinished(requests[2].Name)
+	re.Equal([][]byte{bound.RawRightBound, bound.TxnLeftBound, bound.TxnRightBound}, splitter.splitKeys)
+
+	// Pre-split each key range into 4 regions.
+	requests[3].SplitCount = 4
+	created, err = manager.CreateKeyspace(requests[3])
+	re.NoError(err)
+	bound = MakeRegionBound(created.GetId())
+	status = waitSplitFinished(requests[3].Name)
+	re.Equal(10, status.SplitKeys)
+	for i := 1; i < len(splitter.splitKeys); i++ {
+		re.Negative(bytes.Compare(splitter.splitKeys[i-1], splitter.splitKeys[i]))
+	}
+	re.Equal(bound.RawLeftBound, splitter.splitKeys[0])
+	for _, key := range splitter.splitKeys[1:4] {
+		re.True(bytes.Compare(key, bound.RawLeftBound) > 0 && bytes.Compare(key, bound.RawRightBound) < 0)
+	}
+	re.Equal(bound.RawRightBound, splitter.splitKeys[4])
+	for _, key := range splitter.splitKeys[5:] {
+		re.True(bytes.Compare(key, bound.TxnLeftBound) >= 0 && bytes.Compare(key, bound.TxnRightBound) <= 0)
+	}
+
+	// Illegal split count and non-existing keyspace.
+	request := makeCreateKeyspaceRequests(5)[4]
+	request.SplitCount = MaxSplitCount + 1
+	_, err = manager.CreateKeyspace(request)
+	re.True(IsInvalidArgumentError(err))
+	_, err = manager.GetSplitStatus(request.Name)
+	re.ErrorIs(err, ErrKeyspaceNotFound)
+
+	// The split statuses of the last leader term are dropped.
+	manager.SetRegionSplitter(ctx, splitter, scatterer)
+	_, err = manager.GetSplitStatus(requests[1].Name)
+	re.ErrorIs(err, ErrSplitStatusNotFound)
+}
@@ -15,9 +15,12 @@
 package keyspace
 
 import (
+	"bytes"
+	"encoding/binary"
 	"regexp"
 
 	"github.com/pingcap/errors"
+	"github.com/tikv/pd/pkg/codec"
 )
 
 const (
@@ -32,22 +35,49 @@ var (
 	ErrKeyspaceNotFound = errors.New("keyspace does not exist")
 	// ErrKeyspaceExists indicates target keyspace already exists.
 	// Used when creating a new keyspace.
-	ErrKeyspaceExists   = errors.New("keyspace already exists")
-	errKeyspaceArchived = errors.New("keyspace already archived")
-	errArchiveEnabled   = errors.New("cannot archive ENABLED keyspace")
-	errModifyDefault    = errors.New("cannot modify default keyspace's state")
-	errIllegalOperation = errors.New("unknown operation")
+	ErrKeyspaceExists      = errors.New("keyspace already exists")
+	errKeyspaceArchived    = errors.New("keyspace already archived")
+	errArchiveEnabled      = errors.New("cannot archive ENABLED keyspace")
+	errModifyDefault       = errors.New("cannot modify default keyspace's state")
+	errIllegalOperation    = errors.New("unknown operation")
+	errDisabledTooShort    = errors.New("keyspace has not been disabled long enough to be archived")
+	errKeyspaceNotArchived = errors.New("keyspace is not archived")
+	errIllegalID           = errors.New("illegal keyspace id")
+	errIllegalName         = errors.New("illegal keyspace name")
 )
 
+// IsInvalidArgumentError returns true if the error is caused by an invalid request,
+// such as an illegal keyspace name or config.
+func IsInvalidArgumentError(err error) bool {
+	switch errors.Cause(err) {
+	case errIllegalID, errIllegalName, errIllegalOperation, errModifyDefault,
+		errModifyQuotaState, errIllegalQuota, errIllegalQuotaAction, errIllegalSplitCount:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsIllegalStateError returns true if the error is caused by the current state of the keyspace,
+// which does not allow the requested operation.
+func IsIllegalStateError(err error) bool {
+	switch errors.Cause(err) {
+	case errKeyspaceArchived, errArchiveEnabled, errDisabledTooShort, errKeyspaceNotArchived:
+		return true
+	default:
+		return false
+	}
+}
+
 // validateID check if keyspace falls within the acceptable range.
 // It throws errIllegalID when input id is our of range,
 // or if it collides with reserved id.
 func validateID(spaceID uint32) error {
 	if spaceID > spaceIDMax {
-		return errors.Errorf("illegal keyspace id %d, larger than spaceID Max %d", spaceID, spaceIDMax)
+		return errors.Wrapf(errIllegalID, "%d is larger than spaceID Max %d", spaceID, spaceIDMax)
 	}
 	if spaceID == DefaultKeyspaceID {
-		return errors.Errorf("illegal keyspace id %d, collides with default keyspace id", spaceID)
+		return errors.Wrapf(errIllegalID, "%d collides with default keyspace id", spaceID)
 	}
 	return nil
 }
@@ -61,10 +91,10 @@ func validateName(name string) error {
 		return err
 	}
 	if !isValid {
-		return errors.Errorf("illegal keyspace name %s, should contain only alphanumerical and underline", name)
+		return errors.Wrapf(errIllegalName, "%s should contain only alphanumerical and underline", name)
 	}
 	if name == DefaultKeyspaceName {
-		return errors.Errorf("illegal keyspace name %s, collides with default keyspace name", name)
+		return errors.Wrapf(errIllegalName, "%s collides with default keyspace name", name)
 	}
 	return nil
 }
@@ -77,3 +107,90 @@ func validateName(name string) error {
 func SpaceIDHash(spaceID uint32) uint32 {
 	return spaceID & 0xFF
 }
+
+// RegionBound represents the region boundary of the given keyspace.
+// For a keyspace with id ['a', 'b', 'c'], it has four boundaries:
+//
+//	Lower bound for raw mode: ['r', 'a', 'b', 'c']
+//	Upper bound for raw mode: ['r', 'a', 'b', 'c + 1']
+//	Lower bound for txn mode: ['x', 'a', 'b', 'c']
+//	Upper bound for txn mode: ['x', 'a', 'b', 'c + 1']
+//
+// From which it shares the lower bound with keyspace with id ['a', 'b', 'c-1'].
+// And shares upper bound with keyspace with id ['a', 'b', 'c + 1'].
+// All boundaries are encoded in the same memcomparable format as the region keys.
+type RegionBound struct {
+	RawLeftBound  []byte
+	RawRightBound []byte
+	TxnLeftBound  []byte
+	TxnRightBound []byte
+}
+
+// MakeRegionBound constructs the correct region boundaries of the given keyspace.
+func MakeRegionBound(spaceID uint32) *RegionBound {
+	return &RegionBound{
+		RawLeftBound:  encodeKeyspacePrefix('r', spaceID),
+		RawRightBound: encodeKeyspacePrefix('r', spaceID+1),
+		TxnLeftBound:  encodeKeyspacePrefix('x', spaceID),
+		TxnRightBound: encodeKeyspacePrefix('x', spaceID+1),
+	}
+}
+
+// MakeAllKeyspacesBound constructs the region boundaries covering all the keyspaces.
+func MakeAllKeyspacesBound() *RegionBound {
+	return &RegionBound{
+		RawLeftBound:  encodeKeyspacePrefix('r', 0),
+		RawRightBound: encodeKeyspacePrefix('r', spaceIDMax+1),
+		TxnLeftBound:  encodeKeyspacePrefix('x', 0),
+		TxnRightBound: encodeKeyspacePrefix('x', spaceIDMax+1),
+	}
+}
+
+// encodeKeyspacePrefix encodes the key prefix of the given mode and keyspace.
+// The id one past spaceIDMax is encoded as the next mode byte,
+// so that the upper bound of the last keyspace still covers all of its keys.
+func encodeKeyspacePrefix(mode byte, spaceID uint32) []byte {
+	if spaceID > spaceIDMax {
+		return codec.EncodeBytes([]byte{mode + 1})
+	}
+	idBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(idBytes, spaceID)
+	return codec.EncodeBytes(append([]byte{mode}, idBytes[1:]...))
+}
+
+// Contains checks whether the given region key range lies entirely
+// within one of the keyspace's key ranges.
+func (bound *RegionBound) Contains(startKey, endKey []byte) bool {
+	return rangeContains(bound.RawLeftBound, bound.RawRightBound, startKey, endKey) ||
+		rangeContains(bound.TxnLeftBound, bound.TxnRightBound, startKey, endKey)
+}
+
+func rangeContains(left, right, startKey, endKey []byte) bool {
+	return bytes.Compare(startKey, left) >= 0 &&
+		len(endKey) > 0 && bytes.Compare(endKey, right) <= 0
+}
+
+// GetRegionKeyspaceID returns the ID of the keyspace which the region belongs to.
+// It returns false if the region does not lie entirely within one keyspace.
+func GetRegionKeyspaceID(startKey, endKey []byte) (uint32, bool) {
+	spaceID, ok := getKeyspaceIDByKey(startKey)
+	if !ok || !MakeRegionBound(spaceID).Contains(startKey, endKey) {
+		return 0, false
+	}
+	return spaceID, true
+}
+
+// getKeyspaceIDByKey returns the ID of the keyspace whose key ranges contain the given key.
+func getKeyspaceIDByKey(key []byte) (uint32, bool) {
+	// The first 8 bytes of the memcomparable format are the same as the raw key,
+	// so the mode and the keyspace id can be read directly.
+	if len(key) < 4 || (key[0] != 'r' && key[0] != 'x') {
+		return 0, false
+	}
+	spaceID := uint32(key[1])<<16 | uint32(key[2])<<8 | uint32(key[3])
+	// A key shorter than the keyspace prefix is padded with zeros, which lies before the prefix.
+	if bytes.Compare(key, encodeKeyspacePrefix(key[0], spaceID)) < 0 {
+		return 0, false
+	}
+	return spaceID, true
+}
@@ -15,10 +15,12 @@
 package keyspace
 
 import (
+	"bytes"
 	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/codec"
 )
 
 func TestValidateID(t *testing.T) {
@@ -35,7 +37,9 @@ func TestValidateID(t *testing.T) {
 		{math.MaxUint32, true},
 	}
 	for _, testCase := range testCases {
-		re.Equal(testCase.hasErr, validateID(testCase.id) != nil)
+		err := validateID(testCase.id)
+		re.Equal(testCase.hasErr, err != nil)
+		re.Equal(testCase.hasErr, IsInvalidArgumentError(err))
 	}
 }
 
@@ -57,6 +61,82 @@ func TestValidateName(t *testing.T) {
 		{"keyspace%1", true},
 	}
 	for _, testCase := range testCases {
-		re.Equal(testCase.hasErr, validateName(testCase.name) != nil)
+		err := validateName(testCase.name)
+		re.Equal(testCase.hasErr, err != nil)
+		re.Equal(testCase.hasErr, IsInvalidArgumentError(err))
+	}
+}
+
+func TestMakeRegionBound(t *testing.T) {
+	re := require.New(t)
+	testCases := []struct {
+		id            uint32
+		expectedBound *RegionBound
+	}{
+		{
+			id: 0,
+			expectedBound: &RegionBound{
+				RawLeftBound:  codec.EncodeBytes([]byte{'r', 0, 0, 0}),
+				RawRightBound: codec.EncodeBytes([]byte{'r', 0, 0, 1}),
+				TxnLeftBound:  codec.EncodeBytes([]byte{'x', 0, 0, 0}),
+				TxnRightBound: codec.EncodeBytes([]byte{'x', 0, 0, 1}),
+			},
+		},
+		{
+			id: 255,
+			expectedBound: &RegionBound{
+				RawLeftBound:  codec.EncodeBytes([]byte{'r', 0, 0, 255}),
+				RawRightBound: codec.EncodeBytes([]byte{'r', 0, 1, 0}),
+				TxnLeftBound:  codec.EncodeBytes([]byte{'x', 0, 0, 255}),
+				TxnRightBound: codec.EncodeBytes([]byte{'x', 0, 1, 0}),
+			},
+		},
+		{
+			id: spaceIDMax,
+			expectedBound: &RegionBound{
+				RawLeftBound:  codec.EncodeBytes([]byte{'r', 255, 255, 255}),
+				RawRightBound: codec.EncodeBytes([]byte{'s'}),
+				TxnLeftBound:  codec.EncodeBytes([]byte{'x', 255, 255, 255}),
+				TxnRightBound: codec.EncodeBytes([]byte{'y'}),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		bound := MakeRegionBound(testCase.id)
+		re.Equal(testCase.expectedBound, bound)
+		re.Negative(bytes.Compare(bound.RawLeftBound, bound.RawRightBound))
+		re.Negative(bytes.Compare(bound.TxnLeftBound, bound.TxnRightBound))
+		re.True(bound.Contains(bound.RawLeftBound, bound.RawRightBound))
+		re.True(bound.Contains(bound.TxnLeftBound, bound.TxnRightBound))
+		re.False(bound.Contains(bound.RawLeftBound, bound.TxnRightBound))
+		re.False(bound.Contains(bound.TxnLeftBound, nil))
+	}
+}
+
+func TestGetRegionKeyspaceID(t *testing.T) {
+	re := require.New(t)
+	bound := MakeRegionBound(256)
+	testCases := []struct {
+		startKey, endKey []byte
+		id               uint32
+		ok               bool
+	}{
+		{bound.RawLeftBound, bound.RawRightBound, 256, true},
+		{bound.TxnLeftBound, bound.TxnRightBound, 256, true},
+		{codec.EncodeBytes([]byte{'x', 0, 1, 0, 'a'}), codec.EncodeBytes([]byte{'x', 0, 1, 0, 'b'}), 256, true},
+		{bound.RawLeftBound, bound.TxnRightBound, 0, false},
+		{bound.TxnLeftBound, nil, 0, false},
+		{codec.EncodeBytes([]byte{'x', 0, 0, 255, 'a'}), bound.TxnRightBound, 0, false},
+		{codec.EncodeBytes([]byte("t1")), codec.EncodeBytes([]byte("t2")), 0, false},
+		{nil, bound.RawLeftBound, 0, false},
+	}
+	all := MakeAllKeyspacesBound()
+	for i, testCase := range testCases {
+		id, ok := GetRegionKeyspaceID(testCase.startKey, testCase.endKey)
+		re.Equal(testCase.ok, ok, i)
+		re.Equal(testCase.id, id, i)
+		if ok {
+			re.True(all.Contains(testCase.startKey, testCase.endKey), i)
+		}
 	}
 }
@@ -17,13 +17,16 @@ package server
 import (
 	"context"
 	"path"
+	"strconv"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/pingcap/kvproto/pkg/keyspacepb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/tikv/pd/pkg/grpcutil"
 	"github.com/tikv/pd/server/keyspace"
 	"github.com/tikv/pd/server/storage/endpoint"
 	"go.etcd.io/etcd/clientv3"
+	"google.golang.org/grpc/metadata"
 )
 
 // KeyspaceServer wraps GrpcServer to provide keyspace service.
@@ -68,7 +71,11 @@ func (s *KeyspaceServer) LoadKeyspace(_ context.Context, request *keyspacepb.Loa
 }
 
 // WatchKeyspaces captures and sends keyspace metadata changes to the client via gRPC stream.
-// Note: It sends all existing keyspaces as it's first package to the client.
+// Note: It sends all existing keyspaces as it's first package to the client, unless the client
+// resumes the watch from a revision carried by the metadata of the request. A resumable watch
+// sends the revision it starts from in the response header, and ends right after the first
+// response with the revision to resume from after it in the trailer, so that the client can
+// resume from where it left off.
 func (s *KeyspaceServer) WatchKeyspaces(request *keyspacepb.WatchKeyspacesRequest, stream keyspacepb.Keyspace_WatchKeyspacesServer) error {
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return err
@@ -81,48 +88,130 @@ func (s *KeyspaceServer) WatchKeyspaces(request *keyspacepb.WatchKeyspacesReques
 	ctx, cancel := context.WithCancel(s.Context())
 	defer cancel()
 
-	err := s.sendAllKeyspaceMeta(ctx, stream)
-	if err != nil {
-		return err
+	var (
+		metas               []*keyspacepb.KeyspaceMeta
+		revision, resumable = getKeyspaceWatchRevision(stream.Context())
+		err                 error
+	)
+	if revision == 0 {
+		if metas, revision, err = s.loadAllKeyspaceMeta(ctx); err != nil {
+			return err
+		}
+	}
+	if resumable {
+		if err = stream.SendHeader(metadata.Pairs(grpcutil.KeyspaceWatchRevisionKey, strconv.FormatInt(revision, 10))); err != nil {
+			return err
+		}
+	}
+	watcher := &keyspaceWatcher{s: s, ctx: ctx, streamCtx: stream.Context(), revision: revision}
+	for {
+		if metas == nil {
+			if metas, err = watcher.next(); err != nil || metas == nil {
+				return err
+			}
+		}
+		resp := &keyspacepb.WatchKeyspacesResponse{Header: s.header(), Keyspaces: metas}
+		if resumable {
+			stream.SetTrailer(metadata.Pairs(grpcutil.KeyspaceWatchRevisionKey, strconv.FormatInt(watcher.revision, 10)))
+			return stream.Send(resp)
+		}
+		if err = stream.Send(resp); err != nil {
+			return err
+		}
+		metas = nil
 	}
-	watchChan := s.client.Watch(ctx, path.Join(s.rootPath, endpoint.KeyspaceMetaPrefix()), clientv3.WithPrefix())
+}
+
+// keyspaceWatcher watches the keyspace meta changes after a revision.
+type keyspaceWatcher struct {
+	s         *KeyspaceServer
+	ctx       context.Context
+	streamCtx context.Context
+	// revision is the revision of the changes returned last time.
+	revision  int64
+	watchChan clientv3.WatchChan
+}
+
+// next returns the keyspaces changed after the revision, or all keyspaces if the revision has been
+// compacted. It returns nil once the watch is canceled.
+func (w *keyspaceWatcher) next() ([]*keyspacepb.KeyspaceMeta, error) {
 	for {
+		if w.watchChan == nil {
+			w.watchChan = w.s.client.Watch(w.ctx, path.Join(w.s.rootPath, endpoint.KeyspaceMetaPrefix()),
+				clientv3.WithPrefix(), clientv3.WithRev(w.revision+1))
+		}
 		select {
-		case <-ctx.Done():
-			return nil
-		case res := <-watchChan:
+		case <-w.ctx.Done():
+			return nil, nil
+		case <-w.streamCtx.Done():
+			return nil, nil
+		case res, ok := <-w.watchChan:
+			if !ok {
+				return nil, nil
+			}
+			if res.CompactRevision != 0 {
+				metas, revision, err := w.s.loadAllKeyspaceMeta(w.ctx)
+				if err != nil {
+					return nil, err
+				}
+				w.revision, w.watchChan = revision, nil
+				return metas, nil
+			}
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
 			keyspaces := make([]*keyspacepb.KeyspaceMeta, 0, len(res.Events))
 			for _, event := range res.Events {
 				if event.Type != clientv3.EventTypePut {
 					continue
 				}
 				meta := &keyspacepb.KeyspaceMeta{}
-				if err = proto.Unmarshal(event.Kv.Value, meta); err != nil {
-					return err
+				if err := proto.Unmarshal(event.Kv.Value, meta); err != nil {
+					return nil, err
 				}
 				keyspaces = append(keyspaces, meta)
 			}
+			if len(res.Events) > 0 {
+				w.revision = res.Events[len(res.Events)-1].Kv.ModRevision
+			}
 			if len(keyspaces) > 0 {
-				if err = stream.Send(&keyspacepb.WatchKeyspacesResponse{Header: s.header(), Keyspaces: keyspaces}); err != nil {
-					return err
-				}
+				return keyspaces, nil
 			}
 		}
 	}
 }
 
-func (s *KeyspaceServer) sendAllKeyspaceMeta(ctx context.Context, stream keyspacepb.Keyspace_WatchKeyspacesServer) error {
+// loadAllKeyspaceMeta loads all keyspaces along with the revision they are loaded at.
+func (s *KeyspaceServer) loadAllKeyspaceMeta(ctx context.Context) ([]*keyspacepb.KeyspaceMeta, int64, error) {
 	getResp, err := s.client.Get(ctx, path.Join(s.rootPath, endpoint.KeyspaceMetaPrefix()), clientv3.WithPrefix())
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 	metas := make([]*keyspacepb.KeyspaceMeta, getResp.Count)
 	for i, kv := range getResp.Kvs {
 		meta := &keyspacepb.KeyspaceMeta{}
 		if err = proto.Unmarshal(kv.Value, meta); err != nil {
-			return err
+			return nil, 0, err
 		}
 		metas[i] = meta
 	}
-	return stream.Send(&keyspacepb.WatchKeyspacesResponse{Header: s.header(), Keyspaces: metas})
+	return metas, getResp.Header.GetRevision(), nil
+}
+
+// getKeyspaceWatchRevision returns the revision to resume the keyspace watch from, 0 if it is
+// not set or invalid, and whether the client watches in the resumable way.
+func getKeyspaceWatchRevision(ctx context.Context) (int64, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	values := md.Get(grpcutil.KeyspaceWatchRevisionKey)
+	if len(values) == 0 {
+		return 0, false
+	}
+	revision, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil || revision < 0 {
+		return 0, true
+	}
+	return revision, true
 }
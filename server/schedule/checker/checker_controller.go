@@ -32,6 +32,9 @@ import (
 // DefaultCacheSize is the default length of waiting list.
 const DefaultCacheSize = 1000
 
+// PausableCheckers is the names of the checkers which can be paused.
+var PausableCheckers = []string{"learner", "replica", "rule", "split", "merge", "joint-state"}
+
 // Controller is used to manage all checkers.
 type Controller struct {
 	cluster           schedule.Cluster
@@ -77,7 +80,7 @@ func (c *Controller) CheckRegion(region *core.RegionInfo) []*operator.Operator {
 	opController := c.opController
 
 	if op := c.jointStateChecker.Check(region); op != nil {
-		return []*operator.Operator{op}
+		return withChecker(c.jointStateChecker.GetType(), op)
 	}
 
 	if cl, ok := c.cluster.(interface{ GetRegionLabeler() *labeler.RegionLabeler }); ok {
@@ -88,25 +91,25 @@ func (c *Controller) CheckRegion(region *core.RegionInfo) []*operator.Operator {
 	}
 
 	if op := c.splitChecker.Check(region); op != nil {
-		return []*operator.Operator{op}
+		return withChecker(c.splitChecker.GetType(), op)
 	}
 
 	if c.opts.IsPlacementRulesEnabled() {
 		fit := c.priorityInspector.Inspect(region)
 		if op := c.ruleChecker.CheckWithFit(region, fit); op != nil {
-			if opController.OperatorCount(operator.OpReplica) < c.opts.GetReplicaScheduleLimit() {
-				return []*operator.Operator{op}
+			if opController.OperatorCount(operator.OpReplica) < c.opts.WithWindowLimits(c.ruleChecker.GetWindowLimits()).GetReplicaScheduleLimit() {
+				return withChecker(c.ruleChecker.GetType(), op)
 			}
 			operator.OperatorLimitCounter.WithLabelValues(c.ruleChecker.GetType(), operator.OpReplica.String()).Inc()
 			c.regionWaitingList.Put(region.GetID(), nil)
 		}
 	} else {
 		if op := c.learnerChecker.Check(region); op != nil {
-			return []*operator.Operator{op}
+			return withChecker(c.learnerChecker.GetType(), op)
 		}
 		if op := c.replicaChecker.Check(region); op != nil {
-			if opController.OperatorCount(operator.OpReplica) < c.opts.GetReplicaScheduleLimit() {
-				return []*operator.Operator{op}
+			if opController.OperatorCount(operator.OpReplica) < c.opts.WithWindowLimits(c.replicaChecker.GetWindowLimits()).GetReplicaScheduleLimit() {
+				return withChecker(c.replicaChecker.GetType(), op)
 			}
 			operator.OperatorLimitCounter.WithLabelValues(c.replicaChecker.GetType(), operator.OpReplica.String()).Inc()
 			c.regionWaitingList.Put(region.GetID(), nil)
@@ -114,17 +117,25 @@ func (c *Controller) CheckRegion(region *core.RegionInfo) []*operator.Operator {
 	}
 
 	if c.mergeChecker != nil {
-		allowed := opController.OperatorCount(operator.OpMerge) < c.opts.GetMergeScheduleLimit()
+		allowed := opController.OperatorCount(operator.OpMerge) < c.opts.WithWindowLimits(c.mergeChecker.GetWindowLimits()).GetMergeScheduleLimit()
 		if !allowed {
 			operator.OperatorLimitCounter.WithLabelValues(c.mergeChecker.GetType(), operator.OpMerge.String()).Inc()
 		} else if ops := c.mergeChecker.Check(region); ops != nil {
 			// It makes sure that two operators can be added successfully altogether.
-			return ops
+			return withChecker(c.mergeChecker.GetType(), ops...)
 		}
 	}
 	return nil
 }
 
+// withChecker tags the operators with the name of the checker creating them.
+func withChecker(name string, ops ...*operator.Operator) []*operator.Operator {
+	for _, op := range ops {
+		op.SetCheckerName(name)
+	}
+	return ops
+}
+
 // GetMergeChecker returns the merge checker.
 func (c *Controller) GetMergeChecker() *MergeChecker {
 	return c.mergeChecker
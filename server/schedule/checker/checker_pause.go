@@ -22,14 +22,41 @@ import (
 // PauseController sets and stores delay time in checkers.
 type PauseController struct {
 	delayUntil int64
+	// outOfWindow is 1 if the checker is out of its scheduling windows.
+	outOfWindow int32
+	// windowLimits is the schedule limits overridden by the active scheduling window of the checker.
+	windowLimits atomic.Value
 }
 
 // IsPaused check if checker is paused
 func (c *PauseController) IsPaused() bool {
+	if atomic.LoadInt32(&c.outOfWindow) == 1 {
+		return true
+	}
 	delayUntil := atomic.LoadInt64(&c.delayUntil)
 	return time.Now().Unix() < delayUntil
 }
 
+// SetOutOfWindow sets whether the checker is out of its scheduling windows, a checker out of its windows is paused.
+func (c *PauseController) SetOutOfWindow(outOfWindow bool) {
+	var v int32
+	if outOfWindow {
+		v = 1
+	}
+	atomic.StoreInt32(&c.outOfWindow, v)
+}
+
+// GetWindowLimits returns the schedule limits overridden by the active scheduling window of the checker.
+func (c *PauseController) GetWindowLimits() map[string]uint64 {
+	limits, _ := c.windowLimits.Load().(map[string]uint64)
+	return limits
+}
+
+// SetWindowLimits sets the schedule limits overridden by the active scheduling window of the checker.
+func (c *PauseController) SetWindowLimits(limits map[string]uint64) {
+	c.windowLimits.Store(limits)
+}
+
 // PauseOrResume pause or resume the checker
 func (c *PauseController) PauseOrResume(t int64) {
 	delayUntil := time.Now().Unix() + t
@@ -0,0 +1,204 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server/core"
+	"github.com/tikv/pd/server/schedule/plan"
+)
+
+const (
+	checkRegion = iota
+	pickTargetStore
+)
+
+// maxDiagnosedRegions is the max number of regions whose plans are collected in one diagnosis.
+const maxDiagnosedRegions = 1024
+
+// checkerPlan is the plan of checkers. The first step checks the region,
+// and the second step picks the target store for the new peer.
+type checkerPlan struct {
+	region *core.RegionInfo
+	target *core.StoreInfo
+	status *plan.Status
+	step   int
+}
+
+func newCheckerPlan() *checkerPlan {
+	return &checkerPlan{status: plan.NewStatus(plan.StatusOK)}
+}
+
+func (p *checkerPlan) GetStep() int {
+	return p.step
+}
+
+func (p *checkerPlan) SetResource(resource interface{}) {
+	switch p.step {
+	case checkRegion:
+		p.region = resource.(*core.RegionInfo)
+	case pickTargetStore:
+		p.target = resource.(*core.StoreInfo)
+	}
+}
+
+func (p *checkerPlan) SetResourceWithStep(resource interface{}, step int) {
+	p.step = step
+	p.SetResource(resource)
+}
+
+func (p *checkerPlan) GetResource(step int) uint64 {
+	if p.step < step {
+		return 0
+	}
+	switch step {
+	case checkRegion:
+		return p.region.GetID()
+	case pickTargetStore:
+		return p.target.GetID()
+	}
+	return 0
+}
+
+func (p *checkerPlan) GetStatus() *plan.Status {
+	return p.status
+}
+
+func (p *checkerPlan) SetStatus(status *plan.Status) {
+	p.status = status
+}
+
+func (p *checkerPlan) Clone(opts ...plan.Option) plan.Plan {
+	plan := &checkerPlan{
+		region: p.region,
+		status: p.status,
+		step:   p.step,
+	}
+	for _, opt := range opts {
+		opt(plan)
+	}
+	return plan
+}
+
+// RuleCheckerPlanSummary is used to summarize the plans of rule checker.
+// The failures of picking target are regarded as the status of the target store,
+// and other failures are regarded as the status of the store of region leader.
+func RuleCheckerPlanSummary(plans []plan.Plan) (map[uint64]plan.Status, bool, error) {
+	return summarizeCheckerPlans(plans)
+}
+
+// MergeCheckerPlanSummary is used to summarize the plans of merge checker.
+// All failures are regarded as the status of the store of region leader.
+func MergeCheckerPlanSummary(plans []plan.Plan) (map[uint64]plan.Status, bool, error) {
+	return summarizeCheckerPlans(plans)
+}
+
+func summarizeCheckerPlans(plans []plan.Plan) (map[uint64]plan.Status, bool, error) {
+	storeStatusCounter := make(map[uint64]map[plan.Status]int)
+	normal := true
+	for _, pi := range plans {
+		p, ok := pi.(*checkerPlan)
+		if !ok {
+			return nil, false, errs.ErrDiagnosticLoadPlan
+		}
+		var store uint64
+		if p.step == pickTargetStore {
+			store = p.target.GetID()
+		} else {
+			store = p.region.GetLeader().GetStoreId()
+		}
+		if store == 0 {
+			continue
+		}
+		if !p.status.IsNormal() {
+			normal = false
+		}
+		if _, ok := storeStatusCounter[store]; !ok {
+			storeStatusCounter[store] = make(map[plan.Status]int)
+		}
+		storeStatusCounter[store][*p.status]++
+	}
+
+	statusCounter := make(map[uint64]plan.Status, len(storeStatusCounter))
+	for id, store := range storeStatusCounter {
+		max := 0
+		curStat := *plan.NewStatus(plan.StatusOK)
+		for stat, c := range store {
+			// The status with higher priority is preferred, and then the more frequent one.
+			if stat.Priority() > curStat.Priority() ||
+				(stat.Priority() == curStat.Priority() && (c > max || (c == max && stat.StatusCode < curStat.StatusCode))) {
+				max = c
+				curStat = stat
+			}
+		}
+		statusCounter[id] = curStat
+	}
+	return statusCounter, normal, nil
+}
+
+// DiagnosticController collects the plans of the regions checked by a checker.
+// It is only used by the goroutine which patrols regions, so it is not thread-safe.
+type DiagnosticController struct {
+	basePlan    *checkerPlan
+	collector   *plan.Collector
+	regionCount int
+	// current is the collector of the region being checked, which is nil if
+	// the region is not diagnosed.
+	current *plan.Collector
+}
+
+// StartDiagnose starts to collect the plans of the checked regions.
+func (c *DiagnosticController) StartDiagnose() {
+	c.basePlan = newCheckerPlan()
+	c.collector = plan.NewCollector(c.basePlan)
+	c.regionCount = 0
+	c.current = nil
+}
+
+// FinishDiagnose stops collecting plans and returns the collected ones.
+func (c *DiagnosticController) FinishDiagnose() []plan.Plan {
+	plans := c.collector.GetPlans()
+	c.basePlan, c.collector, c.current = nil, nil, nil
+	return plans
+}
+
+// diagnoseRegion prepares to collect the plans of the region. At most
+// maxDiagnosedRegions regions are diagnosed to bound the memory usage.
+func (c *DiagnosticController) diagnoseRegion(region *core.RegionInfo) {
+	c.current = nil
+	if c.collector == nil || c.regionCount >= maxDiagnosedRegions {
+		return
+	}
+	c.regionCount++
+	c.basePlan.region = region
+	c.basePlan.step = checkRegion
+	c.current = c.collector
+}
+
+// collectRegion collects the plan of the region being checked with the given status.
+func (c *DiagnosticController) collectRegion(status plan.StatusCode) {
+	if c.current != nil {
+		c.current.Collect(plan.SetResourceWithStep(c.basePlan.region, checkRegion), plan.SetStatus(plan.NewStatus(status)))
+	}
+}
+
+// targetCollector returns the collector used to collect the plans of picking
+// target stores for the region being checked.
+func (c *DiagnosticController) targetCollector() *plan.Collector {
+	if c.current != nil {
+		c.basePlan.step = pickTargetStore
+	}
+	return c.current
+}
@@ -35,6 +35,11 @@ func NewJointStateChecker(cluster schedule.Cluster) *JointStateChecker {
 	}
 }
 
+// GetType return JointStateChecker's type.
+func (c *JointStateChecker) GetType() string {
+	return "joint-state-checker"
+}
+
 // Check verifies a region's role, creating an Operator if need.
 func (c *JointStateChecker) Check(region *core.RegionInfo) *operator.Operator {
 	checkerCounter.WithLabelValues("joint_state_checker", "check").Inc()
@@ -35,6 +35,11 @@ func NewLearnerChecker(cluster schedule.Cluster) *LearnerChecker {
 	}
 }
 
+// GetType return LearnerChecker's type.
+func (l *LearnerChecker) GetType() string {
+	return "learner-checker"
+}
+
 // Check verifies a region's role, creating an Operator if need.
 func (l *LearnerChecker) Check(region *core.RegionInfo) *operator.Operator {
 	if l.IsPaused() {
@@ -31,6 +31,7 @@ import (
 	"github.com/tikv/pd/server/schedule/labeler"
 	"github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/server/schedule/plan"
 )
 
 const (
@@ -45,9 +46,13 @@ const (
 	mergeOptionValueDeny = "deny"
 )
 
+// MergeCheckerName is the name of merge checker.
+const MergeCheckerName = "merge-checker"
+
 // MergeChecker ensures region to merge with adjacent region when size is small
 type MergeChecker struct {
 	PauseController
+	DiagnosticController
 	cluster    schedule.Cluster
 	opts       *config.PersistOptions
 	splitCache *cache.TTLUint64
@@ -68,7 +73,7 @@ func NewMergeChecker(ctx context.Context, cluster schedule.Cluster) *MergeChecke
 
 // GetType return MergeChecker's type
 func (m *MergeChecker) GetType() string {
-	return "merge-checker"
+	return MergeCheckerName
 }
 
 // RecordRegionSplit put the recently split region into cache. MergeChecker
@@ -111,21 +116,25 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*operator.Operator {
 		checkerCounter.WithLabelValues("merge_checker", "no-need").Inc()
 		return nil
 	}
+	m.diagnoseRegion(region)
 
 	// skip region has down peers or pending peers
 	if !filter.IsRegionHealthy(region) {
 		checkerCounter.WithLabelValues("merge_checker", "special-peer").Inc()
+		m.collectRegion(plan.StatusRegionUnhealthy)
 		return nil
 	}
 
 	if !filter.IsRegionReplicated(m.cluster, region) {
 		checkerCounter.WithLabelValues("merge_checker", "abnormal-replica").Inc()
+		m.collectRegion(plan.StatusRegionNotReplicated)
 		return nil
 	}
 
 	// skip hot region
 	if m.cluster.IsRegionHot(region) {
 		checkerCounter.WithLabelValues("merge_checker", "hot-region").Inc()
+		m.collectRegion(plan.StatusRegionHot)
 		return nil
 	}
 
@@ -143,6 +152,7 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*operator.Operator {
 
 	if target == nil {
 		checkerCounter.WithLabelValues("merge_checker", "no-target").Inc()
+		m.collectRegion(plan.StatusNoTargetRegion)
 		return nil
 	}
 
@@ -153,17 +163,20 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*operator.Operator {
 	}
 	if target.GetApproximateSize() > maxTargetRegionSizeThreshold {
 		checkerCounter.WithLabelValues("merge_checker", "target-too-large").Inc()
+		m.collectRegion(plan.StatusNoTargetRegion)
 		return nil
 	}
 	if err := m.cluster.GetStoreConfig().CheckRegionSize(uint64(target.GetApproximateSize()+region.GetApproximateSize()),
 		m.opts.GetMaxMergeRegionSize()); err != nil {
 		checkerCounter.WithLabelValues("merge_checker", "split-size-after-merge").Inc()
+		m.collectRegion(plan.StatusNoTargetRegion)
 		return nil
 	}
 
 	if err := m.cluster.GetStoreConfig().CheckRegionKeys(uint64(target.GetApproximateKeys()+region.GetApproximateKeys()),
 		m.opts.GetMaxMergeRegionKeys()); err != nil {
 		checkerCounter.WithLabelValues("merge_checker", "split-keys-after-merge").Inc()
+		m.collectRegion(plan.StatusNoTargetRegion)
 		return nil
 	}
 
@@ -173,9 +186,11 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*operator.Operator {
 	ops, err := operator.CreateMergeRegionOperator("merge-region", m.cluster, region, target, operator.OpMerge)
 	if err != nil {
 		log.Warn("create merge region operator failed", errs.ZapError(err))
+		m.collectRegion(plan.StatusCreateOperatorFailed)
 		return nil
 	}
 	checkerCounter.WithLabelValues("merge_checker", "new-operator").Inc()
+	m.collectRegion(plan.StatusOK)
 	if region.GetApproximateSize() > target.GetApproximateSize() ||
 		region.GetApproximateKeys() > target.GetApproximateKeys() {
 		checkerCounter.WithLabelValues("merge_checker", "larger-source").Inc()
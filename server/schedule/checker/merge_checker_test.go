@@ -32,6 +32,7 @@ import (
 	"github.com/tikv/pd/server/schedule/labeler"
 	"github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/server/schedule/plan"
 	"github.com/tikv/pd/server/versioninfo"
 	"go.uber.org/goleak"
 )
@@ -248,6 +249,22 @@ func (suite *mergeCheckerTestSuite) TestBasic() {
 	suite.NotNil(ops)
 }
 
+func (suite *mergeCheckerTestSuite) TestDiagnose() {
+	suite.cluster.SetSplitMergeInterval(0)
+	suite.mc.StartDiagnose()
+	// The region does not have enough replicas.
+	suite.Nil(suite.mc.Check(suite.regions[0]))
+	// The region is not small enough, so it is not diagnosed.
+	suite.Nil(suite.mc.Check(suite.regions[1]))
+	plans := suite.mc.FinishDiagnose()
+	suite.Len(plans, 1)
+	statuses, normal, err := MergeCheckerPlanSummary(plans)
+	suite.NoError(err)
+	suite.False(normal)
+	suite.Len(statuses, 1)
+	suite.Equal(*plan.NewStatus(plan.StatusRegionNotReplicated), statuses[1])
+}
+
 func (suite *mergeCheckerTestSuite) TestMatchPeers() {
 	suite.cluster.SetSplitMergeInterval(0)
 	// partial store overlap not including leader
@@ -19,6 +19,7 @@ import (
 	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/schedule"
 	"github.com/tikv/pd/server/schedule/filter"
+	"github.com/tikv/pd/server/schedule/plan"
 	"go.uber.org/zap"
 )
 
@@ -31,6 +32,8 @@ type ReplicaStrategy struct {
 	isolationLevel string
 	region         *core.RegionInfo
 	extraFilters   []filter.Filter
+	// collector is used to collect the plans of picking target stores for diagnosis.
+	collector *plan.Collector
 }
 
 // SelectStoreToAdd returns the store to add a replica to a region.
@@ -71,12 +74,12 @@ func (s *ReplicaStrategy) SelectStoreToAdd(coLocationStores []*core.StoreInfo, e
 	isolationComparer := filter.IsolationComparer(s.locationLabels, coLocationStores)
 	strictStateFilter := &filter.StoreStateFilter{ActionScope: s.checkerName, MoveRegion: true}
 	targetCandidate := filter.NewCandidates(s.cluster.GetStores()).
-		FilterTarget(s.cluster.GetOpts(), nil, nil, filters...).
+		FilterTarget(s.cluster.GetOpts(), s.collector, nil, filters...).
 		KeepTheTopStores(isolationComparer, false) // greater isolation score is better
 	if targetCandidate.Len() == 0 {
 		return 0, false
 	}
-	target := targetCandidate.FilterTarget(s.cluster.GetOpts(), nil, nil, strictStateFilter).
+	target := targetCandidate.FilterTarget(s.cluster.GetOpts(), s.collector, nil, strictStateFilter).
 		PickTheTopStore(filter.RegionScoreComparer(s.cluster.GetOpts()), true) // less region score is better
 	if target == nil {
 		return 0, true // filter by temporary states
@@ -29,6 +29,7 @@ import (
 	"github.com/tikv/pd/server/schedule/filter"
 	"github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/server/schedule/plan"
 	"go.uber.org/zap"
 )
 
@@ -43,9 +44,13 @@ var (
 
 const maxPendingListLen = 100000
 
+// RuleCheckerName is the name of rule checker.
+const RuleCheckerName = "rule-checker"
+
 // RuleChecker fix/improve region by placement rules.
 type RuleChecker struct {
 	PauseController
+	DiagnosticController
 	cluster           schedule.Cluster
 	ruleManager       *placement.RuleManager
 	name              string
@@ -59,7 +64,7 @@ func NewRuleChecker(cluster schedule.Cluster, ruleManager *placement.RuleManager
 	return &RuleChecker{
 		cluster:           cluster,
 		ruleManager:       ruleManager,
-		name:              "rule-checker",
+		name:              RuleCheckerName,
 		regionWaitingList: regionWaitingList,
 		pendingList:       cache.NewDefaultCache(maxPendingListLen),
 		record:            newRecord(),
@@ -68,7 +73,7 @@ func NewRuleChecker(cluster schedule.Cluster, ruleManager *placement.RuleManager
 
 // GetType returns RuleChecker's Type
 func (c *RuleChecker) GetType() string {
-	return "rule-checker"
+	return RuleCheckerName
 }
 
 // Check checks if the region matches placement rules and returns Operator to
@@ -109,28 +114,34 @@ func (c *RuleChecker) CheckWithFit(region *core.RegionInfo, fit *placement.Regio
 
 	checkerCounter.WithLabelValues("rule_checker", "check").Inc()
 	c.record.refresh(c.cluster)
+	c.diagnoseRegion(region)
 
 	if len(fit.RuleFits) == 0 {
 		checkerCounter.WithLabelValues("rule_checker", "need-split").Inc()
 		// If the region matches no rules, the most possible reason is it spans across
 		// multiple rules.
+		c.collectRegion(plan.StatusRegionNotMatchRule)
 		return nil
 	}
 	op, err := c.fixOrphanPeers(region, fit)
 	if err != nil {
 		log.Debug("fail to fix orphan peer", errs.ZapError(err))
+		c.collectRegion(plan.StatusCreateOperatorFailed)
 	} else if op != nil {
 		c.pendingList.Remove(region.GetID())
+		c.collectRegion(plan.StatusOK)
 		return op
 	}
 	for _, rf := range fit.RuleFits {
 		op, err := c.fixRulePeer(region, fit, rf)
 		if err != nil {
 			log.Debug("fail to fix rule peer", zap.String("rule-group", rf.Rule.GroupID), zap.String("rule-id", rf.Rule.ID), errs.ZapError(err))
+			c.collectRegion(fixErrorStatus(err))
 			continue
 		}
 		if op != nil {
 			c.pendingList.Remove(region.GetID())
+			c.collectRegion(plan.StatusOK)
 			return op
 		}
 	}
@@ -176,7 +187,9 @@ func (c *RuleChecker) fixRulePeer(region *core.RegionInfo, fit *placement.Region
 func (c *RuleChecker) addRulePeer(region *core.RegionInfo, rf *placement.RuleFit) (*operator.Operator, error) {
 	checkerCounter.WithLabelValues("rule_checker", "add-rule-peer").Inc()
 	ruleStores := c.getRuleFitStores(rf)
-	store, filterByTempState := c.strategy(region, rf.Rule).SelectStoreToAdd(ruleStores)
+	strategy := c.strategy(region, rf.Rule)
+	strategy.collector = c.targetCollector()
+	store, filterByTempState := strategy.SelectStoreToAdd(ruleStores)
 	if store == 0 {
 		checkerCounter.WithLabelValues("rule_checker", "no-store-add").Inc()
 		c.handleFilterState(region, filterByTempState)
@@ -194,7 +207,9 @@ func (c *RuleChecker) addRulePeer(region *core.RegionInfo, rf *placement.RuleFit
 // The peer's store may in Offline or Down, need to be replace.
 func (c *RuleChecker) replaceUnexpectRulePeer(region *core.RegionInfo, rf *placement.RuleFit, fit *placement.RegionFit, peer *metapb.Peer, status string) (*operator.Operator, error) {
 	ruleStores := c.getRuleFitStores(rf)
-	store, filterByTempState := c.strategy(region, rf.Rule).SelectStoreToFix(ruleStores, peer.GetStoreId())
+	strategy := c.strategy(region, rf.Rule)
+	strategy.collector = c.targetCollector()
+	store, filterByTempState := strategy.SelectStoreToFix(ruleStores, peer.GetStoreId())
 	if store == 0 {
 		checkerCounter.WithLabelValues("rule_checker", "no-store-replace").Inc()
 		c.handleFilterState(region, filterByTempState)
@@ -301,8 +316,9 @@ func (c *RuleChecker) allowLeader(fit *placement.RegionFit, peer *metapb.Peer) b
 		return false
 	}
 	for _, rf := range fit.RuleFits {
+		// the constraints referencing the leader are resolved with the store as the new leader.
 		if (rf.Rule.Role == placement.Leader || rf.Rule.Role == placement.Voter) &&
-			placement.MatchLabelConstraints(s, rf.Rule.LabelConstraints) {
+			placement.MatchLabelConstraints(s, placement.ResolveLabelConstraints(rf.Rule.LabelConstraints, s)) {
 			return true
 		}
 	}
@@ -391,14 +407,28 @@ func (c *RuleChecker) isOfflinePeer(peer *metapb.Peer) bool {
 	return !store.IsPreparing() && !store.IsServing()
 }
 
+// fixErrorStatus returns the plan status of the error which fails to fix the region.
+func fixErrorStatus(err error) plan.StatusCode {
+	switch err {
+	case errNoStoreToAdd, errNoStoreToReplace:
+		return plan.StatusRegionNotReplicated
+	case errNoNewLeader, errPeerCannotBeLeader, errPeerCannotBeWitness:
+		return plan.StatusRegionNotMatchRule
+	default:
+		return plan.StatusCreateOperatorFailed
+	}
+}
+
 func (c *RuleChecker) strategy(region *core.RegionInfo, rule *placement.Rule) *ReplicaStrategy {
+	leader := c.cluster.GetStore(region.GetLeader().GetStoreId())
+	constraints := placement.ResolveLabelConstraints(rule.LabelConstraints, leader)
 	return &ReplicaStrategy{
 		checkerName:    c.name,
 		cluster:        c.cluster,
 		isolationLevel: rule.IsolationLevel,
 		locationLabels: rule.LocationLabels,
 		region:         region,
-		extraFilters:   []filter.Filter{filter.NewLabelConstraintFilter(c.name, rule.LabelConstraints)},
+		extraFilters:   []filter.Filter{filter.NewLabelConstraintFilter(c.name, constraints)},
 	}
 }
 
@@ -29,6 +29,7 @@ import (
 	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/server/schedule/plan"
 	"github.com/tikv/pd/server/versioninfo"
 )
 
@@ -71,6 +72,33 @@ func (suite *ruleCheckerTestSuite) TestAddRulePeer() {
 	suite.Equal(uint64(3), op.Step(0).(operator.AddLearner).ToStore)
 }
 
+func (suite *ruleCheckerTestSuite) TestDiagnose() {
+	suite.cluster.AddLeaderStore(1, 1)
+	suite.cluster.AddLeaderStore(2, 1)
+	suite.cluster.AddLeaderStore(3, 1)
+	suite.cluster.SetStoreDown(3)
+	suite.cluster.AddLeaderRegionWithRange(1, "", "", 1, 2)
+	// No plans are collected before the diagnosis starts.
+	suite.Nil(suite.rc.Check(suite.cluster.GetRegion(1)))
+	suite.Empty(suite.rc.FinishDiagnose())
+
+	suite.rc.StartDiagnose()
+	suite.Nil(suite.rc.Check(suite.cluster.GetRegion(1)))
+	statuses, normal, err := RuleCheckerPlanSummary(suite.rc.FinishDiagnose())
+	suite.NoError(err)
+	suite.False(normal)
+	suite.Equal(*plan.NewStatus(plan.StatusRegionNotReplicated), statuses[1])
+	suite.Equal(*plan.NewStatus(plan.StatusStoreAlreadyHasPeer), statuses[2])
+	suite.Equal(*plan.NewStatus(plan.StatusStoreDown), statuses[3])
+
+	// The plan is OK once the operator is created.
+	suite.cluster.SetStoreUp(3)
+	suite.rc.StartDiagnose()
+	suite.NotNil(suite.rc.Check(suite.cluster.GetRegion(1)))
+	plans := suite.rc.FinishDiagnose()
+	suite.True(plans[0].GetStatus().IsOK())
+}
+
 func (suite *ruleCheckerTestSuite) TestAddRulePeerWithIsolationLevel() {
 	suite.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1", "rack": "r1", "host": "h1"})
 	suite.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z1", "rack": "r1", "host": "h2"})
@@ -106,6 +134,32 @@ func (suite *ruleCheckerTestSuite) TestAddRulePeerWithIsolationLevel() {
 	suite.Equal(uint64(4), op.Step(0).(operator.AddLearner).ToStore)
 }
 
+func (suite *ruleCheckerTestSuite) TestAddRulePeerWithLeaderReference() {
+	suite.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1", "host": "h1"})
+	suite.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z2", "host": "h2"})
+	suite.cluster.AddLabelsStore(3, 1, map[string]string{"zone": "z2", "host": "h3"})
+	suite.cluster.AddLabelsStore(4, 1, map[string]string{"zone": "z1", "host": "h4"})
+	suite.cluster.AddLeaderRegionWithRange(1, "", "", 1, 2)
+	suite.ruleManager.SetRule(&placement.Rule{
+		GroupID:          "pd",
+		ID:               "default",
+		Role:             placement.Voter,
+		Count:            1,
+		LabelConstraints: []placement.LabelConstraint{{Key: "zone", Op: placement.NotSameAs, Values: []string{placement.LeaderReference}}},
+	})
+	suite.ruleManager.SetRule(&placement.Rule{
+		GroupID:          "pd",
+		ID:               "local",
+		Role:             placement.Voter,
+		Count:            2,
+		LabelConstraints: []placement.LabelConstraint{{Key: "zone", Op: placement.SameAs, Values: []string{placement.LeaderReference}}},
+	})
+	op := suite.rc.Check(suite.cluster.GetRegion(1))
+	suite.NotNil(op)
+	suite.Equal("add-rule-peer", op.Desc())
+	suite.Equal(uint64(4), op.Step(0).(operator.AddLearner).ToStore)
+}
+
 func (suite *ruleCheckerTestSuite) TestFixPeer() {
 	suite.cluster.AddLeaderStore(1, 1)
 	suite.cluster.AddLeaderStore(2, 1)
@@ -15,6 +15,7 @@
 package labeler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"strings"
@@ -37,6 +38,11 @@ type RegionLabeler struct {
 	rangeList  rangelist.List // sorted LabelRules of the type `KeyRange`
 	ctx        context.Context
 	minExpire  *time.Time
+	// listeners are called after the label rules are changed.
+	listeners []func()
+	// expireTimer notifies the listeners when the earliest label expires, so that
+	// they do not need to wait for the GC to see the expiration.
+	expireTimer *time.Timer
 }
 
 // NewRegionLabeler creates a Labeler instance.
@@ -64,6 +70,9 @@ func (l *RegionLabeler) doGC(gcInterval time.Duration) {
 			l.checkAndClearExpiredLabels()
 			log.Debug("RegionLabeler GC")
 		case <-l.ctx.Done():
+			l.Lock()
+			l.stopExpireTimer()
+			l.Unlock()
 			log.Info("RegionLabeler GC stopped")
 			return
 		}
@@ -71,20 +80,27 @@ func (l *RegionLabeler) doGC(gcInterval time.Duration) {
 }
 
 func (l *RegionLabeler) checkAndClearExpiredLabels() {
-	now := time.Now()
+	if l.clearExpiredLabels(time.Now()) {
+		l.notifyRuleChange()
+	}
+}
+
+// clearExpiredLabels removes the expired labels and returns whether any rule is changed.
+func (l *RegionLabeler) clearExpiredLabels(now time.Time) bool {
 	l.Lock()
 	defer l.Unlock()
 
 	if l.minExpire == nil || l.minExpire.After(now) {
-		return
+		return false
 	}
 	var err error
-	deleted := false
+	changed, deleted := false, false
 
 	for key, rule := range l.labelRules {
 		if !rule.checkAndRemoveExpireLabels(now) {
 			continue
 		}
+		changed = true
 		if len(rule.Labels) == 0 {
 			err = l.storage.DeleteRegionRule(key)
 			delete(l.labelRules, key)
@@ -99,6 +115,67 @@ func (l *RegionLabeler) checkAndClearExpiredLabels() {
 	if deleted {
 		l.buildRangeList()
 	}
+	return changed
+}
+
+// resetExpireTimer schedules notifying the listeners when the earliest unexpired
+// label expires. It must be called with the lock held.
+func (l *RegionLabeler) resetExpireTimer(now time.Time) {
+	l.stopExpireTimer()
+	if l.ctx.Err() != nil {
+		return
+	}
+	var next *time.Time
+	for _, rule := range l.labelRules {
+		for _, label := range rule.Labels {
+			if label.expire == nil || !label.expire.After(now) {
+				continue
+			}
+			if next == nil || label.expire.Before(*next) {
+				next = label.expire
+			}
+		}
+	}
+	if next != nil {
+		l.expireTimer = time.AfterFunc(next.Sub(now), l.onLabelExpire)
+	}
+}
+
+func (l *RegionLabeler) stopExpireTimer() {
+	if l.expireTimer != nil {
+		l.expireTimer.Stop()
+		l.expireTimer = nil
+	}
+}
+
+// onLabelExpire notifies the listeners that a label is expired. The expired labels
+// are ignored by the queries, and they are removed from the storage by the GC.
+func (l *RegionLabeler) onLabelExpire() {
+	if l.ctx.Err() != nil {
+		return
+	}
+	l.Lock()
+	l.resetExpireTimer(time.Now())
+	l.Unlock()
+	l.notifyRuleChange()
+}
+
+// SubscribeRuleChange registers a function which is called after the label rules are changed.
+func (l *RegionLabeler) SubscribeRuleChange(f func()) {
+	l.Lock()
+	defer l.Unlock()
+	l.listeners = append(l.listeners, f)
+}
+
+// notifyRuleChange calls the listeners. It must be called without holding the lock,
+// since the listeners may access the labeler.
+func (l *RegionLabeler) notifyRuleChange() {
+	l.RLock()
+	listeners := l.listeners
+	l.RUnlock()
+	for _, f := range listeners {
+		f()
+	}
 }
 
 func (l *RegionLabeler) loadRules() error {
@@ -144,6 +221,7 @@ func (l *RegionLabeler) buildRangeList() {
 		}
 	}
 	l.rangeList = builder.Build()
+	l.resetExpireTimer(time.Now())
 }
 
 // GetSplitKeys returns all split keys in the range (start, end).
@@ -206,6 +284,7 @@ func (l *RegionLabeler) SetLabelRule(rule *LabelRule) error {
 	if err := rule.checkAndAdjust(); err != nil {
 		return err
 	}
+	defer l.notifyRuleChange()
 	l.Lock()
 	defer l.Unlock()
 	if err := l.storage.SaveRegionRule(rule.ID, rule); err != nil {
@@ -218,6 +297,7 @@ func (l *RegionLabeler) SetLabelRule(rule *LabelRule) error {
 
 // DeleteLabelRule removes a LabelRule.
 func (l *RegionLabeler) DeleteLabelRule(id string) error {
+	defer l.notifyRuleChange()
 	l.Lock()
 	defer l.Unlock()
 	if _, ok := l.labelRules[id]; !ok {
@@ -252,6 +332,7 @@ func (l *RegionLabeler) Patch(patch LabelRulePatch) error {
 	}
 
 	// update inmemory states.
+	defer l.notifyRuleChange()
 	l.Lock()
 	defer l.Unlock()
 
@@ -270,28 +351,59 @@ func (l *RegionLabeler) Patch(patch LabelRulePatch) error {
 func (l *RegionLabeler) GetRegionLabel(region *core.RegionInfo, key string) string {
 	l.RLock()
 	defer l.RUnlock()
-	now := time.Now()
-	value, index := "", -1
 	// search ranges
 	if i, data := l.rangeList.GetData(region.GetStartKey(), region.GetEndKey()); i != -1 {
-		for _, rule := range data {
-			r := rule.(*LabelRule)
-			if r.Index <= index && value != "" {
+		return getLabelValue(data, key, time.Now())
+	}
+	return ""
+}
+
+// getLabelValue returns the label value of the rules for a key.
+// If there are multiple rules that match the key, the one with max rule index will be returned.
+func getLabelValue(data []interface{}, key string, now time.Time) string {
+	value, index := "", -1
+	for _, rule := range data {
+		r := rule.(*LabelRule)
+		if r.Index <= index && value != "" {
+			continue
+		}
+		for _, l := range r.Labels {
+			if l.expireBefore(now) {
 				continue
 			}
-			for _, l := range r.Labels {
-				if l.expireBefore(now) {
-					continue
-				}
-				if l.Key == key {
-					value, index = l.Value, r.Index
-				}
+			if l.Key == key {
+				value, index = l.Value, r.Index
 			}
 		}
 	}
 	return value
 }
 
+// GetLabelKeyRanges returns the key ranges in which the regions are labeled with
+// the key and the value. The adjacent ranges are merged.
+func (l *RegionLabeler) GetLabelKeyRanges(key, value string) []core.KeyRange {
+	l.RLock()
+	defer l.RUnlock()
+	now := time.Now()
+	var ranges []core.KeyRange
+	for i := 0; i < l.rangeList.Len(); i++ {
+		startKey, data := l.rangeList.Get(i)
+		if getLabelValue(data, key, now) != value {
+			continue
+		}
+		var endKey []byte
+		if i+1 < l.rangeList.Len() {
+			endKey, _ = l.rangeList.Get(i + 1)
+		}
+		if n := len(ranges); n > 0 && bytes.Equal(ranges[n-1].EndKey, startKey) {
+			ranges[n-1].EndKey = endKey
+			continue
+		}
+		ranges = append(ranges, core.KeyRange{StartKey: startKey, EndKey: endKey})
+	}
+	return ranges
+}
+
 // ScheduleDisabled returns true if the region is lablelld with schedule-disabled.
 func (l *RegionLabeler) ScheduleDisabled(region *core.RegionInfo) bool {
 	v := l.GetRegionLabel(region, scheduleOptionLabel)
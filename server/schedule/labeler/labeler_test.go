@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -175,6 +176,126 @@ func TestIndex(t *testing.T) {
 	}
 }
 
+func TestGetLabelKeyRanges(t *testing.T) {
+	re := require.New(t)
+	store := storage.NewStorageWithMemoryBackend()
+	labeler, err := NewRegionLabeler(context.Background(), store, time.Millisecond*10)
+	re.NoError(err)
+	rules := []*LabelRule{
+		{ID: "rule0", Labels: []RegionLabel{{Key: "k1", Value: "v0"}}, RuleType: "key-range", Data: makeKeyRanges("", "")},
+		{ID: "rule1", Index: 1, Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, RuleType: "key-range", Data: makeKeyRanges("1234", "5678")},
+		{ID: "rule2", Index: 2, Labels: []RegionLabel{{Key: "k2", Value: "v2"}}, RuleType: "key-range", Data: makeKeyRanges("ab12", "cd12")},
+		{ID: "rule3", Index: 1, Labels: []RegionLabel{{Key: "k2", Value: "v3"}}, RuleType: "key-range", Data: makeKeyRanges("abcd", "efef")},
+	}
+	for _, r := range rules {
+		err := labeler.SetLabelRule(r)
+		re.NoError(err)
+	}
+
+	testCases := []struct {
+		key, value string
+		ranges     []string
+	}{
+		{"k1", "v0", []string{"", "1234", "5678", ""}},
+		{"k1", "v1", []string{"1234", "5678"}},
+		// the adjacent ranges are merged.
+		{"k2", "v2", []string{"ab12", "cd12"}},
+		// the label with the larger index takes effect.
+		{"k2", "v3", []string{"cd12", "efef"}},
+		{"k3", "v0", nil},
+	}
+	for _, testCase := range testCases {
+		var ranges []string
+		for _, r := range labeler.GetLabelKeyRanges(testCase.key, testCase.value) {
+			ranges = append(ranges, hex.EncodeToString(r.StartKey), hex.EncodeToString(r.EndKey))
+		}
+		re.Equal(testCase.ranges, ranges)
+	}
+}
+
+func TestSubscribeRuleChange(t *testing.T) {
+	re := require.New(t)
+	store := storage.NewStorageWithMemoryBackend()
+	labeler, err := NewRegionLabeler(context.Background(), store, time.Hour)
+	re.NoError(err)
+	var notified int
+	var ranges []core.KeyRange
+	labeler.SubscribeRuleChange(func() {
+		notified++
+		// the listener is called without holding the lock.
+		ranges = labeler.GetLabelKeyRanges("k1", "v1")
+	})
+
+	rule := &LabelRule{ID: "rule1", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, RuleType: "key-range", Data: makeKeyRanges("1234", "5678")}
+	re.NoError(labeler.SetLabelRule(rule))
+	re.Equal(1, notified)
+	re.Len(ranges, 1)
+
+	re.NoError(labeler.Patch(LabelRulePatch{DeleteRules: []string{"rule1"}}))
+	re.Equal(2, notified)
+	re.Empty(ranges)
+
+	rule = &LabelRule{ID: "rule2", Labels: []RegionLabel{{Key: "k1", Value: "v1", TTL: "1h"}}, RuleType: "key-range", Data: makeKeyRanges("1234", "5678")}
+	re.NoError(labeler.SetLabelRule(rule))
+	re.Equal(3, notified)
+	re.Len(ranges, 1)
+	expireLabels(labeler)
+	labeler.checkAndClearExpiredLabels()
+	re.Equal(4, notified)
+	re.Empty(ranges)
+	// nothing is changed.
+	labeler.checkAndClearExpiredLabels()
+	re.Equal(4, notified)
+
+	re.Error(labeler.DeleteLabelRule("rule2"))
+}
+
+func TestNotifyLabelExpire(t *testing.T) {
+	re := require.New(t)
+	store := storage.NewStorageWithMemoryBackend()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	labeler, err := NewRegionLabeler(ctx, store, time.Hour)
+	re.NoError(err)
+	var mu sync.Mutex
+	var ranges []core.KeyRange
+	labeler.SubscribeRuleChange(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		ranges = labeler.GetLabelKeyRanges("k1", "v1")
+	})
+	getRanges := func() []core.KeyRange {
+		mu.Lock()
+		defer mu.Unlock()
+		return ranges
+	}
+
+	rule := &LabelRule{ID: "rule1", Labels: []RegionLabel{{Key: "k1", Value: "v1", TTL: "100ms"}}, RuleType: "key-range", Data: makeKeyRanges("1234", "5678")}
+	re.NoError(labeler.SetLabelRule(rule))
+	re.Len(getRanges(), 1)
+	// the listeners are notified once the label expires, without waiting for the GC.
+	re.Eventually(func() bool { return len(getRanges()) == 0 }, time.Second, 10*time.Millisecond)
+	// the expired label is left to the GC.
+	labeler.RLock()
+	re.Len(labeler.labelRules, 1)
+	labeler.RUnlock()
+}
+
+// expireLabels makes all the labels expired.
+func expireLabels(labeler *RegionLabeler) {
+	labeler.Lock()
+	defer labeler.Unlock()
+	expire := time.Now().Add(-time.Second)
+	for _, rule := range labeler.labelRules {
+		for i := range rule.Labels {
+			rule.Labels[i].expire = &expire
+		}
+		rule.minExpire = &expire
+	}
+	labeler.minExpire = &expire
+	labeler.stopExpireTimer()
+}
+
 func TestSaveLoadRule(t *testing.T) {
 	re := require.New(t)
 	store := storage.NewStorageWithMemoryBackend()
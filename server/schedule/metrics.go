@@ -60,6 +60,23 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 16),
 		}, []string{"type"})
 
+	waitingOperatorGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "schedule",
+			Name:      "waiting_operators",
+			Help:      "Gauge of the waiting operators of each source.",
+		}, []string{"source"})
+
+	operatorPromoteDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "pd",
+			Subsystem: "schedule",
+			Name:      "promote_waiting_operators_duration_seconds",
+			Help:      "Bucketed histogram of waiting time (s) of promoted operator of each source.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 16),
+		}, []string{"source"})
+
 	storeLimitCostCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "pd",
@@ -89,6 +106,8 @@ func init() {
 	prometheus.MustRegister(operatorCounter)
 	prometheus.MustRegister(operatorDuration)
 	prometheus.MustRegister(operatorWaitDuration)
+	prometheus.MustRegister(waitingOperatorGauge)
+	prometheus.MustRegister(operatorPromoteDuration)
 	prometheus.MustRegister(storeLimitCostCounter)
 	prometheus.MustRegister(operatorWaitCounter)
 	prometheus.MustRegister(scatterCounter)
@@ -811,8 +811,9 @@ func (b *Builder) allowLeader(peer *metapb.Peer, ignoreClusterLimit bool) bool {
 		return true
 	}
 	for _, r := range b.rules {
+		// the constraints referencing the leader are resolved with the store as the new leader.
 		if (r.Role == placement.Leader || r.Role == placement.Voter) &&
-			placement.MatchLabelConstraints(store, r.LabelConstraints) {
+			placement.MatchLabelConstraints(store, placement.ResolveLabelConstraints(r.LabelConstraints, store)) {
 			return true
 		}
 	}
@@ -40,6 +40,8 @@ type StoreInfluence struct {
 	RegionCount int64
 	LeaderSize  int64
 	LeaderCount int64
+	// LeaderLoads records the leader load rates of the load-based leader schedule policies.
+	LeaderLoads map[core.SchedulePolicy]int64
 	StepCost    map[storelimit.Type]int64
 }
 
@@ -52,6 +54,8 @@ func (s StoreInfluence) ResourceProperty(kind core.ScheduleKind) int64 {
 			return s.LeaderCount
 		case core.BySize:
 			return s.LeaderSize
+		case core.ByReadQuery, core.ByWriteQuery, core.ByReadByte, core.ByWriteByte:
+			return s.LeaderLoads[kind.Policy]
 		default:
 			return 0
 		}
@@ -62,6 +66,28 @@ func (s StoreInfluence) ResourceProperty(kind core.ScheduleKind) int64 {
 	}
 }
 
+func (s *StoreInfluence) addLeaderLoads(region *core.RegionInfo, sign int64) {
+	for _, policy := range []core.SchedulePolicy{core.ByReadQuery, core.ByWriteQuery, core.ByReadByte, core.ByWriteByte} {
+		s.addLeaderLoad(policy, sign*region.GetLeaderLoadRate(policy))
+	}
+}
+
+func (s *StoreInfluence) addPolicyLoads(loads map[core.SchedulePolicy]int64, sign int64) {
+	for policy, rate := range loads {
+		s.addLeaderLoad(policy, sign*rate)
+	}
+}
+
+func (s *StoreInfluence) addLeaderLoad(policy core.SchedulePolicy, rate int64) {
+	if rate == 0 {
+		return
+	}
+	if s.LeaderLoads == nil {
+		s.LeaderLoads = make(map[core.SchedulePolicy]int64)
+	}
+	s.LeaderLoads[policy] += rate
+}
+
 // GetStepCost returns the specific type step cost
 func (s StoreInfluence) GetStepCost(limitType storelimit.Type) int64 {
 	if s.StepCost == nil {
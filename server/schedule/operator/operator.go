@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -51,6 +52,12 @@ type Operator struct {
 	AdditionalInfos  map[string]string
 	ApproximateSize  int64
 	timeout          time.Duration
+	schedulerName    string
+	checkerName      string
+	// predictedLeaderLoads are the leader loads of a region which is predicted to be hot
+	// soon. They are added to the influence of transferring the leader, since the current
+	// loads of the region do not reflect them yet.
+	predictedLeaderLoads map[core.SchedulePolicy]int64
 }
 
 // NewOperator creates a new operator.
@@ -116,6 +123,28 @@ func (o *Operator) SetDesc(desc string) {
 	o.desc = desc
 }
 
+// SetSchedulerName sets the name of the scheduler which creates the operator.
+func (o *Operator) SetSchedulerName(name string) {
+	o.schedulerName = name
+}
+
+// GetSchedulerName returns the name of the scheduler which creates the operator.
+// It is empty if the operator is not created by a scheduler.
+func (o *Operator) GetSchedulerName() string {
+	return o.schedulerName
+}
+
+// SetCheckerName sets the name of the checker which creates the operator.
+func (o *Operator) SetCheckerName(name string) {
+	o.checkerName = name
+}
+
+// GetCheckerName returns the name of the checker which creates the operator.
+// It is empty if the operator is not created by a checker.
+func (o *Operator) GetCheckerName() string {
+	return o.checkerName
+}
+
 // AttachKind attaches an operator kind for the operator.
 func (o *Operator) AttachKind(kind OpKind) {
 	o.kind |= kind
@@ -250,6 +279,22 @@ func (o *Operator) Step(i int) OpStep {
 	return nil
 }
 
+// StepDuration returns the duration of the i-th step. It returns 0 if the step is not finished.
+func (o *Operator) StepDuration(i int) time.Duration {
+	if i < 0 || i >= len(o.stepsTime) {
+		return 0
+	}
+	finishTime := atomic.LoadInt64(&(o.stepsTime[i]))
+	if finishTime == 0 {
+		return 0
+	}
+	startTime := o.GetStartTime()
+	if i > 0 {
+		startTime = time.Unix(0, atomic.LoadInt64(&(o.stepsTime[i-1])))
+	}
+	return time.Unix(0, finishTime).Sub(startTime)
+}
+
 // getCurrentTimeAndStep returns the start time of the i-th step.
 // opStep is nil if the i-th step is not found.
 func (o *Operator) getCurrentTimeAndStep() (startTime time.Time, opStep OpStep) {
@@ -313,11 +358,17 @@ func (o *Operator) GetPriorityLevel() core.PriorityLevel {
 	return o.level
 }
 
+// SetPredictedLeaderLoads sets the leader loads of the region predicted to be hot soon.
+func (o *Operator) SetPredictedLeaderLoads(loads map[core.SchedulePolicy]int64) {
+	o.predictedLeaderLoads = loads
+}
+
 // UnfinishedInfluence calculates the store difference which unfinished operator steps make.
 func (o *Operator) UnfinishedInfluence(opInfluence OpInfluence, region *core.RegionInfo) {
 	for step := atomic.LoadInt32(&o.currentStep); int(step) < len(o.steps); step++ {
 		if !o.steps[int(step)].IsFinish(region) {
 			o.steps[int(step)].Influence(opInfluence, region)
+			o.predictedInfluence(opInfluence, o.steps[int(step)])
 		}
 	}
 }
@@ -326,7 +377,18 @@ func (o *Operator) UnfinishedInfluence(opInfluence OpInfluence, region *core.Reg
 func (o *Operator) TotalInfluence(opInfluence OpInfluence, region *core.RegionInfo) {
 	for step := 0; step < len(o.steps); step++ {
 		o.steps[step].Influence(opInfluence, region)
+		o.predictedInfluence(opInfluence, o.steps[step])
+	}
+}
+
+// predictedInfluence adds the predicted leader loads to the influence if the step transfers the leader.
+func (o *Operator) predictedInfluence(opInfluence OpInfluence, step OpStep) {
+	tl, ok := step.(TransferLeader)
+	if !ok || len(o.predictedLeaderLoads) == 0 {
+		return
 	}
+	opInfluence.GetStoreInfluence(tl.FromStore).addPolicyLoads(o.predictedLeaderLoads, -1)
+	opInfluence.GetStoreInfluence(tl.ToStore).addPolicyLoads(o.predictedLeaderLoads, 1)
 }
 
 // OpHistory is used to log and visualize completed operators.
@@ -371,6 +433,57 @@ func (o *Operator) History() []OpHistory {
 	return histories
 }
 
+// RelatedStores returns the sorted IDs of the stores involved in the operator's steps.
+func (o *Operator) RelatedStores() []uint64 {
+	set := make(map[uint64]struct{})
+	add := func(ids ...uint64) {
+		for _, id := range ids {
+			if id != 0 {
+				set[id] = struct{}{}
+			}
+		}
+	}
+	for _, step := range o.steps {
+		switch s := step.(type) {
+		case TransferLeader:
+			add(s.FromStore, s.ToStore)
+			add(s.ToStores...)
+		case AddPeer:
+			add(s.ToStore)
+		case AddLearner:
+			add(s.ToStore)
+		case PromoteLearner:
+			add(s.ToStore)
+		case RemovePeer:
+			add(s.FromStore)
+		case BecomeWitness:
+			add(s.StoreID)
+		case BecomeNonWitness:
+			add(s.StoreID)
+		case ChangePeerV2Enter:
+			for _, pl := range s.PromoteLearners {
+				add(pl.ToStore)
+			}
+			for _, dv := range s.DemoteVoters {
+				add(dv.ToStore)
+			}
+		case ChangePeerV2Leave:
+			for _, pl := range s.PromoteLearners {
+				add(pl.ToStore)
+			}
+			for _, dv := range s.DemoteVoters {
+				add(dv.ToStore)
+			}
+		}
+	}
+	stores := make([]uint64, 0, len(set))
+	for id := range set {
+		stores = append(stores, id)
+	}
+	sort.Slice(stores, func(i, j int) bool { return stores[i] < stores[j] })
+	return stores
+}
+
 // OpRecord is used to log and visualize completed operators.
 // NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
 type OpRecord struct {
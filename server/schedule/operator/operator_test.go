@@ -487,3 +487,17 @@ func (suite *operatorTestSuite) TestRecord() {
 	suite.Equal(now, ob.FinishTime)
 	suite.Greater(ob.duration.Seconds(), time.Second.Seconds())
 }
+
+func (suite *operatorTestSuite) TestRelatedStores() {
+	op := suite.newTestOperator(1, OpRegion|OpLeader,
+		AddLearner{ToStore: 4, PeerID: 4},
+		ChangePeerV2Enter{
+			PromoteLearners: []PromoteLearner{{ToStore: 4, PeerID: 4}},
+			DemoteVoters:    []DemoteVoter{{ToStore: 2, PeerID: 2}},
+		},
+		TransferLeader{FromStore: 1, ToStore: 3},
+		RemovePeer{FromStore: 2, PeerID: 2},
+	)
+	suite.Equal([]uint64{1, 2, 3, 4}, op.RelatedStores())
+	suite.Empty(suite.newTestOperator(1, OpMerge, MergeRegion{}).RelatedStores())
+}
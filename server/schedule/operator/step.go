@@ -115,8 +115,10 @@ func (tl TransferLeader) Influence(opInfluence OpInfluence, region *core.RegionI
 
 	from.LeaderSize -= region.GetApproximateSize()
 	from.LeaderCount--
+	from.addLeaderLoads(region, -1)
 	to.LeaderSize += region.GetApproximateSize()
 	to.LeaderCount++
+	to.addLeaderLoads(region, 1)
 }
 
 // Timeout returns duration that current step may take.
@@ -67,11 +67,12 @@ type OperatorController struct {
 	wop             WaitingOperator
 	wopStatus       *WaitingOperatorStatus
 	opNotifierQueue operatorQueue
+	snapshotLimit   *storelimit.SnapshotController
 }
 
 // NewOperatorController creates a OperatorController.
 func NewOperatorController(ctx context.Context, cluster Cluster, hbStreams *hbstream.HeartbeatStreams) *OperatorController {
-	return &OperatorController{
+	oc := &OperatorController{
 		ctx:             ctx,
 		cluster:         cluster,
 		operators:       make(map[uint64]*operator.Operator),
@@ -79,10 +80,17 @@ func NewOperatorController(ctx context.Context, cluster Cluster, hbStreams *hbst
 		fastOperators:   cache.NewIDTTL(ctx, time.Minute, FastOperatorFinishTime),
 		counts:          make(map[operator.OpKind]uint64),
 		opRecords:       NewOperatorRecords(ctx),
-		wop:             NewRandBuckets(),
 		wopStatus:       NewWaitingOperatorStatus(),
 		opNotifierQueue: make(operatorQueue, 0),
+		snapshotLimit:   storelimit.NewSnapshotController(),
 	}
+	oc.wop = newRandBucketsWithWeight(oc.getSourceWeight)
+	return oc
+}
+
+// getSourceWeight returns the weight of the source when promoting waiting operators.
+func (oc *OperatorController) getSourceWeight(source string) float64 {
+	return oc.cluster.GetOpts().GetOperatorQuota(source).Weight
 }
 
 // Ctx returns a context which will be canceled once RaftCluster is stopped.
@@ -118,6 +126,7 @@ func (oc *OperatorController) Dispatch(region *core.RegionInfo, source string) {
 			}
 			oc.SendScheduleCommand(region, step, source)
 		case operator.SUCCESS:
+			oc.observeSnapshotSteps(op)
 			if oc.RemoveOperator(op) {
 				operatorWaitCounter.WithLabelValues(op.Desc(), "promote-success").Inc()
 				oc.PromoteWaitingOperator()
@@ -152,6 +161,23 @@ func (oc *OperatorController) Dispatch(region *core.RegionInfo, source string) {
 	}
 }
 
+// observeSnapshotSteps observes the durations of the steps which add peers to
+// stores, which indicate how fast the stores receive and apply snapshots.
+func (oc *OperatorController) observeSnapshotSteps(op *operator.Operator) {
+	for i := 0; i < op.Len(); i++ {
+		var storeID uint64
+		switch step := op.Step(i).(type) {
+		case operator.AddLearner:
+			storeID = step.ToStore
+		case operator.AddPeer:
+			storeID = step.ToStore
+		default:
+			continue
+		}
+		oc.snapshotLimit.ObserveStepDuration(storeID, op.StepDuration(i))
+	}
+}
+
 func (oc *OperatorController) checkStaleOperator(op *operator.Operator, step operator.OpStep, region *core.RegionInfo) bool {
 	err := step.CheckInProgress(oc.cluster, region)
 	if err != nil {
@@ -292,14 +318,14 @@ func (oc *OperatorController) AddWaitingOperator(ops ...*operator.Operator) int
 		}
 		oc.wop.PutOperator(op)
 		if isMerge {
-			// count two merge operators as one, so wopStatus.ops[desc] should
+			// count two merge operators as one, so wopStatus.ops[source] should
 			// not be updated here
 			i++
 			added++
 			oc.wop.PutOperator(ops[i])
 		}
 		operatorWaitCounter.WithLabelValues(desc, "put").Inc()
-		oc.wopStatus.ops[desc]++
+		oc.wopStatus.inc(operatorSource(op))
 		added++
 		needPromoted++
 	}
@@ -354,10 +380,12 @@ func (oc *OperatorController) PromoteWaitingOperator() {
 				_ = op.Cancel()
 				oc.buryOperator(op)
 			}
-			oc.wopStatus.ops[ops[0].Desc()]--
+			oc.wopStatus.dec(operatorSource(ops[0]))
 			continue
 		}
-		oc.wopStatus.ops[ops[0].Desc()]--
+		source := operatorSource(ops[0])
+		oc.wopStatus.dec(source)
+		operatorPromoteDuration.WithLabelValues(source).Observe(ops[0].ElapsedTime().Seconds())
 		break
 	}
 
@@ -411,8 +439,9 @@ func (oc *OperatorController) checkAddOperator(isPromoting bool, ops ...*operato
 			operatorWaitCounter.WithLabelValues(op.Desc(), "unexpected-status").Inc()
 			return false
 		}
-		if !isPromoting && oc.wopStatus.ops[op.Desc()] >= oc.cluster.GetOpts().GetSchedulerMaxWaitingOperator() {
-			log.Debug("exceed max return false", zap.Uint64("waiting", oc.wopStatus.ops[op.Desc()]), zap.String("desc", op.Desc()), zap.Uint64("max", oc.cluster.GetOpts().GetSchedulerMaxWaitingOperator()))
+		source := operatorSource(op)
+		if maxWaiting := oc.cluster.GetOpts().GetOperatorQuota(source).MaxWaiting; !isPromoting && oc.wopStatus.ops[source] >= maxWaiting {
+			log.Debug("exceed max return false", zap.Uint64("waiting", oc.wopStatus.ops[source]), zap.String("desc", op.Desc()), zap.String("source", source), zap.Uint64("max", maxWaiting))
 			operatorWaitCounter.WithLabelValues(op.Desc(), "exceed-max").Inc()
 			return false
 		}
@@ -528,6 +557,29 @@ func (oc *OperatorController) RemoveOperator(op *operator.Operator, extraFields
 	return removed
 }
 
+// RemoveOperators removes the running operators selected by the filter and
+// returns the removed ones. If dryRun is true, the selected operators are
+// returned without being removed.
+func (oc *OperatorController) RemoveOperators(filter *OperatorFilter, dryRun bool) []*operator.Operator {
+	var selected []*operator.Operator
+	for _, op := range oc.GetOperators() {
+		if filter.match(op, oc.cluster.GetRegion(op.RegionID())) {
+			selected = append(selected, op)
+		}
+	}
+	if dryRun {
+		return selected
+	}
+	removed := selected[:0]
+	reason := zap.String("reason", "removed in bulk by filter: "+filter.String())
+	for _, op := range selected {
+		if oc.RemoveOperator(op, reason) {
+			removed = append(removed, op)
+		}
+	}
+	return removed
+}
+
 func (oc *OperatorController) removeOperatorWithoutBury(op *operator.Operator) bool {
 	oc.Lock()
 	defer oc.Unlock()
@@ -606,7 +658,13 @@ func (oc *OperatorController) buryOperator(op *operator.Operator, extraFields ..
 		operatorCounter.WithLabelValues(op.Desc(), "cancel").Inc()
 	}
 
-	oc.opRecords.Put(op)
+	oc.opRecords.Put(op, oc.isOperatorHistoryEnabled())
+}
+
+// isOperatorHistoryEnabled returns whether the finished operators are kept for the history.
+func (oc *OperatorController) isOperatorHistoryEnabled() bool {
+	// The cluster is nil for the operator controllers only used to create schedulers.
+	return oc.cluster != nil && oc.cluster.GetOpts().GetOperatorHistoryReservedDays() > 0
 }
 
 // GetOperatorStatus gets the operator and its status with the specify id.
@@ -691,6 +749,12 @@ func (oc *OperatorController) GetHistory(start time.Time) []operator.OpHistory {
 	return history
 }
 
+// TakeFinishedOperators returns the operators finished since the last call,
+// which are used to persist the history of operators.
+func (oc *OperatorController) TakeFinishedOperators() []*OperatorWithStatus {
+	return oc.opRecords.TakeFinished()
+}
+
 // updateCounts updates resource counts using current pending operators.
 func (oc *OperatorController) updateCounts(operators map[uint64]*operator.Operator) {
 	for k := range oc.counts {
@@ -795,9 +859,19 @@ func (o *OperatorWithStatus) MarshalJSON() ([]byte, error) {
 // OperatorRecords remains the operator and its status for a while.
 type OperatorRecords struct {
 	ttl *cache.TTLUint64
+
+	mu syncutil.Mutex
+	// finished keeps the operators finished since the last time they are taken,
+	// which are used to persist the history of operators.
+	finished []*OperatorWithStatus
 }
 
-const operatorStatusRemainTime = 10 * time.Minute
+const (
+	operatorStatusRemainTime = 10 * time.Minute
+	// maxFinishedOperators is the max number of the finished operators waiting to be taken.
+	// The oldest ones are dropped if they are not taken in time.
+	maxFinishedOperators = 10000
+)
 
 // NewOperatorRecords returns a OperatorRecords.
 func NewOperatorRecords(ctx context.Context) *OperatorRecords {
@@ -815,11 +889,32 @@ func (o *OperatorRecords) Get(id uint64) *OperatorWithStatus {
 	return v.(*OperatorWithStatus)
 }
 
-// Put puts the operator and its status.
-func (o *OperatorRecords) Put(op *operator.Operator) {
+// Put puts the operator and its status. The operator is also kept in the finished
+// operators if keepFinished is true, otherwise the finished operators are dropped.
+func (o *OperatorRecords) Put(op *operator.Operator, keepFinished bool) {
 	id := op.RegionID()
 	record := NewOperatorWithStatus(op)
 	o.ttl.Put(id, record)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !keepFinished {
+		o.finished = nil
+		return
+	}
+	if len(o.finished) >= maxFinishedOperators {
+		o.finished = o.finished[1:]
+	}
+	o.finished = append(o.finished, record)
+}
+
+// TakeFinished returns the operators finished since the last call and clears them.
+func (o *OperatorRecords) TakeFinished() []*OperatorWithStatus {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	finished := o.finished
+	o.finished = nil
+	return finished
 }
 
 // ExceedStoreLimit returns true if the store exceeds the cost limit after adding the operator. Otherwise, returns false.
@@ -854,19 +949,46 @@ func (oc *OperatorController) exceedStoreLimitLocked(ops ...*operator.Operator)
 	return false
 }
 
+// CollectSnapshotStats adjusts the store limits with the snapshot statistics
+// in the store heartbeat, which takes effect in the snapshot store limit mode.
+func (oc *OperatorController) CollectSnapshotStats(stats *pdpb.StoreStats) {
+	oc.snapshotLimit.Collect(stats)
+}
+
+// GetEffectiveStoreLimit returns the effective rate (per minute) of the store limit.
+// In the snapshot store limit mode, the configured rate is adjusted according to the
+// snapshot activity of the store.
+func (oc *OperatorController) GetEffectiveStoreLimit(storeID uint64, limitType storelimit.Type) float64 {
+	rate := oc.cluster.GetOpts().GetStoreLimitByType(storeID, limitType)
+	if oc.cluster.GetOpts().GetStoreLimitMode() != "snapshot" || rate >= storelimit.Unlimited {
+		return rate
+	}
+	return rate * oc.snapshotLimit.Ratio(storeID, limitType)
+}
+
 // getOrCreateStoreLimit is used to get or create the limit of a store.
 func (oc *OperatorController) getOrCreateStoreLimit(storeID uint64, limitType storelimit.Type) *storelimit.StoreLimit {
-	ratePerSec := oc.cluster.GetOpts().GetStoreLimitByType(storeID, limitType) / StoreBalanceBaseTime
+	ratePerSec := oc.GetEffectiveStoreLimit(storeID, limitType) / StoreBalanceBaseTime
 	s := oc.cluster.GetStore(storeID)
 	if s == nil {
 		log.Error("invalid store ID", zap.Uint64("store-id", storeID))
 		return nil
 	}
-	if s.GetStoreLimit(limitType) == nil {
-		oc.cluster.GetBasicCluster().ResetStoreLimit(storeID, limitType, ratePerSec)
+	limit := s.GetStoreLimit(limitType)
+	if limit != nil && ratePerSec == limit.Rate() {
+		return limit
 	}
-	if ratePerSec != s.GetStoreLimit(limitType).Rate() {
-		oc.cluster.GetBasicCluster().ResetStoreLimit(storeID, limitType, ratePerSec)
+	if limit != nil && oc.cluster.GetOpts().GetStoreLimitMode() == "snapshot" {
+		// The effective rate follows the snapshot activity in the snapshot mode, so adjust it
+		// in place rather than resetting the limit, which refills the bucket on every change.
+		limit.SetRate(ratePerSec)
+		return limit
 	}
+	oc.cluster.GetBasicCluster().ResetStoreLimit(storeID, limitType, ratePerSec)
 	return s.GetStoreLimit(limitType)
 }
+
+// RemoveSnapshotStats removes the snapshot statistics of the store which no longer exists.
+func (oc *OperatorController) RemoveSnapshotStats(storeID uint64) {
+	oc.snapshotLimit.RemoveStore(storeID)
+}
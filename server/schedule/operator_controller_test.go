@@ -132,6 +132,78 @@ func (suite *operatorControllerTestSuite) TestOperatorStatus() {
 	ApplyOperator(tc, op2)
 	oc.Dispatch(region2, "test")
 	suite.Equal(pdpb.OperatorStatus_SUCCESS, oc.GetOperatorStatus(2).Status)
+	// The finished operators are taken only once.
+	finished := oc.TakeFinishedOperators()
+	suite.Len(finished, 2)
+	suite.Equal(op1, finished[0].Operator)
+	suite.Equal(op2, finished[1].Operator)
+	suite.Empty(oc.TakeFinishedOperators())
+
+	// The finished operators are not kept when the operator history is disabled.
+	cfg := opt.GetScheduleConfig().Clone()
+	cfg.OperatorHistoryReservedDays = 0
+	opt.SetScheduleConfig(cfg)
+	op3 := operator.NewTestOperator(1, &metapb.RegionEpoch{}, operator.OpRegion, steps...)
+	suite.True(op3.Start())
+	oc.SetOperator(op3)
+	suite.True(oc.RemoveOperator(op3))
+	suite.Empty(oc.TakeFinishedOperators())
+}
+
+func (suite *operatorControllerTestSuite) TestRemoveOperators() {
+	opt := config.NewTestOptions()
+	tc := mockcluster.NewCluster(suite.ctx, opt)
+	stream := hbstream.NewTestHeartbeatStreams(suite.ctx, tc.ID, tc, false /* no need to run */)
+	oc := NewOperatorController(suite.ctx, tc, stream)
+	for i := uint64(1); i <= 4; i++ {
+		tc.AddLeaderStore(i, 1)
+	}
+	tc.AddLeaderRegionWithRange(1, "", "b", 1, 2)
+	tc.AddLeaderRegionWithRange(2, "b", "d", 2, 3)
+	tc.AddLeaderRegionWithRange(3, "d", "", 3, 4)
+	op1 := operator.NewTestOperator(1, &metapb.RegionEpoch{}, operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+	op1.SetSchedulerName("balance-leader-scheduler")
+	op2 := operator.NewTestOperator(2, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 4, PeerID: 4}, operator.RemovePeer{FromStore: 2})
+	op2.SetSchedulerName("balance-region-scheduler")
+	op3 := operator.NewTestOperator(3, &metapb.RegionEpoch{}, operator.OpRegion|operator.OpAdmin, operator.AddPeer{ToStore: 1, PeerID: 5}, operator.RemovePeer{FromStore: 4})
+	for _, op := range []*operator.Operator{op1, op2, op3} {
+		suite.True(op.Start())
+		oc.SetOperator(op)
+	}
+	operator.SetOperatorStatusReachTime(op3, operator.CREATED, time.Now().Add(-time.Hour))
+
+	testCases := []struct {
+		filter   *OperatorFilter
+		expected []uint64
+	}{
+		{&OperatorFilter{}, []uint64{1, 2, 3}},
+		{&OperatorFilter{Desc: "test"}, []uint64{1, 2, 3}},
+		{&OperatorFilter{Scheduler: "balance-region-scheduler"}, []uint64{2}},
+		{&OperatorFilter{Kind: operator.OpRegion}, []uint64{2, 3}},
+		{&OperatorFilter{Kind: operator.OpRegion | operator.OpAdmin}, []uint64{3}},
+		{&OperatorFilter{StoreID: 4}, []uint64{2, 3}},
+		{&OperatorFilter{StoreID: 1, Kind: operator.OpLeader}, []uint64{1}},
+		{&OperatorFilter{StartKey: []byte("a"), EndKey: []byte("c")}, []uint64{1, 2}},
+		{&OperatorFilter{StartKey: []byte("d")}, []uint64{3}},
+		{&OperatorFilter{MinAge: time.Minute}, []uint64{3}},
+	}
+	regionIDs := func(ops []*operator.Operator) []uint64 {
+		ids := make([]uint64, 0, len(ops))
+		for _, op := range ops {
+			ids = append(ids, op.RegionID())
+		}
+		return ids
+	}
+	for _, testCase := range testCases {
+		suite.ElementsMatch(testCase.expected, regionIDs(oc.RemoveOperators(testCase.filter, true)), testCase.filter.String())
+	}
+	suite.Len(oc.GetOperators(), 3)
+
+	suite.ElementsMatch([]uint64{2, 3}, regionIDs(oc.RemoveOperators(&OperatorFilter{StoreID: 4}, false)))
+	suite.Len(oc.GetOperators(), 1)
+	suite.Equal(operator.CANCELED, op2.Status())
+	suite.Equal(operator.CANCELED, op3.Status())
+	suite.Empty(oc.RemoveOperators(&OperatorFilter{StoreID: 4}, false))
 }
 
 func (suite *operatorControllerTestSuite) TestFastFailOperator() {
@@ -434,6 +506,49 @@ func (suite *operatorControllerTestSuite) TestStoreLimit() {
 	suite.False(oc.RemoveOperator(op))
 }
 
+func (suite *operatorControllerTestSuite) TestSnapshotStoreLimit() {
+	opt := config.NewTestOptions()
+	tc := mockcluster.NewCluster(suite.ctx, opt)
+	stream := hbstream.NewTestHeartbeatStreams(suite.ctx, tc.ID, tc, false /* no need to run */)
+	oc := NewOperatorController(suite.ctx, tc, stream)
+	tc.AddLeaderStore(1, 0)
+	tc.AddLeaderStore(2, 0)
+	for i := uint64(1); i <= 10; i++ {
+		tc.AddLeaderRegion(i, 1)
+		tc.PutRegion(tc.GetRegion(i).Clone(core.SetApproximateSize(10)))
+	}
+	tc.SetStoreLimit(2, storelimit.AddPeer, 120)
+
+	// the snapshot activity does not affect the limit in the manual mode.
+	oc.CollectSnapshotStats(&pdpb.StoreStats{StoreId: 2, ReceivingSnapCount: 5})
+	suite.Equal(120.0, oc.GetEffectiveStoreLimit(2, storelimit.AddPeer))
+
+	cfg := opt.GetScheduleConfig().Clone()
+	cfg.StoreLimitMode = "snapshot"
+	opt.SetScheduleConfig(cfg)
+	suite.Equal(60.0, oc.GetEffectiveStoreLimit(2, storelimit.AddPeer))
+	suite.Equal(opt.GetStoreLimitByType(2, storelimit.RemovePeer), oc.GetEffectiveStoreLimit(2, storelimit.RemovePeer))
+	// only 60 add-peer per minute is allowed for the congested store.
+	for i := uint64(1); i <= 5; i++ {
+		op := operator.NewTestOperator(i, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: i})
+		suite.True(oc.AddOperator(op))
+		suite.checkRemoveOperatorSuccess(oc, op)
+	}
+	op := operator.NewTestOperator(6, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 6})
+	suite.False(oc.AddOperator(op))
+
+	// ramp up when the store becomes idle.
+	oc.CollectSnapshotStats(&pdpb.StoreStats{StoreId: 2})
+	suite.InDelta(72.0, oc.GetEffectiveStoreLimit(2, storelimit.AddPeer), 1e-9)
+	// the bucket is not refilled when the rate changes.
+	suite.False(oc.AddOperator(op))
+	suite.InDelta(72.0/StoreBalanceBaseTime, tc.GetStore(2).GetStoreLimit(storelimit.AddPeer).Rate(), 1e-9)
+
+	// the statistics are removed with the store.
+	oc.RemoveSnapshotStats(2)
+	suite.Equal(120.0, oc.GetEffectiveStoreLimit(2, storelimit.AddPeer))
+}
+
 // #1652
 func (suite *operatorControllerTestSuite) TestDispatchOutdatedRegion() {
 	cluster := mockcluster.NewCluster(suite.ctx, config.NewTestOptions())
@@ -781,6 +896,62 @@ func (suite *operatorControllerTestSuite) TestAddWaitingOperator() {
 	suite.Equal(0, controller.AddWaitingOperator(addPeerOp(0)))
 }
 
+func (suite *operatorControllerTestSuite) TestOperatorQuota() {
+	opts := config.NewTestOptions()
+	cluster := mockcluster.NewCluster(suite.ctx, opts)
+	stream := hbstream.NewTestHeartbeatStreams(suite.ctx, cluster.ID, cluster, false /* no need to run */)
+	controller := NewOperatorController(suite.ctx, cluster, stream)
+	cluster.AddLabelsStore(1, 1, map[string]string{"host": "host1"})
+	cluster.AddLabelsStore(2, 1, map[string]string{"host": "host2"})
+	addPeerOp := func(i uint64, scheduler string) *operator.Operator {
+		region := newRegionInfo(i, fmt.Sprintf("%da", i), fmt.Sprintf("%db", i), 1, 1, []uint64{101, 1}, []uint64{101, 1})
+		cluster.PutRegion(region)
+		op, err := operator.CreateAddPeerOperator("add-peer", cluster, region, &metapb.Peer{StoreId: 2}, operator.OpKind(0))
+		suite.NoError(err)
+		op.SetSchedulerName(scheduler)
+		return op
+	}
+	checkerOp := func(i uint64, checker string) *operator.Operator {
+		op := addPeerOp(i, "")
+		op.SetCheckerName(checker)
+		return op
+	}
+
+	scheduleCfg := opts.GetScheduleConfig().Clone()
+	scheduleCfg.OperatorQuotas = map[string]config.OperatorQuotaConfig{
+		"balance-hot-region-scheduler": {MaxWaiting: 2, Weight: 2},
+	}
+	opts.SetScheduleConfig(scheduleCfg)
+	suite.Equal(config.OperatorQuotaConfig{MaxWaiting: 2, Weight: 2}, opts.GetOperatorQuota("balance-hot-region-scheduler"))
+	suite.Equal(config.OperatorQuotaConfig{MaxWaiting: opts.GetSchedulerMaxWaitingOperator(), Weight: 1}, opts.GetOperatorQuota("balance-region-scheduler"))
+
+	// the quota is counted by the scheduler, so a scheduler cannot take up the quota of others.
+	var batch []*operator.Operator
+	for i := uint64(1); i <= 4; i++ {
+		batch = append(batch, addPeerOp(i, "balance-hot-region-scheduler"))
+	}
+	suite.Equal(2, controller.AddWaitingOperator(batch...))
+	batch = batch[:0]
+	for i := uint64(11); i <= 14; i++ {
+		batch = append(batch, addPeerOp(i, "balance-region-scheduler"))
+	}
+	suite.Equal(4, controller.AddWaitingOperator(batch...))
+	suite.Len(controller.GetOperators(), 6)
+
+	// the operators from checkers are counted by the checker, no matter what the description is.
+	scheduleCfg = opts.GetScheduleConfig().Clone()
+	scheduleCfg.OperatorQuotas["rule-checker"] = config.OperatorQuotaConfig{MaxWaiting: 1}
+	opts.SetScheduleConfig(scheduleCfg)
+	suite.Equal(1, controller.AddWaitingOperator(checkerOp(21, "rule-checker"), checkerOp(22, "rule-checker")))
+	suite.Equal(2, controller.AddWaitingOperator(checkerOp(23, "replica-checker"), checkerOp(24, "replica-checker")))
+
+	// the operators from neither schedulers nor checkers are counted by the description.
+	scheduleCfg = opts.GetScheduleConfig().Clone()
+	scheduleCfg.OperatorQuotas["add-peer"] = config.OperatorQuotaConfig{MaxWaiting: 1}
+	opts.SetScheduleConfig(scheduleCfg)
+	suite.Equal(1, controller.AddWaitingOperator(addPeerOp(31, ""), addPeerOp(32, "")))
+}
+
 // issue #5279
 func (suite *operatorControllerTestSuite) TestInvalidStoreId() {
 	opt := config.NewTestOptions()
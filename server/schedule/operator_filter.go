@@ -0,0 +1,100 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tikv/pd/pkg/slice"
+	"github.com/tikv/pd/server/core"
+	"github.com/tikv/pd/server/schedule/operator"
+)
+
+// OperatorFilter selects the running operators to be removed in bulk.
+// The zero value of each field means no restriction on it.
+type OperatorFilter struct {
+	// Desc is the description of operators, which is usually set by the scheduler or checker.
+	Desc string
+	// Scheduler is the name of the scheduler which creates the operators.
+	Scheduler string
+	// Kind selects the operators having all the kinds.
+	Kind operator.OpKind
+	// StoreID selects the operators involving the store.
+	StoreID uint64
+	// StartKey and EndKey select the operators whose regions overlap with the key range.
+	StartKey, EndKey []byte
+	// MinAge selects the operators created at least MinAge ago.
+	MinAge time.Duration
+}
+
+// IsEmpty returns true if the filter has no restriction.
+func (f *OperatorFilter) IsEmpty() bool {
+	return f.Desc == "" && f.Scheduler == "" && f.Kind == 0 && f.StoreID == 0 &&
+		len(f.StartKey) == 0 && len(f.EndKey) == 0 && f.MinAge == 0
+}
+
+func (f *OperatorFilter) String() string {
+	var conditions []string
+	if f.Desc != "" {
+		conditions = append(conditions, "desc="+f.Desc)
+	}
+	if f.Scheduler != "" {
+		conditions = append(conditions, "scheduler="+f.Scheduler)
+	}
+	if f.Kind != 0 {
+		conditions = append(conditions, "kind="+f.Kind.String())
+	}
+	if f.StoreID != 0 {
+		conditions = append(conditions, fmt.Sprintf("store=%d", f.StoreID))
+	}
+	if len(f.StartKey) != 0 || len(f.EndKey) != 0 {
+		conditions = append(conditions, fmt.Sprintf("range=[%s, %s)",
+			core.HexRegionKeyStr(f.StartKey), core.HexRegionKeyStr(f.EndKey)))
+	}
+	if f.MinAge != 0 {
+		conditions = append(conditions, "min-age="+f.MinAge.String())
+	}
+	return strings.Join(conditions, ", ")
+}
+
+func (f *OperatorFilter) match(op *operator.Operator, region *core.RegionInfo) bool {
+	if f.Desc != "" && op.Desc() != f.Desc {
+		return false
+	}
+	if f.Scheduler != "" && op.GetSchedulerName() != f.Scheduler {
+		return false
+	}
+	if op.Kind()&f.Kind != f.Kind {
+		return false
+	}
+	if f.StoreID != 0 && !slice.Contains(op.RelatedStores(), f.StoreID) {
+		return false
+	}
+	if len(f.StartKey) != 0 || len(f.EndKey) != 0 {
+		if region == nil {
+			return false
+		}
+		if len(f.EndKey) != 0 && bytes.Compare(region.GetStartKey(), f.EndKey) >= 0 {
+			return false
+		}
+		if len(region.GetEndKey()) != 0 && bytes.Compare(region.GetEndKey(), f.StartKey) <= 0 {
+			return false
+		}
+	}
+	return f.MinAge == 0 || op.ElapsedTime() >= f.MinAge
+}
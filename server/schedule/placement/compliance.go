@@ -0,0 +1,201 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/tikv/pd/server/core"
+)
+
+// ViolationType is the reason why a region does not comply with its placement rules.
+type ViolationType string
+
+const (
+	// ViolationMissPeer means the rule does not have enough peers and there is
+	// no orphan peer to be moved.
+	ViolationMissPeer ViolationType = "miss-peer"
+	// ViolationWrongLabel means the rule does not have enough peers while some
+	// peers are placed on the stores not matching the label constraints.
+	ViolationWrongLabel ViolationType = "wrong-label"
+	// ViolationWrongRole means some peers of the rule have a different role.
+	ViolationWrongRole ViolationType = "wrong-role"
+	// ViolationIsolationLevel means some peers of the rule are not isolated at
+	// the isolation level of the rule.
+	ViolationIsolationLevel ViolationType = "isolation-level"
+	// ViolationOrphanPeer means the region has peers not belonging to any rule.
+	ViolationOrphanPeer ViolationType = "orphan-peer"
+)
+
+// maxComplianceSamples is the max count of the sampled regions of each violation.
+const maxComplianceSamples = 10
+
+// ComplianceStats is the count and the sampled IDs of the regions violating the rules.
+type ComplianceStats struct {
+	Count   int      `json:"count"`
+	Samples []uint64 `json:"samples"`
+}
+
+func (s *ComplianceStats) add(regionID uint64) {
+	s.Count++
+	if len(s.Samples) < maxComplianceSamples {
+		s.Samples = append(s.Samples, regionID)
+	}
+}
+
+// RuleCompliance is the regions violating a rule grouped by the violation types.
+type RuleCompliance struct {
+	GroupID    string                             `json:"group_id"`
+	ID         string                             `json:"id"`
+	Violations map[ViolationType]*ComplianceStats `json:"violations"`
+}
+
+// ComplianceReport is the regions that do not comply with their placement rules.
+//
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type ComplianceReport struct {
+	Time time.Time `json:"time"`
+	// RegionCount is the count of the checked regions.
+	RegionCount int `json:"region-count"`
+	// NonCompliant is the regions violating any rule.
+	NonCompliant ComplianceStats `json:"non-compliant"`
+	// Rules is the regions violating each rule, sorted by the rule key.
+	Rules []*RuleCompliance `json:"rules"`
+	// OrphanPeers is the regions having orphan peers, which do not belong to any rule.
+	OrphanPeers ComplianceStats `json:"orphan-peers"`
+
+	rules map[[2]string]*RuleCompliance
+}
+
+// NewComplianceReport creates an empty report.
+func NewComplianceReport() *ComplianceReport {
+	return &ComplianceReport{
+		Time:  time.Now(),
+		Rules: []*RuleCompliance{},
+		rules: make(map[[2]string]*RuleCompliance),
+	}
+}
+
+// Observe adds the violations of the region to the report.
+func (r *ComplianceReport) Observe(region *core.RegionInfo, fit *RegionFit) {
+	r.RegionCount++
+	// the regions without rules are not checked.
+	if fit == nil || len(fit.RuleFits) == 0 {
+		return
+	}
+	regionID := region.GetID()
+	compliant := len(fit.OrphanPeers) == 0
+	for _, rf := range fit.RuleFits {
+		// the isolation level is not checked by RuleFit.IsSatisfied.
+		for _, v := range rf.violations(fit) {
+			compliant = false
+			r.ruleCompliance(rf.Rule).add(v, regionID)
+		}
+	}
+	if len(fit.OrphanPeers) > 0 {
+		r.OrphanPeers.add(regionID)
+	}
+	if !compliant {
+		r.NonCompliant.add(regionID)
+	}
+}
+
+func (r *ComplianceReport) ruleCompliance(rule *Rule) *RuleCompliance {
+	rc, ok := r.rules[rule.Key()]
+	if !ok {
+		rc = &RuleCompliance{
+			GroupID:    rule.GroupID,
+			ID:         rule.ID,
+			Violations: make(map[ViolationType]*ComplianceStats),
+		}
+		r.rules[rule.Key()] = rc
+		r.Rules = append(r.Rules, rc)
+		sort.Slice(r.Rules, func(i, j int) bool {
+			return r.Rules[i].GroupID < r.Rules[j].GroupID ||
+				(r.Rules[i].GroupID == r.Rules[j].GroupID && r.Rules[i].ID < r.Rules[j].ID)
+		})
+	}
+	return rc
+}
+
+func (rc *RuleCompliance) add(v ViolationType, regionID uint64) {
+	stats, ok := rc.Violations[v]
+	if !ok {
+		stats = &ComplianceStats{}
+		rc.Violations[v] = stats
+	}
+	stats.add(regionID)
+}
+
+// violations returns the violation types of the rule.
+func (f *RuleFit) violations(fit *RegionFit) []ViolationType {
+	var vs []ViolationType
+	if len(f.Peers) < f.Rule.Count {
+		if len(fit.OrphanPeers) > 0 {
+			vs = append(vs, ViolationWrongLabel)
+		} else {
+			vs = append(vs, ViolationMissPeer)
+		}
+	}
+	if len(f.PeersWithDifferentRole) > 0 {
+		vs = append(vs, ViolationWrongRole)
+	}
+	if !f.isIsolated(fit.regionStores) {
+		vs = append(vs, ViolationIsolationLevel)
+	}
+	return vs
+}
+
+// isIsolated checks if any two peers of the rule are placed in different locations
+// at the isolation level.
+func (f *RuleFit) isIsolated(stores []*core.StoreInfo) bool {
+	if f.Rule.IsolationLevel == "" {
+		return true
+	}
+	level := -1
+	for i, l := range f.Rule.LocationLabels {
+		if l == f.Rule.IsolationLevel {
+			level = i
+			break
+		}
+	}
+	if level < 0 {
+		return true
+	}
+	labels := f.Rule.LocationLabels[:level+1]
+	locations := make(map[string]struct{}, len(f.Peers))
+	for _, p := range f.Peers {
+		location := peerLocation(stores, p, labels)
+		if _, ok := locations[location]; ok {
+			return false
+		}
+		locations[location] = struct{}{}
+	}
+	return true
+}
+
+func peerLocation(stores []*core.StoreInfo, peer *metapb.Peer, labels []string) string {
+	store := getStoreByID(stores, peer.GetStoreId())
+	if store == nil {
+		return ""
+	}
+	var location string
+	for _, l := range labels {
+		location += "/" + store.GetLabelValue(l)
+	}
+	return location
+}
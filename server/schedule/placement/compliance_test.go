@@ -0,0 +1,80 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComplianceReport(t *testing.T) {
+	re := require.New(t)
+	stores := makeStores()
+	voter := &Rule{GroupID: "pd", ID: "default", Role: Voter, Count: 3, LocationLabels: []string{"zone", "rack", "host"}, IsolationLevel: "zone"}
+	learner := &Rule{GroupID: "tiflash", ID: "learner", Role: Learner, Count: 1, LabelConstraints: []LabelConstraint{{Key: "engine", Op: In, Values: []string{"tiflash"}}}}
+
+	testCases := []struct {
+		region     string
+		violations map[string][]ViolationType
+		orphan     bool
+	}{
+		{"1111_leader,2111,3111,1115_learner", nil, false},
+		// the voters are not isolated at the zone level.
+		{"1111_leader,1211,3111,1115_learner", map[string][]ViolationType{"default": {ViolationIsolationLevel}}, false},
+		{"1111_leader,2111,1115_learner", map[string][]ViolationType{"default": {ViolationMissPeer}}, false},
+		// the learner is placed on a store without the tiflash label.
+		{"1111_leader,2111,3111,4111_learner", map[string][]ViolationType{"learner": {ViolationWrongLabel}}, true},
+		{"1111_leader,2111,3111,4111,1115_learner", nil, true},
+		{"1111_leader,2111,3111_learner,1115_learner", map[string][]ViolationType{"default": {ViolationWrongRole}}, false},
+	}
+	report := NewComplianceReport()
+	for i, tc := range testCases {
+		region := makeRegion(tc.region)
+		fit := fitRegion(stores.GetStores(), region, []*Rule{voter, learner})
+		fit.regionStores = stores.GetStores()
+		single := NewComplianceReport()
+		single.Observe(region, fit)
+		report.Observe(region, fit)
+
+		re.Equal(len(tc.violations) > 0 || tc.orphan, single.NonCompliant.Count == 1, i)
+		re.Equal(tc.orphan, single.OrphanPeers.Count == 1, i)
+		re.Len(single.Rules, len(tc.violations), i)
+		for _, rc := range single.Rules {
+			re.Len(rc.Violations, len(tc.violations[rc.ID]), i)
+			for _, v := range tc.violations[rc.ID] {
+				re.Equal([]uint64{region.GetID()}, rc.Violations[v].Samples, i)
+			}
+		}
+	}
+
+	re.Equal(len(testCases), report.RegionCount)
+	re.Equal(5, report.NonCompliant.Count)
+	re.Equal(2, report.OrphanPeers.Count)
+	re.Len(report.Rules, 2)
+	re.Equal("default", report.Rules[0].ID)
+	re.Equal("learner", report.Rules[1].ID)
+	re.Len(report.Rules[0].Violations, 3)
+
+	// the samples are limited.
+	report = NewComplianceReport()
+	region := makeRegion("1111_leader,2111")
+	fit := fitRegion(stores.GetStores(), region, []*Rule{voter})
+	for i := 0; i < maxComplianceSamples*2; i++ {
+		report.Observe(region, fit)
+	}
+	re.Equal(maxComplianceSamples*2, report.Rules[0].Violations[ViolationMissPeer].Count)
+	re.Len(report.Rules[0].Violations[ViolationMissPeer].Samples, maxComplianceSamples)
+}
@@ -79,6 +79,20 @@ func (c *ruleConfig) getGroup(id string) *RuleGroup {
 	return &RuleGroup{ID: id}
 }
 
+// clone returns a copy of the configuration, whose rules and groups can be
+// adjusted without affecting the original ones.
+func (c *ruleConfig) clone() *ruleConfig {
+	cfg := newRuleConfig()
+	for key, r := range c.rules {
+		cfg.rules[key] = r.Clone()
+	}
+	for id, g := range c.groups {
+		group := *g
+		cfg.groups[id] = &group
+	}
+	return cfg
+}
+
 func (c *ruleConfig) beginPatch() *ruleConfigPatch {
 	return &ruleConfigPatch{
 		c:   c,
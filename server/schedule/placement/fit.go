@@ -57,7 +57,11 @@ func (f *RegionFit) IsCached() bool {
 func (f *RegionFit) Replace(srcStoreID uint64, dstStore *core.StoreInfo, region *core.RegionInfo) bool {
 	fit := f.getRuleFitByStoreID(srcStoreID)
 	// check the target store is fit all constraints.
-	if fit == nil || !MatchLabelConstraints(dstStore, fit.Rule.LabelConstraints) {
+	if fit == nil {
+		return false
+	}
+	leader := getStoreByID(f.regionStores, region.GetLeader().GetStoreId())
+	if !MatchLabelConstraints(dstStore, ResolveLabelConstraints(fit.Rule.LabelConstraints, leader)) {
 		return false
 	}
 
@@ -181,6 +185,7 @@ type fitWorker struct {
 	bestFit       RegionFit  // update during execution
 	peers         []*fitPeer // p.selected is updated during execution.
 	rules         []*Rule
+	constraints   [][]LabelConstraint // the resolved label constraints of the rules.
 	needIsolation bool
 	exit          bool
 }
@@ -218,12 +223,18 @@ func newFitWorker(stores []*core.StoreInfo, region *core.RegionInfo, rules []*Ru
 		si, sj := stateScore(region, peers[i].GetId()), stateScore(region, peers[j].GetId())
 		return si > sj || (si == sj && peers[i].GetId() < peers[j].GetId())
 	})
+	leader := getStoreByID(stores, region.GetLeader().GetStoreId())
+	constraints := make([][]LabelConstraint, len(rules))
+	for i, rule := range rules {
+		constraints[i] = ResolveLabelConstraints(rule.LabelConstraints, leader)
+	}
 	return &fitWorker{
 		stores:        stores,
 		bestFit:       RegionFit{RuleFits: make([]*RuleFit, len(rules))},
 		peers:         peers,
 		needIsolation: needIsolation(rules),
 		rules:         rules,
+		constraints:   constraints,
 	}
 }
 
@@ -249,13 +260,13 @@ func (w *fitWorker) fitRule(index int) bool {
 	}
 
 	var candidates []*fitPeer
-	if checkRule(w.rules[index], w.stores) {
+	if checkRule(w.constraints[index], w.stores) {
 		// Only consider stores:
 		// 1. Match label constraints
 		// 2. Role match, or can match after transformed.
 		// 3. Not selected by other rules.
 		for _, p := range w.peers {
-			if !p.selected && MatchLabelConstraints(p.store, w.rules[index].LabelConstraints) {
+			if !p.selected && MatchLabelConstraints(p.store, w.constraints[index]) {
 				candidates = append(candidates, p)
 			}
 		}
@@ -262,3 +262,28 @@ func TestPickPeersFromBinaryInt(t *testing.T) {
 		}
 	}
 }
+
+func TestFitRegionWithLeaderReference(t *testing.T) {
+	re := require.New(t)
+	stores := makeStores()
+	rules := []*Rule{
+		{GroupID: "pd", ID: "local", Role: Voter, Count: 2, LabelConstraints: []LabelConstraint{{Key: "zone", Op: SameAs, Values: []string{LeaderReference}}}},
+		{GroupID: "pd", ID: "remote", Role: Voter, Count: 1, LabelConstraints: []LabelConstraint{{Key: "zone", Op: NotSameAs, Values: []string{LeaderReference}}}},
+	}
+	region := makeRegion("1111_leader,1211,2111")
+	rf := fitRegion(stores.GetStores(), region, rules)
+	re.True(rf.IsSatisfied())
+	re.True(checkPeerMatch(rf.RuleFits[0].Peers, "1111,1211"))
+	re.True(checkPeerMatch(rf.RuleFits[1].Peers, "2111"))
+
+	// the constraints follow the leader.
+	region = makeRegion("1111,1211,2111_leader")
+	rf = fitRegion(stores.GetStores(), region, rules)
+	re.False(rf.IsSatisfied())
+	re.True(checkPeerMatch(rf.RuleFits[0].Peers, "2111"))
+	re.True(checkPeerMatch(rf.RuleFits[1].Peers, "1111"))
+
+	rf.regionStores = stores.GetStores()
+	re.True(rf.Replace(1111, stores.GetStore(3111), region))
+	re.False(rf.Replace(1111, stores.GetStore(2112), region))
+}
@@ -15,14 +15,18 @@
 package placement
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/tikv/pd/pkg/slice"
 	"github.com/tikv/pd/server/core"
 )
 
 // LabelConstraintOp defines how a LabelConstraint matches a store. It can be one of
-// 'in', 'notIn', 'exists', or 'notExists'.
+// 'in', 'notIn', 'exists', 'notExists', 'regex', 'gt', 'lt', 'sameAs' or 'notSameAs'.
 type LabelConstraintOp string
 
 const (
@@ -36,10 +40,77 @@ const (
 	Exists LabelConstraintOp = "exists"
 	// NotExists restricts the store should not have the label.
 	NotExists LabelConstraintOp = "notExists"
+	// Regex restricts the store label value should fully match one of the regular expressions.
+	// If label does not exist, `regex` is always false.
+	Regex LabelConstraintOp = "regex"
+	// Gt restricts the store label value should be a number greater than the value.
+	// If label does not exist or is not a number, `gt` is always false.
+	Gt LabelConstraintOp = "gt"
+	// Lt restricts the store label value should be a number less than the value.
+	// If label does not exist or is not a number, `lt` is always false.
+	Lt LabelConstraintOp = "lt"
+	// SameAs restricts the store label value should be the same as the one of
+	// the leader store of the region. If the leader store does not have the
+	// label, `sameAs` is always false.
+	SameAs LabelConstraintOp = "sameAs"
+	// NotSameAs restricts the store label value should not be the same as the
+	// one of the leader store of the region.
+	NotSameAs LabelConstraintOp = "notSameAs"
 )
 
+// LeaderReference is the only value of `sameAs` and `notSameAs` constraints,
+// which refers to the leader store of the region.
+const LeaderReference = "leader"
+
 func validateOp(op LabelConstraintOp) bool {
-	return op == In || op == NotIn || op == Exists || op == NotExists
+	return op == In || op == NotIn || op == Exists || op == NotExists ||
+		op == Regex || op == Gt || op == Lt || op == SameAs || op == NotSameAs
+}
+
+// validateLabelConstraint checks the op and the values of the constraint.
+func validateLabelConstraint(c LabelConstraint) error {
+	if !validateOp(c.Op) {
+		return fmt.Errorf("invalid op %s", c.Op)
+	}
+	switch c.Op {
+	case Regex:
+		if len(c.Values) == 0 {
+			return fmt.Errorf("op %s requires at least one value", c.Op)
+		}
+		for _, v := range c.Values {
+			if _, err := getLabelRegexp(v); err != nil {
+				return fmt.Errorf("invalid regular expression %s: %v", v, err)
+			}
+		}
+	case Gt, Lt:
+		if len(c.Values) != 1 {
+			return fmt.Errorf("op %s requires exactly one value", c.Op)
+		}
+		if _, err := strconv.ParseFloat(c.Values[0], 64); err != nil {
+			return fmt.Errorf("op %s requires a number, but got %s", c.Op, c.Values[0])
+		}
+	case SameAs, NotSameAs:
+		if len(c.Values) != 1 || c.Values[0] != LeaderReference {
+			return fmt.Errorf("op %s requires exactly one value %s", c.Op, LeaderReference)
+		}
+	}
+	return nil
+}
+
+// labelRegexps caches the compiled regular expressions of the `regex` constraints.
+var labelRegexps sync.Map
+
+func getLabelRegexp(expr string) (*regexp.Regexp, error) {
+	if re, ok := labelRegexps.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	// the label value should match the whole expression.
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, err
+	}
+	labelRegexps.Store(expr, re)
+	return re, nil
 }
 
 // LabelConstraint is used to filter store when trying to place peer of a region.
@@ -62,10 +133,67 @@ func (c *LabelConstraint) MatchStore(store *core.StoreInfo) bool {
 		return store.GetLabelValue(c.Key) != ""
 	case NotExists:
 		return store.GetLabelValue(c.Key) == ""
+	case Regex:
+		label := store.GetLabelValue(c.Key)
+		return label != "" && slice.AnyOf(c.Values, func(i int) bool {
+			re, err := getLabelRegexp(c.Values[i])
+			return err == nil && re.MatchString(label)
+		})
+	case Gt, Lt:
+		if len(c.Values) != 1 {
+			return false
+		}
+		label, err := strconv.ParseFloat(store.GetLabelValue(c.Key), 64)
+		if err != nil {
+			return false
+		}
+		value, err := strconv.ParseFloat(c.Values[0], 64)
+		if err != nil {
+			return false
+		}
+		if c.Op == Gt {
+			return label > value
+		}
+		return label < value
 	}
+	// `sameAs` and `notSameAs` never match before being resolved.
 	return false
 }
 
+func (c *LabelConstraint) isReference() bool {
+	return c.Op == SameAs || c.Op == NotSameAs
+}
+
+// ResolveLabelConstraints returns the constraints for placing the peers of a region, in which
+// the `sameAs` and `notSameAs` constraints are replaced by the `in` and `notIn` constraints on
+// the label value of the leader store. If the leader store is nil, the `sameAs` and `notSameAs`
+// constraints are ignored.
+func ResolveLabelConstraints(constraints []LabelConstraint, leader *core.StoreInfo) []LabelConstraint {
+	if slice.NoneOf(constraints, func(i int) bool { return constraints[i].isReference() }) {
+		return constraints
+	}
+	resolved := make([]LabelConstraint, 0, len(constraints))
+	for _, c := range constraints {
+		if !c.isReference() {
+			resolved = append(resolved, c)
+			continue
+		}
+		if leader == nil {
+			continue
+		}
+		var values []string
+		if label := leader.GetLabelValue(c.Key); label != "" {
+			values = append(values, label)
+		}
+		op := In
+		if c.Op == NotSameAs {
+			op = NotIn
+		}
+		resolved = append(resolved, LabelConstraint{Key: c.Key, Op: op, Values: values})
+	}
+	return resolved
+}
+
 // For backward compatibility. Need to remove later.
 var legacyExclusiveLabels = []string{core.EngineKey, "exclusive"}
 
@@ -96,3 +96,73 @@ func TestLabelConstraints(t *testing.T) {
 		re.Equal(expect[i], matched)
 	}
 }
+
+func TestExtendedLabelConstraint(t *testing.T) {
+	re := require.New(t)
+	stores := []map[string]string{
+		{"zone": "us-east-1a", "gen": "3"}, // 1
+		{"zone": "us-east-1b", "gen": "5"}, // 2
+		{"zone": "us-west-1a", "gen": "x"}, // 3
+		{"zone": "eu-1a"},                  // 4
+	}
+	constraints := []LabelConstraint{
+		{Key: "zone", Op: "regex", Values: []string{"us-east-.*"}},
+		{Key: "zone", Op: "regex", Values: []string{"us-.*-1a", "eu-.*"}},
+		{Key: "zone", Op: "regex", Values: []string{"east"}},
+		{Key: "gen", Op: "gt", Values: []string{"3"}},
+		{Key: "gen", Op: "lt", Values: []string{"4.5"}},
+		{Key: "zone", Op: "sameAs", Values: []string{"leader"}},
+	}
+	expect := [][]int{
+		{1, 2},
+		{1, 3, 4},
+		nil,
+		{2},
+		{1},
+		nil,
+	}
+	for i, constraint := range constraints {
+		var matched []int
+		for j, store := range stores {
+			if constraint.MatchStore(core.NewStoreInfoWithLabel(uint64(j), 0, store)) {
+				matched = append(matched, j+1)
+			}
+		}
+		re.Equal(expect[i], matched)
+	}
+
+	// resolve the constraints referencing the leader.
+	leader := core.NewStoreInfoWithLabel(1, 0, stores[0])
+	cs := []LabelConstraint{
+		{Key: "gen", Op: "exists"},
+		{Key: "zone", Op: "notSameAs", Values: []string{"leader"}},
+	}
+	re.Equal([]LabelConstraint{cs[0], {Key: "zone", Op: "notIn", Values: []string{"us-east-1a"}}}, ResolveLabelConstraints(cs, leader))
+	re.Equal(cs[:1], ResolveLabelConstraints(cs, nil))
+	re.Equal(cs[:1], ResolveLabelConstraints(cs[:1], leader))
+	cs = []LabelConstraint{{Key: "rack", Op: "sameAs", Values: []string{"leader"}}}
+	re.False(MatchLabelConstraints(leader, ResolveLabelConstraints(cs, leader)))
+}
+
+func TestValidateLabelConstraint(t *testing.T) {
+	re := require.New(t)
+	testCases := []struct {
+		constraint LabelConstraint
+		ok         bool
+	}{
+		{LabelConstraint{Key: "zone", Op: "in", Values: []string{"z1"}}, true},
+		{LabelConstraint{Key: "zone", Op: "unknown"}, false},
+		{LabelConstraint{Key: "zone", Op: "regex", Values: []string{"z[1-3]"}}, true},
+		{LabelConstraint{Key: "zone", Op: "regex"}, false},
+		{LabelConstraint{Key: "zone", Op: "regex", Values: []string{"z[1-3"}}, false},
+		{LabelConstraint{Key: "gen", Op: "gt", Values: []string{"1.5"}}, true},
+		{LabelConstraint{Key: "gen", Op: "lt", Values: []string{"a"}}, false},
+		{LabelConstraint{Key: "gen", Op: "lt", Values: []string{"1", "2"}}, false},
+		{LabelConstraint{Key: "zone", Op: "sameAs", Values: []string{"leader"}}, true},
+		{LabelConstraint{Key: "zone", Op: "notSameAs", Values: []string{"store1"}}, false},
+		{LabelConstraint{Key: "zone", Op: "sameAs"}, false},
+	}
+	for _, tc := range testCases {
+		re.Equal(tc.ok, validateLabelConstraint(tc.constraint) == nil, tc.constraint)
+	}
+}
@@ -53,6 +53,13 @@ func (manager *RegionRuleFitCacheManager) Invalid(regionID uint64) {
 	delete(manager.caches, regionID)
 }
 
+// InvalidAll invalid the caches of all regions
+func (manager *RegionRuleFitCacheManager) InvalidAll() {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	manager.caches = make(map[uint64]*RegionRuleFitCache)
+}
+
 // CheckAndGetCache checks whether the region and rules are changed for the stored cache
 // If the check pass, it will return the cache
 func (manager *RegionRuleFitCacheManager) CheckAndGetCache(region *core.RegionInfo,
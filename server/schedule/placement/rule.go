@@ -62,6 +62,7 @@ type Rule struct {
 	StartKeyHex      string            `json:"start_key"`                   // hex format start key, for marshal/unmarshal
 	EndKey           []byte            `json:"-"`                           // range end key
 	EndKeyHex        string            `json:"end_key"`                     // hex format end key, for marshal/unmarshal
+	RegionLabel      *RegionLabel      `json:"region_label,omitempty"`      // used to select the key ranges by region labels instead of start and end keys
 	Role             PeerRoleType      `json:"role"`                        // expected role of the peers
 	IsWitness        bool              `json:"is_witness"`                  // when it is true, it means the role is also a witness
 	Count            int               `json:"count"`                       // expected count of the peers
@@ -104,6 +105,14 @@ func (r *Rule) groupIndex() int {
 	return 0
 }
 
+// RegionLabel is a label of regions assigned by the region label rules. The rule
+// with a RegionLabel applies to the key ranges of the regions with the label, which
+// are resolved dynamically when the region label rules are changed.
+type RegionLabel struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
 // RuleGroup defines properties of a rule group.
 // NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
 type RuleGroup struct {
@@ -21,6 +21,7 @@ import (
 
 	"github.com/pingcap/errors"
 	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/schedule/rangelist"
 )
 
@@ -62,15 +63,31 @@ type ruleContainer interface {
 	iterateRules(func(*Rule))
 }
 
+// LabelRangeResolver resolves the key ranges of the regions with a label.
+type LabelRangeResolver interface {
+	GetLabelKeyRanges(key, value string) []core.KeyRange
+}
+
 // buildRuleList builds the applied ruleList for the give rules
 // rules indicates the map (rule's GroupID, ID) => rule
-func buildRuleList(rules ruleContainer) (ruleList, error) {
+// resolver is used to resolve the key ranges of the rules with region label, and
+// these rules are ignored if it is nil.
+func buildRuleList(rules ruleContainer, resolver LabelRangeResolver) (ruleList, error) {
 	builder := rangelist.NewBuilder()
 	builder.SetCompareFunc(func(a, b interface{}) int {
 		return compareRule(a.(*Rule), b.(*Rule))
 	})
 	rules.iterateRules(func(r *Rule) {
-		builder.AddItem(r.StartKey, r.EndKey, r)
+		if r.RegionLabel == nil {
+			builder.AddItem(r.StartKey, r.EndKey, r)
+			return
+		}
+		if resolver == nil {
+			return
+		}
+		for _, kr := range resolver.GetLabelKeyRanges(r.RegionLabel.Key, r.RegionLabel.Value) {
+			builder.AddItem(kr.StartKey, kr.EndKey, r)
+		}
 	})
 	rangeList := builder.Build()
 
@@ -49,6 +49,15 @@ type RuleManager struct {
 	storeSetInformer core.StoreSetInformer
 	cache            *RegionRuleFitCacheManager
 	opt              *config.PersistOptions
+	// labelResolver resolves the key ranges of the rules with region label.
+	labelResolver LabelRangeResolver
+
+	// versions are the reserved versions of the rules, sorted by version.
+	versions []*ruleVersionRecord
+	// versionSource is the source of the ongoing change, and sourceMu
+	// serializes the changes with different sources.
+	versionSource string
+	sourceMu      syncutil.Mutex
 }
 
 // NewRuleManager creates a RuleManager instance.
@@ -77,6 +86,12 @@ func (m *RuleManager) Initialize(maxReplica int, locationLabels []string) error
 	if err := m.loadGroups(); err != nil {
 		return err
 	}
+	if err := m.loadVersions(); err != nil {
+		return err
+	}
+	if err := m.completePendingVersion(); err != nil {
+		return err
+	}
 	if len(m.ruleConfig.rules) == 0 {
 		// migrate from old config.
 		defaultRule := &Rule{
@@ -92,11 +107,17 @@ func (m *RuleManager) Initialize(maxReplica int, locationLabels []string) error
 		m.ruleConfig.setRule(defaultRule)
 	}
 	m.ruleConfig.adjust()
-	ruleList, err := buildRuleList(m.ruleConfig)
+	ruleList, err := buildRuleList(m.ruleConfig, m.labelResolver)
 	if err != nil {
 		return err
 	}
 	m.ruleList = ruleList
+	if len(m.versions) == 0 {
+		// record the initial rules, so that it can be rolled back to.
+		if err := m.saveInitialVersion(); err != nil {
+			return err
+		}
+	}
 	m.initialized = true
 	return nil
 }
@@ -168,6 +189,14 @@ func (m *RuleManager) adjustRule(r *Rule, groupID string) (err error) {
 	if len(r.EndKey) > 0 && bytes.Compare(r.EndKey, r.StartKey) <= 0 {
 		return errs.ErrRuleContent.FastGenByArgs("endKey should be greater than startKey")
 	}
+	if r.RegionLabel != nil {
+		if r.RegionLabel.Key == "" || r.RegionLabel.Value == "" {
+			return errs.ErrRuleContent.FastGenByArgs("region label key and value should not be empty")
+		}
+		if len(r.StartKey) > 0 || len(r.EndKey) > 0 {
+			return errs.ErrRuleContent.FastGenByArgs("startKey and endKey should be empty when region label is specified")
+		}
+	}
 
 	if m.keyType == core.Table.String() || m.keyType == core.Txn.String() {
 		if len(r.StartKey) > 0 {
@@ -205,14 +234,15 @@ func (m *RuleManager) adjustRule(r *Rule, groupID string) (err error) {
 		return errs.ErrRuleContent.FastGenByArgs(fmt.Sprintf("define multiple leaders by count %d", r.Count))
 	}
 	for _, c := range r.LabelConstraints {
-		if !validateOp(c.Op) {
-			return errs.ErrRuleContent.FastGenByArgs(fmt.Sprintf("invalid op %s", c.Op))
+		if err := validateLabelConstraint(c); err != nil {
+			return errs.ErrRuleContent.FastGenByArgs(err.Error())
 		}
 	}
 
 	if m.storeSetInformer != nil {
 		stores := m.storeSetInformer.GetStores()
-		if len(stores) > 0 && !checkRule(r, stores) {
+		// the constraints referencing the leader are ignored since there is no region.
+		if len(stores) > 0 && !checkRule(ResolveLabelConstraints(r.LabelConstraints, nil), stores) {
 			return errs.ErrRuleContent.FastGenByArgs(fmt.Sprintf("rule '%s' from rule group '%s' can not match any store", r.ID, r.GroupID))
 		}
 	}
@@ -343,6 +373,41 @@ func (m *RuleManager) InvalidCache(regionID uint64) {
 	m.cache.Invalid(regionID)
 }
 
+// SetLabelRangeResolver sets the resolver of the key ranges of the rules with
+// region label, and rebuilds the rules whenever the region label rules are changed.
+func (m *RuleManager) SetLabelRangeResolver(resolver LabelRangeResolver, subscribe func(f func())) {
+	m.Lock()
+	m.labelResolver = resolver
+	m.Unlock()
+	subscribe(m.refreshLabelRanges)
+	m.refreshLabelRanges()
+}
+
+// refreshLabelRanges rebuilds the rule list since the key ranges of the rules
+// with region label may be changed.
+func (m *RuleManager) refreshLabelRanges() {
+	m.Lock()
+	defer m.Unlock()
+	if !m.initialized {
+		return
+	}
+	hasRegionLabel := false
+	m.ruleConfig.iterateRules(func(r *Rule) {
+		hasRegionLabel = hasRegionLabel || r.RegionLabel != nil
+	})
+	if !hasRegionLabel {
+		return
+	}
+	ruleList, err := buildRuleList(m.ruleConfig, m.labelResolver)
+	if err != nil {
+		log.Error("failed to rebuild rules with region labels", errs.ZapError(err))
+		return
+	}
+	m.ruleList = ruleList
+	// the rules applied to the regions may be changed.
+	m.cache.InvalidAll()
+}
+
 func (m *RuleManager) beginPatch() *ruleConfigPatch {
 	return m.ruleConfig.beginPatch()
 }
@@ -350,25 +415,53 @@ func (m *RuleManager) beginPatch() *ruleConfigPatch {
 func (m *RuleManager) tryCommitPatch(patch *ruleConfigPatch) error {
 	patch.adjust()
 
-	ruleList, err := buildRuleList(patch)
+	ruleList, err := buildRuleList(patch, m.labelResolver)
 	if err != nil {
 		return err
 	}
 
 	patch.trim()
 
+	// record the version before saving updates
+	version, err := m.beginVersion(patch)
+	if err != nil {
+		return err
+	}
+
 	// save updates
 	err = m.savePatch(patch.mut)
 	if err != nil {
+		m.abortVersion(patch, version)
 		return err
 	}
 
 	// update in-memory state
 	patch.commit()
 	m.ruleList = ruleList
+
+	if version != nil {
+		m.finishVersion(version)
+	}
 	return nil
 }
 
+// preview applies the patch to a copy of the current configuration, and returns
+// the rules in preview without persisting anything.
+func (m *RuleManager) preview(patch func(p *ruleConfigPatch) error) (*RulePreview, error) {
+	m.RLock()
+	defer m.RUnlock()
+	p := m.ruleConfig.clone().beginPatch()
+	if err := patch(p); err != nil {
+		return nil, err
+	}
+	p.adjust()
+	ruleList, err := buildRuleList(p, m.labelResolver)
+	if err != nil {
+		return nil, err
+	}
+	return &RulePreview{ruleList: ruleList}, nil
+}
+
 func (m *RuleManager) savePatch(p *ruleConfig) error {
 	// TODO: it is not completely safe
 	// 1. in case that half of rules applied, error.. we have to cancel persisted rules
@@ -406,11 +499,8 @@ func (m *RuleManager) SetRules(rules []*Rule) error {
 	m.Lock()
 	defer m.Unlock()
 	p := m.beginPatch()
-	for _, r := range rules {
-		if err := m.adjustRule(r, ""); err != nil {
-			return err
-		}
-		p.setRule(r)
+	if err := m.patchRules(p, rules); err != nil {
+		return err
 	}
 	if err := m.tryCommitPatch(p); err != nil {
 		return err
@@ -420,6 +510,21 @@ func (m *RuleManager) SetRules(rules []*Rule) error {
 	return nil
 }
 
+// PreviewRules returns the rules in preview after inserting or updating the Rules.
+func (m *RuleManager) PreviewRules(rules []*Rule) (*RulePreview, error) {
+	return m.preview(func(p *ruleConfigPatch) error { return m.patchRules(p, rules) })
+}
+
+func (m *RuleManager) patchRules(p *ruleConfigPatch, rules []*Rule) error {
+	for _, r := range rules {
+		if err := m.adjustRule(r, ""); err != nil {
+			return err
+		}
+		p.setRule(r)
+	}
+	return nil
+}
+
 // RuleOpType indicates the operation type
 type RuleOpType string
 
@@ -445,6 +550,35 @@ func (r RuleOp) String() string {
 
 // Batch executes a series of actions at once.
 func (m *RuleManager) Batch(todo []RuleOp) error {
+	if err := m.adjustRuleOps(todo); err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	patch := m.beginPatch()
+	m.patchRuleOps(patch, todo)
+	if err := m.tryCommitPatch(patch); err != nil {
+		return err
+	}
+
+	log.Info("placement rules updated", zap.String("batch", fmt.Sprint(todo)))
+	return nil
+}
+
+// PreviewBatch returns the rules in preview after executing the actions.
+func (m *RuleManager) PreviewBatch(todo []RuleOp) (*RulePreview, error) {
+	if err := m.adjustRuleOps(todo); err != nil {
+		return nil, err
+	}
+	return m.preview(func(p *ruleConfigPatch) error {
+		m.patchRuleOps(p, todo)
+		return nil
+	})
+}
+
+func (m *RuleManager) adjustRuleOps(todo []RuleOp) error {
 	for _, t := range todo {
 		if t.Action == RuleOpAdd {
 			err := m.adjustRule(t.Rule, "")
@@ -453,11 +587,10 @@ func (m *RuleManager) Batch(todo []RuleOp) error {
 			}
 		}
 	}
+	return nil
+}
 
-	m.Lock()
-	defer m.Unlock()
-
-	patch := m.beginPatch()
+func (m *RuleManager) patchRuleOps(patch *ruleConfigPatch, todo []RuleOp) {
 	for _, t := range todo {
 		switch t.Action {
 		case RuleOpAdd:
@@ -466,7 +599,7 @@ func (m *RuleManager) Batch(todo []RuleOp) error {
 			if !t.DeleteByIDPrefix {
 				patch.deleteRule(t.GroupID, t.ID)
 			} else {
-				m.ruleConfig.iterateRules(func(r *Rule) {
+				patch.c.iterateRules(func(r *Rule) {
 					if r.GroupID == t.GroupID && strings.HasPrefix(r.ID, t.ID) {
 						patch.deleteRule(r.GroupID, r.ID)
 					}
@@ -474,13 +607,6 @@ func (m *RuleManager) Batch(todo []RuleOp) error {
 			}
 		}
 	}
-
-	if err := m.tryCommitPatch(patch); err != nil {
-		return err
-	}
-
-	log.Info("placement rules updated", zap.String("batch", fmt.Sprint(todo)))
-	return nil
 }
 
 // GetRuleGroup returns a RuleGroup configuration.
@@ -583,6 +709,22 @@ func (m *RuleManager) SetAllGroupBundles(groups []GroupBundle, override bool) er
 	m.Lock()
 	defer m.Unlock()
 	p := m.beginPatch()
+	if err := m.patchAllGroupBundles(p, groups, override); err != nil {
+		return err
+	}
+	if err := m.tryCommitPatch(p); err != nil {
+		return err
+	}
+	log.Info("full config reset", zap.String("config", fmt.Sprint(groups)))
+	return nil
+}
+
+// PreviewAllGroupBundles returns the rules in preview after resetting configuration.
+func (m *RuleManager) PreviewAllGroupBundles(groups []GroupBundle, override bool) (*RulePreview, error) {
+	return m.preview(func(p *ruleConfigPatch) error { return m.patchAllGroupBundles(p, groups, override) })
+}
+
+func (m *RuleManager) patchAllGroupBundles(p *ruleConfigPatch, groups []GroupBundle, override bool) error {
 	matchID := func(a string) bool {
 		for _, g := range groups {
 			if g.ID == a {
@@ -591,12 +733,12 @@ func (m *RuleManager) SetAllGroupBundles(groups []GroupBundle, override bool) er
 		}
 		return false
 	}
-	for k := range m.ruleConfig.rules {
+	for k := range p.c.rules {
 		if override || matchID(k[0]) {
 			p.deleteRule(k[0], k[1])
 		}
 	}
-	for id := range m.ruleConfig.groups {
+	for id := range p.c.groups {
 		if override || matchID(id) {
 			p.deleteGroup(id)
 		}
@@ -614,10 +756,6 @@ func (m *RuleManager) SetAllGroupBundles(groups []GroupBundle, override bool) er
 			p.setRule(r)
 		}
 	}
-	if err := m.tryCommitPatch(p); err != nil {
-		return err
-	}
-	log.Info("full config reset", zap.String("config", fmt.Sprint(groups)))
 	return nil
 }
 
@@ -627,8 +765,24 @@ func (m *RuleManager) SetGroupBundle(group GroupBundle) error {
 	m.Lock()
 	defer m.Unlock()
 	p := m.beginPatch()
-	if _, ok := m.ruleConfig.groups[group.ID]; ok {
-		for k := range m.ruleConfig.rules {
+	if err := m.patchGroupBundle(p, group); err != nil {
+		return err
+	}
+	if err := m.tryCommitPatch(p); err != nil {
+		return err
+	}
+	log.Info("group is reset", zap.String("group", fmt.Sprint(group)))
+	return nil
+}
+
+// PreviewGroupBundle returns the rules in preview after resetting a Group.
+func (m *RuleManager) PreviewGroupBundle(group GroupBundle) (*RulePreview, error) {
+	return m.preview(func(p *ruleConfigPatch) error { return m.patchGroupBundle(p, group) })
+}
+
+func (m *RuleManager) patchGroupBundle(p *ruleConfigPatch, group GroupBundle) error {
+	if _, ok := p.c.groups[group.ID]; ok {
+		for k := range p.c.rules {
 			if k[0] == group.ID {
 				p.deleteRule(k[0], k[1])
 			}
@@ -645,10 +799,6 @@ func (m *RuleManager) SetGroupBundle(group GroupBundle) error {
 		}
 		p.setRule(r)
 	}
-	if err := m.tryCommitPatch(p); err != nil {
-		return err
-	}
-	log.Info("group is reset", zap.String("group", fmt.Sprint(group)))
 	return nil
 }
 
@@ -693,9 +843,9 @@ func (m *RuleManager) IsInitialized() bool {
 
 // checkRule check the rule whether will have RuleFit after FitRegion
 // in order to reduce the calculation.
-func checkRule(rule *Rule, stores []*core.StoreInfo) bool {
+func checkRule(constraints []LabelConstraint, stores []*core.StoreInfo) bool {
 	return slice.AnyOf(stores, func(idx int) bool {
-		return MatchLabelConstraints(stores[idx], rule.LabelConstraints)
+		return MatchLabelConstraints(stores[idx], constraints)
 	})
 }
 
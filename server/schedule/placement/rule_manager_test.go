@@ -64,6 +64,8 @@ func TestAdjustRule(t *testing.T) {
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 0},
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: -1},
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 3, LabelConstraints: []LabelConstraint{{Op: "foo"}}},
+		{GroupID: "group", ID: "id", Role: "voter", Count: 3, RegionLabel: &RegionLabel{Key: "tier"}},
+		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 3, RegionLabel: &RegionLabel{Key: "tier", Value: "hot"}},
 	}
 	re.NoError(manager.adjustRule(&rules[0], "group"))
 
@@ -347,6 +349,51 @@ func TestRuleVersion(t *testing.T) {
 	re.Equal(uint64(0), newRule.Version)
 }
 
+type mockLabelRangeResolver struct {
+	ranges   map[RegionLabel][]core.KeyRange
+	onChange func()
+}
+
+func (r *mockLabelRangeResolver) GetLabelKeyRanges(key, value string) []core.KeyRange {
+	return r.ranges[RegionLabel{Key: key, Value: value}]
+}
+
+func (r *mockLabelRangeResolver) subscribe(f func()) {
+	r.onChange = f
+}
+
+func TestRegionLabelRule(t *testing.T) {
+	re := require.New(t)
+	_, manager := newTestManager(t)
+	hot := RegionLabel{Key: "tier", Value: "hot"}
+	resolver := &mockLabelRangeResolver{ranges: map[RegionLabel][]core.KeyRange{
+		hot: {core.NewKeyRange("a", "c"), core.NewKeyRange("e", "g")},
+	}}
+	manager.SetLabelRangeResolver(resolver, resolver.subscribe)
+	re.NotNil(resolver.onChange)
+
+	rule := &Rule{GroupID: "pd", ID: "hot", Index: 1, Role: Voter, Count: 5, RegionLabel: &hot}
+	re.NoError(manager.SetRule(rule))
+	checkRules(t, manager.GetRulesByKey([]byte("b")), [][2]string{{"pd", "default"}, {"pd", "hot"}})
+	checkRules(t, manager.GetRulesByKey([]byte("f")), [][2]string{{"pd", "default"}, {"pd", "hot"}})
+	checkRules(t, manager.GetRulesByKey([]byte("d")), [][2]string{{"pd", "default"}})
+	re.Equal(manager.GetRule("pd", "hot").RegionLabel, &hot)
+
+	// the fit cache is invalidated after the ranges are changed.
+	manager.cache.caches[1] = &RegionRuleFitCache{}
+	resolver.ranges[hot] = []core.KeyRange{core.NewKeyRange("c", "e")}
+	resolver.onChange()
+	re.Empty(manager.cache.caches)
+	checkRules(t, manager.GetRulesByKey([]byte("b")), [][2]string{{"pd", "default"}})
+	checkRules(t, manager.GetRulesByKey([]byte("d")), [][2]string{{"pd", "default"}, {"pd", "hot"}})
+
+	// the rule applies to nothing if no region has the label.
+	delete(resolver.ranges, hot)
+	resolver.onChange()
+	checkRules(t, manager.GetRulesByKey([]byte("d")), [][2]string{{"pd", "default"}})
+	re.NotNil(manager.GetRule("pd", "hot"))
+}
+
 func TestCheckApplyRules(t *testing.T) {
 	re := require.New(t)
 	err := checkApplyRules([]*Rule{
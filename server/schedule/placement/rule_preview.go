@@ -0,0 +1,157 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"github.com/docker/go-units"
+	"github.com/tikv/pd/server/core"
+)
+
+// RulePreview is the placement rules which are not applied yet. It is used to
+// estimate the impact of the rules before applying them.
+type RulePreview struct {
+	ruleList ruleList
+}
+
+// GetRulesForApplyRegion returns the rules list that should be applied to a region.
+func (p *RulePreview) GetRulesForApplyRegion(region *core.RegionInfo) []*Rule {
+	return p.ruleList.getRulesForApplyRange(region.GetStartKey(), region.GetEndKey())
+}
+
+// RuleImpactStats is the count and the total size of the regions or peers affected by rules.
+type RuleImpactStats struct {
+	Count int   `json:"count"`
+	Bytes int64 `json:"bytes"`
+}
+
+func (s *RuleImpactStats) add(bytes int64) {
+	s.Count++
+	s.Bytes += bytes
+}
+
+// StoreRuleImpact is the estimated peers to be added to or removed from a store.
+type StoreRuleImpact struct {
+	AddPeer    RuleImpactStats `json:"add-peer"`
+	RemovePeer RuleImpactStats `json:"remove-peer"`
+}
+
+// RuleImpact is the estimated impact of applying placement rules on the current regions.
+//
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RuleImpact struct {
+	// RegionCount is the count of the checked regions.
+	RegionCount int `json:"region-count"`
+	// AddPeer, RemovePeer and MovePeer are the regions which need to add peers only,
+	// remove peers only, and both add and remove peers respectively.
+	AddPeer    RuleImpactStats `json:"add-peer"`
+	RemovePeer RuleImpactStats `json:"remove-peer"`
+	MovePeer   RuleImpactStats `json:"move-peer"`
+	// ChangeRole is the regions which only need to change the roles of peers.
+	ChangeRole RuleImpactStats `json:"change-role"`
+	// Stores is the peers to be added to or removed from each store. The stores of the
+	// new peers are estimated by picking the matched stores with the fewest regions.
+	Stores map[uint64]*StoreRuleImpact `json:"stores"`
+}
+
+// EstimateImpact fits the regions to the rules in preview, and estimates the peers
+// to be added, removed or moved to satisfy the rules.
+func (p *RulePreview) EstimateImpact(storeSet StoreSet, regions []*core.RegionInfo) *RuleImpact {
+	impact := &RuleImpact{
+		RegionCount: len(regions),
+		Stores:      make(map[uint64]*StoreRuleImpact),
+	}
+	storeImpact := func(storeID uint64) *StoreRuleImpact {
+		s, ok := impact.Stores[storeID]
+		if !ok {
+			s = &StoreRuleImpact{}
+			impact.Stores[storeID] = s
+		}
+		return s
+	}
+	stores := storeSet.GetStores()
+	// addedPeers records the estimated new peers of each store to spread them.
+	addedPeers := make(map[uint64]int)
+	for _, region := range regions {
+		rules := p.GetRulesForApplyRegion(region)
+		if len(rules) == 0 {
+			continue
+		}
+		regionStores := getStoresByRegion(storeSet, region)
+		fit := fitRegion(regionStores, region, rules)
+		if fit.IsSatisfied() {
+			continue
+		}
+		bytes := region.GetApproximateSize() * units.MiB
+
+		used := make(map[uint64]struct{}, len(regionStores))
+		for _, store := range regionStores {
+			used[store.GetID()] = struct{}{}
+		}
+		leader := storeSet.GetStore(region.GetLeader().GetStoreId())
+		var adds, changeRoles int
+		for _, rf := range fit.RuleFits {
+			changeRoles += len(rf.PeersWithDifferentRole)
+			constraints := ResolveLabelConstraints(rf.Rule.LabelConstraints, leader)
+			for i := len(rf.Peers); i < rf.Rule.Count; i++ {
+				adds++
+				target := pickPreviewTarget(stores, constraints, used, addedPeers)
+				if target == 0 {
+					continue
+				}
+				used[target] = struct{}{}
+				addedPeers[target]++
+				storeImpact(target).AddPeer.add(bytes)
+			}
+		}
+		for _, peer := range fit.OrphanPeers {
+			storeImpact(peer.GetStoreId()).RemovePeer.add(bytes)
+		}
+
+		switch removes := len(fit.OrphanPeers); {
+		case adds > 0 && removes > 0:
+			impact.MovePeer.add(bytes)
+		case adds > 0:
+			impact.AddPeer.add(bytes)
+		case removes > 0:
+			impact.RemovePeer.add(bytes)
+		case changeRoles > 0:
+			impact.ChangeRole.add(bytes)
+		}
+	}
+	return impact
+}
+
+// pickPreviewTarget picks the store with the fewest regions for a new peer matching the constraints.
+// It returns 0 if there is no store available.
+func pickPreviewTarget(stores []*core.StoreInfo, constraints []LabelConstraint, used map[uint64]struct{}, addedPeers map[uint64]int) uint64 {
+	var target *core.StoreInfo
+	for _, store := range stores {
+		if _, ok := used[store.GetID()]; ok || !store.IsUp() || !MatchLabelConstraints(store, constraints) {
+			continue
+		}
+		if target == nil {
+			target = store
+			continue
+		}
+		count, targetCount := store.GetRegionCount()+addedPeers[store.GetID()], target.GetRegionCount()+addedPeers[target.GetID()]
+		if count < targetCount || (count == targetCount && store.GetID() < target.GetID()) {
+			target = store
+		}
+	}
+	if target == nil {
+		return 0
+	}
+	return target.GetID()
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/docker/go-units"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/server/core"
+)
+
+func TestEstimateRuleImpact(t *testing.T) {
+	re := require.New(t)
+	_, manager := newTestManager(t)
+	stores := core.NewStoresInfo()
+	for id := uint64(1); id <= 5; id++ {
+		labels := map[string]string{"host": fmt.Sprintf("host%d", id)}
+		if id == 5 {
+			labels["engine"] = "tiflash"
+		}
+		stores.SetStore(core.NewStoreInfoWithLabel(id, int(10-id), labels))
+	}
+	regions := []*core.RegionInfo{
+		makeRegion("1_leader,2,3").Clone(core.SetApproximateSize(10)),
+		makeRegion("1_leader,2").Clone(core.SetApproximateSize(20)),
+		makeRegion("1_leader,2,3,4").Clone(core.SetApproximateSize(30)),
+	}
+
+	// The current rules are satisfied except for the region with 2 peers and the one with 4 peers.
+	preview, err := manager.PreviewRules(nil)
+	re.NoError(err)
+	impact := preview.EstimateImpact(stores, regions)
+	re.Equal(3, impact.RegionCount)
+	re.Equal(RuleImpactStats{Count: 1, Bytes: 20 * units.MiB}, impact.AddPeer)
+	re.Equal(RuleImpactStats{Count: 1, Bytes: 30 * units.MiB}, impact.RemovePeer)
+	re.Zero(impact.MovePeer.Count)
+
+	// Add a TiFlash learner to all regions, and keep the voters out of TiFlash.
+	preview, err = manager.PreviewRules([]*Rule{
+		{GroupID: "pd", ID: "default", Role: Voter, Count: 3, LabelConstraints: []LabelConstraint{{Key: "engine", Op: NotIn, Values: []string{"tiflash"}}}},
+		{GroupID: "tiflash", ID: "learner", Role: Learner, Count: 1, LabelConstraints: []LabelConstraint{{Key: "engine", Op: In, Values: []string{"tiflash"}}}},
+	})
+	re.NoError(err)
+	impact = preview.EstimateImpact(stores, regions)
+	re.Equal(RuleImpactStats{Count: 2, Bytes: 30 * units.MiB}, impact.AddPeer)
+	re.Equal(RuleImpactStats{Count: 1, Bytes: 30 * units.MiB}, impact.MovePeer)
+	re.Zero(impact.RemovePeer.Count)
+	re.Equal(RuleImpactStats{Count: 3, Bytes: 60 * units.MiB}, impact.Stores[5].AddPeer)
+	// The new voter is placed on the store with the fewest regions.
+	re.Equal(RuleImpactStats{Count: 1, Bytes: 20 * units.MiB}, impact.Stores[4].AddPeer)
+	re.Equal(RuleImpactStats{Count: 1, Bytes: 30 * units.MiB}, impact.Stores[4].RemovePeer)
+	re.NotContains(impact.Stores, uint64(3))
+
+	// Nothing is persisted by the preview.
+	re.Len(manager.GetAllRules(), 1)
+	re.Equal(3, manager.GetRule("pd", "default").Count)
+	re.Nil(manager.GetRule("tiflash", "learner"))
+
+	// The invalid rules are rejected.
+	_, err = manager.PreviewRules([]*Rule{{GroupID: "pd", ID: "default", Role: Voter, Count: -1}})
+	re.Error(err)
+}
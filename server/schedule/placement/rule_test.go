@@ -181,7 +181,7 @@ func TestBuildRuleList(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Log(testCase.name)
 		config := &ruleConfig{rules: testCase.rules}
-		result, err := buildRuleList(config)
+		result, err := buildRuleList(config, nil)
 		re.NoError(err)
 		re.Equal(testCase.expect.ranges, result.ranges)
 	}
@@ -0,0 +1,476 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server/config"
+	"go.uber.org/zap"
+)
+
+// RuleVersion is a snapshot of all rules and rule groups, which is created
+// every time the rules or rule groups are changed.
+//
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RuleVersion struct {
+	Version uint64    `json:"version"`
+	Time    time.Time `json:"time"`
+	// Source is the source of the change, such as the component and the address of the request.
+	Source string       `json:"source"`
+	Rules  []*Rule      `json:"rules,omitempty"`
+	Groups []*RuleGroup `json:"groups,omitempty"`
+}
+
+// RuleDiff is a rule changed between two versions. Old is nil if the rule is
+// added, and New is nil if the rule is removed.
+type RuleDiff struct {
+	Old *Rule `json:"old,omitempty"`
+	New *Rule `json:"new,omitempty"`
+}
+
+// RuleGroupDiff is a rule group changed between two versions. Old is nil if the
+// group is added, and New is nil if the group is removed.
+type RuleGroupDiff struct {
+	Old *RuleGroup `json:"old,omitempty"`
+	New *RuleGroup `json:"new,omitempty"`
+}
+
+// RuleVersionDiff is the differences between two versions.
+//
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RuleVersionDiff struct {
+	From   uint64          `json:"from"`
+	To     uint64          `json:"to"`
+	Rules  []RuleDiff      `json:"rules"`
+	Groups []RuleGroupDiff `json:"groups"`
+}
+
+// ruleVersionRecord is the persisted form of a version. To keep the storage
+// small, only the oldest reserved version keeps all rules and rule groups, and
+// each later version keeps the changes from its previous version.
+type ruleVersionRecord struct {
+	Version uint64    `json:"version"`
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	// Full means Rules and Groups are all rules and rule groups of the version
+	// rather than the changed ones.
+	Full          bool         `json:"full,omitempty"`
+	Rules         []*Rule      `json:"rules,omitempty"`
+	Groups        []*RuleGroup `json:"groups,omitempty"`
+	DeletedRules  [][2]string  `json:"deleted-rules,omitempty"`
+	DeletedGroups []string     `json:"deleted-groups,omitempty"`
+	// Pending means the version is recorded but the rules and rule groups may not
+	// be saved completely, which is completed on the next load.
+	Pending bool `json:"pending,omitempty"`
+}
+
+func (m *RuleManager) loadVersions() error {
+	return m.storage.LoadRuleVersions(func(k, v string) {
+		var rv ruleVersionRecord
+		if err := json.Unmarshal([]byte(v), &rv); err != nil {
+			log.Error("failed to unmarshal rule version", zap.String("version", k), errs.ZapError(errs.ErrLoadRuleVersion, err))
+			return
+		}
+		m.versions = append(m.versions, &rv)
+	})
+}
+
+// completePendingVersion saves the rules and rule groups of the last version if
+// the change of it was interrupted.
+func (m *RuleManager) completePendingVersion() error {
+	if len(m.versions) == 0 || !m.versions[len(m.versions)-1].Pending {
+		return nil
+	}
+	last := m.versions[len(m.versions)-1]
+	p := m.beginPatch()
+	if err := m.patchVersion(p, m.getVersion(last.Version)); err != nil {
+		return err
+	}
+	p.trim()
+	if err := m.savePatch(p.mut); err != nil {
+		return err
+	}
+	p.commit()
+	last.Pending = false
+	log.Info("interrupted placement rule change completed", zap.Uint64("version", last.Version))
+	return m.storage.SaveRuleVersion(last.Version, last)
+}
+
+func (m *RuleManager) getReservedVersions() uint64 {
+	if m.opt == nil {
+		return config.DefaultPlacementRuleReservedVersions
+	}
+	return m.opt.GetPlacementRuleReservedVersions()
+}
+
+// newRuleVersion returns the version of the rules and rule groups, which are
+// sorted and cleared of the fields maintained at runtime.
+func newRuleVersion(rules map[[2]string]*Rule, groups map[string]*RuleGroup) *RuleVersion {
+	rv := &RuleVersion{}
+	for _, r := range rules {
+		r = r.Clone()
+		// the version and create timestamp are maintained at runtime.
+		r.Version, r.CreateTimestamp = 0, 0
+		rv.Rules = append(rv.Rules, r)
+	}
+	sortRules(rv.Rules)
+	for _, g := range groups {
+		group := *g
+		rv.Groups = append(rv.Groups, &group)
+	}
+	sort.Slice(rv.Groups, func(i, j int) bool {
+		return rv.Groups[i].Index < rv.Groups[j].Index ||
+			(rv.Groups[i].Index == rv.Groups[j].Index && rv.Groups[i].ID < rv.Groups[j].ID)
+	})
+	return rv
+}
+
+// previewVersion returns the rules and rule groups after the patch is committed.
+func (m *RuleManager) previewVersion(patch *ruleConfigPatch) *RuleVersion {
+	p := m.ruleConfig.clone().beginPatch()
+	for key, r := range patch.mut.rules {
+		if r != nil {
+			r = r.Clone()
+		}
+		p.mut.rules[key] = r
+	}
+	for id, g := range patch.mut.groups {
+		group := *g
+		p.mut.groups[id] = &group
+	}
+	p.commit()
+	return newRuleVersion(p.c.rules, p.c.groups)
+}
+
+// newVersionRecord returns the record of the version, which keeps the changes
+// from the previous version. It returns nil if nothing is changed.
+func newVersionRecord(prev, rv *RuleVersion) *ruleVersionRecord {
+	record := &ruleVersionRecord{}
+	prevRules := make(map[[2]string]*Rule, len(prev.Rules))
+	for _, r := range prev.Rules {
+		prevRules[r.Key()] = r
+	}
+	for _, r := range rv.Rules {
+		if old, ok := prevRules[r.Key()]; !ok || !jsonEquals(old, r) {
+			record.Rules = append(record.Rules, r)
+		}
+		delete(prevRules, r.Key())
+	}
+	for _, r := range prev.Rules {
+		if _, ok := prevRules[r.Key()]; ok {
+			record.DeletedRules = append(record.DeletedRules, r.Key())
+		}
+	}
+	prevGroups := make(map[string]*RuleGroup, len(prev.Groups))
+	for _, g := range prev.Groups {
+		prevGroups[g.ID] = g
+	}
+	for _, g := range rv.Groups {
+		if old, ok := prevGroups[g.ID]; !ok || !jsonEquals(old, g) {
+			record.Groups = append(record.Groups, g)
+		}
+		delete(prevGroups, g.ID)
+	}
+	for _, g := range prev.Groups {
+		if _, ok := prevGroups[g.ID]; ok {
+			record.DeletedGroups = append(record.DeletedGroups, g.ID)
+		}
+	}
+	if len(record.Rules) == 0 && len(record.Groups) == 0 &&
+		len(record.DeletedRules) == 0 && len(record.DeletedGroups) == 0 {
+		return nil
+	}
+	return record
+}
+
+// beginVersion records the version to be created by the patch before the patch
+// is saved, so that the change can be completed on the next load if it is
+// interrupted. It returns nil if the version is not recorded.
+func (m *RuleManager) beginVersion(patch *ruleConfigPatch) (*ruleVersionRecord, error) {
+	if m.getReservedVersions() == 0 {
+		return nil, nil
+	}
+	rv := m.previewVersion(patch)
+	var record *ruleVersionRecord
+	if len(m.versions) == 0 {
+		record = &ruleVersionRecord{Version: 1, Full: true, Rules: rv.Rules, Groups: rv.Groups}
+	} else {
+		last := m.versions[len(m.versions)-1]
+		if record = newVersionRecord(m.getVersion(last.Version), rv); record == nil {
+			return nil, nil
+		}
+		record.Version = last.Version + 1
+	}
+	record.Time, record.Source, record.Pending = time.Now(), m.versionSource, true
+	if err := m.storage.SaveRuleVersion(record.Version, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// finishVersion marks the version as saved completely, and removes the oldest
+// versions beyond the reserved number. The changes are saved already, so the
+// errors are only logged.
+func (m *RuleManager) finishVersion(record *ruleVersionRecord) {
+	record.Pending = false
+	m.versions = append(m.versions, record)
+	if err := m.storage.SaveRuleVersion(record.Version, record); err != nil {
+		log.Error("failed to save placement rule version", zap.Uint64("version", record.Version), errs.ZapError(err))
+	}
+	m.trimVersions()
+}
+
+// abortVersion reverts the saved part of the patch which failed to be saved, and
+// drops the version recorded for it. If the patch fails to be reverted, the
+// version is kept so that the change is completed on the next load.
+func (m *RuleManager) abortVersion(patch *ruleConfigPatch, record *ruleVersionRecord) {
+	undo := newRuleConfig()
+	for key := range patch.mut.rules {
+		undo.rules[key] = m.ruleConfig.getRule(key)
+	}
+	for id := range patch.mut.groups {
+		undo.groups[id] = m.ruleConfig.getGroup(id)
+	}
+	if err := m.savePatch(undo); err != nil {
+		log.Error("failed to revert placement rule change", errs.ZapError(err))
+		return
+	}
+	if record == nil {
+		return
+	}
+	if err := m.storage.DeleteRuleVersion(record.Version); err != nil {
+		log.Error("failed to delete placement rule version", zap.Uint64("version", record.Version), errs.ZapError(err))
+	}
+}
+
+// trimVersions removes the oldest versions beyond the reserved number. The new
+// oldest version is saved with all rules and rule groups before the older ones
+// are removed.
+func (m *RuleManager) trimVersions() {
+	reserved := m.getReservedVersions()
+	if reserved == 0 || uint64(len(m.versions)) <= reserved {
+		return
+	}
+	drop := uint64(len(m.versions)) - reserved
+	first := m.versions[drop]
+	if !first.Full {
+		rv := m.getVersion(first.Version)
+		full := &ruleVersionRecord{
+			Version: first.Version,
+			Time:    first.Time,
+			Source:  first.Source,
+			Full:    true,
+			Rules:   rv.Rules,
+			Groups:  rv.Groups,
+		}
+		if err := m.storage.SaveRuleVersion(full.Version, full); err != nil {
+			log.Error("failed to save placement rule version", zap.Uint64("version", full.Version), errs.ZapError(err))
+			return
+		}
+		m.versions[drop] = full
+	}
+	for _, record := range m.versions[:drop] {
+		if err := m.storage.DeleteRuleVersion(record.Version); err != nil {
+			log.Error("failed to delete placement rule version", zap.Uint64("version", record.Version), errs.ZapError(err))
+		}
+	}
+	m.versions = m.versions[drop:]
+}
+
+// saveInitialVersion records the current rules and rule groups as the first version.
+func (m *RuleManager) saveInitialVersion() error {
+	if m.getReservedVersions() == 0 {
+		return nil
+	}
+	rv := newRuleVersion(m.ruleConfig.rules, m.ruleConfig.groups)
+	record := &ruleVersionRecord{
+		Version: 1,
+		Time:    time.Now(),
+		Source:  m.versionSource,
+		Full:    true,
+		Rules:   rv.Rules,
+		Groups:  rv.Groups,
+	}
+	if err := m.storage.SaveRuleVersion(record.Version, record); err != nil {
+		return err
+	}
+	m.versions = append(m.versions, record)
+	return nil
+}
+
+// getVersion returns the rules and rule groups of the version by applying the
+// changes since the last full version. It returns nil if the version does not exist.
+func (m *RuleManager) getVersion(version uint64) *RuleVersion {
+	i := sort.Search(len(m.versions), func(i int) bool { return m.versions[i].Version >= version })
+	if i >= len(m.versions) || m.versions[i].Version != version {
+		return nil
+	}
+	start := i
+	for start > 0 && !m.versions[start].Full {
+		start--
+	}
+	rules := make(map[[2]string]*Rule)
+	groups := make(map[string]*RuleGroup)
+	for _, record := range m.versions[start : i+1] {
+		for _, r := range record.Rules {
+			rules[r.Key()] = r
+		}
+		for _, key := range record.DeletedRules {
+			delete(rules, key)
+		}
+		for _, g := range record.Groups {
+			groups[g.ID] = g
+		}
+		for _, id := range record.DeletedGroups {
+			delete(groups, id)
+		}
+	}
+	rv := newRuleVersion(rules, groups)
+	rv.Version, rv.Time, rv.Source = version, m.versions[i].Time, m.versions[i].Source
+	return rv
+}
+
+// WithVersionSource runs f which changes the rules or rule groups, and annotates
+// the versions created by f with the source.
+func (m *RuleManager) WithVersionSource(source string, f func() error) error {
+	m.sourceMu.Lock()
+	defer m.sourceMu.Unlock()
+	m.Lock()
+	m.versionSource = source
+	m.Unlock()
+	defer func() {
+		m.Lock()
+		m.versionSource = ""
+		m.Unlock()
+	}()
+	return f()
+}
+
+// GetRuleVersions returns all reserved versions without the rules and rule groups.
+func (m *RuleManager) GetRuleVersions() []*RuleVersion {
+	m.RLock()
+	defer m.RUnlock()
+	versions := make([]*RuleVersion, 0, len(m.versions))
+	for _, rv := range m.versions {
+		versions = append(versions, &RuleVersion{Version: rv.Version, Time: rv.Time, Source: rv.Source})
+	}
+	return versions
+}
+
+// GetRuleVersion returns the version with the rules and rule groups. It returns
+// nil if the version does not exist.
+func (m *RuleManager) GetRuleVersion(version uint64) *RuleVersion {
+	m.RLock()
+	defer m.RUnlock()
+	return m.getVersion(version)
+}
+
+// DiffRuleVersions returns the rules and rule groups changed from one version to another.
+func (m *RuleManager) DiffRuleVersions(from, to uint64) (*RuleVersionDiff, error) {
+	m.RLock()
+	defer m.RUnlock()
+	fromVersion, toVersion := m.getVersion(from), m.getVersion(to)
+	if fromVersion == nil {
+		return nil, errs.ErrRuleVersionNotFound.FastGenByArgs(from)
+	}
+	if toVersion == nil {
+		return nil, errs.ErrRuleVersionNotFound.FastGenByArgs(to)
+	}
+	diff := &RuleVersionDiff{From: from, To: to, Rules: []RuleDiff{}, Groups: []RuleGroupDiff{}}
+
+	oldRules := make(map[[2]string]*Rule, len(fromVersion.Rules))
+	for _, r := range fromVersion.Rules {
+		oldRules[r.Key()] = r
+	}
+	for _, r := range toVersion.Rules {
+		old, ok := oldRules[r.Key()]
+		if !ok || !jsonEquals(old, r) {
+			diff.Rules = append(diff.Rules, RuleDiff{Old: old, New: r})
+		}
+		delete(oldRules, r.Key())
+	}
+	for _, r := range fromVersion.Rules {
+		if _, ok := oldRules[r.Key()]; ok {
+			diff.Rules = append(diff.Rules, RuleDiff{Old: r})
+		}
+	}
+
+	oldGroups := make(map[string]*RuleGroup, len(fromVersion.Groups))
+	for _, g := range fromVersion.Groups {
+		oldGroups[g.ID] = g
+	}
+	for _, g := range toVersion.Groups {
+		old, ok := oldGroups[g.ID]
+		if !ok || !jsonEquals(old, g) {
+			diff.Groups = append(diff.Groups, RuleGroupDiff{Old: old, New: g})
+		}
+		delete(oldGroups, g.ID)
+	}
+	for _, g := range fromVersion.Groups {
+		if _, ok := oldGroups[g.ID]; ok {
+			diff.Groups = append(diff.Groups, RuleGroupDiff{Old: g})
+		}
+	}
+	return diff, nil
+}
+
+// RollbackRuleVersion replaces all rules and rule groups with the ones of the
+// version. The rollback itself creates a new version, which is recorded before
+// the rules are saved, so a failed rollback is reverted and an interrupted one
+// is completed on the next load.
+func (m *RuleManager) RollbackRuleVersion(version uint64) error {
+	m.Lock()
+	defer m.Unlock()
+	rv := m.getVersion(version)
+	if rv == nil {
+		return errs.ErrRuleVersionNotFound.FastGenByArgs(version)
+	}
+	p := m.beginPatch()
+	if err := m.patchVersion(p, rv); err != nil {
+		return err
+	}
+	if err := m.tryCommitPatch(p); err != nil {
+		return err
+	}
+	log.Info("placement rules rolled back", zap.Uint64("version", version))
+	return nil
+}
+
+// patchVersion replaces all rules and rule groups in the patch with the ones of the version.
+func (m *RuleManager) patchVersion(p *ruleConfigPatch, rv *RuleVersion) error {
+	for key := range m.ruleConfig.rules {
+		p.deleteRule(key[0], key[1])
+	}
+	for id := range m.ruleConfig.groups {
+		p.deleteGroup(id)
+	}
+	for _, g := range rv.Groups {
+		group := *g
+		p.setGroup(&group)
+	}
+	for _, r := range rv.Rules {
+		r = r.Clone()
+		if err := m.adjustRule(r, ""); err != nil {
+			return err
+		}
+		p.setRule(r)
+	}
+	return nil
+}
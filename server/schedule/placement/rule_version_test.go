@@ -0,0 +1,184 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/server/config"
+	"github.com/tikv/pd/server/storage"
+	"github.com/tikv/pd/server/storage/endpoint"
+)
+
+func TestRuleVersions(t *testing.T) {
+	re := require.New(t)
+	store, manager := newTestManager(t)
+	// the initial rules are recorded.
+	versions := manager.GetRuleVersions()
+	re.Len(versions, 1)
+	re.Equal(uint64(1), versions[0].Version)
+	re.Empty(versions[0].Rules)
+	re.Len(manager.GetRuleVersion(1).Rules, 1)
+
+	rule := &Rule{GroupID: "g1", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: Voter, Count: 3}
+	re.NoError(manager.WithVersionSource("pd-ctl@127.0.0.1", func() error { return manager.SetRule(rule) }))
+	re.NoError(manager.SetRuleGroup(&RuleGroup{ID: "g1", Index: 1}))
+	// unchanged rules do not create a version.
+	re.NoError(manager.SetRule(&Rule{GroupID: "g1", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: Voter, Count: 3}))
+	versions = manager.GetRuleVersions()
+	re.Len(versions, 3)
+	re.Equal("pd-ctl@127.0.0.1", versions[1].Source)
+	re.Empty(versions[2].Source)
+
+	diff, err := manager.DiffRuleVersions(1, 3)
+	re.NoError(err)
+	re.Len(diff.Rules, 1)
+	re.Nil(diff.Rules[0].Old)
+	re.Equal([2]string{"g1", "id"}, diff.Rules[0].New.Key())
+	re.Len(diff.Groups, 1)
+	re.Equal(1, diff.Groups[0].New.Index)
+	diff, err = manager.DiffRuleVersions(3, 1)
+	re.NoError(err)
+	re.Len(diff.Rules, 1)
+	re.Nil(diff.Rules[0].New)
+	_, err = manager.DiffRuleVersions(1, 10)
+	re.Error(err)
+
+	// roll back to the initial rules.
+	re.NoError(manager.RollbackRuleVersion(1))
+	re.Len(manager.GetAllRules(), 1)
+	re.Nil(manager.GetRule("g1", "id"))
+	re.Nil(manager.GetRuleGroup("g1"))
+	re.Len(manager.GetRuleVersions(), 4)
+	re.Error(manager.RollbackRuleVersion(10))
+
+	// the versions are loaded from storage.
+	manager2 := NewRuleManager(store, nil, nil)
+	re.NoError(manager2.Initialize(3, []string{"zone", "rack", "host"}))
+	versions = manager2.GetRuleVersions()
+	re.Len(versions, 4)
+	re.Equal("pd-ctl@127.0.0.1", versions[1].Source)
+	re.NoError(manager2.RollbackRuleVersion(3))
+	re.NotNil(manager2.GetRule("g1", "id"))
+	re.Equal(1, manager2.GetRuleGroup("g1").Index)
+}
+
+func TestRuleVersionsRetention(t *testing.T) {
+	re := require.New(t)
+	opt := config.NewTestOptions()
+	cfg := opt.GetScheduleConfig().Clone()
+	cfg.PlacementRuleReservedVersions = 3
+	opt.SetScheduleConfig(cfg)
+	store := storage.NewStorageWithMemoryBackend()
+	manager := NewRuleManager(store, nil, opt)
+	re.NoError(manager.Initialize(3, []string{"zone", "rack", "host"}))
+	for i := 1; i <= 5; i++ {
+		re.NoError(manager.SetRule(&Rule{GroupID: "pd", ID: "default", Role: Voter, Count: i}))
+	}
+	versions := manager.GetRuleVersions()
+	re.Len(versions, 3)
+	re.Equal(uint64(4), versions[0].Version)
+	re.Nil(manager.GetRuleVersion(3))
+	re.Equal(3, manager.GetRuleVersion(4).Rules[0].Count)
+	var records []*ruleVersionRecord
+	re.NoError(store.LoadRuleVersions(func(k, v string) {
+		var record ruleVersionRecord
+		re.NoError(json.Unmarshal([]byte(v), &record))
+		records = append(records, &record)
+	}))
+	re.Len(records, 3)
+	// the oldest version keeps all rules, and the others only keep the changes.
+	re.True(records[0].Full)
+	re.Len(records[0].Rules, 1)
+	re.False(records[1].Full)
+	re.Len(records[1].Rules, 1)
+	re.Equal(4, records[1].Rules[0].Count)
+
+	// the versions are not recorded if it is closed.
+	cfg.PlacementRuleReservedVersions = 0
+	opt.SetScheduleConfig(cfg)
+	re.NoError(manager.SetRule(&Rule{GroupID: "pd", ID: "default", Role: Voter, Count: 3}))
+	re.Len(manager.GetRuleVersions(), 3)
+}
+
+// faultyRuleStorage fails to delete the rule or save the versions on demand.
+type faultyRuleStorage struct {
+	endpoint.RuleStorage
+	failDelete  string
+	failVersion bool
+}
+
+func (s *faultyRuleStorage) DeleteRule(ruleKey string) error {
+	if ruleKey == s.failDelete {
+		return errors.New("fail to delete rule")
+	}
+	return s.RuleStorage.DeleteRule(ruleKey)
+}
+
+func (s *faultyRuleStorage) SaveRuleVersion(version uint64, ruleVersion interface{}) error {
+	if s.failVersion {
+		return errors.New("fail to save rule version")
+	}
+	return s.RuleStorage.SaveRuleVersion(version, ruleVersion)
+}
+
+func TestRuleVersionsSaveFailure(t *testing.T) {
+	re := require.New(t)
+	store := &faultyRuleStorage{RuleStorage: storage.NewStorageWithMemoryBackend()}
+	manager := NewRuleManager(store, nil, nil)
+	re.NoError(manager.Initialize(3, []string{"zone", "rack", "host"}))
+	re.NoError(manager.SetRules([]*Rule{
+		{GroupID: "g1", ID: "id1", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: Voter, Count: 3},
+		{GroupID: "g1", ID: "id2", StartKeyHex: "223abc", EndKeyHex: "223abf", Role: Voter, Count: 3},
+	}))
+	re.Len(manager.GetRuleVersions(), 2)
+
+	// the change fails if the version fails to be saved.
+	store.failVersion = true
+	re.Error(manager.SetRule(&Rule{GroupID: "g1", ID: "id3", StartKeyHex: "323abc", EndKeyHex: "323abf", Role: Voter, Count: 3}))
+	re.Nil(manager.GetRule("g1", "id3"))
+	re.Len(manager.GetRuleVersions(), 2)
+	store.failVersion = false
+
+	// the rollback is reverted if the rules fail to be saved.
+	store.failDelete = (&Rule{GroupID: "g1", ID: "id2"}).StoreKey()
+	re.Error(manager.RollbackRuleVersion(1))
+	re.NotNil(manager.GetRule("g1", "id1"))
+	re.Len(manager.GetRuleVersions(), 2)
+	manager2 := NewRuleManager(store, nil, nil)
+	re.NoError(manager2.Initialize(3, []string{"zone", "rack", "host"}))
+	re.Len(manager2.GetAllRules(), 3)
+	re.Len(manager2.GetRuleVersions(), 2)
+
+	// the interrupted rollback is completed on the next load.
+	store.failDelete = ""
+	re.NoError(store.SaveRuleVersion(3, &ruleVersionRecord{
+		Version:       3,
+		DeletedRules:  [][2]string{{"g1", "id1"}, {"g1", "id2"}},
+		DeletedGroups: []string{"g1"},
+		Pending:       true,
+	}))
+	manager3 := NewRuleManager(store, nil, nil)
+	re.NoError(manager3.Initialize(3, []string{"zone", "rack", "host"}))
+	re.Len(manager3.GetAllRules(), 1)
+	re.Nil(manager3.GetRule("g1", "id1"))
+	re.Len(manager3.GetRuleVersions(), 3)
+	manager4 := NewRuleManager(store, nil, nil)
+	re.NoError(manager4.Initialize(3, []string{"zone", "rack", "host"}))
+	re.Len(manager4.GetAllRules(), 1)
+}
@@ -31,46 +31,73 @@ type WaitingOperator interface {
 	ListOperator() []*operator.Operator
 }
 
-// Bucket is used to maintain the operators created by a specific scheduler.
+// Bucket is used to maintain the operators with a specific priority.
+// The operators are queued by their sources, and the queues are served in
+// proportion to the weights of the sources.
 type Bucket struct {
 	weight float64
-	ops    []*operator.Operator
+	count  int
+	queues map[string]*sourceQueue
+	// pass is the virtual time of the bucket, which is the pass of the queue served last.
+	pass float64
+}
+
+// sourceQueue is the FIFO queue of the operators from a specific source.
+type sourceQueue struct {
+	ops  []*operator.Operator
+	pass float64
 }
 
 // RandBuckets is an implementation of waiting operators
 type RandBuckets struct {
-	totalWeight float64
-	buckets     []*Bucket
+	totalWeight  float64
+	buckets      []*Bucket
+	sourceWeight func(source string) float64
 }
 
 // NewRandBuckets creates a random buckets.
 func NewRandBuckets() *RandBuckets {
+	return newRandBucketsWithWeight(func(string) float64 { return 1 })
+}
+
+// newRandBucketsWithWeight creates a random buckets which promotes the operators
+// from different sources in proportion to the weights of the sources.
+func newRandBucketsWithWeight(sourceWeight func(source string) float64) *RandBuckets {
 	var buckets []*Bucket
 	for i := 0; i < len(PriorityWeight); i++ {
 		buckets = append(buckets, &Bucket{
 			weight: PriorityWeight[i],
+			queues: make(map[string]*sourceQueue),
 		})
 	}
-	return &RandBuckets{buckets: buckets}
+	return &RandBuckets{buckets: buckets, sourceWeight: sourceWeight}
 }
 
 // PutOperator puts an operator into the random buckets.
 func (b *RandBuckets) PutOperator(op *operator.Operator) {
 	priority := op.GetPriorityLevel()
 	bucket := b.buckets[priority]
-	if len(bucket.ops) == 0 {
+	if bucket.count == 0 {
 		b.totalWeight += bucket.weight
 	}
-	bucket.ops = append(bucket.ops, op)
+	source := operatorSource(op)
+	queue, ok := bucket.queues[source]
+	if !ok {
+		// A new source starts from the virtual time of the bucket so that it
+		// cannot take over the bucket with the accumulated credits.
+		queue = &sourceQueue{pass: bucket.pass}
+		bucket.queues[source] = queue
+	}
+	queue.ops = append(queue.ops, op)
+	bucket.count++
 }
 
 // ListOperator lists all operator in the random buckets.
 func (b *RandBuckets) ListOperator() []*operator.Operator {
 	var ops []*operator.Operator
 	for i := range b.buckets {
-		bucket := b.buckets[i]
-		for j := range bucket.ops {
-			ops = append(ops, bucket.ops[j])
+		for _, queue := range b.buckets[i].queues {
+			ops = append(ops, queue.ops...)
 		}
 	}
 	return ops
@@ -85,21 +112,13 @@ func (b *RandBuckets) GetOperator() []*operator.Operator {
 	var sum float64
 	for i := range b.buckets {
 		bucket := b.buckets[i]
-		if len(bucket.ops) == 0 {
+		if bucket.count == 0 {
 			continue
 		}
 		proportion := bucket.weight / b.totalWeight
 		if r >= sum && r < sum+proportion {
-			var res []*operator.Operator
-			res = append(res, bucket.ops[0])
-			// Merge operation has two operators, and thus it should be handled specifically.
-			if bucket.ops[0].Kind()&operator.OpMerge != 0 {
-				res = append(res, bucket.ops[1])
-				bucket.ops = bucket.ops[2:]
-			} else {
-				bucket.ops = bucket.ops[1:]
-			}
-			if len(bucket.ops) == 0 {
+			res := b.popFromBucket(bucket)
+			if bucket.count == 0 {
 				b.totalWeight -= bucket.weight
 			}
 			return res
@@ -109,7 +128,53 @@ func (b *RandBuckets) GetOperator() []*operator.Operator {
 	return nil
 }
 
-// WaitingOperatorStatus is used to limit the count of each kind of operators.
+// popFromBucket pops the operators from the source with the smallest pass in the bucket.
+func (b *RandBuckets) popFromBucket(bucket *Bucket) []*operator.Operator {
+	var (
+		source string
+		queue  *sourceQueue
+	)
+	for s, q := range bucket.queues {
+		if queue == nil || q.pass < queue.pass || (q.pass == queue.pass && s < source) {
+			source, queue = s, q
+		}
+	}
+	var res []*operator.Operator
+	res = append(res, queue.ops[0])
+	// Merge operation has two operators, and thus it should be handled specifically.
+	if queue.ops[0].Kind()&operator.OpMerge != 0 {
+		res = append(res, queue.ops[1])
+		queue.ops = queue.ops[2:]
+	} else {
+		queue.ops = queue.ops[1:]
+	}
+	bucket.count -= len(res)
+	bucket.pass = queue.pass
+	weight := b.sourceWeight(source)
+	if weight <= 0 {
+		weight = 1
+	}
+	queue.pass += 1 / weight
+	if len(queue.ops) == 0 {
+		delete(bucket.queues, source)
+	}
+	return res
+}
+
+// operatorSource returns the source of the operator, which is the name of the
+// scheduler or the checker creating it. The description is used for the operators
+// from neither of them, e.g. the ones created by the API.
+func operatorSource(op *operator.Operator) string {
+	if name := op.GetSchedulerName(); name != "" {
+		return name
+	}
+	if name := op.GetCheckerName(); name != "" {
+		return name
+	}
+	return op.Desc()
+}
+
+// WaitingOperatorStatus is used to limit the count of waiting operators from each source.
 type WaitingOperatorStatus struct {
 	ops map[string]uint64
 }
@@ -121,6 +186,16 @@ func NewWaitingOperatorStatus() *WaitingOperatorStatus {
 	}
 }
 
+func (s *WaitingOperatorStatus) inc(source string) {
+	s.ops[source]++
+	waitingOperatorGauge.WithLabelValues(source).Set(float64(s.ops[source]))
+}
+
+func (s *WaitingOperatorStatus) dec(source string) {
+	s.ops[source]--
+	waitingOperatorGauge.WithLabelValues(source).Set(float64(s.ops[source]))
+}
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
@@ -34,6 +34,59 @@ func TestRandBuckets(t *testing.T) {
 	re.Nil(rb.GetOperator())
 }
 
+func TestRandBucketsWithSourceWeight(t *testing.T) {
+	re := require.New(t)
+	weights := map[string]float64{"balance-hot-region-scheduler": 2}
+	rb := newRandBucketsWithWeight(func(source string) float64 { return weights[source] })
+	newOperator := func(i uint64, scheduler string) *operator.Operator {
+		op := operator.NewTestOperator(i, &metapb.RegionEpoch{}, operator.OpRegion, []operator.OpStep{
+			operator.RemovePeer{FromStore: uint64(1)},
+		}...)
+		op.SetSchedulerName(scheduler)
+		return op
+	}
+	// balance-region-scheduler fills the bucket before balance-hot-region-scheduler.
+	for i := uint64(0); i < 10; i++ {
+		rb.PutOperator(newOperator(i, "balance-region-scheduler"))
+	}
+	for i := uint64(10); i < 20; i++ {
+		rb.PutOperator(newOperator(i, "balance-hot-region-scheduler"))
+	}
+	// The sources are served in proportion to the weights, and the source
+	// without weight is regarded as weight 1.
+	counts := make(map[string]int)
+	for i := 0; i < 9; i++ {
+		ops := rb.GetOperator()
+		re.Len(ops, 1)
+		counts[ops[0].GetSchedulerName()]++
+	}
+	re.Equal(6, counts["balance-hot-region-scheduler"])
+	re.Equal(3, counts["balance-region-scheduler"])
+	// The operators from the same source are promoted in order.
+	re.Len(rb.ListOperator(), 11)
+	for i := 0; i < 11; i++ {
+		re.NotNil(rb.GetOperator())
+	}
+	re.Nil(rb.GetOperator())
+
+	// A new source starts from the current progress instead of catching up.
+	for i := uint64(0); i < 10; i++ {
+		rb.PutOperator(newOperator(i, "balance-region-scheduler"))
+	}
+	for i := 0; i < 5; i++ {
+		re.NotNil(rb.GetOperator())
+	}
+	for i := uint64(10); i < 12; i++ {
+		rb.PutOperator(newOperator(i, "balance-leader-scheduler"))
+	}
+	counts = make(map[string]int)
+	for i := 0; i < 4; i++ {
+		counts[rb.GetOperator()[0].GetSchedulerName()]++
+	}
+	re.Equal(2, counts["balance-leader-scheduler"])
+	re.Equal(2, counts["balance-region-scheduler"])
+}
+
 func addOperators(wop WaitingOperator) {
 	op := operator.NewTestOperator(uint64(1), &metapb.RegionEpoch{}, operator.OpRegion, []operator.OpStep{
 		operator.RemovePeer{FromStore: uint64(1)},
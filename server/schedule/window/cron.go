@@ -0,0 +1,163 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tikv/pd/pkg/errs"
+)
+
+// cronField is the range of a field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day-of-month", 1, 31},
+	{"month", 1, 12},
+	// Both 0 and 7 stand for Sunday.
+	{"day-of-week", 0, 7},
+}
+
+// cronSchedule is a parsed cron expression "minute hour day-of-month month day-of-week".
+// Each field is either `*`, a value, a range `a-b`, a step `*/n` or `a-b/n`, or a comma
+// separated list of them.
+type cronSchedule struct {
+	// bits[i] has the j-th bit set if the value j matches the i-th field.
+	bits [5]uint64
+	// domStar and dowStar record whether day-of-month and day-of-week are `*`. As in the
+	// standard cron, a day matches if either of them matches when both are restricted.
+	domStar, dowStar bool
+}
+
+func parseCron(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, errs.ErrSchedulingWindow.FastGenByArgs("cron expression should have 5 fields: " + spec)
+	}
+	s := &cronSchedule{}
+	for i, field := range fields {
+		mask, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		s.bits[i] = mask
+	}
+	// Sunday is both 0 and 7.
+	if s.bits[4]&(1<<7) != 0 {
+		s.bits[4] |= 1
+	}
+	s.domStar, s.dowStar = fields[2] == "*", fields[4] == "*"
+	return s, nil
+}
+
+func parseCronField(field string, r cronField) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		min, max, step := r.min, r.max, 1
+		rangeAndStep := strings.SplitN(part, "/", 2)
+		if len(rangeAndStep) == 2 {
+			var err error
+			if step, err = strconv.Atoi(rangeAndStep[1]); err != nil || step <= 0 {
+				return 0, errs.ErrSchedulingWindow.FastGenByArgs("invalid step of " + r.name + ": " + part)
+			}
+		}
+		if rangeAndStep[0] != "*" {
+			bounds := strings.SplitN(rangeAndStep[0], "-", 2)
+			var err error
+			if min, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, errs.ErrSchedulingWindow.FastGenByArgs("invalid " + r.name + ": " + part)
+			}
+			max = min
+			if len(bounds) == 2 {
+				if max, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, errs.ErrSchedulingWindow.FastGenByArgs("invalid " + r.name + ": " + part)
+				}
+			} else if len(rangeAndStep) == 2 {
+				// `a/n` means from a to the max value with step n.
+				max = r.max
+			}
+		}
+		if min < r.min || max > r.max || min > max {
+			return 0, errs.ErrSchedulingWindow.FastGenByArgs(r.name + " out of range: " + part)
+		}
+		for v := min; v <= max; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+// match returns whether the given time matches the cron expression at the minute granularity.
+func (s *cronSchedule) match(t time.Time) bool {
+	if s.bits[0]&(1<<uint(t.Minute())) == 0 ||
+		s.bits[1]&(1<<uint(t.Hour())) == 0 ||
+		s.bits[3]&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	return s.matchDay(t)
+}
+
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	domMatch := s.bits[2]&(1<<uint(t.Day())) != 0
+	dowMatch := s.bits[4]&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// prev returns the latest time not after t matching the cron expression at the minute
+// granularity. It skips the months, days and hours which do not match as a whole, and
+// returns false if there is no match since the given bound.
+func (s *cronSchedule) prev(t, bound time.Time) (time.Time, bool) {
+	t = t.Truncate(time.Minute)
+	loc := t.Location()
+	for !t.Before(bound) {
+		year, month, day := t.Date()
+		if s.bits[3]&(1<<uint(month)) == 0 {
+			// Go to the last minute of the previous month.
+			t = time.Date(year, month, 1, 0, 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(year, month, day, 0, 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		hours := s.bits[1] & (1<<uint(t.Hour()+1) - 1)
+		if hours == 0 {
+			t = time.Date(year, month, day, 0, 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if hour := bits.Len64(hours) - 1; hour < t.Hour() {
+			t = time.Date(year, month, day, hour, 59, 0, 0, loc)
+		}
+		minutes := s.bits[0] & (1<<uint(t.Minute()+1) - 1)
+		if minutes == 0 {
+			t = time.Date(year, month, day, t.Hour(), 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		t = time.Date(year, month, day, t.Hour(), bits.Len64(minutes)-1, 0, 0, loc)
+		return t, !t.Before(bound)
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,222 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/syncutil"
+	"github.com/tikv/pd/pkg/typeutil"
+	"github.com/tikv/pd/server/config"
+	"github.com/tikv/pd/server/storage/endpoint"
+	"go.uber.org/zap"
+)
+
+const maxWindowDuration = 7 * 24 * time.Hour
+
+// Window is a period of time in which a scheduler or checker is allowed to run. The window
+// starts at every time matching the cron expression and lasts for the duration.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Window struct {
+	// Cron is the cron expression "minute hour day-of-month month day-of-week" in the
+	// local time zone of PD, e.g. "0 22 * * 1-5" starts at 22:00 on weekdays.
+	Cron     string            `json:"cron"`
+	Duration typeutil.Duration `json:"duration"`
+	// Limits overrides the schedule limits of the cluster for the scheduler or checker owning the
+	// window within it, e.g. "region-schedule-limit".
+	Limits map[string]uint64 `json:"limits,omitempty"`
+
+	schedule *cronSchedule
+}
+
+func (w *Window) adjust() error {
+	schedule, err := parseCron(w.Cron)
+	if err != nil {
+		return err
+	}
+	if w.Duration.Duration < time.Minute || w.Duration.Duration > maxWindowDuration {
+		return errs.ErrSchedulingWindow.FastGenByArgs("duration should be between 1m and 168h: " + w.Duration.String())
+	}
+	for limit := range w.Limits {
+		if !config.IsWindowLimit(limit) {
+			return errs.ErrSchedulingWindow.FastGenByArgs("unsupported limit: " + limit)
+		}
+	}
+	w.schedule = schedule
+	return nil
+}
+
+// activeSince returns the start time of the window if it is active at the given time.
+func (w *Window) activeSince(now time.Time) (time.Time, bool) {
+	bound := now.Add(-w.Duration.Duration)
+	start, ok := w.schedule.prev(now, bound)
+	if !ok || !start.After(bound) {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+// Policy is the windows of a scheduler or checker. A scheduler or checker with windows
+// only runs within any of them, and one without windows is not restricted.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Policy struct {
+	Windows []*Window `json:"windows"`
+}
+
+// Adjust validates the policy and prepares the windows to be evaluated.
+func (p *Policy) Adjust() error {
+	for _, w := range p.Windows {
+		if err := w.adjust(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Status is the window status of a scheduler or checker.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Status struct {
+	// Active indicates whether the scheduler or checker is within a window or not restricted by windows.
+	Active bool `json:"active"`
+	// Window is the current window if any.
+	Window *Window    `json:"window,omitempty"`
+	Start  *time.Time `json:"start,omitempty"`
+	End    *time.Time `json:"end,omitempty"`
+}
+
+// GetStatus returns the window status at the given time.
+func (p *Policy) GetStatus(now time.Time) *Status {
+	if p == nil || len(p.Windows) == 0 {
+		return &Status{Active: true}
+	}
+	for _, w := range p.Windows {
+		if start, ok := w.activeSince(now); ok {
+			end := start.Add(w.Duration.Duration)
+			return &Status{Active: true, Window: w, Start: &start, End: &end}
+		}
+	}
+	return &Status{}
+}
+
+// Manager manages the window policies of schedulers and checkers.
+type Manager struct {
+	syncutil.RWMutex
+	storage  endpoint.ConfigStorage
+	policies map[string]*Policy
+}
+
+// NewManager creates a new window Manager.
+func NewManager(storage endpoint.ConfigStorage) *Manager {
+	return &Manager{
+		storage:  storage,
+		policies: make(map[string]*Policy),
+	}
+}
+
+// Load loads the window policies from storage.
+func (m *Manager) Load() error {
+	names, data, err := m.storage.LoadAllSchedulingWindows()
+	if err != nil {
+		return err
+	}
+	policies := make(map[string]*Policy, len(names))
+	for i, name := range names {
+		policy := &Policy{}
+		if err := json.Unmarshal([]byte(data[i]), policy); err != nil {
+			log.Error("failed to unmarshal scheduling windows", zap.String("name", name), errs.ZapError(errs.ErrJSONUnmarshal, err))
+			continue
+		}
+		if err := policy.Adjust(); err != nil {
+			log.Error("invalid scheduling windows", zap.String("name", name), errs.ZapError(err))
+			continue
+		}
+		policies[name] = policy
+	}
+	m.Lock()
+	defer m.Unlock()
+	m.policies = policies
+	return nil
+}
+
+// GetPolicy returns the window policy of a scheduler or checker, nil means no windows.
+func (m *Manager) GetPolicy(name string) *Policy {
+	m.RLock()
+	defer m.RUnlock()
+	return m.policies[name]
+}
+
+// GetPolicies returns the window policies of all schedulers and checkers.
+func (m *Manager) GetPolicies() map[string]*Policy {
+	m.RLock()
+	defer m.RUnlock()
+	policies := make(map[string]*Policy, len(m.policies))
+	for name, policy := range m.policies {
+		policies[name] = policy
+	}
+	return policies
+}
+
+// SetPolicy validates and persists the window policy of a scheduler or checker.
+// A policy without windows removes the restriction.
+func (m *Manager) SetPolicy(name string, policy *Policy) error {
+	if policy == nil || len(policy.Windows) == 0 {
+		return m.DeletePolicy(name)
+	}
+	if err := policy.Adjust(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	m.Lock()
+	defer m.Unlock()
+	if err := m.storage.SaveSchedulingWindows(name, data); err != nil {
+		return err
+	}
+	m.policies[name] = policy
+	return nil
+}
+
+// DeletePolicy removes the window policy of a scheduler or checker.
+func (m *Manager) DeletePolicy(name string) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.policies[name]; !ok {
+		return nil
+	}
+	if err := m.storage.RemoveSchedulingWindows(name); err != nil {
+		return err
+	}
+	delete(m.policies, name)
+	return nil
+}
+
+// GetStatus returns the window status of a scheduler or checker at the given time.
+func (m *Manager) GetStatus(name string, now time.Time) *Status {
+	return m.GetPolicy(name).GetStatus(now)
+}
+
+// GetActiveLimits returns the schedule limits overridden by the active window of a scheduler
+// or checker at the given time, which only apply to the scheduler or checker.
+func (m *Manager) GetActiveLimits(name string, now time.Time) map[string]uint64 {
+	if status := m.GetStatus(name, now); status.Window != nil {
+		return status.Window.Limits
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/typeutil"
+	"github.com/tikv/pd/server/storage"
+)
+
+func TestParseCron(t *testing.T) {
+	re := require.New(t)
+	// 2022-10-17 is a Monday.
+	monday := time.Date(2022, 10, 17, 22, 30, 0, 0, time.Local)
+	sunday := time.Date(2022, 10, 16, 22, 30, 0, 0, time.Local)
+	testCases := []struct {
+		spec    string
+		matches []time.Time
+		misses  []time.Time
+	}{
+		{"* * * * *", []time.Time{monday, sunday}, nil},
+		{"30 22 * * *", []time.Time{monday, sunday}, []time.Time{monday.Add(time.Minute), monday.Add(time.Hour)}},
+		{"*/15 22-23 * * 1-5", []time.Time{monday, monday.Add(45 * time.Minute)}, []time.Time{sunday, monday.Add(10 * time.Minute)}},
+		{"30 22 * * 0,6", []time.Time{sunday}, []time.Time{monday}},
+		{"30 22 * * 7", []time.Time{sunday}, []time.Time{monday}},
+		{"30 22 1 10 *", []time.Time{monday.AddDate(0, 0, -16)}, []time.Time{monday}},
+		// Either day-of-month or day-of-week matches if both are restricted.
+		{"30 22 16 * 1", []time.Time{monday, sunday}, []time.Time{monday.AddDate(0, 0, 1)}},
+		{"10/20 * * * *", []time.Time{monday}, []time.Time{monday.Add(-30 * time.Minute)}},
+	}
+	for _, testCase := range testCases {
+		s, err := parseCron(testCase.spec)
+		re.NoError(err, testCase.spec)
+		for _, match := range testCase.matches {
+			re.True(s.match(match), "%s %s", testCase.spec, match)
+		}
+		for _, miss := range testCase.misses {
+			re.False(s.match(miss), "%s %s", testCase.spec, miss)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := parseCron(spec)
+		re.Error(err, spec)
+	}
+}
+
+func TestCronPrev(t *testing.T) {
+	re := require.New(t)
+	// 2022-10-17 is a Monday.
+	now := time.Date(2022, 10, 17, 22, 30, 0, 0, time.Local)
+	bound := now.Add(-maxWindowDuration)
+	for _, spec := range []string{"* * * * *", "30 22 * * *", "*/15 22-23 * * 1-5", "0 12 * * 0,6", "45 * 16 * 1", "10/20 3 * 10 *", "0 0 1 1 *"} {
+		s, err := parseCron(spec)
+		re.NoError(err, spec)
+		// The result should be the same as probing minute by minute.
+		expected, found := time.Time{}, false
+		for t := now; !t.Before(bound); t = t.Add(-time.Minute) {
+			if s.match(t) {
+				expected, found = t, true
+				break
+			}
+		}
+		for _, at := range []time.Time{now, now.Add(30 * time.Second)} {
+			prev, ok := s.prev(at, bound)
+			re.Equal(found, ok, spec)
+			if found {
+				re.True(expected.Equal(prev), "%s %s %s", spec, expected, prev)
+			}
+		}
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	re := require.New(t)
+	policy := &Policy{Windows: []*Window{
+		{Cron: "0 22 * * *", Duration: typeutil.NewDuration(6 * time.Hour), Limits: map[string]uint64{"region-schedule-limit": 64}},
+		{Cron: "0 12 * * *", Duration: typeutil.NewDuration(time.Hour)},
+	}}
+	re.NoError(policy.Adjust())
+
+	night := time.Date(2022, 10, 17, 22, 0, 0, 0, time.Local)
+	status := policy.GetStatus(night.Add(7 * time.Hour))
+	re.False(status.Active)
+	re.Nil(status.Window)
+	status = policy.GetStatus(night.Add(5 * time.Hour))
+	re.True(status.Active)
+	re.Equal(policy.Windows[0], status.Window)
+	re.Equal(night, *status.Start)
+	re.Equal(night.Add(6*time.Hour), *status.End)
+	status = policy.GetStatus(night.Add(-9*time.Hour - 30*time.Minute))
+	re.True(status.Active)
+	re.Equal(policy.Windows[1], status.Window)
+	// No windows means no restriction.
+	re.True((*Policy)(nil).GetStatus(night).Active)
+	re.True((&Policy{}).GetStatus(night).Active)
+
+	for _, w := range []*Window{
+		{Cron: "0 22 * *", Duration: typeutil.NewDuration(time.Hour)},
+		{Cron: "0 22 * * *", Duration: typeutil.NewDuration(time.Second)},
+		{Cron: "0 22 * * *", Duration: typeutil.NewDuration(8 * 24 * time.Hour)},
+		{Cron: "0 22 * * *", Duration: typeutil.NewDuration(time.Hour), Limits: map[string]uint64{"max-snapshot-count": 1}},
+	} {
+		re.Error((&Policy{Windows: []*Window{w}}).Adjust())
+	}
+}
+
+func TestManager(t *testing.T) {
+	re := require.New(t)
+	store := storage.NewStorageWithMemoryBackend()
+	manager := NewManager(store)
+	night := time.Date(2022, 10, 17, 23, 0, 0, 0, time.Local)
+	re.NoError(manager.SetPolicy("balance-region-scheduler", &Policy{Windows: []*Window{
+		{Cron: "0 22 * * *", Duration: typeutil.NewDuration(6 * time.Hour), Limits: map[string]uint64{"region-schedule-limit": 64, "merge-schedule-limit": 4}},
+	}}))
+	re.NoError(manager.SetPolicy("merge", &Policy{Windows: []*Window{
+		{Cron: "0 23 * * *", Duration: typeutil.NewDuration(time.Hour), Limits: map[string]uint64{"merge-schedule-limit": 16}},
+	}}))
+	re.Error(manager.SetPolicy("merge", &Policy{Windows: []*Window{{Cron: "0 23 * * *"}}}))
+	// The limits of a window only apply to its owner.
+	re.Equal(map[string]uint64{"merge-schedule-limit": 16}, manager.GetActiveLimits("merge", night))
+	re.Equal(map[string]uint64{"region-schedule-limit": 64, "merge-schedule-limit": 4}, manager.GetActiveLimits("balance-region-scheduler", night.Add(time.Hour)))
+	re.Empty(manager.GetActiveLimits("merge", night.Add(time.Hour)))
+	re.Empty(manager.GetActiveLimits("balance-region-scheduler", night.Add(-2*time.Hour)))
+	re.Empty(manager.GetActiveLimits("replica", night))
+	re.False(manager.GetStatus("merge", night.Add(time.Hour)).Active)
+	re.True(manager.GetStatus("replica", night.Add(time.Hour)).Active)
+
+	// The policies are persisted.
+	manager = NewManager(store)
+	re.NoError(manager.Load())
+	re.Len(manager.GetPolicies(), 2)
+	re.True(manager.GetStatus("merge", night).Active)
+	// Empty windows remove the policy.
+	re.NoError(manager.SetPolicy("merge", &Policy{}))
+	re.NoError(manager.DeletePolicy("balance-region-scheduler"))
+	manager = NewManager(store)
+	re.NoError(manager.Load())
+	re.Empty(manager.GetPolicies())
+}
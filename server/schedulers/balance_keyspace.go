@@ -0,0 +1,634 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/tikv/pd/pkg/apiutil"
+	"github.com/tikv/pd/pkg/slice"
+	"github.com/tikv/pd/pkg/syncutil"
+	"github.com/tikv/pd/server/core"
+	"github.com/tikv/pd/server/keyspace"
+	"github.com/tikv/pd/server/schedule"
+	"github.com/tikv/pd/server/schedule/filter"
+	"github.com/tikv/pd/server/schedule/operator"
+	"github.com/tikv/pd/server/schedule/plan"
+	"github.com/tikv/pd/server/storage/endpoint"
+	"github.com/unrolled/render"
+)
+
+const (
+	// BalanceKeyspaceName is balance keyspace scheduler name.
+	BalanceKeyspaceName = "balance-keyspace-scheduler"
+	// BalanceKeyspaceType is balance keyspace scheduler type.
+	BalanceKeyspaceType = "balance-keyspace"
+
+	defaultKeyspaceToleranceRatio = 0.1
+	// minKeyspaceTolerantCount is the min difference of the counts between the source and the target store,
+	// moving one region or leader reduces the difference by 2, so it avoids moving back and forth.
+	minKeyspaceTolerantCount = 2
+	// balanceKeyspaceRetryLimit is the max count of the regions tried for each pair of stores.
+	balanceKeyspaceRetryLimit = 10
+	// keyspaceStatsRefreshInterval is how long the collected keyspace stats are reused, since
+	// collecting them scans all the regions of the keyspaces.
+	keyspaceStatsRefreshInterval = time.Minute
+)
+
+func init() {
+	schedule.RegisterSliceDecoderBuilder(BalanceKeyspaceType, func(args []string) schedule.ConfigDecoder {
+		return func(v interface{}) error {
+			return nil
+		}
+	})
+
+	schedule.RegisterScheduler(BalanceKeyspaceType, func(opController *schedule.OperatorController, storage endpoint.ConfigStorage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
+		conf := &balanceKeyspaceSchedulerConfig{storage: storage}
+		if err := decoder(conf); err != nil {
+			return nil, err
+		}
+		conf.adjust()
+		return newBalanceKeyspaceScheduler(opController, conf), nil
+	})
+}
+
+type balanceKeyspaceSchedulerConfig struct {
+	mu      syncutil.RWMutex
+	storage endpoint.ConfigStorage
+	// IncludeKeyspaces are the IDs of the keyspaces to be balanced. Empty means all the keyspaces.
+	IncludeKeyspaces []uint32 `json:"include-keyspaces"`
+	// ExcludeKeyspaces are the IDs of the keyspaces not to be balanced.
+	ExcludeKeyspaces []uint32 `json:"exclude-keyspaces"`
+	// KeyspacePriorities is the keyspace ID -> priority. The keyspaces with higher priority are
+	// balanced first, and the priority of the keyspaces not in it is 0.
+	KeyspacePriorities map[uint32]int `json:"keyspace-priorities"`
+	// ToleranceRatio is the ratio of the average count of the keyspace's regions or leaders on
+	// each store. The stores are considered balanced if the difference of the counts is within it.
+	ToleranceRatio float64 `json:"tolerance-ratio"`
+	// version is increased whenever the config is updated.
+	version uint64
+}
+
+func (conf *balanceKeyspaceSchedulerConfig) adjust() {
+	if conf.IncludeKeyspaces == nil {
+		conf.IncludeKeyspaces = make([]uint32, 0)
+	}
+	if conf.ExcludeKeyspaces == nil {
+		conf.ExcludeKeyspaces = make([]uint32, 0)
+	}
+	if conf.KeyspacePriorities == nil {
+		conf.KeyspacePriorities = make(map[uint32]int)
+	}
+	if conf.ToleranceRatio == 0 {
+		conf.ToleranceRatio = defaultKeyspaceToleranceRatio
+	}
+}
+
+func (conf *balanceKeyspaceSchedulerConfig) persist() error {
+	data, err := schedule.EncodeConfig(conf)
+	if err != nil {
+		return err
+	}
+	return conf.storage.SaveScheduleConfig(BalanceKeyspaceName, data)
+}
+
+func (conf *balanceKeyspaceSchedulerConfig) Clone() *balanceKeyspaceSchedulerConfig {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	priorities := make(map[uint32]int, len(conf.KeyspacePriorities))
+	for id, priority := range conf.KeyspacePriorities {
+		priorities[id] = priority
+	}
+	return &balanceKeyspaceSchedulerConfig{
+		IncludeKeyspaces:   append(conf.IncludeKeyspaces[:0:0], conf.IncludeKeyspaces...),
+		ExcludeKeyspaces:   append(conf.ExcludeKeyspaces[:0:0], conf.ExcludeKeyspaces...),
+		KeyspacePriorities: priorities,
+		ToleranceRatio:     conf.ToleranceRatio,
+	}
+}
+
+func (conf *balanceKeyspaceSchedulerConfig) getIncludeKeyspaces() []uint32 {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return append(conf.IncludeKeyspaces[:0:0], conf.IncludeKeyspaces...)
+}
+
+func (conf *balanceKeyspaceSchedulerConfig) isKeyspaceIncluded(id uint32) bool {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	if len(conf.IncludeKeyspaces) > 0 && !slice.Contains(conf.IncludeKeyspaces, id) {
+		return false
+	}
+	return !slice.Contains(conf.ExcludeKeyspaces, id)
+}
+
+func (conf *balanceKeyspaceSchedulerConfig) getKeyspacePriority(id uint32) int {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.KeyspacePriorities[id]
+}
+
+func (conf *balanceKeyspaceSchedulerConfig) getVersion() uint64 {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.version
+}
+
+func (conf *balanceKeyspaceSchedulerConfig) getToleranceRatio() float64 {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.ToleranceRatio
+}
+
+func (conf *balanceKeyspaceSchedulerConfig) update(include, exclude *[]uint32, priorities *map[uint32]int, toleranceRatio *float64) (int, string) {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	if toleranceRatio != nil && *toleranceRatio <= 0 {
+		return http.StatusBadRequest, "tolerance-ratio should be positive"
+	}
+	oldInclude, oldExclude := conf.IncludeKeyspaces, conf.ExcludeKeyspaces
+	oldPriorities, oldToleranceRatio := conf.KeyspacePriorities, conf.ToleranceRatio
+	if include != nil {
+		conf.IncludeKeyspaces = append(make([]uint32, 0, len(*include)), *include...)
+	}
+	if exclude != nil {
+		conf.ExcludeKeyspaces = append(make([]uint32, 0, len(*exclude)), *exclude...)
+	}
+	if priorities != nil {
+		conf.KeyspacePriorities = make(map[uint32]int, len(*priorities))
+		for id, priority := range *priorities {
+			conf.KeyspacePriorities[id] = priority
+		}
+	}
+	if toleranceRatio != nil {
+		conf.ToleranceRatio = *toleranceRatio
+	}
+	if err := conf.persist(); err != nil {
+		conf.IncludeKeyspaces, conf.ExcludeKeyspaces = oldInclude, oldExclude
+		conf.KeyspacePriorities, conf.ToleranceRatio = oldPriorities, oldToleranceRatio
+		return http.StatusInternalServerError, err.Error()
+	}
+	conf.version++
+	return http.StatusOK, "success"
+}
+
+type balanceKeyspaceHandler struct {
+	rd     *render.Render
+	config *balanceKeyspaceSchedulerConfig
+}
+
+func newBalanceKeyspaceHandler(config *balanceKeyspaceSchedulerConfig) http.Handler {
+	h := &balanceKeyspaceHandler{
+		config: config,
+		rd:     render.New(render.Options{IndentJSON: true}),
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/config", h.UpdateConfig).Methods(http.MethodPost)
+	router.HandleFunc("/list", h.ListConfig).Methods(http.MethodGet)
+	return router
+}
+
+func (handler *balanceKeyspaceHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		IncludeKeyspaces   *[]uint32       `json:"include-keyspaces"`
+		ExcludeKeyspaces   *[]uint32       `json:"exclude-keyspaces"`
+		KeyspacePriorities *map[uint32]int `json:"keyspace-priorities"`
+		ToleranceRatio     *float64        `json:"tolerance-ratio"`
+	}
+	if err := apiutil.ReadJSONRespondError(handler.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	if input.IncludeKeyspaces == nil && input.ExcludeKeyspaces == nil && input.KeyspacePriorities == nil && input.ToleranceRatio == nil {
+		handler.rd.JSON(w, http.StatusBadRequest, "config item not found")
+		return
+	}
+	httpCode, v := handler.config.update(input.IncludeKeyspaces, input.ExcludeKeyspaces, input.KeyspacePriorities, input.ToleranceRatio)
+	handler.rd.JSON(w, httpCode, v)
+}
+
+func (handler *balanceKeyspaceHandler) ListConfig(w http.ResponseWriter, r *http.Request) {
+	conf := handler.config.Clone()
+	handler.rd.JSON(w, http.StatusOK, conf)
+}
+
+// keyspaceStats is the regions of a keyspace grouped by the stores.
+type keyspaceStats struct {
+	id       uint32
+	priority int
+	// regions is the store ID -> the regions having peers on the store.
+	regions map[uint64][]*core.RegionInfo
+	// leaders is the store ID -> the regions having leaders on the store.
+	leaders map[uint64][]*core.RegionInfo
+	// ops are the operators created since the stats were collected, whose moves
+	// are not reflected in the stats.
+	ops []*operator.Operator
+}
+
+// pendingInfluence returns the influence of the operators created since the stats were
+// collected. The operators which are never added or end without success are ignored.
+func (stats *keyspaceStats) pendingInfluence(cluster schedule.Cluster) operator.OpInfluence {
+	influence := operator.OpInfluence{StoresInfluence: make(map[uint64]*operator.StoreInfluence)}
+	ops := stats.ops[:0]
+	for _, op := range stats.ops {
+		switch status := op.Status(); status {
+		case operator.STARTED, operator.SUCCESS:
+		case operator.CREATED:
+			if op.ElapsedTime() >= operator.OperatorExpireTime {
+				continue
+			}
+		default:
+			continue
+		}
+		ops = append(ops, op)
+		if region := cluster.GetRegion(op.RegionID()); region != nil {
+			op.TotalInfluence(influence, region)
+		}
+	}
+	stats.ops = ops
+	return influence
+}
+
+// storeCounts returns the count of the regions on each store, together with the
+// changes made by the pending operators.
+func storeCounts(regions map[uint64][]*core.RegionInfo, influence operator.OpInfluence, delta func(*operator.StoreInfluence) int64) map[uint64]int {
+	counts := make(map[uint64]int, len(regions))
+	for storeID, rs := range regions {
+		counts[storeID] = len(rs)
+	}
+	for storeID, infl := range influence.StoresInfluence {
+		counts[storeID] += int(delta(infl))
+	}
+	return counts
+}
+
+type balanceKeyspaceScheduler struct {
+	*BaseScheduler
+	conf    *balanceKeyspaceSchedulerConfig
+	handler http.Handler
+	// engineFilter selects the stores which may hold the keyspaces' regions, no matter
+	// whether they are available now, to calculate the average counts.
+	engineFilter  filter.Filter
+	regionFilters []filter.Filter
+	leaderFilters []filter.Filter
+	r             *rand.Rand
+
+	mu syncutil.Mutex
+	// stats are the collected keyspace stats, which are refreshed every
+	// keyspaceStatsRefreshInterval or when the config is updated.
+	stats        []*keyspaceStats
+	statsTime    time.Time
+	statsVersion uint64
+}
+
+// newBalanceKeyspaceScheduler creates a scheduler that balances the regions and leaders of
+// each keyspace between stores. It makes sure that a keyspace's regions are not gathered on
+// a few stores, even if the cluster looks balanced as a whole.
+func newBalanceKeyspaceScheduler(opController *schedule.OperatorController, conf *balanceKeyspaceSchedulerConfig) schedule.Scheduler {
+	engineFilter := filter.NewEngineFilter(BalanceKeyspaceName, filter.NotSpecialEngines)
+	return &balanceKeyspaceScheduler{
+		BaseScheduler: NewBaseScheduler(opController),
+		conf:          conf,
+		handler:       newBalanceKeyspaceHandler(conf),
+		engineFilter:  engineFilter,
+		regionFilters: []filter.Filter{
+			&filter.StoreStateFilter{ActionScope: BalanceKeyspaceName, MoveRegion: true},
+			filter.NewSpecialUseFilter(BalanceKeyspaceName),
+			engineFilter,
+		},
+		leaderFilters: []filter.Filter{
+			&filter.StoreStateFilter{ActionScope: BalanceKeyspaceName, TransferLeader: true},
+			filter.NewSpecialUseFilter(BalanceKeyspaceName),
+			engineFilter,
+		},
+		r: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (s *balanceKeyspaceScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *balanceKeyspaceScheduler) GetName() string {
+	return BalanceKeyspaceName
+}
+
+func (s *balanceKeyspaceScheduler) GetType() string {
+	return BalanceKeyspaceType
+}
+
+func (s *balanceKeyspaceScheduler) EncodeConfig() ([]byte, error) {
+	s.conf.mu.RLock()
+	defer s.conf.mu.RUnlock()
+	return schedule.EncodeConfig(s.conf)
+}
+
+func (s *balanceKeyspaceScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool {
+	return s.allowBalanceLeader(cluster) || s.allowBalanceRegion(cluster)
+}
+
+func (s *balanceKeyspaceScheduler) allowBalanceLeader(cluster schedule.Cluster) bool {
+	allowed := s.OpController.OperatorCount(operator.OpLeader) < cluster.GetOpts().GetLeaderScheduleLimit()
+	if !allowed {
+		operator.OperatorLimitCounter.WithLabelValues(s.GetType(), operator.OpLeader.String()).Inc()
+	}
+	return allowed
+}
+
+func (s *balanceKeyspaceScheduler) allowBalanceRegion(cluster schedule.Cluster) bool {
+	allowed := s.OpController.OperatorCount(operator.OpRegion) < cluster.GetOpts().GetRegionScheduleLimit()
+	if !allowed {
+		operator.OperatorLimitCounter.WithLabelValues(s.GetType(), operator.OpRegion.String()).Inc()
+	}
+	return allowed
+}
+
+func (s *balanceKeyspaceScheduler) Schedule(cluster schedule.Cluster, dryRun bool) ([]*operator.Operator, []plan.Plan) {
+	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stores := cluster.GetStores()
+	for _, stats := range s.getKeyspaceStats(cluster) {
+		if s.allowBalanceLeader(cluster) {
+			if op := s.balanceLeader(cluster, stores, stats); op != nil {
+				if !dryRun {
+					stats.ops = append(stats.ops, op)
+				}
+				op.Counters = append(op.Counters,
+					schedulerCounter.WithLabelValues(s.GetName(), "new-operator"),
+					schedulerCounter.WithLabelValues(s.GetName(), "new-leader-operator"))
+				return []*operator.Operator{op}, nil
+			}
+		}
+		if s.allowBalanceRegion(cluster) {
+			if op := s.balanceRegion(cluster, stores, stats); op != nil {
+				if !dryRun {
+					stats.ops = append(stats.ops, op)
+				}
+				op.Counters = append(op.Counters,
+					schedulerCounter.WithLabelValues(s.GetName(), "new-operator"),
+					schedulerCounter.WithLabelValues(s.GetName(), "new-region-operator"))
+				return []*operator.Operator{op}, nil
+			}
+		}
+	}
+	schedulerCounter.WithLabelValues(s.GetName(), "no-need-balance").Inc()
+	return nil, nil
+}
+
+// getKeyspaceStats returns the cached keyspace stats, and collects them again if they are
+// expired or the config has been updated.
+func (s *balanceKeyspaceScheduler) getKeyspaceStats(cluster schedule.Cluster) []*keyspaceStats {
+	version := s.conf.getVersion()
+	if s.stats == nil || s.statsVersion != version || time.Since(s.statsTime) >= keyspaceStatsRefreshInterval {
+		s.stats = s.collectKeyspaceStats(cluster)
+		s.statsTime = time.Now()
+		s.statsVersion = version
+	}
+	return s.stats
+}
+
+// collectKeyspaceStats groups the regions of the keyspaces to be balanced by the stores.
+// The result is sorted by the priority in descending order.
+func (s *balanceKeyspaceScheduler) collectKeyspaceStats(cluster schedule.Cluster) []*keyspaceStats {
+	var bounds []*keyspace.RegionBound
+	if include := s.conf.getIncludeKeyspaces(); len(include) > 0 {
+		for _, id := range include {
+			bounds = append(bounds, keyspace.MakeRegionBound(id))
+		}
+	} else {
+		bounds = append(bounds, keyspace.MakeAllKeyspacesBound())
+	}
+
+	statsMap := make(map[uint32]*keyspaceStats)
+	observe := func(regions []*core.RegionInfo) {
+		for _, region := range regions {
+			id, ok := keyspace.GetRegionKeyspaceID(region.GetStartKey(), region.GetEndKey())
+			if !ok || !s.conf.isKeyspaceIncluded(id) {
+				continue
+			}
+			stats, ok := statsMap[id]
+			if !ok {
+				stats = &keyspaceStats{
+					id:       id,
+					priority: s.conf.getKeyspacePriority(id),
+					regions:  make(map[uint64][]*core.RegionInfo),
+					leaders:  make(map[uint64][]*core.RegionInfo),
+				}
+				statsMap[id] = stats
+			}
+			for _, peer := range region.GetPeers() {
+				stats.regions[peer.GetStoreId()] = append(stats.regions[peer.GetStoreId()], region)
+			}
+			if leader := region.GetLeader(); leader != nil {
+				stats.leaders[leader.GetStoreId()] = append(stats.leaders[leader.GetStoreId()], region)
+			}
+		}
+	}
+	for _, bound := range bounds {
+		observe(cluster.ScanRegions(bound.RawLeftBound, bound.RawRightBound, -1))
+		observe(cluster.ScanRegions(bound.TxnLeftBound, bound.TxnRightBound, -1))
+	}
+
+	ret := make([]*keyspaceStats, 0, len(statsMap))
+	for _, stats := range statsMap {
+		ret = append(ret, stats)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].priority != ret[j].priority {
+			return ret[i].priority > ret[j].priority
+		}
+		return ret[i].id < ret[j].id
+	})
+	return ret
+}
+
+// getTolerantCount returns the max difference of the counts between the stores,
+// within which the stores are considered balanced.
+func (s *balanceKeyspaceScheduler) getTolerantCount(counts map[uint64]int, stores []*core.StoreInfo) int {
+	if len(stores) == 0 {
+		return minKeyspaceTolerantCount
+	}
+	total := 0
+	for _, store := range stores {
+		total += counts[store.GetID()]
+	}
+	tolerantCount := int(math.Ceil(float64(total) / float64(len(stores)) * s.conf.getToleranceRatio()))
+	if tolerantCount < minKeyspaceTolerantCount {
+		return minKeyspaceTolerantCount
+	}
+	return tolerantCount
+}
+
+// sortStoresByCount sorts the stores by the count of the regions on them.
+func sortStoresByCount(stores []*core.StoreInfo, counts map[uint64]int, desc bool) {
+	sort.SliceStable(stores, func(i, j int) bool {
+		ci, cj := counts[stores[i].GetID()], counts[stores[j].GetID()]
+		if desc {
+			return ci > cj
+		}
+		return ci < cj
+	})
+}
+
+// selectRegions returns at most balanceKeyspaceRetryLimit regions randomly.
+func (s *balanceKeyspaceScheduler) selectRegions(regions []*core.RegionInfo) []*core.RegionInfo {
+	if len(regions) <= balanceKeyspaceRetryLimit {
+		return regions
+	}
+	ret := make([]*core.RegionInfo, 0, balanceKeyspaceRetryLimit)
+	for _, i := range s.r.Perm(len(regions))[:balanceKeyspaceRetryLimit] {
+		ret = append(ret, regions[i])
+	}
+	return ret
+}
+
+// worsensGlobalBalance checks whether the target store would have a higher global score than
+// the source store beyond the global tolerance after the operator finishes.
+func (s *balanceKeyspaceScheduler) worsensGlobalBalance(cluster schedule.Cluster, kind core.ScheduleKind, op *operator.Operator, region *core.RegionInfo, source, target *core.StoreInfo) bool {
+	opInfluence := s.OpController.GetOpInfluence(cluster)
+	op.TotalInfluence(opInfluence, region)
+	solver := newSolver(NewBalanceSchedulerPlan(), kind, cluster, opInfluence)
+	tolerantResource := solver.getTolerantResource()
+	sourceDelta := solver.GetOpInfluence(source.GetID()) + tolerantResource
+	targetDelta := solver.GetOpInfluence(target.GetID())
+	var sourceScore, targetScore float64
+	switch kind.Resource {
+	case core.LeaderKind:
+		sourceScore = solver.leaderScore(source, sourceDelta)
+		targetScore = solver.leaderScore(target, targetDelta)
+	case core.RegionKind:
+		opts := cluster.GetOpts()
+		sourceScore = source.RegionScore(opts.GetRegionScoreFormulaVersion(), opts.GetHighSpaceRatio(), opts.GetLowSpaceRatio(), sourceDelta)
+		targetScore = target.RegionScore(opts.GetRegionScoreFormulaVersion(), opts.GetHighSpaceRatio(), opts.GetLowSpaceRatio(), targetDelta)
+	}
+	return targetScore > sourceScore
+}
+
+func (s *balanceKeyspaceScheduler) balanceLeader(cluster schedule.Cluster, stores []*core.StoreInfo, stats *keyspaceStats) *operator.Operator {
+	opts := cluster.GetOpts()
+	sources := filter.SelectSourceStores(stores, s.leaderFilters, opts, nil, nil)
+	targets := filter.SelectTargetStores(stores, s.leaderFilters, opts, nil, nil)
+	counts := storeCounts(stats.leaders, stats.pendingInfluence(cluster), func(infl *operator.StoreInfluence) int64 { return infl.LeaderCount })
+	tolerantCount := s.getTolerantCount(counts, filter.SelectTargetStores(stores, []filter.Filter{s.engineFilter}, opts, nil, nil))
+	kind := core.NewScheduleKind(core.LeaderKind, opts.GetLeaderSchedulePolicy())
+	sortStoresByCount(sources, counts, true)
+	sortStoresByCount(targets, counts, false)
+
+	pendingFilter := filter.NewRegionPendingFilter()
+	downFilter := filter.NewRegionDownFilter()
+	for _, source := range sources {
+		for _, target := range targets {
+			if counts[source.GetID()]-counts[target.GetID()] < tolerantCount {
+				break
+			}
+			for _, cached := range s.selectRegions(stats.leaders[source.GetID()]) {
+				// The cached region may be out of date, so check the latest one.
+				region := cluster.GetRegion(cached.GetID())
+				if region == nil || region.GetLeader().GetStoreId() != source.GetID() || s.OpController.GetOperator(region.GetID()) != nil {
+					continue
+				}
+				if region.GetStoreVoter(target.GetID()) == nil || !filter.IsRegionHealthyAllowPending(region) {
+					continue
+				}
+				if filter.SelectOneRegion([]*core.RegionInfo{region}, nil, pendingFilter, downFilter) == nil {
+					continue
+				}
+				finalFilters := s.leaderFilters
+				if leaderFilter := filter.NewPlacementLeaderSafeguard(s.GetName(), opts, cluster.GetBasicCluster(), cluster.GetRuleManager(), region, source, false /*allowMoveLeader*/); leaderFilter != nil {
+					finalFilters = append(s.leaderFilters[:len(s.leaderFilters):len(s.leaderFilters)], leaderFilter)
+				}
+				if !filter.Target(opts, target, finalFilters) {
+					continue
+				}
+				op, err := operator.CreateTransferLeaderOperator(fmt.Sprintf("balance-keyspace-leader-%d", stats.id), cluster, region,
+					source.GetID(), target.GetID(), []uint64{}, operator.OpLeader)
+				if err != nil {
+					schedulerCounter.WithLabelValues(s.GetName(), "create-operator-fail").Inc()
+					continue
+				}
+				if s.worsensGlobalBalance(cluster, kind, op, region, source, target) {
+					schedulerCounter.WithLabelValues(s.GetName(), "global-unbalance").Inc()
+					continue
+				}
+				return op
+			}
+		}
+	}
+	schedulerCounter.WithLabelValues(s.GetName(), "no-need-balance-leader").Inc()
+	return nil
+}
+
+func (s *balanceKeyspaceScheduler) balanceRegion(cluster schedule.Cluster, stores []*core.StoreInfo, stats *keyspaceStats) *operator.Operator {
+	opts := cluster.GetOpts()
+	sources := filter.SelectSourceStores(stores, s.regionFilters, opts, nil, nil)
+	targets := filter.SelectTargetStores(stores, s.regionFilters, opts, nil, nil)
+	counts := storeCounts(stats.regions, stats.pendingInfluence(cluster), func(infl *operator.StoreInfluence) int64 { return infl.RegionCount })
+	tolerantCount := s.getTolerantCount(counts, filter.SelectTargetStores(stores, []filter.Filter{s.engineFilter}, opts, nil, nil))
+	kind := core.NewScheduleKind(core.RegionKind, core.BySize)
+	sortStoresByCount(sources, counts, true)
+	sortStoresByCount(targets, counts, false)
+
+	pendingFilter := filter.NewRegionPendingFilter()
+	downFilter := filter.NewRegionDownFilter()
+	replicaFilter := filter.NewRegionReplicatedFilter(cluster)
+	for _, source := range sources {
+		for _, target := range targets {
+			if counts[source.GetID()]-counts[target.GetID()] < tolerantCount {
+				break
+			}
+			for _, cached := range s.selectRegions(stats.regions[source.GetID()]) {
+				// The cached region may be out of date, so check the latest one.
+				region := cluster.GetRegion(cached.GetID())
+				if region == nil || region.GetStorePeer(source.GetID()) == nil || s.OpController.GetOperator(region.GetID()) != nil {
+					continue
+				}
+				if region.GetStorePeer(target.GetID()) != nil {
+					continue
+				}
+				if filter.SelectOneRegion([]*core.RegionInfo{region}, nil, pendingFilter, downFilter, replicaFilter) == nil {
+					continue
+				}
+				finalFilters := append(s.regionFilters[:len(s.regionFilters):len(s.regionFilters)],
+					filter.NewExcludedFilter(s.GetName(), nil, region.GetStoreIDs()),
+					filter.NewPlacementSafeguard(s.GetName(), opts, cluster.GetBasicCluster(), cluster.GetRuleManager(), region, source, nil),
+				)
+				if !filter.Target(opts, target, finalFilters) {
+					continue
+				}
+				oldPeer := region.GetStorePeer(source.GetID())
+				op, err := operator.CreateMovePeerOperator(fmt.Sprintf("balance-keyspace-region-%d", stats.id), cluster, region, operator.OpRegion,
+					source.GetID(), &metapb.Peer{StoreId: target.GetID(), Role: oldPeer.GetRole()})
+				if err != nil {
+					schedulerCounter.WithLabelValues(s.GetName(), "create-operator-fail").Inc()
+					continue
+				}
+				if s.worsensGlobalBalance(cluster, kind, op, region, source, target) {
+					schedulerCounter.WithLabelValues(s.GetName(), "global-unbalance").Inc()
+					continue
+				}
+				return op
+			}
+		}
+	}
+	schedulerCounter.WithLabelValues(s.GetName(), "no-need-balance-region").Inc()
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/codec"
+	"github.com/tikv/pd/pkg/mock/mockcluster"
+	"github.com/tikv/pd/server/config"
+	"github.com/tikv/pd/server/schedule"
+	"github.com/tikv/pd/server/schedule/operator"
+	"github.com/tikv/pd/server/storage"
+)
+
+func keyspaceRegionKey(spaceID uint32, suffix byte) string {
+	return string(codec.EncodeBytes([]byte{'x', byte(spaceID >> 16), byte(spaceID >> 8), byte(spaceID), suffix}))
+}
+
+func TestBalanceKeyspace(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opt := config.NewTestOptions()
+	tc := mockcluster.NewCluster(ctx, opt)
+	tc.SetPlacementRuleEnabled(false)
+	for i := uint64(1); i <= 4; i++ {
+		tc.AddRegionStore(i, 0)
+	}
+	// The leaders of keyspace 1 are all on store 1.
+	for i := uint64(1); i <= 6; i++ {
+		tc.AddLeaderRegionWithRange(i, keyspaceRegionKey(1, byte(i)), keyspaceRegionKey(1, byte(i+1)), 1, 2, 3)
+	}
+	// The leaders of keyspace 2 are balanced, but it has no regions on store 4.
+	for i := uint64(1); i <= 6; i++ {
+		tc.AddLeaderRegionWithRange(10+i, keyspaceRegionKey(2, byte(i)), keyspaceRegionKey(2, byte(i+1)), i%3+1, (i+1)%3+1, (i+2)%3+1)
+	}
+
+	storage := storage.NewStorageWithMemoryBackend()
+	sl, err := schedule.CreateScheduler(BalanceKeyspaceType, schedule.NewOperatorController(ctx, nil, nil), storage, schedule.ConfigSliceDecoder(BalanceKeyspaceType, []string{}))
+	re.NoError(err)
+	re.True(sl.IsScheduleAllowed(tc))
+	ops, _ := sl.Schedule(tc, false)
+	re.Len(ops, 1)
+	re.Equal("balance-keyspace-leader-1", ops[0].Desc())
+	re.Equal(operator.OpLeader, ops[0].Kind()&operator.OpLeader)
+	re.Equal(uint64(1), ops[0].Step(0).(operator.TransferLeader).FromStore)
+
+	// The keyspaces with higher priority are balanced first.
+	conf := sl.(*balanceKeyspaceScheduler).conf
+	priorities := map[uint32]int{2: 1}
+	_, msg := conf.update(nil, nil, &priorities, nil)
+	re.Equal("success", msg)
+	ops, _ = sl.Schedule(tc, false)
+	re.Len(ops, 1)
+	re.Equal("balance-keyspace-region-2", ops[0].Desc())
+	re.Equal(uint64(4), ops[0].Step(0).(operator.AddLearner).ToStore)
+
+	// The excluded keyspaces are not balanced.
+	exclude := []uint32{2}
+	_, msg = conf.update(nil, &exclude, nil, nil)
+	re.Equal("success", msg)
+	ops, _ = sl.Schedule(tc, false)
+	re.Len(ops, 1)
+	re.Equal("balance-keyspace-leader-1", ops[0].Desc())
+
+	// Only the included keyspaces are balanced.
+	include := []uint32{3}
+	_, msg = conf.update(&include, &[]uint32{}, nil, nil)
+	re.Equal("success", msg)
+	ops, _ = sl.Schedule(tc, false)
+	re.Empty(ops)
+
+	// The differences within the tolerance are not balanced.
+	include, ratio := []uint32{1}, 5.0
+	_, msg = conf.update(&include, nil, nil, &ratio)
+	re.Equal("success", msg)
+	ops, _ = sl.Schedule(tc, false)
+	re.Empty(ops)
+	ratio = -1
+	_, msg = conf.update(nil, nil, nil, &ratio)
+	re.NotEqual("success", msg)
+
+	// The config is persisted.
+	names, data, err := storage.LoadAllScheduleConfig()
+	re.NoError(err)
+	re.Equal([]string{BalanceKeyspaceName}, names)
+	sl, err = schedule.CreateScheduler(BalanceKeyspaceType, schedule.NewOperatorController(ctx, nil, nil), storage, schedule.ConfigJSONDecoder([]byte(data[0])))
+	re.NoError(err)
+	newConf := sl.(*balanceKeyspaceScheduler).conf.Clone()
+	re.Equal([]uint32{1}, newConf.IncludeKeyspaces)
+	re.Empty(newConf.ExcludeKeyspaces)
+	re.Equal(priorities, newConf.KeyspacePriorities)
+	re.Equal(5.0, newConf.ToleranceRatio)
+}
+
+func TestBalanceKeyspaceGlobalBalance(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opt := config.NewTestOptions()
+	tc := mockcluster.NewCluster(ctx, opt)
+	tc.SetPlacementRuleEnabled(false)
+	for i := uint64(1); i <= 3; i++ {
+		tc.AddRegionStore(i, 0)
+	}
+	// The leaders of keyspace 1 are all on store 1, and the followers are all on store 2.
+	for i := uint64(1); i <= 6; i++ {
+		tc.AddLeaderRegionWithRange(i, keyspaceRegionKey(1, byte(i)), keyspaceRegionKey(1, byte(i+1)), 1, 2)
+	}
+
+	sl, err := schedule.CreateScheduler(BalanceKeyspaceType, schedule.NewOperatorController(ctx, nil, nil), storage.NewStorageWithMemoryBackend(), schedule.ConfigSliceDecoder(BalanceKeyspaceType, []string{}))
+	re.NoError(err)
+	// Store 2 holds much more leaders than store 1 in the whole cluster.
+	tc.UpdateLeaderCount(1, 10)
+	tc.UpdateLeaderCount(2, 30)
+	ops, _ := sl.Schedule(tc, false)
+	for _, op := range ops {
+		re.NotEqual(operator.OpLeader, op.Kind()&operator.OpLeader)
+	}
+
+	tc.UpdateLeaderCount(2, 10)
+	ops, _ = sl.Schedule(tc, false)
+	re.Len(ops, 1)
+	re.Equal("balance-keyspace-leader-1", ops[0].Desc())
+	re.Equal(uint64(2), ops[0].Step(0).(operator.TransferLeader).ToStore)
+}
+
+func TestBalanceKeyspaceStatsCache(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opt := config.NewTestOptions()
+	tc := mockcluster.NewCluster(ctx, opt)
+	tc.SetPlacementRuleEnabled(false)
+	for i := uint64(1); i <= 3; i++ {
+		tc.AddRegionStore(i, 0)
+	}
+	for i := uint64(1); i <= 6; i++ {
+		tc.AddLeaderRegionWithRange(i, keyspaceRegionKey(1, byte(i)), keyspaceRegionKey(1, byte(i+1)), 1, 2, 3)
+	}
+
+	sl, err := schedule.CreateScheduler(BalanceKeyspaceType, schedule.NewOperatorController(ctx, nil, nil), storage.NewStorageWithMemoryBackend(), schedule.ConfigSliceDecoder(BalanceKeyspaceType, []string{}))
+	re.NoError(err)
+	s := sl.(*balanceKeyspaceScheduler)
+	ops, _ := sl.Schedule(tc, true)
+	re.Len(ops, 1)
+	re.Len(s.stats, 1)
+	re.Len(s.stats[0].leaders[1], 6)
+
+	re.Empty(s.stats[0].ops)
+
+	// The cached stats are not changed by the created operators, which are counted
+	// until they end without success.
+	ops, _ = sl.Schedule(tc, false)
+	re.Len(ops, 1)
+	re.Len(s.stats[0].leaders[1], 6)
+	re.Len(s.stats[0].ops, 1)
+	influence := s.stats[0].pendingInfluence(tc)
+	re.Equal(int64(-1), influence.GetStoreInfluence(1).LeaderCount)
+	ops[0].Cancel()
+	re.Empty(s.stats[0].pendingInfluence(tc).StoresInfluence)
+	re.Empty(s.stats[0].ops)
+
+	// The regions are checked again since the cached ones may be out of date.
+	for i := uint64(1); i <= 6; i++ {
+		tc.AddLeaderRegionWithRange(i, keyspaceRegionKey(1, byte(i)), keyspaceRegionKey(1, byte(i+1)), 2, 1, 3)
+	}
+	ops, _ = sl.Schedule(tc, false)
+	re.Empty(ops)
+	re.Len(s.stats[0].leaders[1], 6)
+
+	// The new regions are not collected until the stats expire.
+	tc.AddLeaderRegionWithRange(7, keyspaceRegionKey(2, 1), keyspaceRegionKey(2, 2), 1, 2, 3)
+	sl.Schedule(tc, false)
+	re.Len(s.stats, 1)
+	s.statsTime = s.statsTime.Add(-keyspaceStatsRefreshInterval)
+	sl.Schedule(tc, false)
+	re.Len(s.stats, 2)
+	re.Equal(uint32(2), s.stats[1].id)
+
+	// The stats are collected again once the config is updated.
+	exclude := []uint32{2}
+	_, msg := s.conf.update(nil, &exclude, nil, nil)
+	re.Equal("success", msg)
+	sl.Schedule(tc, false)
+	re.Len(s.stats, 1)
+}
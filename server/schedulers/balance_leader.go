@@ -52,6 +52,9 @@ const (
 	BalanceLeaderBatchSize = 4
 	// MaxBalanceLeaderBatchSize is maximum of balance leader batch size
 	MaxBalanceLeaderBatchSize = 10
+	// minLeaderLoadRatio is the ratio of the average leader load, the leaders with lower load are
+	// not transferred under the load-based leader schedule policies.
+	minLeaderLoadRatio = 0.1
 
 	transferIn  = "transfer-in"
 	transferOut = "transfer-out"
@@ -363,7 +366,7 @@ func (l *balanceLeaderScheduler) Schedule(cluster schedule.Cluster, dryRun bool)
 
 	stores := cluster.GetStores()
 	scoreFunc := func(store *core.StoreInfo) float64 {
-		return store.LeaderScore(solver.kind.Policy, solver.GetOpInfluence(store.GetID()))
+		return solver.leaderScore(store, solver.GetOpInfluence(store.GetID()))
 	}
 	sourceCandidate := newCandidateStores(filter.SelectSourceStores(stores, l.filters, cluster.GetOpts(), collector, l.filterCounter), false, scoreFunc)
 	targetCandidate := newCandidateStores(filter.SelectTargetStores(stores, l.filters, cluster.GetOpts(), nil, l.filterCounter), true, scoreFunc)
@@ -448,11 +451,28 @@ func makeInfluence(op *operator.Operator, plan *solver, usedRegions map[uint64]s
 	}
 }
 
+// filterLowLoadRegions drops the regions whose leader load is too low under the load-based
+// leader schedule policies. Transferring them hardly narrows the load gap between the stores,
+// but skews the leader counts.
+func filterLowLoadRegions(solver *solver, regions []*core.RegionInfo) []*core.RegionInfo {
+	if !solver.kind.Policy.IsLoadPolicy() {
+		return regions
+	}
+	minLoad := int64(solver.averageLeaderLoad() * minLeaderLoadRatio)
+	ret := make([]*core.RegionInfo, 0, len(regions))
+	for _, region := range regions {
+		if load := region.GetLeaderLoadRate(solver.kind.Policy); load > 0 && load >= minLoad {
+			ret = append(ret, region)
+		}
+	}
+	return ret
+}
+
 // transferLeaderOut transfers leader from the source store.
 // It randomly selects a health region from the source store, then picks
 // the best follower peer and transfers the leader.
 func (l *balanceLeaderScheduler) transferLeaderOut(solver *solver, collector *plan.Collector) *operator.Operator {
-	solver.region = filter.SelectOneRegion(solver.RandLeaderRegions(solver.SourceStoreID(), l.conf.Ranges),
+	solver.region = filter.SelectOneRegion(filterLowLoadRegions(solver, solver.RandLeaderRegions(solver.SourceStoreID(), l.conf.Ranges)),
 		collector, filter.NewRegionPendingFilter(), filter.NewRegionDownFilter())
 	if solver.region == nil {
 		log.Debug("store has no leader", zap.String("scheduler", l.GetName()), zap.Uint64("store-id", solver.SourceStoreID()))
@@ -476,11 +496,10 @@ func (l *balanceLeaderScheduler) transferLeaderOut(solver *solver, collector *pl
 		finalFilters = append(l.filters, leaderFilter)
 	}
 	targets = filter.SelectTargetStores(targets, finalFilters, opts, collector, l.filterCounter)
-	leaderSchedulePolicy := opts.GetLeaderSchedulePolicy()
 	sort.Slice(targets, func(i, j int) bool {
 		iOp := solver.GetOpInfluence(targets[i].GetID())
 		jOp := solver.GetOpInfluence(targets[j].GetID())
-		return targets[i].LeaderScore(leaderSchedulePolicy, iOp) < targets[j].LeaderScore(leaderSchedulePolicy, jOp)
+		return solver.leaderScore(targets[i], iOp) < solver.leaderScore(targets[j], jOp)
 	})
 	for _, solver.target = range targets {
 		if op := l.createOperator(solver, collector); op != nil {
@@ -496,7 +515,7 @@ func (l *balanceLeaderScheduler) transferLeaderOut(solver *solver, collector *pl
 // It randomly selects a health region from the target store, then picks
 // the worst follower peer and transfers the leader.
 func (l *balanceLeaderScheduler) transferLeaderIn(solver *solver, collector *plan.Collector) *operator.Operator {
-	solver.region = filter.SelectOneRegion(solver.RandFollowerRegions(solver.TargetStoreID(), l.conf.Ranges),
+	solver.region = filter.SelectOneRegion(filterLowLoadRegions(solver, solver.RandFollowerRegions(solver.TargetStoreID(), l.conf.Ranges)),
 		nil, filter.NewRegionPendingFilter(), filter.NewRegionDownFilter())
 	if solver.region == nil {
 		log.Debug("store has no follower", zap.String("scheduler", l.GetName()), zap.Uint64("store-id", solver.TargetStoreID()))
@@ -114,6 +114,43 @@ func (p *balanceSchedulerPlan) Clone(opts ...plan.Option) plan.Plan {
 
 // BalancePlanSummary is used to summarize for BalancePlan
 func BalancePlanSummary(plans []plan.Plan) (map[uint64]plan.Status, bool, error) {
+	return summarizeBalanceSchedulerPlans(plans, func(p *balanceSchedulerPlan, step int) uint64 {
+		// `step == pickRegion` is a special processing in summary, because we want to exclude the factor of region
+		// and consider the failure as the status of source store.
+		if step == pickRegion {
+			return p.source.GetID()
+		}
+		return p.GetResource(step)
+	})
+}
+
+// HotRegionPlanSummary is used to summarize the plans of hot-region scheduler.
+// All failures are regarded as the status of the hot source store, because it
+// explains why the load of the store cannot be reduced.
+func HotRegionPlanSummary(plans []plan.Plan) (map[uint64]plan.Status, bool, error) {
+	return summarizeBalanceSchedulerPlans(plans, sourceStoreOfPlan)
+}
+
+// EvictLeaderPlanSummary is used to summarize the plans of evict-leader scheduler.
+// All failures are regarded as the status of the store whose leaders are evicted.
+func EvictLeaderPlanSummary(plans []plan.Plan) (map[uint64]plan.Status, bool, error) {
+	return summarizeBalanceSchedulerPlans(plans, sourceStoreOfPlan)
+}
+
+// GrantLeaderPlanSummary is used to summarize the plans of grant-leader scheduler.
+// The granted store is recorded as the source of the plan, so all failures are
+// regarded as the status of the store which leaders are granted to.
+func GrantLeaderPlanSummary(plans []plan.Plan) (map[uint64]plan.Status, bool, error) {
+	return summarizeBalanceSchedulerPlans(plans, sourceStoreOfPlan)
+}
+
+func sourceStoreOfPlan(p *balanceSchedulerPlan, _ int) uint64 {
+	return p.source.GetID()
+}
+
+// summarizeBalanceSchedulerPlans summarizes the plans by the store which `storeOf` attributes the plan to.
+// Only the plans reaching the furthest step of a store are considered.
+func summarizeBalanceSchedulerPlans(plans []plan.Plan, storeOf func(p *balanceSchedulerPlan, step int) uint64) (map[uint64]plan.Status, bool, error) {
 	// storeStatusCounter is used to count the number of various statuses of each store
 	storeStatusCounter := make(map[uint64]map[plan.Status]int)
 	// statusCounter is used to count the number of status which is regarded as best status of each store
@@ -130,14 +167,7 @@ func BalancePlanSummary(plans []plan.Plan) (map[uint64]plan.Status, bool, error)
 		if step > pickTarget {
 			step = pickTarget
 		}
-		var store uint64
-		// `step == pickRegion` is a special processing in summary, because we want to exclude the factor of region
-		// and consider the failure as the status of source store.
-		if step == pickRegion {
-			store = p.source.GetID()
-		} else {
-			store = p.GetResource(step)
-		}
+		store := storeOf(p, step)
 		maxStep, ok := storeMaxStep[store]
 		if !ok {
 			maxStep = -1
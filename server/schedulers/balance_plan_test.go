@@ -270,3 +270,22 @@ func (suite *balanceSchedulerPlanAnalyzeTestSuite) TestAnalyzerResult6() {
 			5: plan.NewStatus(plan.StatusStoreDown),
 		}))
 }
+
+func (suite *balanceSchedulerPlanAnalyzeTestSuite) TestHotRegionPlanSummary() {
+	plans := make([]plan.Plan, 0)
+	plans = append(plans, &balanceSchedulerPlan{source: suite.stores[4], step: 0, status: plan.NewStatus(plan.StatusStoreScoreDisallowed)})
+	plans = append(plans, &balanceSchedulerPlan{source: suite.stores[3], step: 0, status: plan.NewStatus(plan.StatusStoreScoreDisallowed)})
+	plans = append(plans, &balanceSchedulerPlan{source: suite.stores[0], region: suite.regions[0], step: 2, target: suite.stores[1], status: plan.NewStatus(plan.StatusStoreScoreDisallowed)})
+	plans = append(plans, &balanceSchedulerPlan{source: suite.stores[0], region: suite.regions[0], step: 2, target: suite.stores[2], status: plan.NewStatus(plan.StatusStoreDown)})
+	statuses, isNormal, err := HotRegionPlanSummary(plans)
+	suite.NoError(err)
+	suite.False(isNormal)
+	// The failures of targets are regarded as the status of the hot source store.
+	suite.Len(statuses, 3)
+	suite.True(suite.check(statuses,
+		map[uint64]*plan.Status{
+			1: plan.NewStatus(plan.StatusStoreDown),
+			4: plan.NewStatus(plan.StatusStoreScoreDisallowed),
+			5: plan.NewStatus(plan.StatusStoreScoreDisallowed),
+		}))
+}
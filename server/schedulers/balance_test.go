@@ -33,6 +33,7 @@ import (
 	"github.com/tikv/pd/server/schedule/hbstream"
 	"github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/schedule/plan"
+	"github.com/tikv/pd/server/statistics"
 	"github.com/tikv/pd/server/storage"
 	"github.com/tikv/pd/server/versioninfo"
 )
@@ -331,6 +332,40 @@ func (suite *balanceLeaderSchedulerTestSuite) TestBalanceLeaderSchedulePolicy()
 	suite.NotEmpty(suite.schedule())
 }
 
+func (suite *balanceLeaderSchedulerTestSuite) TestBalanceLeaderLoadPolicy() {
+	// Stores:          1       2       3       4
+	// Leader Count:    10      10      10      10
+	// Read Query:      1000    100     100     100
+	// Region1:         L       F       F       F
+	for storeID, query := range map[uint64]uint64{1: 1000, 2: 100, 3: 100, 4: 100} {
+		suite.tc.AddLeaderStore(storeID, 10)
+		suite.tc.UpdateStorageReadQuery(storeID, query*statistics.StoreHeartBeatReportInterval)
+	}
+	suite.tc.AddLeaderRegion(1, 1, 2, 3, 4)
+	suite.Empty(suite.schedule())
+	suite.tc.SetLeaderSchedulePolicy(core.ByWriteQuery.String())
+	suite.Empty(suite.schedule())
+	// The leaders without load are not transferred.
+	suite.tc.SetLeaderSchedulePolicy(core.ByReadQuery.String())
+	suite.Empty(suite.schedule())
+	suite.tc.AddRegionWithReadInfo(1, 1, 0, 0, 100*statistics.RegionHeartBeatReportInterval,
+		statistics.RegionHeartBeatReportInterval, []uint64{2, 3, 4}, 0)
+	ops := suite.schedule()
+	suite.NotEmpty(ops)
+	suite.Equal(uint64(1), ops[0].Step(0).(operator.TransferLeader).FromStore)
+
+	// Stores:          1       2       3       4
+	// Read Query:      310     300     300     290
+	for storeID, query := range map[uint64]uint64{1: 310, 2: 300, 3: 300, 4: 290} {
+		suite.tc.UpdateStorageReadQuery(storeID, query*statistics.StoreHeartBeatReportInterval)
+	}
+	suite.Empty(suite.schedule())
+	plans := suite.dryRun()
+	suite.NotEmpty(plans)
+	suite.Equal(3, plans[0].GetStep())
+	suite.Equal(plan.StatusStoreScoreDisallowed, int(plans[0].GetStatus().StatusCode))
+}
+
 func (suite *balanceLeaderSchedulerTestSuite) TestBalanceLeaderTolerantRatio() {
 	suite.tc.SetTolerantSizeRatio(2.5)
 	// test schedule leader by count, with tolerantSizeRatio=2.5
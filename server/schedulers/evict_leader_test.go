@@ -28,6 +28,7 @@ import (
 	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/schedule"
 	"github.com/tikv/pd/server/schedule/operator"
+	"github.com/tikv/pd/server/schedule/plan"
 	"github.com/tikv/pd/server/storage"
 )
 
@@ -57,6 +58,38 @@ func TestEvictLeader(t *testing.T) {
 	re.True(ops[0].Step(0).(operator.TransferLeader).IsFinish(tc.MockRegionInfo(1, 2, []uint64{1, 3}, []uint64{}, &metapb.RegionEpoch{ConfVer: 0, Version: 0})))
 }
 
+func TestEvictLeaderDiagnosis(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opt := config.NewTestOptions()
+	tc := mockcluster.NewCluster(ctx, opt)
+	tc.AddLeaderStore(1, 0)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	tc.AddLeaderRegion(1, 1, 2, 3)
+	tc.SetStoreDown(2)
+	tc.SetStoreDown(3)
+
+	sl, err := schedule.CreateScheduler(EvictLeaderType, schedule.NewOperatorController(ctx, nil, nil), storage.NewStorageWithMemoryBackend(), schedule.ConfigSliceDecoder(EvictLeaderType, []string{"1"}))
+	re.NoError(err)
+	ops, plans := sl.Schedule(tc, true)
+	re.Empty(ops)
+	statuses, normal, err := EvictLeaderPlanSummary(plans)
+	re.NoError(err)
+	re.False(normal)
+	// The failures of targets are regarded as the status of the evicted store.
+	re.Len(statuses, 1)
+	re.Equal(*plan.NewStatus(plan.StatusStoreDown), statuses[1])
+
+	// No plans are collected without diagnosis.
+	tc.SetStoreUp(2)
+	ops, plans = sl.Schedule(tc, false)
+	re.Len(ops, 1)
+	re.Empty(plans)
+}
+
 func TestEvictLeaderWithUnhealthyPeer(t *testing.T) {
 	re := require.New(t)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -15,14 +15,25 @@
 package schedulers
 
 import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/apiutil"
+	"github.com/tikv/pd/pkg/movingaverage"
+	"github.com/tikv/pd/pkg/slice"
+	"github.com/tikv/pd/pkg/syncutil"
+	"github.com/tikv/pd/pkg/typeutil"
 	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/schedule"
 	"github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/schedule/plan"
 	"github.com/tikv/pd/server/storage/endpoint"
+	"github.com/unrolled/render"
 	"go.uber.org/zap"
 )
 
@@ -34,6 +45,13 @@ const (
 
 	slowStoreEvictThreshold   = 100
 	slowStoreRecoverThreshold = 1
+	// slowStoreTrendThreshold is the slow score above which a store is evicted if its slow score keeps rising.
+	slowStoreTrendThreshold = 80
+	// slowTrendWindowSize is the number of store heartbeats used to smooth the slow score and its slope.
+	slowTrendWindowSize = 5
+
+	defaultMaxEvictedSlowStores     = 1
+	defaultSlowStoreRecoverDuration = 10 * time.Minute
 )
 
 func init() {
@@ -48,13 +66,35 @@ func init() {
 		if err := decoder(conf); err != nil {
 			return nil, err
 		}
+		conf.adjust()
 		return newEvictSlowStoreScheduler(opController, conf), nil
 	})
 }
 
 type evictSlowStoreSchedulerConfig struct {
+	mu            syncutil.RWMutex
 	storage       endpoint.ConfigStorage
 	EvictedStores []uint64 `json:"evict-stores"`
+	// EvictedReasons records why the stores are evicted.
+	EvictedReasons map[uint64]string `json:"evict-reasons,omitempty"`
+	// MaxEvictedStores is the max number of stores which can be evicted at the same time. No more
+	// stores are evicted if there are more slow stores than it allows, which usually means a
+	// cluster-wide problem rather than a few bad stores.
+	MaxEvictedStores int `json:"max-evicted-stores"`
+	// RecoverDuration is how long a slow store needs to keep healthy before its leaders are allowed back.
+	RecoverDuration typeutil.Duration `json:"recover-duration"`
+}
+
+func (conf *evictSlowStoreSchedulerConfig) adjust() {
+	if conf.MaxEvictedStores == 0 {
+		conf.MaxEvictedStores = defaultMaxEvictedSlowStores
+	}
+	if conf.RecoverDuration.Duration == 0 {
+		conf.RecoverDuration = typeutil.NewDuration(defaultSlowStoreRecoverDuration)
+	}
+	if conf.EvictedReasons == nil {
+		conf.EvictedReasons = make(map[uint64]string)
+	}
 }
 
 func (conf *evictSlowStoreSchedulerConfig) Persist() error {
@@ -74,40 +114,226 @@ func (conf *evictSlowStoreSchedulerConfig) getSchedulerName() string {
 }
 
 func (conf *evictSlowStoreSchedulerConfig) getStores() []uint64 {
-	return conf.EvictedStores
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	stores := make([]uint64, len(conf.EvictedStores))
+	copy(stores, conf.EvictedStores)
+	return stores
 }
 
 func (conf *evictSlowStoreSchedulerConfig) getKeyRangesByID(id uint64) []core.KeyRange {
-	if conf.evictStore() != id {
+	if !conf.isEvicted(id) {
 		return nil
 	}
 	return []core.KeyRange{core.NewKeyRange("", "")}
 }
 
-func (conf *evictSlowStoreSchedulerConfig) evictStore() uint64 {
-	if len(conf.EvictedStores) == 0 {
-		return 0
+func (conf *evictSlowStoreSchedulerConfig) isEvicted(id uint64) bool {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return slice.Contains(conf.EvictedStores, id)
+}
+
+func (conf *evictSlowStoreSchedulerConfig) getMaxEvictedStores() int {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.MaxEvictedStores
+}
+
+func (conf *evictSlowStoreSchedulerConfig) getRecoverDuration() time.Duration {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.RecoverDuration.Duration
+}
+
+func (conf *evictSlowStoreSchedulerConfig) addStoresAndPersist(reasons map[uint64]string) error {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	oldStores := conf.EvictedStores
+	for id, reason := range reasons {
+		conf.EvictedStores = append(conf.EvictedStores, id)
+		conf.EvictedReasons[id] = reason
+	}
+	if err := conf.Persist(); err != nil {
+		conf.EvictedStores = oldStores
+		for id := range reasons {
+			delete(conf.EvictedReasons, id)
+		}
+		return err
 	}
-	return conf.EvictedStores[0]
+	return nil
 }
 
-func (conf *evictSlowStoreSchedulerConfig) setStoreAndPersist(id uint64) error {
-	conf.EvictedStores = []uint64{id}
+func (conf *evictSlowStoreSchedulerConfig) removeStoresAndPersist(ids []uint64) error {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	stores := make([]uint64, 0, len(conf.EvictedStores))
+	for _, id := range conf.EvictedStores {
+		if !slice.Contains(ids, id) {
+			stores = append(stores, id)
+		}
+	}
+	conf.EvictedStores = stores
+	for _, id := range ids {
+		delete(conf.EvictedReasons, id)
+	}
 	return conf.Persist()
 }
 
-func (conf *evictSlowStoreSchedulerConfig) clearAndPersist() (oldID uint64, err error) {
-	oldID = conf.evictStore()
-	if oldID > 0 {
-		conf.EvictedStores = []uint64{}
-		err = conf.Persist()
+func (conf *evictSlowStoreSchedulerConfig) clearAndPersist() (oldIDs []uint64, err error) {
+	oldIDs = conf.getStores()
+	if len(oldIDs) > 0 {
+		err = conf.removeStoresAndPersist(oldIDs)
 	}
 	return
 }
 
+func (conf *evictSlowStoreSchedulerConfig) update(maxEvictedStores *int, recoverDuration *typeutil.Duration) (int, string) {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	if maxEvictedStores != nil && *maxEvictedStores < 1 {
+		return http.StatusBadRequest, "max-evicted-stores should be a positive integer"
+	}
+	if recoverDuration != nil && recoverDuration.Duration <= 0 {
+		return http.StatusBadRequest, "recover-duration should be positive"
+	}
+	oldMaxEvictedStores, oldRecoverDuration := conf.MaxEvictedStores, conf.RecoverDuration
+	if maxEvictedStores != nil {
+		conf.MaxEvictedStores = *maxEvictedStores
+	}
+	if recoverDuration != nil {
+		conf.RecoverDuration = *recoverDuration
+	}
+	if err := conf.Persist(); err != nil {
+		conf.MaxEvictedStores, conf.RecoverDuration = oldMaxEvictedStores, oldRecoverDuration
+		return http.StatusInternalServerError, err.Error()
+	}
+	return http.StatusOK, "success"
+}
+
+func (conf *evictSlowStoreSchedulerConfig) Clone() *evictSlowStoreSchedulerConfig {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	stores := make([]uint64, len(conf.EvictedStores))
+	copy(stores, conf.EvictedStores)
+	reasons := make(map[uint64]string, len(conf.EvictedReasons))
+	for id, reason := range conf.EvictedReasons {
+		reasons[id] = reason
+	}
+	return &evictSlowStoreSchedulerConfig{
+		EvictedStores:    stores,
+		EvictedReasons:   reasons,
+		MaxEvictedStores: conf.MaxEvictedStores,
+		RecoverDuration:  conf.RecoverDuration,
+	}
+}
+
+type evictSlowStoreHandler struct {
+	rd     *render.Render
+	config *evictSlowStoreSchedulerConfig
+}
+
+func newEvictSlowStoreHandler(config *evictSlowStoreSchedulerConfig) http.Handler {
+	h := &evictSlowStoreHandler{
+		config: config,
+		rd:     render.New(render.Options{IndentJSON: true}),
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/config", h.UpdateConfig).Methods(http.MethodPost)
+	router.HandleFunc("/list", h.ListConfig).Methods(http.MethodGet)
+	return router
+}
+
+func (handler *evictSlowStoreHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		MaxEvictedStores *int               `json:"max-evicted-stores"`
+		RecoverDuration  *typeutil.Duration `json:"recover-duration"`
+	}
+	if err := apiutil.ReadJSONRespondError(handler.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	if input.MaxEvictedStores == nil && input.RecoverDuration == nil {
+		handler.rd.JSON(w, http.StatusBadRequest, "config item not found")
+		return
+	}
+	httpCode, v := handler.config.update(input.MaxEvictedStores, input.RecoverDuration)
+	handler.rd.JSON(w, httpCode, v)
+}
+
+func (handler *evictSlowStoreHandler) ListConfig(w http.ResponseWriter, r *http.Request) {
+	conf := handler.config.Clone()
+	handler.rd.JSON(w, http.StatusOK, conf)
+}
+
+// slowTrend tracks the slow score of a store over its heartbeats.
+type slowTrend struct {
+	// score is the smoothed slow score.
+	score *movingaverage.MedianFilter
+	// slope is the smoothed change rate of the smoothed slow score per second.
+	slope        *movingaverage.WMA
+	samples      int
+	lastScore    float64
+	lastTime     time.Time
+	healthySince time.Time
+}
+
+func newSlowTrend() *slowTrend {
+	return &slowTrend{
+		score: movingaverage.NewMedianFilter(slowTrendWindowSize),
+		slope: movingaverage.NewWMA(slowTrendWindowSize),
+	}
+}
+
+// observe records the slow score of the store if there is a new heartbeat.
+func (t *slowTrend) observe(store *core.StoreInfo) {
+	now := store.GetLastHeartbeatTS()
+	if !now.After(t.lastTime) {
+		return
+	}
+	rawScore := store.GetSlowScore()
+	t.score.Add(float64(rawScore))
+	score := t.score.Get()
+	if t.samples > 0 {
+		t.slope.Add((score - t.lastScore) / now.Sub(t.lastTime).Seconds())
+	}
+	t.samples++
+	t.lastScore, t.lastTime = score, now
+	if rawScore <= slowStoreRecoverThreshold {
+		if t.healthySince.IsZero() {
+			t.healthySince = now
+		}
+	} else {
+		t.healthySince = time.Time{}
+	}
+}
+
+// isSlow returns whether the slow score stays at the top or keeps rising above the threshold.
+func (t *slowTrend) isSlow() bool {
+	if t.samples < slowTrendWindowSize {
+		return false
+	}
+	score := t.score.Get()
+	return score >= slowStoreEvictThreshold || (score >= slowStoreTrendThreshold && t.slope.Get() > 0)
+}
+
+// isRecovered returns whether the store keeps healthy for the duration.
+func (t *slowTrend) isRecovered(duration time.Duration) bool {
+	return !t.healthySince.IsZero() && t.lastTime.Sub(t.healthySince) >= duration
+}
+
+func (t *slowTrend) reason() string {
+	return fmt.Sprintf("slow score %.0f, trend %+.2f/s", t.score.Get(), t.slope.Get())
+}
+
 type evictSlowStoreScheduler struct {
 	*BaseScheduler
-	conf *evictSlowStoreSchedulerConfig
+	conf    *evictSlowStoreSchedulerConfig
+	handler http.Handler
+	trends  map[uint64]*slowTrend
+}
+
+func (s *evictSlowStoreScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
 }
 
 func (s *evictSlowStoreScheduler) GetName() string {
@@ -119,13 +345,16 @@ func (s *evictSlowStoreScheduler) GetType() string {
 }
 
 func (s *evictSlowStoreScheduler) EncodeConfig() ([]byte, error) {
+	s.conf.mu.RLock()
+	defer s.conf.mu.RUnlock()
 	return schedule.EncodeConfig(s.conf)
 }
 
 func (s *evictSlowStoreScheduler) Prepare(cluster schedule.Cluster) error {
-	evictStore := s.conf.evictStore()
-	if evictStore != 0 {
-		return cluster.SlowStoreEvicted(evictStore)
+	for _, storeID := range s.conf.getStores() {
+		if err := cluster.SlowStoreEvicted(storeID); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -134,33 +363,45 @@ func (s *evictSlowStoreScheduler) Cleanup(cluster schedule.Cluster) {
 	s.cleanupEvictLeader(cluster)
 }
 
-func (s *evictSlowStoreScheduler) prepareEvictLeader(cluster schedule.Cluster, storeID uint64) error {
-	err := s.conf.setStoreAndPersist(storeID)
+func (s *evictSlowStoreScheduler) prepareEvictLeader(cluster schedule.Cluster, reasons map[uint64]string) error {
+	err := s.conf.addStoresAndPersist(reasons)
 	if err != nil {
-		log.Info("evict-slow-store-scheduler persist config failed", zap.Uint64("store-id", storeID))
+		log.Info("evict-slow-store-scheduler persist config failed", zap.Any("stores", reasons))
 		return err
 	}
+	for storeID := range reasons {
+		if err := cluster.SlowStoreEvicted(storeID); err != nil {
+			log.Info("failed to mark the slow store as evicted", zap.Uint64("store-id", storeID), zap.Error(err))
+		}
+	}
+	return nil
+}
 
-	return cluster.SlowStoreEvicted(storeID)
+func (s *evictSlowStoreScheduler) recoverEvictLeader(cluster schedule.Cluster, storeIDs []uint64) {
+	if err := s.conf.removeStoresAndPersist(storeIDs); err != nil {
+		log.Info("evict-slow-store-scheduler persist config failed", zap.Uint64s("store-ids", storeIDs))
+	}
+	for _, storeID := range storeIDs {
+		cluster.SlowStoreRecovered(storeID)
+	}
 }
 
 func (s *evictSlowStoreScheduler) cleanupEvictLeader(cluster schedule.Cluster) {
-	evictSlowStore, err := s.conf.clearAndPersist()
+	evictSlowStores, err := s.conf.clearAndPersist()
 	if err != nil {
-		log.Info("evict-slow-store-scheduler persist config failed", zap.Uint64("store-id", evictSlowStore))
+		log.Info("evict-slow-store-scheduler persist config failed", zap.Uint64s("store-ids", evictSlowStores))
 	}
-	if evictSlowStore == 0 {
-		return
+	for _, storeID := range evictSlowStores {
+		cluster.SlowStoreRecovered(storeID)
 	}
-	cluster.SlowStoreRecovered(evictSlowStore)
 }
 
 func (s *evictSlowStoreScheduler) schedulerEvictLeader(cluster schedule.Cluster) []*operator.Operator {
-	return scheduleEvictLeaderBatch(s.GetName(), s.GetType(), cluster, s.conf, EvictLeaderBatchSize)
+	return scheduleEvictLeaderBatch(s.GetName(), s.GetType(), cluster, s.conf, EvictLeaderBatchSize, NewBalanceSchedulerPlan(), nil)
 }
 
 func (s *evictSlowStoreScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool {
-	if s.conf.evictStore() != 0 {
+	if len(s.conf.getStores()) != 0 {
 		allowed := s.OpController.OperatorCount(operator.OpLeader) < cluster.GetOpts().GetLeaderScheduleLimit()
 		if !allowed {
 			operator.OperatorLimitCounter.WithLabelValues(s.GetType(), operator.OpLeader.String()).Inc()
@@ -170,54 +411,84 @@ func (s *evictSlowStoreScheduler) IsScheduleAllowed(cluster schedule.Cluster) bo
 	return true
 }
 
+// observeStores updates the slow trends of all the stores.
+func (s *evictSlowStoreScheduler) observeStores(cluster schedule.Cluster) {
+	stores := cluster.GetStores()
+	exist := make(map[uint64]struct{}, len(stores))
+	for _, store := range stores {
+		if store.IsRemoved() {
+			continue
+		}
+		exist[store.GetID()] = struct{}{}
+		trend, ok := s.trends[store.GetID()]
+		if !ok {
+			trend = newSlowTrend()
+			s.trends[store.GetID()] = trend
+		}
+		trend.observe(store)
+	}
+	for storeID := range s.trends {
+		if _, ok := exist[storeID]; !ok {
+			delete(s.trends, storeID)
+		}
+	}
+}
+
 func (s *evictSlowStoreScheduler) Schedule(cluster schedule.Cluster, dryRun bool) ([]*operator.Operator, []plan.Plan) {
 	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
-	var ops []*operator.Operator
-
-	if s.conf.evictStore() != 0 {
-		store := cluster.GetStore(s.conf.evictStore())
+	s.observeStores(cluster)
+
+	// Recover the evicted stores which have been removed or keep healthy long enough.
+	var recovered []uint64
+	recoverDuration := s.conf.getRecoverDuration()
+	evictedStores := s.conf.getStores()
+	for _, storeID := range evictedStores {
+		store := cluster.GetStore(storeID)
 		if store == nil || store.IsRemoved() {
-			// Previous slow store had been removed, remove the scheduler and check
-			// slow node next time.
-			log.Info("slow store has been removed",
-				zap.Uint64("store-id", store.GetID()))
-		} else if store.GetSlowScore() <= slowStoreRecoverThreshold {
-			log.Info("slow store has been recovered",
-				zap.Uint64("store-id", store.GetID()))
-		} else {
-			return s.schedulerEvictLeader(cluster), nil
+			// Previous slow store had been removed, check slow node next time.
+			log.Info("slow store has been removed", zap.Uint64("store-id", storeID))
+			recovered = append(recovered, storeID)
+		} else if trend := s.trends[storeID]; trend != nil && trend.isRecovered(recoverDuration) {
+			log.Info("slow store has been recovered", zap.Uint64("store-id", storeID))
+			recovered = append(recovered, storeID)
 		}
-		s.cleanupEvictLeader(cluster)
-		return ops, nil
+	}
+	if len(recovered) > 0 {
+		s.recoverEvictLeader(cluster, recovered)
+		evictedStores = s.conf.getStores()
 	}
 
-	var slowStore *core.StoreInfo
-
-	for _, store := range cluster.GetStores() {
-		if store.IsRemoved() {
-			continue
+	// Evict the slow stores if they are within the limit.
+	if quota := s.conf.getMaxEvictedStores() - len(evictedStores); quota > 0 {
+		var slowStores []*core.StoreInfo
+		for _, store := range cluster.GetStores() {
+			if store.IsRemoved() || !(store.IsPreparing() || store.IsServing()) || slice.Contains(evictedStores, store.GetID()) {
+				continue
+			}
+			if trend := s.trends[store.GetID()]; trend != nil && trend.isSlow() {
+				slowStores = append(slowStores, store)
+			}
 		}
-
-		if (store.IsPreparing() || store.IsServing()) && store.IsSlow() {
-			// Do nothing if there is more than one slow store.
-			if slowStore != nil {
-				return ops, nil
+		// Do nothing if there are more slow stores than the limit allows.
+		if len(slowStores) > quota {
+			log.Info("too many slow stores, skip evicting leaders",
+				zap.Int("slow-stores", len(slowStores)), zap.Int("quota", quota))
+			schedulerCounter.WithLabelValues(s.GetName(), "too-many-slow-stores").Inc()
+		} else if len(slowStores) > 0 {
+			reasons := make(map[uint64]string, len(slowStores))
+			for _, store := range slowStores {
+				reasons[store.GetID()] = s.trends[store.GetID()].reason()
+				log.Info("detected slow store, start to evict leaders",
+					zap.Uint64("store-id", store.GetID()), zap.String("reason", reasons[store.GetID()]))
+			}
+			if err := s.prepareEvictLeader(cluster, reasons); err != nil {
+				log.Info("prepare for evicting leader failed", zap.Error(err), zap.Any("stores", reasons))
 			}
-			slowStore = store
 		}
 	}
 
-	if slowStore == nil || slowStore.GetSlowScore() < slowStoreEvictThreshold {
-		return ops, nil
-	}
-
-	// If there is only one slow store, evict leaders from that store.
-	log.Info("detected slow store, start to evict leaders",
-		zap.Uint64("store-id", slowStore.GetID()))
-	err := s.prepareEvictLeader(cluster, slowStore.GetID())
-	if err != nil {
-		log.Info("prepare for evicting leader failed", zap.Error(err), zap.Uint64("store-id", slowStore.GetID()))
-		return ops, nil
+	if len(s.conf.getStores()) == 0 {
+		return nil, nil
 	}
 	return s.schedulerEvictLeader(cluster), nil
 }
@@ -229,6 +500,8 @@ func newEvictSlowStoreScheduler(opController *schedule.OperatorController, conf
 	s := &evictSlowStoreScheduler{
 		BaseScheduler: base,
 		conf:          conf,
+		handler:       newEvictSlowStoreHandler(conf),
+		trends:        make(map[uint64]*slowTrend),
 	}
 	return s
 }
@@ -17,13 +17,16 @@ package schedulers
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pingcap/failpoint"
 	"github.com/stretchr/testify/suite"
 	"github.com/tikv/pd/pkg/mock/mockcluster"
 	"github.com/tikv/pd/pkg/testutil"
+	"github.com/tikv/pd/pkg/typeutil"
 	"github.com/tikv/pd/server/config"
 	"github.com/tikv/pd/server/core"
 	"github.com/tikv/pd/server/schedule"
@@ -72,24 +75,48 @@ func (suite *evictSlowStoreTestSuite) TearDownTest() {
 	suite.cancel()
 }
 
+// heartbeat reports the slow score of the store with a heartbeat 10s after the previous one.
+func (suite *evictSlowStoreTestSuite) heartbeat(storeID, slowScore uint64) {
+	store := suite.tc.GetStore(storeID)
+	stats := typeutil.DeepClone(store.GetStoreStats(), core.StoreStatsFactory)
+	stats.SlowScore = slowScore
+	suite.tc.PutStore(store.Clone(
+		core.SetStoreStats(stats),
+		core.SetLastHeartbeatTS(store.GetLastHeartbeatTS().Add(10*time.Second)),
+	))
+}
+
 func (suite *evictSlowStoreTestSuite) TestEvictSlowStore() {
-	storeInfo := suite.tc.GetStore(1)
-	newStoreInfo := storeInfo.Clone(func(store *core.StoreInfo) {
-		store.GetStoreStats().SlowScore = 100
-	})
-	suite.tc.PutStore(newStoreInfo)
-	suite.True(suite.es.IsScheduleAllowed(suite.tc))
+	// A single slow heartbeat is not enough to evict the store.
+	for i := 0; i < slowTrendWindowSize-1; i++ {
+		suite.heartbeat(1, 100)
+		suite.True(suite.es.IsScheduleAllowed(suite.tc))
+		ops, _ := suite.es.Schedule(suite.tc, false)
+		suite.Empty(ops)
+	}
 	// Add evict leader scheduler to store 1
+	suite.heartbeat(1, 100)
 	ops, _ := suite.es.Schedule(suite.tc, false)
 	testutil.CheckMultiTargetTransferLeader(suite.Require(), ops[0], operator.OpLeader, 1, []uint64{2})
 	suite.Equal(EvictSlowStoreType, ops[0].Desc())
 	// Cannot balance leaders to store 1
 	ops, _ = suite.bs.Schedule(suite.tc, false)
 	suite.Empty(ops)
-	newStoreInfo = storeInfo.Clone(func(store *core.StoreInfo) {
-		store.GetStoreStats().SlowScore = 0
-	})
-	suite.tc.PutStore(newStoreInfo)
+
+	es2, ok := suite.es.(*evictSlowStoreScheduler)
+	suite.True(ok)
+	suite.Equal([]uint64{1}, es2.conf.getStores())
+	suite.Contains(es2.conf.Clone().EvictedReasons[1], "slow score 100")
+	recoverDuration := typeutil.NewDuration(30 * time.Second)
+	code, _ := es2.conf.update(nil, &recoverDuration)
+	suite.Equal(http.StatusOK, code)
+	// The store is recovered only if it keeps healthy for the recover duration.
+	for i := 0; i < 3; i++ {
+		suite.heartbeat(1, 0)
+		suite.es.Schedule(suite.tc, false)
+		suite.Equal([]uint64{1}, es2.conf.getStores())
+	}
+	suite.heartbeat(1, 0)
 	// Evict leader scheduler of store 1 should be removed, then leader can be balanced to store 1
 	ops, _ = suite.es.Schedule(suite.tc, false)
 	suite.Empty(ops)
@@ -99,10 +126,7 @@ func (suite *evictSlowStoreTestSuite) TestEvictSlowStore() {
 	// no slow store need to evict.
 	ops, _ = suite.es.Schedule(suite.tc, false)
 	suite.Empty(ops)
-
-	es2, ok := suite.es.(*evictSlowStoreScheduler)
-	suite.True(ok)
-	suite.Zero(es2.conf.evictStore())
+	suite.Empty(es2.conf.getStores())
 
 	// check the value from storage.
 	sches, vs, err := es2.conf.storage.LoadAllScheduleConfig()
@@ -118,18 +142,63 @@ func (suite *evictSlowStoreTestSuite) TestEvictSlowStore() {
 	err = json.Unmarshal([]byte(valueStr), &persistValue)
 	suite.NoError(err)
 	suite.Equal(es2.conf.EvictedStores, persistValue.EvictedStores)
-	suite.Zero(persistValue.evictStore())
+	suite.Empty(persistValue.EvictedStores)
+	suite.Equal(recoverDuration, persistValue.RecoverDuration)
+}
+
+func (suite *evictSlowStoreTestSuite) TestEvictSlowStoreTrend() {
+	suite.tc.AddLeaderStore(4, 0)
+	es2, ok := suite.es.(*evictSlowStoreScheduler)
+	suite.True(ok)
+	code, _ := es2.conf.update(func(v int) *int { return &v }(2), nil)
+	suite.Equal(http.StatusOK, code)
+
+	// Store 1: the slow score keeps rising.
+	// Store 2: the slow score stays at the top.
+	// Store 3: the slow score is high but stable.
+	// Store 4: the slow score jumps once.
+	scores := map[uint64][]uint64{
+		1: {80, 85, 90, 95, 99},
+		2: {100, 100, 100, 100, 100},
+		3: {90, 90, 90, 90, 90},
+		4: {1, 1, 100, 1, 1},
+	}
+	for i := 0; i < slowTrendWindowSize; i++ {
+		for storeID, score := range scores {
+			suite.heartbeat(storeID, score[i])
+		}
+		suite.es.Schedule(suite.tc, false)
+	}
+	suite.ElementsMatch([]uint64{1, 2}, es2.conf.getStores())
+	reasons := es2.conf.Clone().EvictedReasons
+	suite.Len(reasons, 2)
+	suite.Contains(reasons[1], "slow score 90")
+
+	// No store is evicted if there are more slow stores than the limit.
+	code, _ = es2.conf.update(func(v int) *int { return &v }(1), nil)
+	suite.Equal(http.StatusOK, code)
+	suite.es.Cleanup(suite.tc)
+	suite.Empty(es2.conf.getStores())
+	suite.es.Schedule(suite.tc, false)
+	suite.Empty(es2.conf.getStores())
+	// Evict the slow store once it is the only one.
+	for i := 0; i < slowTrendWindowSize; i++ {
+		suite.heartbeat(1, 1)
+		suite.heartbeat(2, 100)
+		suite.es.Schedule(suite.tc, false)
+	}
+	suite.Equal([]uint64{2}, es2.conf.getStores())
 }
 
 func (suite *evictSlowStoreTestSuite) TestEvictSlowStorePrepare() {
 	es2, ok := suite.es.(*evictSlowStoreScheduler)
 	suite.True(ok)
-	suite.Zero(es2.conf.evictStore())
+	suite.Empty(es2.conf.getStores())
 	// prepare with no evict store.
 	suite.es.Prepare(suite.tc)
 
-	es2.conf.setStoreAndPersist(1)
-	suite.Equal(uint64(1), es2.conf.evictStore())
+	es2.conf.addStoresAndPersist(map[uint64]string{1: "test"})
+	suite.Equal([]uint64{1}, es2.conf.getStores())
 	// prepare with evict store.
 	suite.es.Prepare(suite.tc)
 }
@@ -138,16 +207,14 @@ func (suite *evictSlowStoreTestSuite) TestEvictSlowStorePersistFail() {
 	persisFail := "github.com/tikv/pd/server/schedulers/persistFail"
 	suite.NoError(failpoint.Enable(persisFail, "return(true)"))
 
-	storeInfo := suite.tc.GetStore(1)
-	newStoreInfo := storeInfo.Clone(func(store *core.StoreInfo) {
-		store.GetStoreStats().SlowScore = 100
-	})
-	suite.tc.PutStore(newStoreInfo)
 	suite.True(suite.es.IsScheduleAllowed(suite.tc))
 	// Add evict leader scheduler to store 1
-	ops, _ := suite.es.Schedule(suite.tc, false)
-	suite.Empty(ops)
+	for i := 0; i < slowTrendWindowSize; i++ {
+		suite.heartbeat(1, 100)
+		ops, _ := suite.es.Schedule(suite.tc, false)
+		suite.Empty(ops)
+	}
 	suite.NoError(failpoint.Disable(persisFail))
-	ops, _ = suite.es.Schedule(suite.tc, false)
+	ops, _ := suite.es.Schedule(suite.tc, false)
 	suite.NotEmpty(ops)
 }
@@ -20,6 +20,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/log"
 	"github.com/tikv/pd/pkg/apiutil"
 	"github.com/tikv/pd/pkg/errs"
@@ -239,8 +240,24 @@ func (s *grantLeaderScheduler) Schedule(cluster schedule.Cluster, dryRun bool) (
 	ops := make([]*operator.Operator, 0, len(s.conf.StoreIDWithRanges))
 	pendingFilter := filter.NewRegionPendingFilter()
 	downFilter := filter.NewRegionDownFilter()
+	// The granted store is recorded as the source of plans.
+	basePlan := NewBalanceSchedulerPlan()
+	var collector *plan.Collector
+	if dryRun {
+		collector = plan.NewCollector(basePlan)
+	}
 	for id, ranges := range s.conf.StoreIDWithRanges {
-		region := filter.SelectOneRegion(cluster.RandFollowerRegions(id, ranges), nil, pendingFilter, downFilter)
+		basePlan.step = pickSource
+		store := cluster.GetStore(id)
+		if store == nil {
+			if collector != nil {
+				collector.Collect(plan.SetResource(core.NewStoreInfo(&metapb.Store{Id: id})), plan.SetStatus(plan.NewStatus(plan.StatusStoreNotExisted)))
+			}
+			continue
+		}
+		basePlan.source = store
+		basePlan.step = pickRegion
+		region := filter.SelectOneRegion(cluster.RandFollowerRegions(id, ranges), collector, pendingFilter, downFilter)
 		if region == nil {
 			schedulerCounter.WithLabelValues(s.GetName(), "no-follower").Inc()
 			continue
@@ -249,6 +266,9 @@ func (s *grantLeaderScheduler) Schedule(cluster schedule.Cluster, dryRun bool) (
 		op, err := operator.CreateForceTransferLeaderOperator(GrantLeaderType, cluster, region, region.GetLeader().GetStoreId(), id, operator.OpLeader)
 		if err != nil {
 			log.Debug("fail to create grant leader operator", errs.ZapError(err))
+			if collector != nil {
+				collector.Collect(plan.SetResource(region), plan.SetStatus(plan.NewStatus(plan.StatusCreateOperatorFailed)))
+			}
 			continue
 		}
 		op.Counters = append(op.Counters, schedulerCounter.WithLabelValues(s.GetName(), "new-operator"))
@@ -256,7 +276,7 @@ func (s *grantLeaderScheduler) Schedule(cluster schedule.Cluster, dryRun bool) (
 		ops = append(ops, op)
 	}
 
-	return ops, nil
+	return ops, collector.GetPlans()
 }
 
 type grantLeaderHandler struct {
@@ -107,6 +107,11 @@ type hotScheduler struct {
 	// config of hot scheduler
 	conf                *hotRegionSchedulerConfig
 	searchRevertRegions [resourceTypeLen]bool // Whether to search revert regions.
+
+	// basePlan and collector are used to collect plans for diagnosis.
+	// Every time `Schedule()` will reset them.
+	basePlan  *balanceSchedulerPlan
+	collector *plan.Collector
 }
 
 func newHotScheduler(opController *schedule.OperatorController, conf *hotRegionSchedulerConfig) *hotScheduler {
@@ -118,6 +123,7 @@ func newHotScheduler(opController *schedule.OperatorController, conf *hotRegionS
 		r:              rand.New(rand.NewSource(time.Now().UnixNano())),
 		regionPendings: make(map[uint64]*pendingInfluence),
 		conf:           conf,
+		basePlan:       NewBalanceSchedulerPlan(),
 	}
 	for ty := resourceType(0); ty < resourceTypeLen; ty++ {
 		ret.stLoadInfos[ty] = map[uint64]*statistics.StoreLoadDetail{}
@@ -156,26 +162,31 @@ func (h *hotScheduler) IsScheduleAllowed(cluster schedule.Cluster) bool {
 
 func (h *hotScheduler) Schedule(cluster schedule.Cluster, dryRun bool) ([]*operator.Operator, []plan.Plan) {
 	schedulerCounter.WithLabelValues(h.GetName(), "schedule").Inc()
-	return h.dispatch(h.types[h.r.Int()%len(h.types)], cluster), nil
+	return h.dispatch(h.types[h.r.Int()%len(h.types)], cluster, dryRun)
 }
 
-func (h *hotScheduler) dispatch(typ statistics.RWType, cluster schedule.Cluster) []*operator.Operator {
+func (h *hotScheduler) dispatch(typ statistics.RWType, cluster schedule.Cluster, dryRun bool) ([]*operator.Operator, []plan.Plan) {
 	h.Lock()
 	defer h.Unlock()
 
+	h.basePlan = NewBalanceSchedulerPlan()
+	h.collector = nil
+	if dryRun {
+		h.collector = plan.NewCollector(h.basePlan)
+	}
 	h.prepareForBalance(typ, cluster)
 	// it can not move earlier to support to use api and metrics.
 	if h.conf.IsForbidRWType(typ) {
-		return nil
+		return nil, nil
 	}
 
 	switch typ {
 	case statistics.Read:
-		return h.balanceHotReadRegions(cluster)
+		return h.balanceHotReadRegions(cluster), h.collector.GetPlans()
 	case statistics.Write:
-		return h.balanceHotWriteRegions(cluster)
+		return h.balanceHotWriteRegions(cluster), h.collector.GetPlans()
 	}
-	return nil
+	return nil, nil
 }
 
 // prepareForBalance calculate the summary of pending Influence for each store and prepare the load detail for
@@ -185,11 +196,15 @@ func (h *hotScheduler) prepareForBalance(typ statistics.RWType, cluster schedule
 	h.summaryPendingInfluence(cluster)
 	storesLoads := cluster.GetStoresLoads()
 	isTraceRegionFlow := cluster.GetOpts().IsTraceRegionFlow()
+	isPredictionEnabled := cluster.GetOpts().IsHotRegionPredictionEnabled()
 
 	switch typ {
 	case statistics.Read:
 		// update read statistics
 		regionRead := cluster.RegionReadStats()
+		if isPredictionEnabled {
+			regionRead, storesLoads = mergePredictedStats(statistics.Read, regionRead, cluster.PredictedRegionStats(statistics.Read), storesLoads)
+		}
 		h.stLoadInfos[readLeader] = statistics.SummaryStoresLoad(
 			h.stInfos,
 			storesLoads,
@@ -205,6 +220,9 @@ func (h *hotScheduler) prepareForBalance(typ statistics.RWType, cluster schedule
 	case statistics.Write:
 		// update write statistics
 		regionWrite := cluster.RegionWriteStats()
+		if isPredictionEnabled {
+			regionWrite, storesLoads = mergePredictedStats(statistics.Write, regionWrite, cluster.PredictedRegionStats(statistics.Write), storesLoads)
+		}
 		h.stLoadInfos[writeLeader] = statistics.SummaryStoresLoad(
 			h.stInfos,
 			storesLoads,
@@ -220,6 +238,63 @@ func (h *hotScheduler) prepareForBalance(typ statistics.RWType, cluster schedule
 	}
 }
 
+// mergePredictedStats merges the peers which are predicted to be hot soon but are not hot now
+// into the hot peers, and adds their loads to the loads of the stores, so that they can be
+// spread before becoming hot. The predicted loads are merged at the current location of the
+// regions in every round, and the running operators are covered by the pending influence.
+func mergePredictedStats(
+	rwTy statistics.RWType,
+	regionStats, predictedStats map[uint64][]*statistics.HotPeerStat,
+	storesLoads map[uint64][]float64,
+) (map[uint64][]*statistics.HotPeerStat, map[uint64][]float64) {
+	if len(predictedStats) == 0 {
+		return regionStats, storesLoads
+	}
+	hotRegions := make(map[uint64]struct{})
+	mergedStats := make(map[uint64][]*statistics.HotPeerStat, len(regionStats))
+	for storeID, peers := range regionStats {
+		for _, peer := range peers {
+			hotRegions[peer.RegionID] = struct{}{}
+		}
+		mergedStats[storeID] = append(peers[:0:0], peers...)
+	}
+	mergedLoads := make(map[uint64][]float64, len(storesLoads))
+	for storeID, loads := range storesLoads {
+		mergedLoads[storeID] = append(loads[:0:0], loads...)
+	}
+
+	var byteKind, keyKind, queryKind statistics.StoreStatKind
+	switch rwTy {
+	case statistics.Read:
+		byteKind, keyKind, queryKind = statistics.StoreReadBytes, statistics.StoreReadKeys, statistics.StoreReadQuery
+	case statistics.Write:
+		byteKind, keyKind, queryKind = statistics.StoreWriteBytes, statistics.StoreWriteKeys, statistics.StoreWriteQuery
+	}
+	for storeID, peers := range predictedStats {
+		loads, ok := mergedLoads[storeID]
+		if !ok {
+			continue
+		}
+		for _, peer := range peers {
+			if _, ok := hotRegions[peer.RegionID]; ok {
+				continue
+			}
+			mergedStats[storeID] = append(mergedStats[storeID], peer)
+			loads[byteKind] += peer.Loads[statistics.ByteDim]
+			loads[keyKind] += peer.Loads[statistics.KeyDim]
+			// The write queries are only handled by the leader.
+			if rwTy == statistics.Read || peer.IsLeader() {
+				loads[queryKind] += peer.Loads[statistics.QueryDim]
+			}
+			if rwTy == statistics.Write {
+				loads[statistics.StoreRegionsWriteBytes] += peer.Loads[statistics.ByteDim]
+				loads[statistics.StoreRegionsWriteKeys] += peer.Loads[statistics.KeyDim]
+			}
+		}
+	}
+	return mergedStats, mergedLoads
+}
+
 // summaryPendingInfluence calculate the summary of pending Influence for each store
 // and clean the region from regionInfluence if they have ended operator.
 // It makes each dim rate or count become `weight` times to the origin value.
@@ -324,10 +399,18 @@ func (h *hotScheduler) balanceHotWriteRegions(cluster schedule.Cluster) []*opera
 	s := h.r.Intn(100)
 	switch {
 	case s < int(schedulePeerPr*100):
-		peerSolver := newBalanceSolver(h, cluster, statistics.Write, movePeer)
-		ops := peerSolver.solve()
-		if len(ops) > 0 && peerSolver.tryAddPendingInfluence() {
-			return ops
+		// The hot peers of each engine are balanced separately, and the engines are
+		// tried in random order so that any engine will not be starved.
+		engines := statistics.SummaryEngines(h.stInfos)
+		h.r.Shuffle(len(engines), func(i, j int) {
+			engines[i], engines[j] = engines[j], engines[i]
+		})
+		for _, engine := range engines {
+			peerSolver := newEngineBalanceSolver(h, cluster, statistics.Write, movePeer, engine)
+			ops := peerSolver.solve()
+			if len(ops) > 0 && peerSolver.tryAddPendingInfluence() {
+				return ops
+			}
 		}
 	default:
 	}
@@ -410,10 +493,15 @@ func isAvailableV1(s *solution) bool {
 type balanceSolver struct {
 	schedule.Cluster
 	sche         *hotScheduler
+	basePlan     *balanceSchedulerPlan
+	collector    *plan.Collector
 	stLoadDetail map[uint64]*statistics.StoreLoadDetail
 	rwTy         statistics.RWType
 	opTy         opType
 	resourceTy   resourceType
+	// engine is the engine of the stores to be balanced. The hot peers are only
+	// balanced among the stores of the same engine.
+	engine string
 
 	cur *solution
 
@@ -451,7 +539,14 @@ type balanceSolver struct {
 func (bs *balanceSolver) init() {
 	// Init store load detail according to the type.
 	bs.resourceTy = toResourceType(bs.rwTy, bs.opTy)
-	bs.stLoadDetail = bs.sche.stLoadInfos[bs.resourceTy]
+	if stLoadDetail := bs.sche.stLoadInfos[bs.resourceTy]; stLoadDetail != nil {
+		bs.stLoadDetail = make(map[uint64]*statistics.StoreLoadDetail, len(stLoadDetail))
+		for id, detail := range stLoadDetail {
+			if detail.Engine() == bs.engine {
+				bs.stLoadDetail[id] = detail
+			}
+		}
+	}
 
 	bs.maxSrc = &statistics.StoreLoad{Loads: make([]float64, statistics.DimLen)}
 	bs.minDst = &statistics.StoreLoad{
@@ -531,6 +626,9 @@ func (bs *balanceSolver) getPriorities() []string {
 	case writeLeader:
 		return adjustPrioritiesConfig(querySupport, bs.sche.conf.GetWriteLeaderPriorities(), getWriteLeaderPriorities)
 	case writePeer:
+		if bs.engine != core.EngineTiKV {
+			return adjustPrioritiesConfig(querySupport, bs.sche.conf.GetTiFlashWritePeerPriorities(), getTiFlashWritePeerPriorities)
+		}
 		return adjustPrioritiesConfig(querySupport, bs.sche.conf.GetWritePeerPriorities(), getWritePeerPriorities)
 	}
 	log.Error("illegal type or illegal operator while getting the priority", zap.String("type", bs.rwTy.String()), zap.String("operator", bs.opTy.String()))
@@ -538,11 +636,19 @@ func (bs *balanceSolver) getPriorities() []string {
 }
 
 func newBalanceSolver(sche *hotScheduler, cluster schedule.Cluster, rwTy statistics.RWType, opTy opType) *balanceSolver {
+	return newEngineBalanceSolver(sche, cluster, rwTy, opTy, core.EngineTiKV)
+}
+
+// newEngineBalanceSolver creates a solver which balances the stores of the engine.
+func newEngineBalanceSolver(sche *hotScheduler, cluster schedule.Cluster, rwTy statistics.RWType, opTy opType, engine string) *balanceSolver {
 	bs := &balanceSolver{
-		Cluster: cluster,
-		sche:    sche,
-		rwTy:    rwTy,
-		opTy:    opTy,
+		Cluster:   cluster,
+		sche:      sche,
+		basePlan:  sche.basePlan,
+		collector: sche.collector,
+		rwTy:      rwTy,
+		opTy:      opTy,
+		engine:    engine,
 	}
 	bs.init()
 	return bs
@@ -584,16 +690,24 @@ func (bs *balanceSolver) solve() []*operator.Operator {
 	}
 
 	bs.cur = &solution{}
+	bs.basePlan.step = pickSource
 	tryUpdateBestSolution := func() {
 		if label, ok := bs.filterUniformStore(); ok {
 			schedulerCounter.WithLabelValues(bs.sche.GetName(), fmt.Sprintf("%s-skip-%s-uniform-store", bs.rwTy.String(), label)).Inc()
+			bs.collectDstStore(plan.StatusStoreScoreDisallowed)
+			return
+		}
+		if !bs.isAvailable(bs.cur) {
+			bs.collectDstStore(plan.StatusStoreScoreDisallowed)
 			return
 		}
-		if bs.isAvailable(bs.cur) && bs.betterThan(bs.best) {
+		if bs.betterThan(bs.best) {
 			if newOps := bs.buildOperators(); len(newOps) > 0 {
 				bs.ops = newOps
 				clone := *bs.cur
 				bs.best = &clone
+			} else {
+				bs.collectDstStore(plan.StatusCreateOperatorFailed)
 			}
 		}
 	}
@@ -613,6 +727,8 @@ func (bs *balanceSolver) solve() []*operator.Operator {
 	for _, srcStore := range bs.filterSrcStores() {
 		bs.cur.srcStore = srcStore
 		srcStoreID := srcStore.GetID()
+		bs.basePlan.source = srcStore.StoreInfo
+		bs.basePlan.step = pickRegion
 		for _, mainPeerStat := range bs.filterHotPeers(srcStore) {
 			if bs.cur.region = bs.getRegion(mainPeerStat, srcStoreID); bs.cur.region == nil {
 				continue
@@ -621,6 +737,8 @@ func (bs *balanceSolver) solve() []*operator.Operator {
 				continue
 			}
 			bs.cur.mainPeerStat = mainPeerStat
+			bs.basePlan.region = bs.cur.region
+			bs.basePlan.step = pickTarget
 
 			for _, dstStore := range bs.filterDstStores() {
 				bs.cur.dstStore = dstStore
@@ -651,11 +769,18 @@ func (bs *balanceSolver) solve() []*operator.Operator {
 	return bs.ops
 }
 
+// collectDstStore collects the plan of the current solution with the given status.
+func (bs *balanceSolver) collectDstStore(status plan.StatusCode) {
+	if bs.collector != nil {
+		bs.collector.Collect(plan.SetResource(bs.cur.dstStore.StoreInfo), plan.SetStatus(plan.NewStatus(status)))
+	}
+}
+
 func (bs *balanceSolver) tryAddPendingInfluence() bool {
 	if bs.best == nil || len(bs.ops) == 0 {
 		return false
 	}
-	if bs.best.srcStore.IsTiFlash() != bs.best.dstStore.IsTiFlash() {
+	if bs.best.srcStore.Engine() != bs.best.dstStore.Engine() {
 		schedulerCounter.WithLabelValues(bs.sche.GetName(), "not-same-engine").Inc()
 		return false
 	}
@@ -666,13 +791,13 @@ func (bs *balanceSolver) tryAddPendingInfluence() bool {
 	srcStoreID := bs.best.srcStore.GetID()
 	dstStoreID := bs.best.dstStore.GetID()
 	infl := bs.collectPendingInfluence(bs.best.mainPeerStat)
-	if !bs.sche.tryAddPendingInfluence(bs.ops[0], srcStoreID, dstStoreID, infl, maxZombieDur) {
+	if !bs.sche.tryAddPendingInfluence(bs.ops[0], srcStoreID, dstStoreID, infl, pendingZombieDur(bs.best.mainPeerStat, maxZombieDur)) {
 		return false
 	}
 	// revert peers
 	if bs.best.revertPeerStat != nil {
 		infl := bs.collectPendingInfluence(bs.best.revertPeerStat)
-		if !bs.sche.tryAddPendingInfluence(bs.ops[1], dstStoreID, srcStoreID, infl, maxZombieDur) {
+		if !bs.sche.tryAddPendingInfluence(bs.ops[1], dstStoreID, srcStoreID, infl, pendingZombieDur(bs.best.revertPeerStat, maxZombieDur)) {
 			return false
 		}
 	}
@@ -691,6 +816,16 @@ func (bs *balanceSolver) collectPendingInfluence(peer *statistics.HotPeerStat) s
 	return infl
 }
 
+// pendingZombieDur returns how long the pending influence of the peer is kept after the
+// operator ends. The loads of a predicted peer are not in the store statistics, and they
+// follow the region as soon as the operator ends, so keeping the influence counts them twice.
+func pendingZombieDur(peer *statistics.HotPeerStat, maxZombieDur time.Duration) time.Duration {
+	if peer.IsPredicted() {
+		return 0
+	}
+	return maxZombieDur
+}
+
 // Depending on the source of the statistics used, a different ZombieDuration will be used.
 // If the statistics are from the sum of Regions, there will be a longer ZombieDuration.
 func (bs *balanceSolver) calcMaxZombieDur() time.Duration {
@@ -704,7 +839,7 @@ func (bs *balanceSolver) calcMaxZombieDur() time.Duration {
 		}
 		return bs.sche.conf.GetRegionsStatZombieDuration()
 	case writePeer:
-		if bs.best.srcStore.IsTiFlash() {
+		if !bs.best.srcStore.IsTiKV() {
 			return bs.sche.conf.GetRegionsStatZombieDuration()
 		}
 		return bs.sche.conf.GetStoreStatZombieDuration()
@@ -721,7 +856,7 @@ func (bs *balanceSolver) filterSrcStores() map[uint64]*statistics.StoreLoadDetai
 	confEnableForTiFlash := bs.sche.conf.GetEnableForTiFlash()
 	for id, detail := range bs.stLoadDetail {
 		srcToleranceRatio := confSrcToleranceRatio
-		if detail.IsTiFlash() {
+		if !detail.IsTiKV() {
 			if !confEnableForTiFlash {
 				continue
 			}
@@ -739,6 +874,9 @@ func (bs *balanceSolver) filterSrcStores() map[uint64]*statistics.StoreLoadDetai
 			hotSchedulerResultCounter.WithLabelValues("src-store-succ", strconv.FormatUint(id, 10)).Inc()
 		} else {
 			hotSchedulerResultCounter.WithLabelValues("src-store-failed", strconv.FormatUint(id, 10)).Inc()
+			if bs.collector != nil {
+				bs.collector.Collect(plan.SetResource(detail.StoreInfo), plan.SetStatus(plan.NewStatus(plan.StatusStoreScoreDisallowed)))
+			}
 		}
 	}
 	return ret
@@ -937,7 +1075,7 @@ func (bs *balanceSolver) pickDstStores(filters []filter.Filter, candidates []*st
 	for _, detail := range candidates {
 		store := detail.StoreInfo
 		dstToleranceRatio := confDstToleranceRatio
-		if detail.IsTiFlash() {
+		if !detail.IsTiKV() {
 			if !confEnableForTiFlash {
 				continue
 			}
@@ -953,12 +1091,30 @@ func (bs *balanceSolver) pickDstStores(filters []filter.Filter, candidates []*st
 				hotSchedulerResultCounter.WithLabelValues("dst-store-succ", strconv.FormatUint(id, 10)).Inc()
 			} else {
 				hotSchedulerResultCounter.WithLabelValues("dst-store-failed", strconv.FormatUint(id, 10)).Inc()
+				if bs.collector != nil {
+					bs.collector.Collect(plan.SetResource(store), plan.SetStatus(plan.NewStatus(plan.StatusStoreScoreDisallowed)))
+				}
 			}
+		} else {
+			bs.collectFilteredDstStore(store, filters)
 		}
 	}
 	return ret
 }
 
+// collectFilteredDstStore collects the plan with the status of the first filter which the store cannot pass.
+func (bs *balanceSolver) collectFilteredDstStore(store *core.StoreInfo, filters []filter.Filter) {
+	if bs.collector == nil {
+		return
+	}
+	for _, f := range filters {
+		if status := f.Target(bs.GetOpts(), store); !status.IsOK() {
+			bs.collector.Collect(plan.SetResource(store), plan.SetStatus(status))
+			return
+		}
+	}
+}
+
 func (bs *balanceSolver) checkDstByPriorityAndTolerance(maxLoad, expect *statistics.StoreLoad, toleranceRatio float64) bool {
 	return bs.checkByPriorityAndTolerance(maxLoad.Loads, func(i int) bool {
 		return maxLoad.Loads[i]*toleranceRatio < expect.Loads[i]
@@ -1310,12 +1466,12 @@ func (bs *balanceSolver) buildOperators() (ops []*operator.Operator) {
 
 	currentOp, typ, err := createOperator(bs.cur.region, srcStoreID, dstStoreID)
 	if err == nil {
-		bs.decorateOperator(currentOp, false, sourceLabel, targetLabel, typ, dim)
+		bs.decorateOperator(currentOp, false, bs.cur.mainPeerStat, sourceLabel, targetLabel, typ, dim)
 		ops = []*operator.Operator{currentOp}
 		if bs.cur.revertRegion != nil {
 			currentOp, typ, err = createOperator(bs.cur.revertRegion, dstStoreID, srcStoreID)
 			if err == nil {
-				bs.decorateOperator(currentOp, true, targetLabel, sourceLabel, typ, dim)
+				bs.decorateOperator(currentOp, true, bs.cur.revertPeerStat, targetLabel, sourceLabel, typ, dim)
 				ops = append(ops, currentOp)
 			}
 		}
@@ -1393,8 +1549,14 @@ func (bs *balanceSolver) createWriteOperator(region *core.RegionInfo, srcStoreID
 	return
 }
 
-func (bs *balanceSolver) decorateOperator(op *operator.Operator, isRevert bool, sourceLabel, targetLabel, typ, dim string) {
+func (bs *balanceSolver) decorateOperator(op *operator.Operator, isRevert bool, peer *statistics.HotPeerStat, sourceLabel, targetLabel, typ, dim string) {
 	op.SetPriorityLevel(core.High)
+	if peer.IsPredicted() {
+		// The region is not hot yet, but predicted to be hot soon by the hot regions history.
+		op.SetDesc("predicted-" + op.Desc())
+		op.SetPredictedLeaderLoads(predictedLeaderLoads(bs.rwTy, peer))
+		op.Counters = append(op.Counters, schedulerCounter.WithLabelValues(bs.sche.GetName(), "predicted"))
+	}
 	op.FinishedCounters = append(op.FinishedCounters,
 		hotDirectionCounter.WithLabelValues(typ, bs.rwTy.String(), sourceLabel, "out", dim),
 		hotDirectionCounter.WithLabelValues(typ, bs.rwTy.String(), targetLabel, "in", dim),
@@ -1409,6 +1571,25 @@ func (bs *balanceSolver) decorateOperator(op *operator.Operator, isRevert bool,
 	}
 }
 
+// predictedLeaderLoads returns the loads of the peer predicted to be hot by the load-based
+// leader schedule policies, which are added to the operator influence.
+func predictedLeaderLoads(rwTy statistics.RWType, peer *statistics.HotPeerStat) map[core.SchedulePolicy]int64 {
+	loads := peer.GetLoads()
+	switch rwTy {
+	case statistics.Read:
+		return map[core.SchedulePolicy]int64{
+			core.ByReadByte:  int64(loads[statistics.ByteDim]),
+			core.ByReadQuery: int64(loads[statistics.QueryDim]),
+		}
+	case statistics.Write:
+		return map[core.SchedulePolicy]int64{
+			core.ByWriteByte:  int64(loads[statistics.ByteDim]),
+			core.ByWriteQuery: int64(loads[statistics.QueryDim]),
+		}
+	}
+	return nil
+}
+
 func (bs *balanceSolver) logBestSolution() {
 	best := bs.best
 	if best == nil {
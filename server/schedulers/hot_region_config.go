@@ -43,16 +43,18 @@ const (
 )
 
 var defaultPrioritiesConfig = prioritiesConfig{
-	read:        []string{statistics.QueryPriority, statistics.BytePriority},
-	writeLeader: []string{statistics.QueryPriority, statistics.BytePriority},
-	writePeer:   []string{statistics.BytePriority, statistics.KeyPriority},
+	read:             []string{statistics.QueryPriority, statistics.BytePriority},
+	writeLeader:      []string{statistics.QueryPriority, statistics.BytePriority},
+	writePeer:        []string{statistics.BytePriority, statistics.KeyPriority},
+	tiflashWritePeer: []string{statistics.BytePriority, statistics.KeyPriority},
 }
 
 // because tikv below 5.2.0 does not report query information, we will use byte and key as the scheduling dimensions
 var compatiblePrioritiesConfig = prioritiesConfig{
-	read:        []string{statistics.BytePriority, statistics.KeyPriority},
-	writeLeader: []string{statistics.KeyPriority, statistics.BytePriority},
-	writePeer:   []string{statistics.BytePriority, statistics.KeyPriority},
+	read:             []string{statistics.BytePriority, statistics.KeyPriority},
+	writeLeader:      []string{statistics.KeyPriority, statistics.BytePriority},
+	writePeer:        []string{statistics.BytePriority, statistics.KeyPriority},
+	tiflashWritePeer: []string{statistics.BytePriority, statistics.KeyPriority},
 }
 
 // params about hot region.
@@ -82,26 +84,27 @@ func initHotRegionScheduleConfig() *hotRegionSchedulerConfig {
 
 func (conf *hotRegionSchedulerConfig) getValidConf() *hotRegionSchedulerConfig {
 	return &hotRegionSchedulerConfig{
-		MinHotByteRate:         conf.MinHotByteRate,
-		MinHotKeyRate:          conf.MinHotKeyRate,
-		MinHotQueryRate:        conf.MinHotQueryRate,
-		MaxZombieRounds:        conf.MaxZombieRounds,
-		MaxPeerNum:             conf.MaxPeerNum,
-		ByteRateRankStepRatio:  conf.ByteRateRankStepRatio,
-		KeyRateRankStepRatio:   conf.KeyRateRankStepRatio,
-		QueryRateRankStepRatio: conf.QueryRateRankStepRatio,
-		CountRankStepRatio:     conf.CountRankStepRatio,
-		GreatDecRatio:          conf.GreatDecRatio,
-		MinorDecRatio:          conf.MinorDecRatio,
-		SrcToleranceRatio:      conf.SrcToleranceRatio,
-		DstToleranceRatio:      conf.DstToleranceRatio,
-		ReadPriorities:         adjustPrioritiesConfig(conf.lastQuerySupported, conf.ReadPriorities, getReadPriorities),
-		WriteLeaderPriorities:  adjustPrioritiesConfig(conf.lastQuerySupported, conf.WriteLeaderPriorities, getWriteLeaderPriorities),
-		WritePeerPriorities:    adjustPrioritiesConfig(conf.lastQuerySupported, conf.WritePeerPriorities, getWritePeerPriorities),
-		StrictPickingStore:     conf.StrictPickingStore,
-		EnableForTiFlash:       conf.EnableForTiFlash,
-		RankFormulaVersion:     conf.getRankFormulaVersionLocked(),
-		ForbidRWType:           conf.getForbidRWTypeLocked(),
+		MinHotByteRate:             conf.MinHotByteRate,
+		MinHotKeyRate:              conf.MinHotKeyRate,
+		MinHotQueryRate:            conf.MinHotQueryRate,
+		MaxZombieRounds:            conf.MaxZombieRounds,
+		MaxPeerNum:                 conf.MaxPeerNum,
+		ByteRateRankStepRatio:      conf.ByteRateRankStepRatio,
+		KeyRateRankStepRatio:       conf.KeyRateRankStepRatio,
+		QueryRateRankStepRatio:     conf.QueryRateRankStepRatio,
+		CountRankStepRatio:         conf.CountRankStepRatio,
+		GreatDecRatio:              conf.GreatDecRatio,
+		MinorDecRatio:              conf.MinorDecRatio,
+		SrcToleranceRatio:          conf.SrcToleranceRatio,
+		DstToleranceRatio:          conf.DstToleranceRatio,
+		ReadPriorities:             adjustPrioritiesConfig(conf.lastQuerySupported, conf.ReadPriorities, getReadPriorities),
+		WriteLeaderPriorities:      adjustPrioritiesConfig(conf.lastQuerySupported, conf.WriteLeaderPriorities, getWriteLeaderPriorities),
+		WritePeerPriorities:        adjustPrioritiesConfig(conf.lastQuerySupported, conf.WritePeerPriorities, getWritePeerPriorities),
+		TiFlashWritePeerPriorities: adjustPrioritiesConfig(conf.lastQuerySupported, conf.TiFlashWritePeerPriorities, getTiFlashWritePeerPriorities),
+		StrictPickingStore:         conf.StrictPickingStore,
+		EnableForTiFlash:           conf.EnableForTiFlash,
+		RankFormulaVersion:         conf.getRankFormulaVersionLocked(),
+		ForbidRWType:               conf.getForbidRWTypeLocked(),
 	}
 }
 
@@ -134,10 +137,13 @@ type hotRegionSchedulerConfig struct {
 	WriteLeaderPriorities []string `json:"write-leader-priorities"`
 	WritePeerPriorities   []string `json:"write-peer-priorities"`
 	ReadPriorities        []string `json:"read-priorities"`
+	// The stores of TiFlash and other columnar engines only have write peers, and
+	// they are balanced among the stores of the same engine.
+	TiFlashWritePeerPriorities []string `json:"tiflash-write-peer-priorities"`
 
 	StrictPickingStore bool `json:"strict-picking-store,string"` // only for v1
 
-	// Separately control whether to start hotspot scheduling for TiFlash
+	// Separately control whether to start hotspot scheduling for TiFlash and other columnar engines
 	EnableForTiFlash bool `json:"enable-for-tiflash,string"`
 	// Version used by `calcProgressiveRank1 and betterThan1. The v2 version code is in hot_region_v2.go.
 	RankFormulaVersion string `json:"rank-formula-version"`
@@ -283,6 +289,12 @@ func (conf *hotRegionSchedulerConfig) GetWritePeerPriorities() []string {
 	return conf.WritePeerPriorities
 }
 
+func (conf *hotRegionSchedulerConfig) GetTiFlashWritePeerPriorities() []string {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.TiFlashWritePeerPriorities
+}
+
 func (conf *hotRegionSchedulerConfig) IsStrictPickingStoreEnabled() bool {
 	conf.RLock()
 	defer conf.RUnlock()
@@ -368,6 +380,11 @@ func (conf *hotRegionSchedulerConfig) valid() error {
 	} else if pm[statistics.QueryPriority] {
 		return errs.ErrSchedulerConfig.FastGenByArgs("query is not allowed to be set in priorities for write-peer-priorities")
 	}
+	if pm, err := isPriorityValid(conf.TiFlashWritePeerPriorities); err != nil {
+		return err
+	} else if pm[statistics.QueryPriority] {
+		return errs.ErrSchedulerConfig.FastGenByArgs("query is not allowed to be set in priorities for tiflash-write-peer-priorities")
+	}
 
 	if conf.RankFormulaVersion != "" && conf.RankFormulaVersion != "v1" && conf.RankFormulaVersion != "v2" {
 		return errs.ErrSchedulerConfig.FastGenByArgs("invalid rank-formula-version")
@@ -449,15 +466,17 @@ func (conf *hotRegionSchedulerConfig) checkQuerySupport(cluster schedule.Cluster
 }
 
 type prioritiesConfig struct {
-	read        []string
-	writeLeader []string
-	writePeer   []string
+	read             []string
+	writeLeader      []string
+	writePeer        []string
+	tiflashWritePeer []string
 }
 
 func (conf *hotRegionSchedulerConfig) applyPrioritiesConfig(p prioritiesConfig) {
 	conf.ReadPriorities = append(p.read[:0:0], p.read...)
 	conf.WriteLeaderPriorities = append(p.writeLeader[:0:0], p.writeLeader...)
 	conf.WritePeerPriorities = append(p.writePeer[:0:0], p.writePeer...)
+	conf.TiFlashWritePeerPriorities = append(p.tiflashWritePeer[:0:0], p.tiflashWritePeer...)
 }
 
 func getReadPriorities(c *prioritiesConfig) []string {
@@ -472,6 +491,10 @@ func getWritePeerPriorities(c *prioritiesConfig) []string {
 	return c.writePeer
 }
 
+func getTiFlashWritePeerPriorities(c *prioritiesConfig) []string {
+	return c.tiflashWritePeer
+}
+
 // adjustPrioritiesConfig will adjust config for cluster with low version tikv
 // because tikv below 5.2.0 does not report query information, we will use byte and key as the scheduling dimensions
 func adjustPrioritiesConfig(querySupport bool, origins []string, getPriorities func(*prioritiesConfig) []string) []string {
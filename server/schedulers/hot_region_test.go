@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/hex"
 	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -545,6 +546,91 @@ func TestHotWriteRegionScheduleByteRateOnlyWithTiFlash(t *testing.T) {
 	}
 }
 
+func TestHotWriteRegionScheduleWithOtherEngine(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statistics.Denoising = false
+	opt := config.NewTestOptions()
+	tc := mockcluster.NewCluster(ctx, opt)
+	tc.SetClusterVersion(versioninfo.MinSupportedVersion(versioninfo.Version4_0))
+	tc.SetHotRegionCacheHitsThreshold(0)
+	re.NoError(tc.RuleManager.SetRules([]*placement.Rule{
+		{
+			GroupID:        "pd",
+			ID:             "default",
+			Role:           placement.Voter,
+			Count:          3,
+			LocationLabels: []string{"host"},
+		},
+		{
+			GroupID:        "columnar",
+			ID:             "columnar",
+			Role:           placement.Learner,
+			Count:          1,
+			LocationLabels: []string{"host"},
+			LabelConstraints: []placement.LabelConstraint{
+				{
+					Key:    core.EngineKey,
+					Op:     placement.In,
+					Values: []string{"columnar"},
+				},
+			},
+		},
+	}))
+	sche, err := schedule.CreateScheduler(statistics.Write.String(), schedule.NewOperatorController(ctx, nil, nil), storage.NewStorageWithMemoryBackend(), nil)
+	re.NoError(err)
+	hb := sche.(*hotScheduler)
+
+	// Add TiKV stores 1, 2, 3 and the stores 4, 5, 6 of another columnar engine.
+	tc.AddLabelsStore(1, 3, map[string]string{"host": "h1"})
+	tc.AddLabelsStore(2, 3, map[string]string{"host": "h2"})
+	tc.AddLabelsStore(3, 3, map[string]string{"host": "h3"})
+	tc.AddLabelsStore(4, 3, map[string]string{"host": "h4", "engine": "columnar"})
+	tc.AddLabelsStore(5, 0, map[string]string{"host": "h5", "engine": "columnar"})
+	tc.AddLabelsStore(6, 0, map[string]string{"host": "h6", "engine": "columnar"})
+	for i := uint64(1); i <= 6; i++ {
+		tc.UpdateStorageWrittenBytes(i, 0)
+	}
+	// All the hot learners are placed on store 4.
+	addRegionInfo(tc, statistics.Write, []testRegionInfo{
+		{1, []uint64{1, 2, 3, 4}, 512 * units.KiB, 5 * units.KiB, 3000},
+		{2, []uint64{1, 2, 3, 4}, 512 * units.KiB, 5 * units.KiB, 3000},
+		{3, []uint64{1, 2, 3, 4}, 512 * units.KiB, 5 * units.KiB, 3000},
+	})
+
+	tc.ObserveRegionsStats()
+
+	// The stores of each engine have separate expectations.
+	hb.prepareForBalance(statistics.Write, tc)
+	re.Equal([]string{"columnar", core.EngineTiKV}, statistics.SummaryEngines(hb.stInfos))
+	for _, id := range []uint64{4, 5, 6} {
+		re.InDelta(3*512*units.KiB/3.0, hb.stLoadInfos[writePeer][id].LoadPred.Expect.Loads[statistics.ByteDim], 1)
+	}
+	re.Zero(hb.stLoadInfos[writePeer][1].LoadPred.Expect.Loads[statistics.ByteDim])
+
+	// The hot learners are only moved to the stores of the same engine.
+	for i := 0; i < 20; i++ {
+		clearPendingInfluence(hb)
+		ops, _ := hb.Schedule(tc, false)
+		re.Len(ops, 1)
+		re.Equal(2, ops[0].Len())
+		re.Contains([]uint64{5, 6}, ops[0].Step(0).(operator.AddLearner).ToStore)
+		re.Equal(uint64(4), ops[0].Step(1).(operator.RemovePeer).FromStore)
+	}
+
+	// Disable for the other engines.
+	hb.conf.SetEnableForTiFlash(false)
+	for i := 0; i < 20; i++ {
+		clearPendingInfluence(hb)
+		ops, _ := hb.Schedule(tc, false)
+		for _, op := range ops {
+			re.Equal(1, op.Len())
+			testutil.CheckTransferLeaderFrom(re, op, operator.OpHotRegion, 1)
+		}
+	}
+}
+
 func TestHotWriteRegionScheduleWithQuery(t *testing.T) {
 	re := require.New(t)
 	originValue := schedulePeerPr
@@ -727,6 +813,70 @@ func TestHotWriteRegionScheduleCheckHot(t *testing.T) {
 	re.Empty(ops)
 }
 
+func TestHotWriteRegionScheduleWithPrediction(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statistics.Denoising = false
+	opt := config.NewTestOptions()
+	hb, err := schedule.CreateScheduler(statistics.Write.String(), schedule.NewOperatorController(ctx, nil, nil), storage.NewStorageWithMemoryBackend(), nil)
+	re.NoError(err)
+	hb.(*hotScheduler).types = []statistics.RWType{statistics.Write}
+
+	tc := mockcluster.NewCluster(ctx, opt)
+	tc.SetHotRegionCacheHitsThreshold(0)
+	tc.SetClusterVersion(versioninfo.MinSupportedVersion(versioninfo.Version4_0))
+	for i := uint64(1); i <= 5; i++ {
+		tc.AddRegionStore(i, 20)
+		tc.UpdateStorageWrittenBytes(i, 0)
+	}
+	// The regions are not hot now, but they were hot at this time on the last 3 days.
+	now := time.Now()
+	for i := uint64(1); i <= 3; i++ {
+		tc.AddLeaderRegion(i, 1, 2, 3)
+		for day := 1; day <= 3; day++ {
+			tc.ObserveHotPeriod(i, statistics.Write, now.Add(-time.Duration(day)*24*time.Hour), []float64{512 * units.KiB, 5 * units.KiB, 0})
+		}
+	}
+
+	// The prediction is disabled by default.
+	ops, _ := hb.Schedule(tc, false)
+	re.Empty(ops)
+
+	tc.SetEnableHotRegionPrediction(true)
+	hb.(*hotScheduler).prepareForBalance(statistics.Write, tc)
+	re.Len(hb.(*hotScheduler).stLoadInfos[writePeer][1].HotPeers, 3)
+	re.InDelta(3*512*units.KiB, hb.(*hotScheduler).stLoadInfos[writePeer][1].LoadPred.Current.Loads[statistics.ByteDim], 1)
+	re.InDelta(3*3*512*units.KiB/5.0, hb.(*hotScheduler).stLoadInfos[writePeer][1].LoadPred.Expect.Loads[statistics.ByteDim], 1)
+
+	var transferLeaderOp *operator.Operator
+	for i := 0; i < 20; i++ {
+		clearPendingInfluence(hb.(*hotScheduler))
+		ops, _ = hb.Schedule(tc, false)
+		re.NotEmpty(ops)
+		for _, op := range ops {
+			re.True(strings.HasPrefix(op.Desc(), "predicted-"), op.Desc())
+			for j := 0; j < op.Len(); j++ {
+				if _, ok := op.Step(j).(operator.TransferLeader); ok {
+					transferLeaderOp = op
+				}
+			}
+		}
+	}
+	// The predicted influence is recorded as the pending influence.
+	pending := hb.(*hotScheduler).regionPendings[ops[0].RegionID()]
+	re.NotNil(pending)
+	re.Equal(float64(512*units.KiB), pending.origin.Loads[statistics.RegionWriteBytes])
+	// The predicted loads follow the region once the operator ends, so the influence is not kept.
+	re.Zero(pending.maxZombieDuration)
+	// The predicted leader loads are added to the operator influence.
+	re.NotNil(transferLeaderOp)
+	influence := operator.OpInfluence{StoresInfluence: make(map[uint64]*operator.StoreInfluence)}
+	transferLeaderOp.TotalInfluence(influence, tc.GetRegion(transferLeaderOp.RegionID()))
+	leaderKind := core.NewScheduleKind(core.LeaderKind, core.ByWriteByte)
+	re.Equal(int64(-512*units.KiB), influence.GetStoreInfluence(1).ResourceProperty(leaderKind))
+}
+
 func TestHotWriteRegionScheduleWithLeader(t *testing.T) {
 	re := require.New(t)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -2268,6 +2418,14 @@ func TestConfigValidation(t *testing.T) {
 	err = hc.valid()
 	re.NoError(err)
 
+	// query is not allowed to be set in priorities for tiflash-write-peer-priorities
+	hc.TiFlashWritePeerPriorities = []string{"query", "key"}
+	err = hc.valid()
+	re.Error(err)
+	hc.TiFlashWritePeerPriorities = []string{"key", "byte"}
+	err = hc.valid()
+	re.NoError(err)
+
 	// rank-formula-version
 	// default
 	hc = initHotRegionScheduleConfig()
@@ -47,25 +47,67 @@ type solver struct {
 	tolerantSizeRatio float64
 	tolerantSource    int64
 	fit               *placement.RegionFit
+	// storesLoads is only used by the load-based leader schedule policies.
+	storesLoads map[uint64][]float64
 
 	sourceScore float64
 	targetScore float64
 }
 
 func newSolver(basePlan *balanceSchedulerPlan, kind core.ScheduleKind, cluster schedule.Cluster, opInfluence operator.OpInfluence) *solver {
-	return &solver{
+	s := &solver{
 		balanceSchedulerPlan: basePlan,
 		Cluster:              cluster,
 		kind:                 kind,
 		opInfluence:          opInfluence,
 		tolerantSizeRatio:    adjustTolerantRatio(cluster, kind),
 	}
+	if kind.Resource == core.LeaderKind && kind.Policy.IsLoadPolicy() {
+		s.storesLoads = cluster.GetStoresLoads()
+	}
+	return s
 }
 
 func (p *solver) GetOpInfluence(storeID uint64) int64 {
 	return p.opInfluence.GetStoreInfluence(storeID).ResourceProperty(p.kind)
 }
 
+// leaderScore returns the leader score of the store, the delta is the change of the
+// resource which the leader schedule policy balances on.
+func (p *solver) leaderScore(store *core.StoreInfo, delta int64) float64 {
+	if kind, ok := statistics.LeaderLoadStatKind(p.kind.Policy); ok {
+		return store.LeaderLoadScore(p.storeLoad(store.GetID(), kind), delta)
+	}
+	return store.LeaderScore(p.kind.Policy, delta)
+}
+
+func (p *solver) storeLoad(storeID uint64, kind statistics.StoreStatKind) float64 {
+	loads := p.storesLoads[storeID]
+	if int(kind) >= len(loads) {
+		return 0
+	}
+	return loads[kind]
+}
+
+// averageLeaderLoad returns the average load of a leader in the stores,
+// which plays the same role as the average region size does in the tolerance.
+func (p *solver) averageLeaderLoad() float64 {
+	kind, ok := statistics.LeaderLoadStatKind(p.kind.Policy)
+	if !ok {
+		return 0
+	}
+	var totalLoad float64
+	var leaderCount int
+	for _, store := range p.GetStores() {
+		totalLoad += p.storeLoad(store.GetID(), kind)
+		leaderCount += store.GetLeaderCount()
+	}
+	if leaderCount == 0 {
+		return 0
+	}
+	return totalLoad / float64(leaderCount)
+}
+
 func (p *solver) SourceStoreID() uint64 {
 	return p.source.GetID()
 }
@@ -101,7 +143,7 @@ func (p *solver) sourceStoreScore(scheduleName string) float64 {
 	switch p.kind.Resource {
 	case core.LeaderKind:
 		sourceDelta := influence - tolerantResource
-		score = p.source.LeaderScore(p.kind.Policy, sourceDelta)
+		score = p.leaderScore(p.source, sourceDelta)
 	case core.RegionKind:
 		sourceDelta := influence*influenceAmp - tolerantResource
 		score = p.source.RegionScore(opts.GetRegionScoreFormulaVersion(), opts.GetHighSpaceRatio(), opts.GetLowSpaceRatio(), sourceDelta)
@@ -129,7 +171,7 @@ func (p *solver) targetStoreScore(scheduleName string) float64 {
 	switch p.kind.Resource {
 	case core.LeaderKind:
 		targetDelta := influence + tolerantResource
-		score = p.target.LeaderScore(p.kind.Policy, targetDelta)
+		score = p.leaderScore(p.target, targetDelta)
 	case core.RegionKind:
 		targetDelta := influence*influenceAmp + tolerantResource
 		score = p.target.RegionScore(opts.GetRegionScoreFormulaVersion(), opts.GetHighSpaceRatio(), opts.GetLowSpaceRatio(), targetDelta)
@@ -166,6 +208,8 @@ func (p *solver) getTolerantResource() int64 {
 
 	if p.kind.Resource == core.LeaderKind && p.kind.Policy == core.ByCount {
 		p.tolerantSource = int64(p.tolerantSizeRatio)
+	} else if p.kind.Resource == core.LeaderKind && p.kind.Policy.IsLoadPolicy() {
+		p.tolerantSource = int64(p.averageLeaderLoad() * p.tolerantSizeRatio)
 	} else {
 		regionSize := p.GetAverageRegionSize()
 		p.tolerantSource = int64(float64(regionSize) * p.tolerantSizeRatio)
@@ -34,6 +34,7 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/diagnosticspb"
+	"github.com/pingcap/kvproto/pkg/gcpb"
 	"github.com/pingcap/kvproto/pkg/keyspacepb"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
@@ -157,6 +158,8 @@ type Server struct {
 
 	// hot region history info storeage
 	hotRegionStorage *storage.HotRegionStorage
+	// operator history storage
+	operatorHistoryStorage *storage.OperatorHistoryStorage
 	// Store as map[string]*grpc.ClientConn
 	clientConns sync.Map
 	// tsoDispatcher is used to dispatch different TSO requests to
@@ -287,6 +290,7 @@ func CreateServer(ctx context.Context, cfg *config.Config, serviceBuilders ...Ha
 		grpcServer := &GrpcServer{Server: s}
 		pdpb.RegisterPDServer(gs, grpcServer)
 		keyspacepb.RegisterKeyspaceServer(gs, &KeyspaceServer{GrpcServer: grpcServer})
+		gcpb.RegisterGCServer(gs, &GCServer{GrpcServer: grpcServer})
 		diagnosticspb.RegisterDiagnosticsServer(gs, s)
 	}
 	s.etcdCfg = etcdCfg
@@ -434,11 +438,11 @@ func (s *Server) startServer(ctx context.Context) error {
 		Member:    s.member.MemberValue(),
 		Step:      keyspace.AllocStep,
 	})
-	s.keyspaceManager, err = keyspace.NewKeyspaceManager(s.storage, keyspaceIDAllocator)
+	s.basicCluster = core.NewBasicCluster()
+	s.keyspaceManager, err = keyspace.NewKeyspaceManager(s.storage, s.basicCluster, keyspaceIDAllocator, &s.cfg.Keyspace)
 	if err != nil {
 		return err
 	}
-	s.basicCluster = core.NewBasicCluster()
 	s.cluster = cluster.NewRaftCluster(ctx, s.clusterID, syncer.NewRegionSyncer(s), s.client, s.httpClient)
 	s.hbStreams = hbstream.NewHeartbeatStreams(ctx, s.clusterID, s.cluster)
 	// initial hot_region_storage in here.
@@ -447,6 +451,11 @@ func (s *Server) startServer(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	s.operatorHistoryStorage, err = storage.NewOperatorHistoryStorage(
+		ctx, filepath.Join(s.cfg.DataDir, "operator-history"), s.handler)
+	if err != nil {
+		return err
+	}
 	// Run callbacks
 	for _, cb := range s.startCallbacks {
 		cb()
@@ -514,6 +523,10 @@ func (s *Server) Close() {
 		log.Error("close hot region storage meet error", errs.ZapError(err))
 	}
 
+	if err := s.operatorHistoryStorage.Close(); err != nil {
+		log.Error("close operator history storage meet error", errs.ZapError(err))
+	}
+
 	// Run callbacks
 	for _, cb := range s.closeCallbacks {
 		cb()
@@ -779,6 +792,11 @@ func (s *Server) GetHistoryHotRegionStorage() *storage.HotRegionStorage {
 	return s.hotRegionStorage
 }
 
+// GetOperatorHistoryStorage returns the backend storage of the operator history.
+func (s *Server) GetOperatorHistoryStorage() *storage.OperatorHistoryStorage {
+	return s.operatorHistoryStorage
+}
+
 // SetStorage changes the storage only for test purpose.
 // When we use it, we should prevent calling GetStorage, otherwise, it may cause a data race problem.
 func (s *Server) SetStorage(storage storage.Storage) {
@@ -820,6 +838,11 @@ func (s *Server) GetKeyspaceManager() *keyspace.Manager {
 	return s.keyspaceManager
 }
 
+// GetGCSafePointManager returns the GC safe point manager of server.
+func (s *Server) GetGCSafePointManager() *gc.SafePointManager {
+	return s.gcSafePointManager
+}
+
 // Name returns the unique etcd Name for this server in etcd cluster.
 func (s *Server) Name() string {
 	return s.cfg.Name
@@ -1494,6 +1517,13 @@ func (s *Server) campaignLeader() {
 		return
 	}
 	defer s.stopRaftCluster()
+	// Persist the operators finished at the end of the leadership, since the new leader
+	// does not take them over.
+	defer func() {
+		if err := s.operatorHistoryStorage.Flush(); err != nil {
+			log.Error("failed to flush operator history", errs.ZapError(err))
+		}
+	}()
 	if err := s.idAllocator.Rebase(); err != nil {
 		log.Error("failed to sync id from etcd", errs.ZapError(err))
 		return
@@ -1502,6 +1532,10 @@ func (s *Server) campaignLeader() {
 	s.member.EnableLeader()
 	// Check the cluster dc-location after the PD leader is elected.
 	go s.tsoAllocatorManager.ClusterDCLocationChecker()
+	// Keep the keyspace lifecycle moving until the leadership is lost.
+	go s.keyspaceManager.RunLifecycleJob(ctx)
+	// Export the diagnosis of service GC safe points as metrics.
+	go s.gcSafePointManager.RunMetricsJob(ctx, s.persistOptions, s.GetGlobalTS)
 	defer resetLeaderOnce.Do(func() {
 		// as soon as cancel the leadership keepalive, then other member have chance
 		// to be new leader.
@@ -37,7 +37,7 @@ func (c tikvCollector) Engine() string {
 }
 
 func (c tikvCollector) Filter(info *StoreSummaryInfo, kind core.ResourceKind) bool {
-	if info.IsTiFlash() {
+	if !info.IsTiKV() {
 		return false
 	}
 	switch kind {
@@ -75,16 +75,20 @@ func (c tikvCollector) GetLoads(storeLoads, peerLoadSum []float64, rwTy RWType,
 	return
 }
 
+// tiflashCollector handles the stores of TiFlash and the other engines which only
+// hold learners and report statistics like TiFlash. The stores of each engine are
+// collected separately.
 type tiflashCollector struct {
+	engine            string
 	isTraceRegionFlow bool
 }
 
-func newTiFlashCollector(isTraceRegionFlow bool) storeCollector {
-	return tiflashCollector{isTraceRegionFlow: isTraceRegionFlow}
+func newTiFlashCollector(engine string, isTraceRegionFlow bool) storeCollector {
+	return tiflashCollector{engine: engine, isTraceRegionFlow: isTraceRegionFlow}
 }
 
 func (c tiflashCollector) Engine() string {
-	return core.EngineTiFlash
+	return c.engine
 }
 
 func (c tiflashCollector) Filter(info *StoreSummaryInfo, kind core.ResourceKind) bool {
@@ -92,7 +96,7 @@ func (c tiflashCollector) Filter(info *StoreSummaryInfo, kind core.ResourceKind)
 	case core.LeaderKind:
 		return false
 	case core.RegionKind:
-		return info.IsTiFlash()
+		return info.Engine() == c.engine
 	}
 	return false
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/tikv/pd/pkg/movingaverage"
 	"github.com/tikv/pd/pkg/slice"
+	"github.com/tikv/pd/server/core"
 	"go.uber.org/zap"
 )
 
@@ -82,6 +83,8 @@ type HotPeerStat struct {
 	AntiCount int `json:"anti_count"`
 
 	Kind RWType `json:"-"`
+	// engine is the engine of the store, and the statistics are never inherited across engines.
+	engine string
 	// Loads contains only Kind-related statistics and is DimLen in length.
 	Loads []float64 `json:"loads"`
 
@@ -106,6 +109,16 @@ type HotPeerStat struct {
 	// If the item in storeA is just inherited from storeB,
 	// then other store, such as storeC, will be forbidden to inherit from storeA until the item in storeA is hot.
 	allowInherited bool
+	// predicted means the peer is not hot yet, but predicted to be hot soon by HotPeriodPredictor.
+	predicted bool
+}
+
+// GetEngine returns the engine of the store where the peer is located.
+func (stat *HotPeerStat) GetEngine() string {
+	if stat.engine == "" {
+		return core.EngineTiKV
+	}
+	return stat.engine
 }
 
 // ID returns region ID. Implementing TopNItem.
@@ -226,6 +239,11 @@ func (stat *HotPeerStat) GetStores() []uint64 {
 	return stores
 }
 
+// IsPredicted indicates whether the item is predicted to be hot rather than being hot now.
+func (stat *HotPeerStat) IsPredicted() bool {
+	return stat.predicted
+}
+
 // IsLearner indicates whether the item is learner.
 func (stat *HotPeerStat) IsLearner() bool {
 	return stat.isLearner
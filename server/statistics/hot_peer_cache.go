@@ -59,7 +59,10 @@ var MinHotThresholds = [RegionStatCount]float64{
 	RegionWriteQueryNum: 32,
 }
 
-// hotPeerCache saves the hot peer's statistics.
+// hotPeerCache saves the hot peer's statistics. The TopN and the thresholds are
+// kept for each store, so the hot peers of different engines never compete with
+// each other, and the statistics are only inherited from the peers on the stores
+// of the same engine.
 type hotPeerCache struct {
 	kind               RWType
 	peersOfStore       map[uint64]*TopN               // storeID -> hot peers
@@ -182,6 +185,7 @@ func (f *hotPeerCache) checkPeerFlow(peer *core.PeerInfo, region *core.RegionInf
 		StoreID:        storeID,
 		RegionID:       regionID,
 		Kind:           f.kind,
+		engine:         peer.GetEngine(),
 		Loads:          f.kind.GetLoadRatesFromPeer(peer),
 		LastUpdateTime: time.Now(),
 		isLeader:       region.GetLeader().GetStoreId() == storeID,
@@ -196,6 +200,11 @@ func (f *hotPeerCache) checkPeerFlow(peer *core.PeerInfo, region *core.RegionInf
 	if oldItem == nil {
 		for _, storeID := range f.getAllStoreIDs(region) {
 			oldItem = f.getOldHotPeerStat(regionID, storeID)
+			// the loads of different engines are not comparable, e.g. a TiFlash learner
+			// should not inherit the loads of a TiKV peer.
+			if oldItem != nil && oldItem.GetEngine() != newItem.GetEngine() {
+				oldItem = nil
+			}
 			if oldItem != nil && oldItem.allowInherited {
 				newItem.source = inherit
 				break
@@ -231,6 +240,7 @@ func (f *hotPeerCache) checkColdPeer(storeID uint64, reportRegions map[uint64]*c
 				StoreID:  storeID,
 				RegionID: regionID,
 				Kind:     f.kind,
+				engine:   oldItem.engine,
 				// use 0 to make the cold newItem won't affect the loads.
 				Loads:          make([]float64, len(oldItem.Loads)),
 				LastUpdateTime: time.Now(),
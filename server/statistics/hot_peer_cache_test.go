@@ -586,6 +586,40 @@ func TestCacheInherit(t *testing.T) {
 	}
 }
 
+func TestCacheInheritEngine(t *testing.T) {
+	re := require.New(t)
+	cache := NewHotPeerCache(Read)
+	region := buildRegion(Read, 3, 10)
+	for i := 1; i <= 200; i++ {
+		checkAndUpdate(re, cache, region)
+	}
+	// move a peer to the TiFlash store.
+	tiflashStoreID := uint64(10)
+	index, _ := pickFollower(region)
+	srcStoreID := region.GetPeers()[index].GetStoreId()
+	_, region = schedule(re, addReplica, region, tiflashStoreID)
+	_, region = schedule(re, removeReplica, region, srcStoreID)
+	reportInterval := region.GetInterval()
+	interval := reportInterval.GetEndTimestamp() - reportInterval.GetStartTimestamp()
+	rets := cache.collectExpiredItems(region)
+	for _, peer := range region.GetPeers() {
+		peerInfo := core.NewPeerInfo(peer, region.GetLoads(), interval)
+		if peer.GetStoreId() == tiflashStoreID {
+			peerInfo.WithEngine(core.EngineTiFlash)
+		}
+		if item := cache.checkPeerFlow(peerInfo, region); item != nil {
+			rets = append(rets, item)
+		}
+	}
+	updateFlow(cache, rets)
+	// the TiFlash peer does not inherit the statistics of the TiKV peer.
+	item := cache.getOldHotPeerStat(region.GetID(), tiflashStoreID)
+	re.NotNil(item)
+	re.Equal(core.EngineTiFlash, item.GetEngine())
+	re.Equal(direct, item.source)
+	re.Equal(Add, item.actionType)
+}
+
 type testMovingAverageCase struct {
 	report []float64
 	expect []float64
@@ -0,0 +1,229 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"time"
+
+	"github.com/tikv/pd/pkg/syncutil"
+	"github.com/tikv/pd/server/core"
+)
+
+const (
+	// HotPeriodSlot is the granularity of the hot periods in a day.
+	HotPeriodSlot = 10 * time.Minute
+	// HotPeriodWindow is how long the hot regions history is used to predict the hot periods.
+	HotPeriodWindow = 7 * 24 * time.Hour
+	// minHotPeriodDays is the min count of the days on which a region is hot in the same slot,
+	// so that the slot is considered as a recurring hot period of the region.
+	minHotPeriodDays = 3
+
+	secondsPerDay = int64(24 * time.Hour / time.Second)
+)
+
+type hotPeriodKey struct {
+	regionID uint64
+	rwTy     RWType
+	slot     int64
+}
+
+// hotPrediction is the cached regions predicted to be hot in a slot of a day.
+type hotPrediction struct {
+	day, slot  int64
+	generation uint64
+	regions    map[uint64][]float64
+}
+
+// HotPeriodPredictor predicts the recurring hot periods of the regions, such as
+// the daily batch jobs, from the hot regions history. A day is divided into slots,
+// and a region is predicted to be hot in a slot if it was hot in the same slot on
+// at least minHotPeriodDays of the recent days.
+type HotPeriodPredictor struct {
+	syncutil.RWMutex
+	// periods stores the max loads of the region in the slot of each day.
+	periods map[hotPeriodKey]map[int64][]float64
+	// generation is increased whenever the periods are changed, which
+	// invalidates the cached predictions.
+	generation  uint64
+	predictions map[RWType]*hotPrediction
+}
+
+// NewHotPeriodPredictor creates a HotPeriodPredictor.
+func NewHotPeriodPredictor() *HotPeriodPredictor {
+	return &HotPeriodPredictor{
+		periods:     make(map[hotPeriodKey]map[int64][]float64),
+		predictions: make(map[RWType]*hotPrediction),
+	}
+}
+
+func hotPeriodOf(t time.Time) (day, slot int64) {
+	sec := t.Unix()
+	return sec / secondsPerDay, sec % secondsPerDay / int64(HotPeriodSlot/time.Second)
+}
+
+// ObserveHotPeriod records that the region is hot with the loads at the given time.
+// The loads are DimLen in length.
+func (p *HotPeriodPredictor) ObserveHotPeriod(regionID uint64, rwTy RWType, t time.Time, loads []float64) {
+	day, slot := hotPeriodOf(t)
+	key := hotPeriodKey{regionID: regionID, rwTy: rwTy, slot: slot}
+	p.Lock()
+	defer p.Unlock()
+	days, ok := p.periods[key]
+	if !ok {
+		days = make(map[int64][]float64)
+		p.periods[key] = days
+	}
+	maxLoads, ok := days[day]
+	if !ok {
+		maxLoads = make([]float64, DimLen)
+		days[day] = maxLoads
+		p.generation++
+	}
+	for i := range maxLoads {
+		if i < len(loads) && loads[i] > maxLoads[i] {
+			maxLoads[i] = loads[i]
+			p.generation++
+		}
+	}
+}
+
+// PredictHotRegions returns the regionID -> loads of the regions which are predicted to
+// be hot at the given time. The loads are averaged over the days on which the region was hot.
+func (p *HotPeriodPredictor) PredictHotRegions(rwTy RWType, t time.Time) map[uint64][]float64 {
+	day, slot := hotPeriodOf(t)
+	minDay := day - int64(HotPeriodWindow/(24*time.Hour))
+	p.RLock()
+	defer p.RUnlock()
+	ret := make(map[uint64][]float64)
+	for key, days := range p.periods {
+		if key.rwTy != rwTy || key.slot != slot {
+			continue
+		}
+		count := 0
+		sum := make([]float64, DimLen)
+		for d, loads := range days {
+			// only the previous days are used to find the recurring periods.
+			if d < minDay || d >= day {
+				continue
+			}
+			count++
+			for i := range sum {
+				sum[i] += loads[i]
+			}
+		}
+		if count < minHotPeriodDays {
+			continue
+		}
+		for i := range sum {
+			sum[i] /= float64(count)
+		}
+		ret[key.regionID] = sum
+	}
+	return ret
+}
+
+// GCHotPeriods removes the records which are out of the window.
+func (p *HotPeriodPredictor) GCHotPeriods(now time.Time) {
+	day, _ := hotPeriodOf(now)
+	minDay := day - int64(HotPeriodWindow/(24*time.Hour))
+	p.Lock()
+	defer p.Unlock()
+	for key, days := range p.periods {
+		for d := range days {
+			if d < minDay {
+				delete(days, d)
+				p.generation++
+			}
+		}
+		if len(days) == 0 {
+			delete(p.periods, key)
+		}
+	}
+}
+
+// ResetHotPeriods removes all the records.
+func (p *HotPeriodPredictor) ResetHotPeriods() {
+	p.Lock()
+	defer p.Unlock()
+	p.periods = make(map[hotPeriodKey]map[int64][]float64)
+	p.generation++
+}
+
+// PredictedPeerStats returns the storeID -> stat of the peers which are predicted to be hot
+// in the current or the next slot. For the write type, all the peers of the region are
+// returned, and for the read type, only the leader is returned.
+func (p *HotPeriodPredictor) PredictedPeerStats(rwTy RWType, now time.Time, getRegion func(regionID uint64) *core.RegionInfo) map[uint64][]*HotPeerStat {
+	ret := make(map[uint64][]*HotPeerStat)
+	for regionID, loads := range p.predictSoonHotRegions(rwTy, now) {
+		region := getRegion(regionID)
+		if region == nil || region.GetLeader() == nil {
+			continue
+		}
+		peers := region.GetPeers()
+		if rwTy == Read {
+			peers = peers[:0:0]
+			peers = append(peers, region.GetLeader())
+		}
+		for _, peer := range peers {
+			storeID := peer.GetStoreId()
+			ret[storeID] = append(ret[storeID], &HotPeerStat{
+				StoreID:        storeID,
+				RegionID:       regionID,
+				Kind:           rwTy,
+				Loads:          append(loads[:0:0], loads...),
+				LastUpdateTime: now,
+				isLeader:       region.GetLeader().GetStoreId() == storeID,
+				isLearner:      core.IsLearner(peer),
+				peers:          region.GetPeers(),
+				predicted:      true,
+			})
+		}
+	}
+	return ret
+}
+
+// predictSoonHotRegions returns the regionID -> max loads of the regions which are
+// predicted to be hot in the current or the next slot. The result is cached until
+// the slot passes or the periods are changed, and it must not be modified.
+func (p *HotPeriodPredictor) predictSoonHotRegions(rwTy RWType, now time.Time) map[uint64][]float64 {
+	day, slot := hotPeriodOf(now)
+	p.RLock()
+	generation := p.generation
+	prediction := p.predictions[rwTy]
+	p.RUnlock()
+	if prediction != nil && prediction.day == day && prediction.slot == slot && prediction.generation == generation {
+		return prediction.regions
+	}
+
+	predicted := p.PredictHotRegions(rwTy, now)
+	for regionID, loads := range p.PredictHotRegions(rwTy, now.Add(HotPeriodSlot)) {
+		if old, ok := predicted[regionID]; ok {
+			for i := range old {
+				if loads[i] > old[i] {
+					old[i] = loads[i]
+				}
+			}
+			continue
+		}
+		predicted[regionID] = loads
+	}
+	p.Lock()
+	// the periods may be changed during the prediction.
+	if p.generation == generation {
+		p.predictions[rwTy] = &hotPrediction{day: day, slot: slot, generation: generation, regions: predicted}
+	}
+	p.Unlock()
+	return predicted
+}
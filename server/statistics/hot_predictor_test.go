@@ -0,0 +1,138 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/server/core"
+)
+
+func TestHotPeriodPredictor(t *testing.T) {
+	re := require.New(t)
+	p := NewHotPeriodPredictor()
+	now := time.Date(2022, 8, 10, 2, 5, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	// region 1 is hot at 02:00 ~ 02:10 on the last 3 days.
+	for i := 1; i <= 3; i++ {
+		p.ObserveHotPeriod(1, Write, now.Add(-time.Duration(i)*day), []float64{float64(i) * 100, 10, 1})
+		p.ObserveHotPeriod(1, Write, now.Add(-time.Duration(i)*day+time.Minute), []float64{50, 20, 1})
+	}
+	// region 2 is hot at the same time on only 2 days.
+	for i := 1; i <= 2; i++ {
+		p.ObserveHotPeriod(2, Write, now.Add(-time.Duration(i)*day), []float64{100, 10, 1})
+	}
+	// region 3 is hot at 02:10 ~ 02:20 on the last 3 days, and it is read hot.
+	for i := 1; i <= 3; i++ {
+		p.ObserveHotPeriod(3, Read, now.Add(-time.Duration(i)*day+10*time.Minute), []float64{100, 10, 1})
+	}
+	// region 4 is hot only today.
+	for i := 0; i < 3; i++ {
+		p.ObserveHotPeriod(4, Write, now.Add(-time.Duration(i)*time.Second), []float64{100, 10, 1})
+	}
+
+	predicted := p.PredictHotRegions(Write, now)
+	re.Len(predicted, 1)
+	// the max loads of each day are averaged.
+	re.Equal([]float64{200, 20, 1}, predicted[1])
+	re.Empty(p.PredictHotRegions(Read, now))
+	re.Len(p.PredictHotRegions(Read, now.Add(HotPeriodSlot)), 1)
+	re.Empty(p.PredictHotRegions(Write, now.Add(time.Hour)))
+
+	// the records out of the window are not used.
+	re.Len(p.PredictHotRegions(Write, now.Add(4*day)), 1)
+	re.Empty(p.PredictHotRegions(Write, now.Add(5*day)))
+	p.GCHotPeriods(now.Add(5 * day))
+	re.Empty(p.PredictHotRegions(Write, now.Add(4*day)))
+
+	p.ObserveHotPeriod(1, Write, now.Add(-day), []float64{100, 10, 1})
+	p.ResetHotPeriods()
+	re.Empty(p.periods)
+}
+
+func TestPredictedPeerStats(t *testing.T) {
+	re := require.New(t)
+	p := NewHotPeriodPredictor()
+	now := time.Date(2022, 8, 10, 2, 5, 0, 0, time.UTC)
+	for i := 1; i <= 3; i++ {
+		ts := now.Add(-time.Duration(i) * 24 * time.Hour)
+		p.ObserveHotPeriod(1, Write, ts, []float64{100, 10, 1})
+		p.ObserveHotPeriod(1, Read, ts.Add(HotPeriodSlot), []float64{100, 10, 1})
+		// region 2 is not found.
+		p.ObserveHotPeriod(2, Write, ts, []float64{100, 10, 1})
+	}
+	peers := []*metapb.Peer{
+		{Id: 11, StoreId: 1},
+		{Id: 12, StoreId: 2},
+		{Id: 13, StoreId: 3, Role: metapb.PeerRole_Learner},
+	}
+	region := core.NewRegionInfo(&metapb.Region{Id: 1, Peers: peers}, peers[1])
+	getRegion := func(id uint64) *core.RegionInfo {
+		if id == region.GetID() {
+			return region
+		}
+		return nil
+	}
+
+	stats := p.PredictedPeerStats(Write, now, getRegion)
+	re.Len(stats, 3)
+	for storeID, peerStats := range stats {
+		re.Len(peerStats, 1)
+		stat := peerStats[0]
+		re.True(stat.IsPredicted())
+		re.Equal(uint64(1), stat.RegionID)
+		re.Equal(storeID, stat.StoreID)
+		re.Equal(storeID == 2, stat.IsLeader())
+		re.Equal(storeID == 3, stat.IsLearner())
+		re.Equal([]float64{100, 10, 1}, stat.GetLoads())
+	}
+
+	// the read peers predicted in the next slot are returned, and only the leader is returned.
+	stats = p.PredictedPeerStats(Read, now, getRegion)
+	re.Len(stats, 1)
+	re.Len(stats[2], 1)
+	re.True(stats[2][0].IsLeader())
+}
+
+func TestPredictedPeerStatsCache(t *testing.T) {
+	re := require.New(t)
+	p := NewHotPeriodPredictor()
+	now := time.Date(2022, 8, 10, 2, 5, 0, 0, time.UTC)
+	for i := 1; i <= 3; i++ {
+		p.ObserveHotPeriod(1, Write, now.Add(-time.Duration(i)*24*time.Hour), []float64{100, 10, 1})
+	}
+	region := core.NewRegionInfo(&metapb.Region{Id: 1, Peers: []*metapb.Peer{{Id: 11, StoreId: 1}}}, &metapb.Peer{Id: 11, StoreId: 1})
+	getRegion := func(uint64) *core.RegionInfo { return region }
+	re.Equal([]float64{100, 10, 1}, p.PredictedPeerStats(Write, now, getRegion)[1][0].GetLoads())
+	prediction := p.predictions[Write]
+	re.NotNil(prediction)
+
+	// observing the same loads again does not invalidate the cache.
+	p.ObserveHotPeriod(1, Write, now.Add(-24*time.Hour), []float64{100, 10, 1})
+	p.PredictedPeerStats(Write, now, getRegion)
+	re.Same(prediction, p.predictions[Write])
+
+	// the cache is invalidated if the loads are changed or the slot passes.
+	p.ObserveHotPeriod(1, Write, now.Add(-24*time.Hour), []float64{400, 10, 1})
+	re.Equal([]float64{200, 10, 1}, p.PredictedPeerStats(Write, now, getRegion)[1][0].GetLoads())
+	re.NotSame(prediction, p.predictions[Write])
+	prediction = p.predictions[Write]
+	re.Empty(p.PredictedPeerStats(Write, now.Add(2*HotPeriodSlot), getRegion))
+	re.NotSame(prediction, p.predictions[Write])
+}
@@ -20,6 +20,7 @@ import (
 
 // HotPeersStat records all hot regions statistics
 type HotPeersStat struct {
+	Engine         string            `json:"engine,omitempty"`
 	StoreByteRate  float64           `json:"store_bytes"`
 	StoreKeyRate   float64           `json:"store_keys"`
 	StoreQueryRate float64           `json:"store_query"`
@@ -22,12 +22,14 @@ import (
 type HotStat struct {
 	*HotCache
 	*StoresStats
+	*HotPeriodPredictor
 }
 
 // NewHotStat creates the container to hold cluster's hotspot statistics.
 func NewHotStat(ctx context.Context) *HotStat {
 	return &HotStat{
-		HotCache:    NewHotCache(ctx),
-		StoresStats: NewStoresStats(),
+		HotCache:           NewHotCache(ctx),
+		StoresStats:        NewStoresStats(),
+		HotPeriodPredictor: NewHotPeriodPredictor(),
 	}
 }
@@ -145,6 +145,22 @@ func (k StoreStatKind) String() string {
 	return "unknown StoreStatKind"
 }
 
+// LeaderLoadStatKind returns the store statistics kind which the load-based leader schedule policy balances on.
+func LeaderLoadStatKind(policy core.SchedulePolicy) (StoreStatKind, bool) {
+	switch policy {
+	case core.ByReadQuery:
+		return StoreReadQuery, true
+	case core.ByWriteQuery:
+		return StoreWriteQuery, true
+	case core.ByReadByte:
+		return StoreReadBytes, true
+	case core.ByWriteByte:
+		return StoreWriteBytes, true
+	default:
+		return 0, false
+	}
+}
+
 // sourceKind represents the statistics item source.
 type sourceKind int
 
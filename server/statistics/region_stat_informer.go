@@ -26,4 +26,7 @@ type RegionStatInformer interface {
 	// RegionReadStats return the storeID -> read stat of peers on this store.
 	// The result only includes peers that are hot enough.
 	RegionReadStats() map[uint64][]*HotPeerStat
+	// PredictedRegionStats return the storeID -> stat of peers on this store
+	// which are predicted to be hot soon by the hot regions history.
+	PredictedRegionStats(rw RWType) map[uint64][]*HotPeerStat
 }
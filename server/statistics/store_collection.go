@@ -113,7 +113,16 @@ func (s *storeStatistics) Observe(store *core.StoreInfo, stats *StoresStats) {
 	s.WitnessCount += store.GetWitnessCount()
 
 	storeStatusGauge.WithLabelValues(storeAddress, id, "region_score").Set(store.RegionScore(s.opt.GetRegionScoreFormulaVersion(), s.opt.GetHighSpaceRatio(), s.opt.GetLowSpaceRatio(), 0))
-	storeStatusGauge.WithLabelValues(storeAddress, id, "leader_score").Set(store.LeaderScore(s.opt.GetLeaderSchedulePolicy(), 0))
+	leaderSchedulePolicy := s.opt.GetLeaderSchedulePolicy()
+	if kind, ok := LeaderLoadStatKind(leaderSchedulePolicy); ok {
+		var load float64
+		if storeFlowStats := stats.GetRollingStoreStats(store.GetID()); storeFlowStats != nil {
+			load = storeFlowStats.GetLoad(kind)
+		}
+		storeStatusGauge.WithLabelValues(storeAddress, id, "leader_score").Set(store.LeaderLoadScore(load, 0))
+	} else {
+		storeStatusGauge.WithLabelValues(storeAddress, id, "leader_score").Set(store.LeaderScore(leaderSchedulePolicy, 0))
+	}
 	storeStatusGauge.WithLabelValues(storeAddress, id, "region_size").Set(float64(store.GetRegionSize()))
 	storeStatusGauge.WithLabelValues(storeAddress, id, "region_count").Set(float64(store.GetRegionCount()))
 	storeStatusGauge.WithLabelValues(storeAddress, id, "leader_size").Set(float64(store.GetLeaderSize()))
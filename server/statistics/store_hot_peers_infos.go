@@ -17,7 +17,9 @@ package statistics
 import (
 	"fmt"
 	"math"
+	"sort"
 
+	"github.com/tikv/pd/pkg/slice"
 	"github.com/tikv/pd/server/core"
 )
 
@@ -76,27 +78,39 @@ func SummaryStoresLoad(
 	// loadDetail stores the storeID -> hotPeers stat and its current and future stat(rate,count)
 	loadDetail := make(map[uint64]*StoreLoadDetail, len(storesLoads))
 
-	tikvLoadDetail := summaryStoresLoadByEngine(
-		storeInfos,
-		storesLoads,
-		storeHotPeers,
-		rwTy, kind,
-		newTikvCollector(),
-	)
-	tiflashLoadDetail := summaryStoresLoadByEngine(
-		storeInfos,
-		storesLoads,
-		storeHotPeers,
-		rwTy, kind,
-		newTiFlashCollector(isTraceRegionFlow),
-	)
-
-	for _, detail := range append(tikvLoadDetail, tiflashLoadDetail...) {
-		loadDetail[detail.GetID()] = detail
+	// The expectations are calculated separately for the stores of each engine.
+	collectors := []storeCollector{newTikvCollector()}
+	for _, engine := range SummaryEngines(storeInfos) {
+		if engine != core.EngineTiKV {
+			collectors = append(collectors, newTiFlashCollector(engine, isTraceRegionFlow))
+		}
+	}
+	for _, collector := range collectors {
+		for _, detail := range summaryStoresLoadByEngine(
+			storeInfos,
+			storesLoads,
+			storeHotPeers,
+			rwTy, kind,
+			collector,
+		) {
+			loadDetail[detail.GetID()] = detail
+		}
 	}
 	return loadDetail
 }
 
+// SummaryEngines returns the sorted engines of the stores.
+func SummaryEngines(storeInfos map[uint64]*StoreSummaryInfo) []string {
+	engines := make([]string, 0, 2)
+	for _, info := range storeInfos {
+		if !slice.AnyOf(engines, func(i int) bool { return engines[i] == info.Engine() }) {
+			engines = append(engines, info.Engine())
+		}
+	}
+	sort.Strings(engines)
+	return engines
+}
+
 func summaryStoresLoadByEngine(
 	storeInfos map[uint64]*StoreSummaryInfo,
 	storesLoads map[uint64][]float64,
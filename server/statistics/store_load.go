@@ -33,6 +33,7 @@ func (li *StoreLoadDetail) ToHotPeersStat() *HotPeersStat {
 		li.LoadPred.Current.Loads[KeyDim], li.LoadPred.Current.Loads[QueryDim]
 	if len(li.HotPeers) == 0 {
 		return &HotPeersStat{
+			Engine:         li.Engine(),
 			StoreByteRate:  storeByteRate,
 			StoreKeyRate:   storeKeyRate,
 			StoreQueryRate: storeQueryRate,
@@ -55,6 +56,7 @@ func (li *StoreLoadDetail) ToHotPeersStat() *HotPeersStat {
 	}
 
 	return &HotPeersStat{
+		Engine:         li.Engine(),
 		TotalBytesRate: byteRate,
 		TotalKeysRate:  keyRate,
 		TotalQueryRate: queryRate,
@@ -93,7 +95,7 @@ func toHotPeerStatShow(p *HotPeerStat) HotPeerStatShow {
 // StoreSummaryInfo records the summary information of store.
 type StoreSummaryInfo struct {
 	*core.StoreInfo
-	isTiFlash  bool
+	engine     string
 	PendingSum *Influence
 }
 
@@ -109,7 +111,7 @@ func SummaryStoreInfos(stores []*core.StoreInfo) map[uint64]*StoreSummaryInfo {
 	for _, store := range stores {
 		info := &StoreSummaryInfo{
 			StoreInfo:  store,
-			isTiFlash:  store.IsTiFlash(),
+			engine:     store.GetEngine(),
 			PendingSum: nil,
 		}
 		infos[store.GetID()] = info
@@ -136,12 +138,25 @@ func (s *StoreSummaryInfo) AddInfluence(infl *Influence, w float64) {
 
 // IsTiFlash returns true if the store is TiFlash.
 func (s *StoreSummaryInfo) IsTiFlash() bool {
-	return s.isTiFlash
+	return s.engine == core.EngineTiFlash
+}
+
+// IsTiKV returns true if the store is TiKV.
+func (s *StoreSummaryInfo) IsTiKV() bool {
+	return s.Engine() == core.EngineTiKV
+}
+
+// Engine returns the engine of the store.
+func (s *StoreSummaryInfo) Engine() string {
+	if s.engine == "" {
+		return core.EngineTiKV
+	}
+	return s.engine
 }
 
 // SetEngineAsTiFlash set whether store is TiFlash, it is only used in tests.
 func (s *StoreSummaryInfo) SetEngineAsTiFlash() {
-	s.isTiFlash = true
+	s.engine = core.EngineTiFlash
 }
 
 // StoreLoad records the current load.
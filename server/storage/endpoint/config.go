@@ -29,6 +29,12 @@ type ConfigStorage interface {
 	LoadAllScheduleConfig() ([]string, []string, error)
 	SaveScheduleConfig(scheduleName string, data []byte) error
 	RemoveScheduleConfig(scheduleName string) error
+	LoadAllSchedulingWindows() ([]string, []string, error)
+	SaveSchedulingWindows(name string, data []byte) error
+	RemoveSchedulingWindows(name string) error
+	LoadAllSchedulerDryRun() ([]string, error)
+	SaveSchedulerDryRun(scheduleName string) error
+	RemoveSchedulerDryRun(scheduleName string) error
 }
 
 var _ ConfigStorage = (*StorageEndpoint)(nil)
@@ -74,3 +80,43 @@ func (se *StorageEndpoint) SaveScheduleConfig(scheduleName string, data []byte)
 func (se *StorageEndpoint) RemoveScheduleConfig(scheduleName string) error {
 	return se.Remove(scheduleConfigPath(scheduleName))
 }
+
+// LoadAllSchedulingWindows loads the scheduling windows of all schedulers and checkers.
+func (se *StorageEndpoint) LoadAllSchedulingWindows() ([]string, []string, error) {
+	prefix := schedulingWindowPath + "/"
+	keys, values, err := se.LoadRange(prefix, clientv3.GetPrefixRangeEnd(prefix), 1000)
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, prefix)
+	}
+	return keys, values, err
+}
+
+// SaveSchedulingWindows saves the scheduling windows of a scheduler or checker.
+func (se *StorageEndpoint) SaveSchedulingWindows(name string, data []byte) error {
+	return se.Save(schedulingWindowsPath(name), string(data))
+}
+
+// RemoveSchedulingWindows removes the scheduling windows of a scheduler or checker.
+func (se *StorageEndpoint) RemoveSchedulingWindows(name string) error {
+	return se.Remove(schedulingWindowsPath(name))
+}
+
+// LoadAllSchedulerDryRun loads the names of the schedulers in dry-run mode.
+func (se *StorageEndpoint) LoadAllSchedulerDryRun() ([]string, error) {
+	prefix := schedulerDryRunPath + "/"
+	keys, _, err := se.LoadRange(prefix, clientv3.GetPrefixRangeEnd(prefix), 1000)
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, prefix)
+	}
+	return keys, err
+}
+
+// SaveSchedulerDryRun marks the scheduler as in dry-run mode.
+func (se *StorageEndpoint) SaveSchedulerDryRun(scheduleName string) error {
+	return se.Save(schedulerDryRunKeyPath(scheduleName), "true")
+}
+
+// RemoveSchedulerDryRun removes the dry-run mark of the scheduler.
+func (se *StorageEndpoint) RemoveSchedulerDryRun(scheduleName string) error {
+	return se.Remove(schedulerDryRunKeyPath(scheduleName))
+}
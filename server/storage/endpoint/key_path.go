@@ -29,9 +29,12 @@ const (
 	gcPath                     = "gc"
 	rulesPath                  = "rules"
 	ruleGroupPath              = "rule_group"
+	ruleVersionPath            = "rule_version"
 	regionLabelPath            = "region_label"
 	replicationPath            = "replication_mode"
 	customScheduleConfigPath   = "scheduler_config"
+	schedulingWindowPath       = "scheduling_window"
+	schedulerDryRunPath        = "scheduler_dry_run"
 	gcWorkerServiceSafePointID = "gc_worker"
 	minResolvedTS              = "min_resolved_ts"
 	externalTimeStamp          = "external_timestamp"
@@ -66,6 +69,14 @@ func scheduleConfigPath(scheduleName string) string {
 	return path.Join(customScheduleConfigPath, scheduleName)
 }
 
+func schedulingWindowsPath(name string) string {
+	return path.Join(schedulingWindowPath, name)
+}
+
+func schedulerDryRunKeyPath(scheduleName string) string {
+	return path.Join(schedulerDryRunPath, scheduleName)
+}
+
 // StorePath returns the store meta info key path with the given store ID.
 func StorePath(storeID uint64) string {
 	return path.Join(clusterPath, "s", fmt.Sprintf("%020d", storeID))
@@ -112,6 +123,10 @@ func ruleGroupIDPath(groupID string) string {
 	return path.Join(ruleGroupPath, groupID)
 }
 
+func ruleVersionKeyPath(version uint64) string {
+	return path.Join(ruleVersionPath, fmt.Sprintf("%020d", version))
+}
+
 func regionLabelKeyPath(ruleKey string) string {
 	return path.Join(regionLabelPath, ruleKey)
 }
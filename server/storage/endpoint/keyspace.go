@@ -48,6 +48,8 @@ type KeyspaceStorage interface {
 	// It first constructs path to spaceID with the given name, then attempt to retrieve
 	// target spaceID. If the target keyspace does not exist, result boolean is set to false.
 	LoadKeyspaceIDByName(name string) (bool, uint32, error)
+	// RemoveKeyspaceIDByName removes the keyspace name to ID lookup information.
+	RemoveKeyspaceIDByName(name string) error
 }
 
 var _ KeyspaceStorage = (*StorageEndpoint)(nil)
@@ -114,3 +116,9 @@ func (se *StorageEndpoint) LoadKeyspaceIDByName(name string) (bool, uint32, erro
 	}
 	return true, uint32(id64), nil
 }
+
+// RemoveKeyspaceIDByName removes the keyspace name to ID lookup information from storage.
+func (se *StorageEndpoint) RemoveKeyspaceIDByName(name string) error {
+	key := KeyspaceIDPath(name)
+	return se.Remove(key)
+}
@@ -15,8 +15,10 @@
 package endpoint
 
 import (
+	"encoding/json"
 	"strings"
 
+	"github.com/tikv/pd/pkg/errs"
 	"go.etcd.io/etcd/clientv3"
 )
 
@@ -28,6 +30,9 @@ type RuleStorage interface {
 	LoadRuleGroups(f func(k, v string)) error
 	SaveRuleGroup(groupID string, group interface{}) error
 	DeleteRuleGroup(groupID string) error
+	LoadRuleVersions(f func(k, v string)) error
+	SaveRuleVersion(version uint64, ruleVersion interface{}) error
+	DeleteRuleVersion(version uint64) error
 	LoadRegionRules(f func(k, v string)) error
 	SaveRegionRule(ruleKey string, rule interface{}) error
 	DeleteRegionRule(ruleKey string) error
@@ -60,6 +65,25 @@ func (se *StorageEndpoint) DeleteRuleGroup(groupID string) error {
 	return se.Remove(ruleGroupIDPath(groupID))
 }
 
+// LoadRuleVersions loads all versions of the placement rules from storage.
+func (se *StorageEndpoint) LoadRuleVersions(f func(k, v string)) error {
+	return se.loadRangeByPrefix(ruleVersionPath+"/", f)
+}
+
+// SaveRuleVersion stores a version of the placement rules to storage.
+func (se *StorageEndpoint) SaveRuleVersion(version uint64, ruleVersion interface{}) error {
+	value, err := json.Marshal(ruleVersion)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByArgs()
+	}
+	return se.Save(ruleVersionKeyPath(version), string(value))
+}
+
+// DeleteRuleVersion removes a version of the placement rules from storage.
+func (se *StorageEndpoint) DeleteRuleVersion(version uint64) error {
+	return se.Remove(ruleVersionKeyPath(version))
+}
+
 // LoadRegionRules loads region rules from storage.
 func (se *StorageEndpoint) LoadRegionRules(f func(k, v string)) error {
 	return se.loadRangeByPrefix(regionLabelPath+"/", f)
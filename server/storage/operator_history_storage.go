@@ -0,0 +1,303 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/syncutil"
+	"github.com/tikv/pd/server/storage/kv"
+	"go.uber.org/zap"
+)
+
+// operatorHistoryFlushInterval is the interval to write the finished operators into leveldb.
+const operatorHistoryFlushInterval = time.Minute
+
+// OperatorHistoryStorage is used to store the history of finished operators.
+// It will pull the finished operators every `operatorHistoryFlushInterval`,
+// and delete data beyond the reserved days.
+// The history is kept in the local leveldb of the PD which is the leader when the
+// operators finish, it is not replicated, so the history written during the former
+// leaderships of other PDs can only be queried from them.
+// Close() must be called after the use.
+type OperatorHistoryStorage struct {
+	*kv.LevelDBKV
+	loopWg  sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	handler OperatorHistoryStorageHandler
+	// seq tells apart the operators of the same region finished in the same millisecond.
+	seq uint64
+
+	curReservedDays uint64
+	mu              syncutil.RWMutex
+}
+
+// HistoryOperators wraps HistoryOperator.
+//
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type HistoryOperators struct {
+	HistoryOperator []*HistoryOperator `json:"history_operator"`
+}
+
+// HistoryOperator is the storage format of a finished operator.
+type HistoryOperator struct {
+	// FinishTime and CreateTime are in ms.
+	FinishTime     int64    `json:"finish_time"`
+	CreateTime     int64    `json:"create_time"`
+	RegionID       uint64   `json:"region_id"`
+	Desc           string   `json:"desc"`
+	Kind           string   `json:"kind"`
+	SchedulerName  string   `json:"scheduler_name,omitempty"`
+	Status         string   `json:"status"`
+	StoreIDs       []uint64 `json:"store_ids"`
+	Steps          []string `json:"steps"`
+	AdditionalInfo string   `json:"additional_info,omitempty"`
+}
+
+// OperatorHistoryStorageHandler helps operator history storage get the finished operators.
+type OperatorHistoryStorageHandler interface {
+	// PackHistoryOperators gets the operators finished since the last call in HistoryOperator form.
+	PackHistoryOperators() ([]HistoryOperator, error)
+	// IsLeader return true means this server is leader.
+	IsLeader() bool
+	// GetOperatorHistoryReservedDays gets days the history of finished operators is kept.
+	GetOperatorHistoryReservedDays() uint64
+}
+
+// NewOperatorHistoryStorage creates storage to store the history of finished operators.
+func NewOperatorHistoryStorage(
+	ctx context.Context,
+	filePath string,
+	handler OperatorHistoryStorageHandler,
+) (*OperatorHistoryStorage, error) {
+	levelDB, err := kv.NewLevelDBKV(filePath)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	o := &OperatorHistoryStorage{
+		LevelDBKV:       levelDB,
+		ctx:             ctx,
+		cancel:          cancel,
+		handler:         handler,
+		curReservedDays: handler.GetOperatorHistoryReservedDays(),
+	}
+	o.loopWg.Add(2)
+	go o.backgroundFlush()
+	go o.backgroundDelete()
+	return o, nil
+}
+
+// Delete the operators whose finish_time is smaller than time.Now() minus reserved days in the background.
+func (o *OperatorHistoryStorage) backgroundDelete() {
+	// make delete happened in defaultDeleteTime clock.
+	now := time.Now()
+	next := time.Date(now.Year(), now.Month(), now.Day(), defaultDeleteTime, 0, 0, 0, now.Location())
+	d := next.Sub(now)
+	if d < 0 {
+		d += 24 * time.Hour
+	}
+	isFirst := true
+	ticker := time.NewTicker(d)
+	defer func() {
+		ticker.Stop()
+		o.loopWg.Done()
+	}()
+	for {
+		select {
+		case <-ticker.C:
+			o.updateReservedDays()
+			if isFirst {
+				ticker.Reset(24 * time.Hour)
+				isFirst = false
+			}
+			curReservedDays := o.getCurReservedDays()
+			if curReservedDays == 0 {
+				continue
+			}
+			if err := o.delete(int(curReservedDays)); err != nil {
+				log.Error("delete operator history meet error", errs.ZapError(err))
+			}
+		case <-o.ctx.Done():
+			return
+		}
+	}
+}
+
+// Write the finished operators into db in the background.
+func (o *OperatorHistoryStorage) backgroundFlush() {
+	ticker := time.NewTicker(operatorHistoryFlushInterval)
+	defer func() {
+		ticker.Stop()
+		o.loopWg.Done()
+	}()
+	for {
+		select {
+		case <-ticker.C:
+			if !o.handler.IsLeader() {
+				continue
+			}
+			if err := o.Flush(); err != nil {
+				log.Error("write operator history meet error", errs.ZapError(err))
+			}
+		case <-o.ctx.Done():
+			return
+		}
+	}
+}
+
+// Flush writes the operators finished since the last flush into leveldb. Besides the
+// periodical flush, it is called once the PD steps down or closes, so that the operators
+// finished at the end of the leadership are not lost.
+func (o *OperatorHistoryStorage) Flush() error {
+	o.updateReservedDays()
+	if o.getCurReservedDays() == 0 {
+		return nil
+	}
+	return o.flush()
+}
+
+// NewIterator returns an iterator which traverses the operators finished in [startTime, endTime].
+func (o *OperatorHistoryStorage) NewIterator(startTime, endTime int64) OperatorHistoryIterator {
+	startKey := operatorHistoryTimePrefix(startTime)
+	endKey := operatorHistoryTimePrefix(endTime + 1)
+	return OperatorHistoryIterator{
+		iter: o.LevelDBKV.NewIterator(&util.Range{Start: []byte(startKey), Limit: []byte(endKey)}, nil),
+	}
+}
+
+// Close closes the kv.
+func (o *OperatorHistoryStorage) Close() error {
+	o.cancel()
+	o.loopWg.Wait()
+	if err := o.Flush(); err != nil {
+		log.Error("write operator history meet error", errs.ZapError(err))
+	}
+	if err := o.LevelDBKV.Close(); err != nil {
+		return errs.ErrLevelDBClose.Wrap(err).GenWithStackByArgs()
+	}
+	return nil
+}
+
+func (o *OperatorHistoryStorage) updateReservedDays() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	reservedDays := o.handler.GetOperatorHistoryReservedDays()
+	if reservedDays != o.curReservedDays {
+		log.Info("operator history reserved days changed",
+			zap.Uint64("previous-reserved-days", o.curReservedDays),
+			zap.Uint64("new-reserved-days", reservedDays))
+		o.curReservedDays = reservedDays
+	}
+}
+
+func (o *OperatorHistoryStorage) getCurReservedDays() uint64 {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.curReservedDays
+}
+
+func (o *OperatorHistoryStorage) flush() error {
+	operators, err := o.handler.PackHistoryOperators()
+	if err != nil || len(operators) == 0 {
+		return err
+	}
+	batch := new(leveldb.Batch)
+	for i := range operators {
+		value, err := json.Marshal(&operators[i])
+		if err != nil {
+			return errs.ErrProtoMarshal.Wrap(err).GenWithStackByCause()
+		}
+		seq := atomic.AddUint64(&o.seq, 1)
+		batch.Put([]byte(OperatorHistoryPath(operators[i].FinishTime, operators[i].RegionID, seq)), value)
+	}
+	if err := o.LevelDBKV.Write(batch, nil); err != nil {
+		return errs.ErrLevelDBWrite.Wrap(err).GenWithStackByCause()
+	}
+	return nil
+}
+
+func (o *OperatorHistoryStorage) delete(reservedDays int) error {
+	startKey := operatorHistoryTimePrefix(0)
+	endTime := time.Now().AddDate(0, 0, 0-reservedDays).UnixNano() / int64(time.Millisecond)
+	endKey := operatorHistoryTimePrefix(endTime + 1)
+	iter := o.LevelDBKV.NewIterator(&util.Range{Start: []byte(startKey), Limit: []byte(endKey)}, nil)
+	defer iter.Release()
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Delete(iter.Key())
+	}
+	if err := o.LevelDBKV.Write(batch, nil); err != nil {
+		return errs.ErrLevelDBWrite.Wrap(err).GenWithStackByCause()
+	}
+	return nil
+}
+
+// OperatorHistoryIterator iterates over the history of operators in the order of finish time.
+type OperatorHistoryIterator struct {
+	iter iterator.Iterator
+}
+
+// Next moves the iterator to the next key/value pair.
+// And return the HistoryOperator which it is now pointing to.
+// It will return (nil, nil) and release the iterator if there is no more HistoryOperator.
+func (it *OperatorHistoryIterator) Next() (*HistoryOperator, error) {
+	if !it.iter.Next() {
+		it.iter.Release()
+		return nil, it.iter.Error()
+	}
+	var op HistoryOperator
+	if err := json.Unmarshal(it.iter.Value(), &op); err != nil {
+		it.iter.Release()
+		return nil, err
+	}
+	return &op, nil
+}
+
+// Release releases the iterator, it is needed if the iteration stops before Next returns nil.
+func (it *OperatorHistoryIterator) Release() {
+	it.iter.Release()
+}
+
+// OperatorHistoryPath generates the key of a finished operator for OperatorHistoryStorage.
+// The sequence tells apart the operators of the same region finished in the same millisecond.
+func OperatorHistoryPath(finishTime int64, regionID, seq uint64) string {
+	return path.Join(
+		operatorHistoryTimePrefix(finishTime),
+		fmt.Sprintf("%020d", regionID),
+		fmt.Sprintf("%020d", seq),
+	)
+}
+
+// operatorHistoryTimePrefix is the prefix of the keys of the operators finished at the given time.
+func operatorHistoryTimePrefix(finishTime int64) string {
+	return path.Join(
+		"schedule",
+		"operator_history",
+		fmt.Sprintf("%020d", finishTime),
+	)
+}
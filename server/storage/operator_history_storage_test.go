@@ -0,0 +1,112 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockPackHistoryOperators struct {
+	operators    []HistoryOperator
+	reservedDays uint64
+}
+
+func (m *mockPackHistoryOperators) PackHistoryOperators() ([]HistoryOperator, error) {
+	operators := m.operators
+	m.operators = nil
+	return operators, nil
+}
+
+func (m *mockPackHistoryOperators) IsLeader() bool {
+	return true
+}
+
+func (m *mockPackHistoryOperators) GetOperatorHistoryReservedDays() uint64 {
+	return m.reservedDays
+}
+
+func TestOperatorHistoryStorage(t *testing.T) {
+	re := require.New(t)
+	handler := &mockPackHistoryOperators{reservedDays: 7}
+	dir := t.TempDir()
+	store, err := NewOperatorHistoryStorage(context.Background(), dir, handler)
+	re.NoError(err)
+
+	now := time.Now()
+	toMs := func(t time.Time) int64 { return t.UnixNano() / int64(time.Millisecond) }
+	for i := 0; i < 10; i++ {
+		handler.operators = append(handler.operators, HistoryOperator{
+			FinishTime:    toMs(now.AddDate(0, 0, -i)),
+			RegionID:      uint64(i),
+			Desc:          "balance-region",
+			Kind:          "region",
+			SchedulerName: "balance-region-scheduler",
+			Status:        "Success",
+			StoreIDs:      []uint64{1, 2},
+			Steps:         []string{"add learner peer 1 on store 2", "remove peer on store 1"},
+		})
+	}
+	re.NoError(store.flush())
+	// The finished operators are taken only once.
+	re.NoError(store.flush())
+
+	iter := store.NewIterator(toMs(now.AddDate(0, 0, -2)), toMs(now))
+	var results []*HistoryOperator
+	for next, err := iter.Next(); next != nil; next, err = iter.Next() {
+		re.NoError(err)
+		results = append(results, next)
+	}
+	re.Len(results, 3)
+	// The operators are sorted by finish time.
+	for i, op := range results {
+		re.Equal(uint64(2-i), op.RegionID)
+		re.Equal([]uint64{1, 2}, op.StoreIDs)
+		re.Equal("balance-region-scheduler", op.SchedulerName)
+	}
+
+	re.NoError(store.delete(5))
+	iter = store.NewIterator(0, toMs(now))
+	count := 0
+	for next, err := iter.Next(); next != nil; next, err = iter.Next() {
+		re.NoError(err)
+		re.Less(next.RegionID, uint64(5))
+		count++
+	}
+	re.Equal(5, count)
+
+	// The operators of the same region finished in the same millisecond are all kept.
+	handler.operators = []HistoryOperator{{FinishTime: toMs(now), RegionID: 1, Status: "Canceled"}, {FinishTime: toMs(now), RegionID: 1, Status: "Replaced"}}
+	re.NoError(store.Flush())
+	// The operators finished since the last flush are written once the storage is closed.
+	handler.operators = []HistoryOperator{{FinishTime: toMs(now), RegionID: 2, Status: "Success"}}
+	re.NoError(store.Close())
+	store, err = NewOperatorHistoryStorage(context.Background(), dir, handler)
+	re.NoError(err)
+	defer store.Close()
+	iter = store.NewIterator(toMs(now), toMs(now))
+	var regions []uint64
+	var statuses []string
+	for next, err := iter.Next(); next != nil; next, err = iter.Next() {
+		re.NoError(err)
+		regions = append(regions, next.RegionID)
+		statuses = append(statuses, next.Status)
+	}
+	re.Equal([]uint64{0, 1, 1, 2}, regions)
+	re.Equal([]string{"Success", "Canceled", "Replaced", "Success"}, statuses)
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/tikv/pd/server"
 	"github.com/tikv/pd/server/config"
 	"github.com/tikv/pd/server/core"
+	"github.com/tikv/pd/server/keyspace"
 	"github.com/tikv/pd/server/storage/endpoint"
 	"github.com/tikv/pd/server/tso"
 	"github.com/tikv/pd/tests"
@@ -1360,6 +1361,68 @@ func (suite *clientTestSuite) TestUpdateServiceGCSafePoint() {
 	suite.Equal(int64(math.MaxInt64), minSsp.ExpiredAt)
 }
 
+func (suite *clientTestSuite) TestUpdateKeyspaceGCSafePoint() {
+	ctx := context.Background()
+	manager := suite.srv.GetKeyspaceManager()
+	meta, err := manager.CreateKeyspace(&keyspace.CreateKeyspaceRequest{Name: "gc_1", Now: time.Now().Unix()})
+	suite.NoError(err)
+	keyspaceID := meta.GetId()
+	other, err := manager.CreateKeyspace(&keyspace.CreateKeyspaceRequest{Name: "gc_2", Now: time.Now().Unix()})
+	suite.NoError(err)
+	safePoint, err := suite.client.LoadKeyspaceGCSafePoint(ctx, keyspaceID)
+	suite.NoError(err)
+	suite.Equal(uint64(0), safePoint)
+
+	// The safe points of a non-existing keyspace should not be stored.
+	_, err = suite.client.UpdateKeyspaceGCSafePoint(ctx, other.GetId()+1, 10)
+	suite.Error(err)
+	_, err = suite.client.UpdateKeyspaceServiceGCSafePoint(ctx, other.GetId()+1, "a", 1000, 10)
+	suite.Error(err)
+
+	// Without any service safe point, the GC safe point can be advanced freely.
+	minSafePoint, err := suite.client.GetKeyspaceMinServiceGCSafePoint(ctx, keyspaceID)
+	suite.NoError(err)
+	suite.Greater(minSafePoint, uint64(0))
+	newSafePoint, err := suite.client.UpdateKeyspaceGCSafePoint(ctx, keyspaceID, 10)
+	suite.NoError(err)
+	suite.Equal(uint64(10), newSafePoint)
+	safePoint, err = suite.client.LoadKeyspaceGCSafePoint(ctx, keyspaceID)
+	suite.NoError(err)
+	suite.Equal(uint64(10), safePoint)
+	// The GC safe point of other keyspaces should not be affected.
+	safePoint, err = suite.client.LoadKeyspaceGCSafePoint(ctx, other.GetId())
+	suite.NoError(err)
+	suite.Equal(uint64(0), safePoint)
+
+	// Service safe point less than the GC safe point should not be accepted.
+	gcSafePoint, err := suite.client.UpdateKeyspaceServiceGCSafePoint(ctx, keyspaceID, "a", 1000, 5)
+	suite.NoError(err)
+	suite.Equal(uint64(10), gcSafePoint)
+	minSafePoint, err = suite.client.GetKeyspaceMinServiceGCSafePoint(ctx, keyspaceID)
+	suite.NoError(err)
+	suite.Greater(minSafePoint, uint64(10))
+
+	_, err = suite.client.UpdateKeyspaceServiceGCSafePoint(ctx, keyspaceID, "a", 1000, 20)
+	suite.NoError(err)
+	minSafePoint, err = suite.client.GetKeyspaceMinServiceGCSafePoint(ctx, keyspaceID)
+	suite.NoError(err)
+	suite.Equal(uint64(20), minSafePoint)
+	// The GC safe point cannot exceed the min service safe point.
+	newSafePoint, err = suite.client.UpdateKeyspaceGCSafePoint(ctx, keyspaceID, 30)
+	suite.NoError(err)
+	suite.Equal(uint64(10), newSafePoint)
+	newSafePoint, err = suite.client.UpdateKeyspaceGCSafePoint(ctx, keyspaceID, 20)
+	suite.NoError(err)
+	suite.Equal(uint64(20), newSafePoint)
+
+	// Remove the service safe point with a non-positive TTL.
+	_, err = suite.client.UpdateKeyspaceServiceGCSafePoint(ctx, keyspaceID, "a", -1, 0)
+	suite.NoError(err)
+	newSafePoint, err = suite.client.UpdateKeyspaceGCSafePoint(ctx, keyspaceID, 30)
+	suite.NoError(err)
+	suite.Equal(uint64(30), newSafePoint)
+}
+
 func (suite *clientTestSuite) TestScatterRegion() {
 	regionID := regionIDAllocator.alloc()
 	region := &metapb.Region{
@@ -15,13 +15,16 @@
 package client_test
 
 import (
+	"context"
 	"fmt"
+	"testing"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/keyspacepb"
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/pd/server"
 	"github.com/tikv/pd/server/keyspace"
+	"github.com/tikv/pd/tests"
 )
 
 const (
@@ -117,3 +120,105 @@ func (suite *clientTestSuite) TestWatchKeyspace() {
 	loaded = <-watchChan
 	re.Equal([]*keyspacepb.KeyspaceMeta{expected}, loaded)
 }
+
+func TestKeyspaceManagement(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The keyspace management relies on the HTTP API, which is not served by the client test suite.
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	endpoints := runServer(re, cluster)
+	cli := setupCli(re, ctx, endpoints)
+	defer cli.Close()
+
+	name := "test_management"
+	created, err := cli.CreateKeyspace(ctx, name, map[string]string{testConfig1: "100"})
+	re.NoError(err)
+	re.Equal(name, created.GetName())
+	re.Equal(keyspacepb.KeyspaceState_ENABLED, created.GetState())
+	loaded, err := cli.LoadKeyspace(ctx, name)
+	re.NoError(err)
+	re.Equal(created, loaded)
+	// Creating a keyspace with an existing name should result in error.
+	_, err = cli.CreateKeyspace(ctx, name, nil)
+	re.Error(err)
+
+	// Update config with JSON merge patch semantics.
+	value := "200"
+	updated, err := cli.UpdateKeyspaceConfig(ctx, name, map[string]*string{
+		testConfig1: nil,
+		testConfig2: &value,
+	})
+	re.NoError(err)
+	re.Equal(map[string]string{testConfig2: value}, updated.GetConfig())
+	// Update state.
+	updated, err = cli.UpdateKeyspaceState(ctx, name, keyspacepb.KeyspaceState_DISABLED)
+	re.NoError(err)
+	re.Equal(keyspacepb.KeyspaceState_DISABLED, updated.GetState())
+	loaded, err = cli.LoadKeyspace(ctx, name)
+	re.NoError(err)
+	re.Equal(updated, loaded)
+	_, err = cli.UpdateKeyspaceState(ctx, "non-existing", keyspacepb.KeyspaceState_DISABLED)
+	re.Error(err)
+
+	// Load all keyspaces page by page.
+	var paged []*keyspacepb.KeyspaceMeta
+	pageToken := ""
+	for {
+		page, nextPageToken, err := cli.LoadKeyspaces(ctx, pageToken, 2)
+		re.NoError(err)
+		re.LessOrEqual(len(page), 2)
+		paged = append(paged, page...)
+		if nextPageToken == "" {
+			break
+		}
+		pageToken = nextPageToken
+	}
+	re.Contains(paged, updated)
+	all, nextPageToken, err := cli.LoadKeyspaces(ctx, "", 0)
+	re.NoError(err)
+	re.Empty(nextPageToken)
+	re.Equal(all, paged)
+}
+
+func (suite *clientTestSuite) TestWatchKeyspacesFromRevision() {
+	re := suite.Require()
+	ctx, cancel := context.WithCancel(suite.ctx)
+	watchChan, err := suite.client.WatchKeyspacesFromRevision(ctx, 0)
+	re.NoError(err)
+	// Watching from revision 0 should start with all existing keyspaces.
+	initial := <-watchChan
+	re.NotEmpty(initial.Keyspaces)
+	revision := initial.Revision
+	re.Positive(revision)
+	cancel()
+
+	// Resuming the watch should only receive the changes after the revision.
+	created := mustMakeTestKeyspaces(re, suite.srv, 50, 5)
+	ctx, cancel = context.WithCancel(suite.ctx)
+	defer cancel()
+	watchChan, err = suite.client.WatchKeyspacesFromRevision(ctx, revision)
+	re.NoError(err)
+	var loaded []*keyspacepb.KeyspaceMeta
+	for len(loaded) < len(created) {
+		resp := <-watchChan
+		// The revision advances with the responses.
+		re.Greater(resp.Revision, revision)
+		revision = resp.Revision
+		loaded = append(loaded, resp.Keyspaces...)
+	}
+	re.Equal(created, loaded)
+	cancel()
+
+	// Resuming from the revision of the last response should not replay the received changes.
+	created = mustMakeTestKeyspaces(re, suite.srv, 60, 1)
+	ctx, cancel = context.WithCancel(suite.ctx)
+	defer cancel()
+	watchChan, err = suite.client.WatchKeyspacesFromRevision(ctx, revision)
+	re.NoError(err)
+	resp := <-watchChan
+	re.Equal(created, resp.Keyspaces)
+	re.Greater(resp.Revision, revision)
+}
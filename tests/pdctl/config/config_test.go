@@ -357,6 +357,20 @@ func TestPlacementRules(t *testing.T) {
 	re.Len(fit.RuleFits, 3)
 	re.Equal([2]string{"pd", "default"}, fit.RuleFits[0].Rule.Key())
 
+	// test save with dry run
+	rules[0].Count = 5
+	b, _ = json.Marshal(rules)
+	os.WriteFile(fname, b, 0600)
+	impact := &placement.RuleImpact{}
+	output, err = pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), "-u", pdAddr, "config", "placement-rules", "save", "--in="+fname, "--dry-run")
+	re.NoError(err)
+	re.NoError(json.Unmarshal(output, impact))
+	re.Equal(1, impact.AddPeer.Count)
+	output, err = pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), "-u", pdAddr, "config", "placement-rules", "show", "--group=pd")
+	re.NoError(err)
+	re.NoError(json.Unmarshal(output, &rules2))
+	re.Equal(3, rules2[0].Count)
+
 	// test delete
 	rules[0].Count = 0
 	b, _ = json.Marshal(rules)
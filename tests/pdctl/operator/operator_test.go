@@ -16,6 +16,7 @@ package operator_test
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"strings"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/pd/server/config"
 	"github.com/tikv/pd/server/core"
+	"github.com/tikv/pd/server/storage"
 	"github.com/tikv/pd/tests"
 	"github.com/tikv/pd/tests/pdctl"
 	pdctlCmd "github.com/tikv/pd/tools/pd-ctl/pdctl"
@@ -179,20 +181,51 @@ func TestOperator(t *testing.T) {
 		re.Contains(string(records), "admin")
 	}
 
+	// operator history <start> with filters queries the persisted history.
+	re.NoError(leaderServer.GetServer().GetOperatorHistoryStorage().Flush())
+	historyCmd := []string{"-u", pdAddr, "operator", "history", "0", "--region=3", "--status=canceled"}
+	output, err := pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), historyCmd...)
+	re.NoError(err)
+	var history storage.HistoryOperators
+	re.NoError(json.Unmarshal(output, &history))
+	// The split operators on region 3 are all canceled.
+	re.Len(history.HistoryOperator, 4)
+	for _, op := range history.HistoryOperator {
+		re.Equal(uint64(3), op.RegionID)
+		re.Equal("Canceled", op.Status)
+		re.Contains(op.Desc, "split-region")
+	}
+	historyCmd = []string{"-u", pdAddr, "operator", "history", "0", "--region=3", "--status=canceled", "--limit=1"}
+	output, err = pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), historyCmd...)
+	re.NoError(err)
+	history = storage.HistoryOperators{}
+	re.NoError(json.Unmarshal(output, &history))
+	re.Len(history.HistoryOperator, 1)
+
 	// operator add merge-region <source_region_id> <target_region_id>
 	args := []string{"-u", pdAddr, "operator", "add", "merge-region", "1", "3"}
 	_, err = pdctl.ExecuteCommand(cmd, args...)
 	re.NoError(err)
 	args = []string{"-u", pdAddr, "operator", "show"}
-	output, err := pdctl.ExecuteCommand(cmd, args...)
+	output, err = pdctl.ExecuteCommand(cmd, args...)
 	re.NoError(err)
 	re.Contains(string(output), "merge region 1 into region 3")
-	args = []string{"-u", pdAddr, "operator", "remove", "1"}
-	_, err = pdctl.ExecuteCommand(cmd, args...)
+	// operator remove --filter=<key>=<value> removes the operators in bulk.
+	// A new command is used each time to reset the flags.
+	args = []string{"-u", pdAddr, "operator", "remove", "--filter=kind=merge", "--filter=start-key=62", "--dry-run"}
+	output, err = pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), args...)
 	re.NoError(err)
-	args = []string{"-u", pdAddr, "operator", "remove", "3"}
-	_, err = pdctl.ExecuteCommand(cmd, args...)
+	re.Contains(string(output), `"count": 1`)
+	re.Contains(string(output), `"dry-run": true`)
+	args = []string{"-u", pdAddr, "operator", "remove", "--filter=desc=admin-merge-region"}
+	output, err = pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), args...)
+	re.NoError(err)
+	re.Contains(string(output), `"count": 2`)
+	re.Contains(string(output), "desc=admin-merge-region")
+	args = []string{"-u", pdAddr, "operator", "remove", "--filter=unknown=1"}
+	output, err = pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), args...)
 	re.NoError(err)
+	re.Contains(string(output), "Unknown filter key")
 
 	_, err = pdctl.ExecuteCommand(cmd, "config", "set", "enable-placement-rules", "true")
 	re.NoError(err)
@@ -301,25 +301,26 @@ func TestScheduler(t *testing.T) {
 	echo = mustExec([]string{"-u", pdAddr, "scheduler", "config", "evict-leader-scheduler"}, nil)
 	re.Contains(echo, "[404] scheduler not found")
 	expected1 := map[string]interface{}{
-		"min-hot-byte-rate":          float64(100),
-		"min-hot-key-rate":           float64(10),
-		"min-hot-query-rate":         float64(10),
-		"max-zombie-rounds":          float64(3),
-		"max-peer-number":            float64(1000),
-		"byte-rate-rank-step-ratio":  0.05,
-		"key-rate-rank-step-ratio":   0.05,
-		"query-rate-rank-step-ratio": 0.05,
-		"count-rank-step-ratio":      0.01,
-		"great-dec-ratio":            0.95,
-		"minor-dec-ratio":            0.99,
-		"src-tolerance-ratio":        1.05,
-		"dst-tolerance-ratio":        1.05,
-		"read-priorities":            []interface{}{"byte", "key"},
-		"write-leader-priorities":    []interface{}{"key", "byte"},
-		"write-peer-priorities":      []interface{}{"byte", "key"},
-		"strict-picking-store":       "true",
-		"enable-for-tiflash":         "true",
-		"rank-formula-version":       "v2",
+		"min-hot-byte-rate":             float64(100),
+		"min-hot-key-rate":              float64(10),
+		"min-hot-query-rate":            float64(10),
+		"max-zombie-rounds":             float64(3),
+		"max-peer-number":               float64(1000),
+		"byte-rate-rank-step-ratio":     0.05,
+		"key-rate-rank-step-ratio":      0.05,
+		"query-rate-rank-step-ratio":    0.05,
+		"count-rank-step-ratio":         0.01,
+		"great-dec-ratio":               0.95,
+		"minor-dec-ratio":               0.99,
+		"src-tolerance-ratio":           1.05,
+		"dst-tolerance-ratio":           1.05,
+		"read-priorities":               []interface{}{"byte", "key"},
+		"write-leader-priorities":       []interface{}{"key", "byte"},
+		"write-peer-priorities":         []interface{}{"byte", "key"},
+		"tiflash-write-peer-priorities": []interface{}{"byte", "key"},
+		"strict-picking-store":          "true",
+		"enable-for-tiflash":            "true",
+		"rank-formula-version":          "v2",
 	}
 	var conf map[string]interface{}
 	mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler", "list"}, &conf)
@@ -467,4 +468,24 @@ func TestScheduler(t *testing.T) {
 	re.Contains(echo, "\"degree\": 10")
 	echo = mustExec([]string{"-u", pdAddr, "scheduler", "remove", "split-bucket-scheduler"}, nil)
 	re.Contains(echo, "Success!")
+
+	// test balance keyspace scheduler
+	echo = mustExec([]string{"-u", pdAddr, "scheduler", "add", "balance-keyspace-scheduler"}, nil)
+	re.Contains(echo, "Success!")
+	echo = mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-keyspace-scheduler", "set", "include-keyspaces", "1,2"}, nil)
+	re.Contains(echo, "Success")
+	echo = mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-keyspace-scheduler", "set", "keyspace-priorities", "2:10"}, nil)
+	re.Contains(echo, "Success")
+	echo = mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-keyspace-scheduler", "set", "keyspace-priorities", "2"}, nil)
+	re.Contains(echo, "invalid keyspace priority")
+	conf = make(map[string]interface{})
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-keyspace-scheduler"}, &conf)
+	re.Equal([]interface{}{1.0, 2.0}, conf["include-keyspaces"])
+	re.Equal(map[string]interface{}{"2": 10.0}, conf["keyspace-priorities"])
+	echo = mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-keyspace-scheduler", "set", "include-keyspaces", ""}, nil)
+	re.Contains(echo, "Success")
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-keyspace-scheduler"}, &conf)
+	re.Empty(conf["include-keyspaces"])
+	echo = mustExec([]string{"-u", pdAddr, "scheduler", "remove", "balance-keyspace-scheduler"}, nil)
+	re.Contains(echo, "Success!")
 }
@@ -0,0 +1,347 @@
+// Copyright 2022 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/tikv/pd/pkg/testutil"
+	"github.com/tikv/pd/server/apiv2/handlers"
+	"github.com/tikv/pd/server/config"
+	"github.com/tikv/pd/server/keyspace"
+	"github.com/tikv/pd/tests"
+	"go.uber.org/goleak"
+)
+
+const keyspacesPrefix = "/pd/api/v2/keyspaces"
+
+// dialClient used to dial http request.
+var dialClient = &http.Client{
+	Transport: &http.Transport{
+		DisableKeepAlives: true,
+	},
+}
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m, testutil.LeakOptions...)
+}
+
+type keyspaceTestSuite struct {
+	suite.Suite
+	server  *tests.TestServer
+	cluster *tests.TestCluster
+	cleanup func()
+}
+
+func TestKeyspaceTestSuite(t *testing.T) {
+	suite.Run(t, new(keyspaceTestSuite))
+}
+
+func (suite *keyspaceTestSuite) SetupTest() {
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.cleanup = cancel
+	cluster, err := tests.NewTestCluster(ctx, 1, func(conf *config.Config, serverName string) {
+		// Allow archiving a keyspace right after it's disabled.
+		conf.Keyspace.MinDisabledDuration.Duration = 0
+	})
+	suite.cluster = cluster
+	suite.NoError(err)
+	suite.NoError(cluster.RunInitialServers())
+	suite.NotEmpty(cluster.WaitLeader())
+	suite.server = cluster.GetServer(cluster.GetLeader())
+	suite.NoError(suite.server.BootstrapCluster())
+}
+
+func (suite *keyspaceTestSuite) TearDownTest() {
+	suite.cleanup()
+	suite.cluster.Destroy()
+}
+
+func (suite *keyspaceTestSuite) TestCreateLoadKeyspace() {
+	re := suite.Require()
+	keyspaces := mustMakeTestKeyspaces(re, suite.server, 10)
+	for _, created := range keyspaces {
+		loaded := mustLoadKeyspaces(re, suite.server, created.Name)
+		re.Equal(created, loaded)
+		loadedByID := mustLoadKeyspaceByID(re, suite.server, created.Id)
+		re.Equal(created, loadedByID)
+	}
+	defaultKeyspace := mustLoadKeyspaces(re, suite.server, keyspace.DefaultKeyspaceName)
+	re.Equal(keyspace.DefaultKeyspaceName, defaultKeyspace.Name)
+	re.Equal(keyspacepb.KeyspaceState_ENABLED, defaultKeyspace.State)
+
+	// Creating a keyspace with existing name must fail with conflict.
+	data, err := json.Marshal(&handlers.CreateKeyspaceParams{Name: keyspaces[0].Name})
+	re.NoError(err)
+	code, _ := sendRequest(re, suite.server, http.MethodPost, keyspacesPrefix, data)
+	re.Equal(http.StatusConflict, code)
+	// Loading a non-existing keyspace must fail with not found.
+	code, _ = sendRequest(re, suite.server, http.MethodGet, keyspacesPrefix+"/not_exist", nil)
+	re.Equal(http.StatusNotFound, code)
+	code, _ = sendRequest(re, suite.server, http.MethodGet, keyspacesPrefix+"/id/not_a_number", nil)
+	re.Equal(http.StatusBadRequest, code)
+}
+
+func (suite *keyspaceTestSuite) TestUpdateKeyspaceConfig() {
+	re := suite.Require()
+	keyspaces := mustMakeTestKeyspaces(re, suite.server, 10)
+	for _, created := range keyspaces {
+		config1val := "300"
+		updateRequest := &handlers.UpdateConfigParams{
+			Config: map[string]*string{
+				"config1": &config1val,
+				"config2": nil,
+			},
+		}
+		updated := mustUpdateKeyspaceConfig(re, suite.server, created.Name, updateRequest)
+		checkUpdateRequest(re, updateRequest, created.Config, updated.Config)
+	}
+}
+
+func (suite *keyspaceTestSuite) TestLoadKeyspaceUsage() {
+	re := suite.Require()
+	created := mustMakeTestKeyspaces(re, suite.server, 1)[0]
+	code, data := sendRequest(re, suite.server, http.MethodGet, keyspacesPrefix+"/"+created.Name+"/usage", nil)
+	re.Equal(http.StatusOK, code)
+	usage := &keyspace.Usage{}
+	re.NoError(json.Unmarshal(data, usage))
+	re.Equal(&keyspace.Usage{}, usage)
+	code, _ = sendRequest(re, suite.server, http.MethodGet, keyspacesPrefix+"/not_exist/usage", nil)
+	re.Equal(http.StatusNotFound, code)
+}
+
+func (suite *keyspaceTestSuite) TestLoadKeyspaceSplitStatus() {
+	re := suite.Require()
+	created := mustMakeTestKeyspaces(re, suite.server, 1)[0]
+	// The regions are split in the background after the keyspace is created.
+	testutil.Eventually(re, func() bool {
+		code, data := sendRequest(re, suite.server, http.MethodGet, keyspacesPrefix+"/"+created.Name+"/split-status", nil)
+		re.Equal(http.StatusOK, code)
+		status := &keyspace.SplitStatus{}
+		re.NoError(json.Unmarshal(data, status))
+		re.Equal("keyspace-"+created.Name, status.ScatterGroup)
+		return status.Finished
+	})
+	code, _ := sendRequest(re, suite.server, http.MethodGet, keyspacesPrefix+"/not_exist/split-status", nil)
+	re.Equal(http.StatusNotFound, code)
+}
+
+func (suite *keyspaceTestSuite) TestUpdateKeyspaceState() {
+	re := suite.Require()
+	keyspaces := mustMakeTestKeyspaces(re, suite.server, 10)
+	for _, created := range keyspaces {
+		// Should NOT allow archiving ENABLED keyspace.
+		code, _ := sendUpdateStateRequest(re, suite.server, created.Name, &handlers.UpdateStateParam{State: "archived"})
+		re.Equal(http.StatusConflict, code)
+		// Disabling an ENABLED keyspace is allowed.
+		code, disabled := sendUpdateStateRequest(re, suite.server, created.Name, &handlers.UpdateStateParam{State: "disabled"})
+		re.Equal(http.StatusOK, code)
+		re.Equal(keyspacepb.KeyspaceState_DISABLED, disabled.State)
+		// Disabling an already DISABLED keyspace should not result in any change.
+		code, disabledAgain := sendUpdateStateRequest(re, suite.server, created.Name, &handlers.UpdateStateParam{State: "disabled"})
+		re.Equal(http.StatusOK, code)
+		re.Equal(disabled, disabledAgain)
+		// Unknown state must be rejected.
+		code, _ = sendUpdateStateRequest(re, suite.server, created.Name, &handlers.UpdateStateParam{State: "unknown"})
+		re.Equal(http.StatusBadRequest, code)
+		// Archiving a DISABLED keyspace should be allowed.
+		code, archived := sendUpdateStateRequest(re, suite.server, created.Name, &handlers.UpdateStateParam{State: "archived"})
+		re.Equal(http.StatusOK, code)
+		re.Equal(keyspacepb.KeyspaceState_ARCHIVED, archived.State)
+		// Modifying ARCHIVED keyspace is not allowed.
+		code, _ = sendUpdateStateRequest(re, suite.server, created.Name, &handlers.UpdateStateParam{State: "enabled"})
+		re.Equal(http.StatusConflict, code)
+		data, err := json.Marshal(&handlers.UpdateConfigParams{Config: map[string]*string{"config1": nil}})
+		re.NoError(err)
+		code, _ = sendRequest(re, suite.server, http.MethodPatch, keyspacesPrefix+"/"+created.Name+"/config", data)
+		re.Equal(http.StatusConflict, code)
+	}
+	// Changing default keyspace's state is NOT allowed.
+	code, _ := sendUpdateStateRequest(re, suite.server, keyspace.DefaultKeyspaceName, &handlers.UpdateStateParam{State: "disabled"})
+	re.Equal(http.StatusBadRequest, code)
+}
+
+func (suite *keyspaceTestSuite) TestInvalidKeyspaceRequest() {
+	re := suite.Require()
+	// Illegal name.
+	data, err := json.Marshal(&handlers.CreateKeyspaceParams{Name: "illegal/name"})
+	re.NoError(err)
+	code, _ := sendRequest(re, suite.server, http.MethodPost, keyspacesPrefix, data)
+	re.Equal(http.StatusBadRequest, code)
+	// Illegal quota config.
+	data, err = json.Marshal(&handlers.CreateKeyspaceParams{Name: "quota", Config: map[string]string{keyspace.StorageQuotaKey: "abc"}})
+	re.NoError(err)
+	code, _ = sendRequest(re, suite.server, http.MethodPost, keyspacesPrefix, data)
+	re.Equal(http.StatusBadRequest, code)
+	// The quota state is maintained by PD.
+	created := mustMakeTestKeyspaces(re, suite.server, 1)[0]
+	state := keyspace.QuotaStateReadOnly
+	data, err = json.Marshal(&handlers.UpdateConfigParams{Config: map[string]*string{keyspace.QuotaStateKey: &state}})
+	re.NoError(err)
+	code, _ = sendRequest(re, suite.server, http.MethodPatch, keyspacesPrefix+"/"+created.Name+"/config", data)
+	re.Equal(http.StatusBadRequest, code)
+}
+
+func (suite *keyspaceTestSuite) TestLoadRangeKeyspace() {
+	re := suite.Require()
+	keyspaces := mustMakeTestKeyspaces(re, suite.server, 50)
+	loadResponse := sendLoadRangeRequest(re, suite.server, "", "")
+	re.Empty(loadResponse.NextPageToken) // Load response should contain no more pages.
+	// Load response should contain all created keyspace and a default.
+	re.Len(loadResponse.Keyspaces, len(keyspaces)+1)
+	for i, created := range keyspaces {
+		re.Equal(created, loadResponse.Keyspaces[i+1].KeyspaceMeta)
+	}
+	re.Equal(keyspace.DefaultKeyspaceName, loadResponse.Keyspaces[0].Name)
+
+	// Paginate through all keyspaces with a small page size.
+	loaded := make([]*handlers.KeyspaceMeta, 0, len(keyspaces)+1)
+	pageToken := ""
+	for {
+		resp := sendLoadRangeRequest(re, suite.server, pageToken, "7")
+		re.LessOrEqual(len(resp.Keyspaces), 7)
+		loaded = append(loaded, resp.Keyspaces...)
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+	re.Equal(loadResponse.Keyspaces, loaded)
+
+	// Negative limit should be rejected.
+	code, _ := sendRequest(re, suite.server, http.MethodGet, keyspacesPrefix+"?limit=-1", nil)
+	re.Equal(http.StatusBadRequest, code)
+}
+
+func sendRequest(re *require.Assertions, server *tests.TestServer, method, path string, body []byte) (int, []byte) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewBuffer(body)
+	}
+	httpReq, err := http.NewRequest(method, server.GetAddr()+path, reader)
+	re.NoError(err)
+	resp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	re.NoError(err)
+	return resp.StatusCode, data
+}
+
+func sendLoadRangeRequest(re *require.Assertions, server *tests.TestServer, token, limit string) *handlers.LoadAllKeyspacesResponse {
+	path := fmt.Sprintf("%s?page_token=%s&limit=%s", keyspacesPrefix, token, limit)
+	code, data := sendRequest(re, server, http.MethodGet, path, nil)
+	re.Equal(http.StatusOK, code)
+	resp := &handlers.LoadAllKeyspacesResponse{}
+	re.NoError(json.Unmarshal(data, resp))
+	return resp
+}
+
+func sendUpdateStateRequest(re *require.Assertions, server *tests.TestServer, name string, request *handlers.UpdateStateParam) (int, *keyspacepb.KeyspaceMeta) {
+	data, err := json.Marshal(request)
+	re.NoError(err)
+	code, data := sendRequest(re, server, http.MethodPut, keyspacesPrefix+"/"+name+"/state", data)
+	if code != http.StatusOK {
+		return code, nil
+	}
+	meta := &handlers.KeyspaceMeta{}
+	re.NoError(json.Unmarshal(data, meta))
+	return code, meta.KeyspaceMeta
+}
+
+func mustMakeTestKeyspaces(re *require.Assertions, server *tests.TestServer, count int) []*keyspacepb.KeyspaceMeta {
+	testConfig := map[string]string{
+		"config1": "100",
+		"config2": "200",
+	}
+	resultMeta := make([]*keyspacepb.KeyspaceMeta, count)
+	for i := 0; i < count; i++ {
+		createRequest := &handlers.CreateKeyspaceParams{
+			Name:   fmt.Sprintf("test_keyspace%d", i),
+			Config: testConfig,
+		}
+		resultMeta[i] = mustCreateKeyspace(re, server, createRequest)
+	}
+	return resultMeta
+}
+
+func mustCreateKeyspace(re *require.Assertions, server *tests.TestServer, request *handlers.CreateKeyspaceParams) *keyspacepb.KeyspaceMeta {
+	data, err := json.Marshal(request)
+	re.NoError(err)
+	code, data := sendRequest(re, server, http.MethodPost, keyspacesPrefix, data)
+	re.Equal(http.StatusOK, code)
+	meta := &handlers.KeyspaceMeta{}
+	re.NoError(json.Unmarshal(data, meta))
+	checkCreateRequest(re, request, meta.KeyspaceMeta)
+	return meta.KeyspaceMeta
+}
+
+func mustUpdateKeyspaceConfig(re *require.Assertions, server *tests.TestServer, name string, request *handlers.UpdateConfigParams) *keyspacepb.KeyspaceMeta {
+	data, err := json.Marshal(request)
+	re.NoError(err)
+	code, data := sendRequest(re, server, http.MethodPatch, keyspacesPrefix+"/"+name+"/config", data)
+	re.Equal(http.StatusOK, code)
+	meta := &handlers.KeyspaceMeta{}
+	re.NoError(json.Unmarshal(data, meta))
+	return meta.KeyspaceMeta
+}
+
+func mustLoadKeyspaces(re *require.Assertions, server *tests.TestServer, name string) *keyspacepb.KeyspaceMeta {
+	code, data := sendRequest(re, server, http.MethodGet, keyspacesPrefix+"/"+name, nil)
+	re.Equal(http.StatusOK, code)
+	meta := &handlers.KeyspaceMeta{}
+	re.NoError(json.Unmarshal(data, meta))
+	return meta.KeyspaceMeta
+}
+
+func mustLoadKeyspaceByID(re *require.Assertions, server *tests.TestServer, id uint32) *keyspacepb.KeyspaceMeta {
+	code, data := sendRequest(re, server, http.MethodGet, fmt.Sprintf("%s/id/%d", keyspacesPrefix, id), nil)
+	re.Equal(http.StatusOK, code)
+	meta := &handlers.KeyspaceMeta{}
+	re.NoError(json.Unmarshal(data, meta))
+	return meta.KeyspaceMeta
+}
+
+// checkCreateRequest verifies a keyspace meta matches a create request.
+func checkCreateRequest(re *require.Assertions, request *handlers.CreateKeyspaceParams, meta *keyspacepb.KeyspaceMeta) {
+	re.Equal(request.Name, meta.Name)
+	re.Equal(keyspacepb.KeyspaceState_ENABLED, meta.State)
+	re.Equal(request.Config, meta.Config)
+}
+
+// checkUpdateRequest verifies a keyspace meta matches a update request.
+func checkUpdateRequest(re *require.Assertions, request *handlers.UpdateConfigParams, oldConfig, newConfig map[string]string) {
+	expected := map[string]string{}
+	for k, v := range oldConfig {
+		expected[k] = v
+	}
+	for k, v := range request.Config {
+		if v == nil {
+			delete(expected, k)
+		} else {
+			expected[k] = *v
+		}
+	}
+	re.Equal(expected, newConfig)
+}
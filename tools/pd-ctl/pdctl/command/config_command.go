@@ -452,6 +452,7 @@ func NewPlacementRulesCommand() *cobra.Command {
 		Run:   putPlacementRulesFunc,
 	}
 	save.Flags().String("in", "rules.json", "the filename contains rules")
+	save.Flags().Bool("dry-run", false, "only estimate the impact of the rules without saving them")
 	ruleGroup := &cobra.Command{
 		Use:   "rule-group",
 		Short: "rule group configurations",
@@ -488,6 +489,7 @@ func NewPlacementRulesCommand() *cobra.Command {
 		Run:   setRuleBundle,
 	}
 	ruleBundleSet.Flags().String("in", "group.json", "the file contains one group config and its rules")
+	ruleBundleSet.Flags().Bool("dry-run", false, "only estimate the impact of the rules without saving them")
 	ruleBundleDelete := &cobra.Command{
 		Use:   "delete <id>",
 		Short: "delete rule group config and its rules by group id",
@@ -507,8 +509,14 @@ func NewPlacementRulesCommand() *cobra.Command {
 	}
 	ruleBundleSave.Flags().String("in", "rules.json", "the file contains all group configs and all rules")
 	ruleBundleSave.Flags().Bool("partial", false, "do not drop all old configurations, partial update")
+	ruleBundleSave.Flags().Bool("dry-run", false, "only estimate the impact of the rules without saving them")
 	ruleBundle.AddCommand(ruleBundleGet, ruleBundleSet, ruleBundleDelete, ruleBundleLoad, ruleBundleSave)
-	c.AddCommand(enable, disable, show, load, save, ruleGroup, ruleBundle)
+	compliance := &cobra.Command{
+		Use:   "compliance",
+		Short: "show the regions violating placement rules",
+		Run:   getComplianceReportFunc,
+	}
+	c.AddCommand(enable, disable, show, load, save, ruleGroup, ruleBundle, compliance)
 	return c
 }
 
@@ -580,6 +588,15 @@ func getPlacementRulesFunc(cmd *cobra.Command, args []string) {
 	cmd.Println("rules saved to file " + file)
 }
 
+func getComplianceReportFunc(cmd *cobra.Command, args []string) {
+	res, err := doRequest(cmd, path.Join(rulesPrefix, "compliance"), http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(res)
+}
+
 func putPlacementRulesFunc(cmd *cobra.Command, args []string) {
 	var file string
 	if f := cmd.Flag("in"); f != nil {
@@ -609,12 +626,21 @@ func putPlacementRulesFunc(cmd *cobra.Command, args []string) {
 	}
 
 	b, _ := json.Marshal(validOpts)
-	_, err = doRequest(cmd, rulesBatchPrefix, http.MethodPost, http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewBuffer(b)))
+	reqPath := rulesBatchPrefix
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		reqPath += "?dry_run=true"
+	}
+	res, err := doRequest(cmd, reqPath, http.MethodPost, http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewBuffer(b)))
 	if err != nil {
 		cmd.Printf("failed to save rules %s: %s\n", b, err)
 		return
 	}
 
+	if dryRun {
+		cmd.Println(res)
+		return
+	}
 	cmd.Println("Success!")
 }
 
@@ -727,6 +753,9 @@ func setRuleBundle(cmd *cobra.Command, args []string) {
 	}
 
 	reqPath := path.Join(ruleBundlePrefix, id.GroupID)
+	if ok, _ := cmd.Flags().GetBool("dry-run"); ok {
+		reqPath += "?dry_run=true"
+	}
 
 	res, err := doRequest(cmd, reqPath, http.MethodPost, http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewReader(content)))
 	if err != nil {
@@ -793,9 +822,16 @@ func saveRuleBundle(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	path := ruleBundlePrefix
+	query := make(url.Values)
 	if ok, _ := cmd.Flags().GetBool("partial"); ok {
-		path += "?partial=true"
+		query.Set("partial", "true")
+	}
+	if ok, _ := cmd.Flags().GetBool("dry-run"); ok {
+		query.Set("dry_run", "true")
+	}
+	path := ruleBundlePrefix
+	if len(query) > 0 {
+		path += "?" + query.Encode()
 	}
 
 	res, err := doRequest(cmd, path, http.MethodPost, http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewReader(content)))
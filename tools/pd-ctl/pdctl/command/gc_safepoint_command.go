@@ -32,6 +32,17 @@ func NewServiceGCSafepointCommand() *cobra.Command {
 		Run:   showSSPs,
 	}
 	l.AddCommand(NewDeleteServiceGCSafepointCommand())
+	l.AddCommand(NewDiagnoseServiceGCSafepointCommand())
+	return l
+}
+
+// NewDiagnoseServiceGCSafepointCommand return a subcommand to diagnose which service gc safepoints block the GC
+func NewDiagnoseServiceGCSafepointCommand() *cobra.Command {
+	l := &cobra.Command{
+		Use:   "diagnose",
+		Short: "show the age, TTL and gap to the gc safepoint of each service gc safepoint",
+		Run:   diagnoseSSPs,
+	}
 	return l
 }
 
@@ -55,6 +66,15 @@ func showSSPs(cmd *cobra.Command, args []string) {
 	cmd.Println(r)
 }
 
+func diagnoseSSPs(cmd *cobra.Command, args []string) {
+	r, err := doRequest(cmd, serviceGCSafepointPrefix+"/diagnose", http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to diagnose service GC safepoint: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
 func deleteSSP(cmd *cobra.Command, args []string) {
 	if len(args) != 1 {
 		cmd.Usage()
@@ -17,7 +17,9 @@ package command
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/pingcap/errors"
 	"github.com/spf13/cobra"
@@ -419,14 +421,39 @@ func scatterRegionCommandFunc(cmd *cobra.Command, args []string) {
 // NewRemoveOperatorCommand returns a command to remove operators.
 func NewRemoveOperatorCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "remove <region_id>",
-		Short: "remove the region operator",
-		Run:   removeOperatorCommandFunc,
-	}
+		Use:   "remove <region_id> | --filter=<key>=<value> [--filter=<key>=<value>]... [--dry-run] [--format=raw|encode|hex]",
+		Short: "remove the region operator, or remove the operators selected by the filters in bulk",
+		Long: `remove the region operator, or remove the operators selected by the filters in bulk.
+The supported filter keys are desc, scheduler, kind, store, start-key, end-key and min-age, e.g.
+  operator remove --filter=scheduler=balance-region-scheduler --filter=min-age=10m --dry-run`,
+		Run: removeOperatorCommandFunc,
+	}
+	c.Flags().StringArray("filter", nil, "the filter selecting the operators to remove")
+	c.Flags().Bool("dry-run", false, "only count the selected operators without removing them")
+	c.Flags().String("format", "hex", "the format of start-key and end-key")
 	return c
 }
 
+// operatorFilterKeys maps the filter keys to the query parameters.
+var operatorFilterKeys = map[string]string{
+	"desc":      "desc",
+	"scheduler": "scheduler",
+	"kind":      "kind",
+	"store":     "store_id",
+	"start-key": "start_key",
+	"end-key":   "end_key",
+	"min-age":   "min_age",
+}
+
 func removeOperatorCommandFunc(cmd *cobra.Command, args []string) {
+	if filters, _ := cmd.Flags().GetStringArray("filter"); len(filters) > 0 {
+		if len(args) != 0 {
+			cmd.Println(cmd.UsageString())
+			return
+		}
+		removeOperatorsByFilter(cmd, filters)
+		return
+	}
 	if len(args) != 1 {
 		cmd.Println(cmd.UsageString())
 		return
@@ -441,18 +468,92 @@ func removeOperatorCommandFunc(cmd *cobra.Command, args []string) {
 	cmd.Println("Success!")
 }
 
+func removeOperatorsByFilter(cmd *cobra.Command, filters []string) {
+	query := url.Values{}
+	for _, filter := range filters {
+		kv := strings.SplitN(filter, "=", 2)
+		if len(kv) != 2 {
+			cmd.Printf("Invalid filter %s, it should be <key>=<value>\n", filter)
+			return
+		}
+		key, ok := operatorFilterKeys[kv[0]]
+		if !ok {
+			cmd.Printf("Unknown filter key %s\n", kv[0])
+			return
+		}
+		value := kv[1]
+		if kv[0] == "start-key" || kv[0] == "end-key" {
+			var err error
+			if value, err = parseKey(cmd.Flags(), value); err != nil {
+				cmd.Println("Error: ", err)
+				return
+			}
+		}
+		query.Set(key, value)
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		query.Set("dry_run", "true")
+	}
+	r, err := doRequest(cmd, operatorsPrefix+"?"+query.Encode(), http.MethodDelete, http.Header{})
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
 // NewHistoryOperatorCommand returns a command to history finished operators.
 func NewHistoryOperatorCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:     "history <start>",
-		Short:   "list all finished operators since start, start is a timestamp",
+		Use:     "history <start> [--end=<end>] [--region=<region_id>] [--store=<store_id>] [--scheduler=<name>] [--kind=<kind>] [--status=<status>] [--limit=<limit>]",
+		Short:   "list all finished operators since start, start is a timestamp. The persisted history is queried if any flag is specified",
+		Long:    "list all finished operators since start, start is a timestamp. The persisted history is queried if any flag is specified. The persisted history is kept in the local storage of the current PD leader and is not replicated, so it is lost after the leader changes",
 		Run:     historyOperatorCommandFunc,
 		Example: HistoryExample,
 	}
+	c.Flags().String("end", "", "the end timestamp of the persisted history")
+	c.Flags().Uint64("region", 0, "only show the operators of the region")
+	c.Flags().Uint64("store", 0, "only show the operators involving the store")
+	c.Flags().String("scheduler", "", "only show the operators created by the scheduler")
+	c.Flags().String("kind", "", "only show the operators of the kind, e.g. leader, region, admin")
+	c.Flags().String("status", "", "only show the operators finished with the status, e.g. success, canceled, timeout")
+	c.Flags().Int("limit", 0, "the max number of the operators to show from the persisted history, 1000 by default")
 	return c
 }
 
 func historyOperatorCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	// Only the local flags are checked, since the persistent flags of the root command,
+	// e.g. the PD address, are merged into the flags of the command.
+	query := url.Values{}
+	for flag, key := range map[string]string{
+		"end":       "to",
+		"region":    "region_id",
+		"store":     "store_id",
+		"scheduler": "scheduler",
+		"kind":      "kind",
+		"status":    "status",
+		"limit":     "limit",
+	} {
+		if cmd.LocalFlags().Changed(flag) {
+			query.Set(key, cmd.LocalFlags().Lookup(flag).Value.String())
+		}
+	}
+	if len(query) > 0 {
+		if len(args) == 1 {
+			query.Set("from", args[0])
+		}
+		history, err := doRequest(cmd, operatorsPrefix+"/history?"+query.Encode(), http.MethodGet, http.Header{})
+		if err != nil {
+			cmd.Println(err)
+			return
+		}
+		cmd.Println(history)
+		return
+	}
 	path := operatorsPrefix + "/" + "records"
 	if len(args) == 1 {
 		path += "?from=" + args[0]
@@ -44,6 +44,7 @@ var (
 	regionsKeyPrefix        = "pd/api/v1/regions/key"
 	regionsSiblingPrefix    = "pd/api/v1/regions/sibling"
 	regionsRangeHolesPrefix = "pd/api/v1/regions/range-holes"
+	regionsLabelPrefix      = "pd/api/v1/regions/label"
 	regionIDPrefix          = "pd/api/v1/region/id"
 	regionKeyPrefix         = "pd/api/v1/region/key"
 )
@@ -61,6 +62,7 @@ func NewRegionCommand() *cobra.Command {
 	r.AddCommand(NewRegionWithStoreCommand())
 	r.AddCommand(NewRegionsByKeysCommand())
 	r.AddCommand(NewRangesWithRangeHolesCommand())
+	r.AddCommand(NewRegionWithLabelCommand())
 
 	topRead := &cobra.Command{
 		Use:   `topread <limit> [--jq="<query string>"]`,
@@ -454,6 +456,37 @@ func showRegionWithStoreCommandFunc(cmd *cobra.Command, args []string) {
 	cmd.Println(r)
 }
 
+// NewRegionWithLabelCommand returns regions with label subcommand of regionCmd
+func NewRegionWithLabelCommand() *cobra.Command {
+	r := &cobra.Command{
+		Use:   "label <key> <value> [--offset=<offset>] [--limit=<limit>]",
+		Short: "show the regions with a specific label and the statistics of them",
+		Run:   showRegionWithLabelCommandFunc,
+	}
+	r.Flags().Int("offset", 0, "the offset of the regions sorted by the start key")
+	r.Flags().Int("limit", 16, "the limit count of the regions")
+	return r
+}
+
+func showRegionWithLabelCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	offset, _ := cmd.Flags().GetInt("offset")
+	limit, _ := cmd.Flags().GetInt("limit")
+	query := url.Values{}
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	prefix := fmt.Sprintf("%s/%s/%s?%s", regionsLabelPrefix, url.PathEscape(args[0]), url.PathEscape(args[1]), query.Encode())
+	r, err := doRequest(cmd, prefix, http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get regions with the given label: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
 const (
 	rangeHolesLongDesc = `There are some cases that the region range is not continuous, for example, the region doesn't send the heartbeat to PD after a splitting.
 This command will output all empty ranges without any region info.`
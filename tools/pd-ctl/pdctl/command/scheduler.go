@@ -33,6 +33,8 @@ var (
 	schedulersPrefix          = "pd/api/v1/schedulers"
 	schedulerConfigPrefix     = "pd/api/v1/scheduler-config"
 	schedulerDiagnosticPrefix = "pd/api/v1/schedulers/diagnostic"
+	schedulerDryRunPrefix     = "pd/api/v1/schedulers/dry-run"
+	schedulingWindowsPrefix   = "pd/api/v1/scheduling-windows"
 	evictLeaderSchedulerName  = "evict-leader-scheduler"
 	grantLeaderSchedulerName  = "grant-leader-scheduler"
 )
@@ -50,6 +52,8 @@ func NewSchedulerCommand() *cobra.Command {
 	c.AddCommand(NewResumeSchedulerCommand())
 	c.AddCommand(NewConfigSchedulerCommand())
 	c.AddCommand(NewDescribeSchedulerCommand())
+	c.AddCommand(NewDryRunSchedulerCommand())
+	c.AddCommand(NewSchedulingWindowCommand())
 	return c
 }
 
@@ -98,6 +102,147 @@ func resumeSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 	postJSON(cmd, path, input)
 }
 
+// NewDryRunSchedulerCommand returns a command to manage the dry-run mode of a scheduler.
+func NewDryRunSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "dry-run",
+		Short: "record the operators of a scheduler without dispatching them",
+	}
+	c.AddCommand(&cobra.Command{
+		Use:   "enable <scheduler>",
+		Short: "enable the dry-run mode of a scheduler, use `all` for all schedulers",
+		Run:   setSchedulerDryRunCommandFunc(true),
+	})
+	c.AddCommand(&cobra.Command{
+		Use:   "disable <scheduler>",
+		Short: "disable the dry-run mode of a scheduler, use `all` for all schedulers",
+		Run:   setSchedulerDryRunCommandFunc(false),
+	})
+	c.AddCommand(&cobra.Command{
+		Use:   "show <scheduler>",
+		Short: "show the operators recorded by a scheduler in dry-run mode",
+		Run:   showSchedulerDryRunCommandFunc,
+	})
+	return c
+}
+
+func setSchedulerDryRunCommandFunc(enable bool) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Println(cmd.UsageString())
+			return
+		}
+		path := schedulerDryRunPrefix + "/" + args[0]
+		input := map[string]interface{}{"enable": enable}
+		postJSON(cmd, path, input)
+	}
+}
+
+func showSchedulerDryRunCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	path := schedulerDryRunPrefix + "/" + args[0]
+	r, err := doRequest(cmd, path, http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the dry-run result of scheduler %s: %s\n", args[0], err)
+		return
+	}
+	cmd.Println(r)
+}
+
+// NewSchedulingWindowCommand returns a command to manage the scheduling windows of schedulers and checkers.
+func NewSchedulingWindowCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "window",
+		Short: "manage the windows in which a scheduler or checker is allowed to run",
+	}
+	c.AddCommand(&cobra.Command{
+		Use:   "show [<scheduler>|<checker>]",
+		Short: "show the scheduling windows of a scheduler or checker, or all of them",
+		Run:   showSchedulingWindowsCommandFunc,
+	})
+	c.AddCommand(&cobra.Command{
+		Use:   "add <scheduler>|<checker> <cron> <duration> [<limit>=<value>]...",
+		Short: "add a window starting at every time matching the cron expression \"minute hour day-of-month month day-of-week\" and lasting for the duration, e.g. `scheduler window add balance-region-scheduler \"0 22 * * *\" 6h region-schedule-limit=64`",
+		Run:   addSchedulingWindowCommandFunc,
+	})
+	c.AddCommand(&cobra.Command{
+		Use:   "delete <scheduler>|<checker>",
+		Short: "delete all the scheduling windows of a scheduler or checker",
+		Run:   deleteSchedulingWindowsCommandFunc,
+	})
+	return c
+}
+
+func showSchedulingWindowsCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	path := schedulingWindowsPrefix
+	if len(args) == 1 {
+		path += "/" + args[0]
+	}
+	r, err := doRequest(cmd, path, http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the scheduling windows: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func addSchedulingWindowCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 3 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	limits := make(map[string]uint64)
+	for _, arg := range args[3:] {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			cmd.Println(cmd.UsageString())
+			return
+		}
+		value, err := strconv.ParseUint(kv[1], 10, 64)
+		if err != nil {
+			cmd.Printf("Failed to parse the limit %s: %s\n", arg, err)
+			return
+		}
+		limits[kv[0]] = value
+	}
+	path := schedulingWindowsPrefix + "/" + args[0]
+	r, err := doRequest(cmd, path, http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the scheduling windows: %s\n", err)
+		return
+	}
+	var policy map[string][]interface{}
+	if err = json.Unmarshal([]byte(r), &policy); err != nil {
+		cmd.Printf("Failed to parse the scheduling windows: %s\n", err)
+		return
+	}
+	w := map[string]interface{}{"cron": args[1], "duration": args[2]}
+	if len(limits) > 0 {
+		w["limits"] = limits
+	}
+	postJSON(cmd, path, map[string]interface{}{"windows": append(policy["windows"], w)})
+}
+
+func deleteSchedulingWindowsCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	path := schedulingWindowsPrefix + "/" + args[0]
+	if _, err := doRequest(cmd, path, http.MethodDelete, http.Header{}); err != nil {
+		cmd.Printf("Failed to delete the scheduling windows: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
 // NewShowSchedulerCommand returns a command to show schedulers.
 func NewShowSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -105,8 +250,9 @@ func NewShowSchedulerCommand() *cobra.Command {
 		Short: "show schedulers",
 		Run:   showSchedulerCommandFunc,
 	}
-	c.Flags().String("status", "", "the scheduler status value can be [paused | disabled]")
+	c.Flags().String("status", "", "the scheduler status value can be [paused | disabled | dry-run]")
 	c.Flags().BoolP("timestamp", "t", false, "fetch the paused and resume timestamp for paused scheduler(s)")
+	c.Flags().BoolP("window", "w", false, "fetch the current scheduling window of the schedulers")
 	return c
 }
 
@@ -122,6 +268,8 @@ func showSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 		if tsFlag, _ := cmd.Flags().GetBool("timestamp"); tsFlag {
 			url += "&timestamp=true"
 		}
+	} else if windowFlag, _ := cmd.Flags().GetBool("window"); windowFlag {
+		url += "?window=true"
 	}
 	r, err := doRequest(cmd, url, http.MethodGet, http.Header{})
 	if err != nil {
@@ -151,6 +299,7 @@ func NewAddSchedulerCommand() *cobra.Command {
 	c.AddCommand(NewEvictSlowStoreSchedulerCommand())
 	c.AddCommand(NewGrantHotRegionSchedulerCommand())
 	c.AddCommand(NewSplitBucketSchedulerCommand())
+	c.AddCommand(NewBalanceKeyspaceSchedulerCommand())
 	return c
 }
 
@@ -302,6 +451,16 @@ func NewBalanceRegionSchedulerCommand() *cobra.Command {
 	return c
 }
 
+// NewBalanceKeyspaceSchedulerCommand returns a command to add a balance-keyspace-scheduler.
+func NewBalanceKeyspaceSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "balance-keyspace-scheduler",
+		Short: "add a scheduler to balance the regions and leaders of each keyspace between stores",
+		Run:   addSchedulerCommandFunc,
+	}
+	return c
+}
+
 // NewBalanceHotRegionSchedulerCommand returns a command to add a balance-hot-region-scheduler.
 func NewBalanceHotRegionSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -467,6 +626,8 @@ func NewConfigSchedulerCommand() *cobra.Command {
 		newConfigGrantHotRegionCommand(),
 		newConfigBalanceLeaderCommand(),
 		newSplitBucketCommand(),
+		newConfigEvictSlowStoreCommand(),
+		newConfigBalanceKeyspaceCommand(),
 	)
 	return c
 }
@@ -491,6 +652,46 @@ func newConfigBalanceLeaderCommand() *cobra.Command {
 	return c
 }
 
+func newConfigEvictSlowStoreCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "evict-slow-store-scheduler",
+		Short: "evict-slow-store-scheduler config",
+		Run:   listSchedulerConfigCommandFunc,
+	}
+
+	c.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "show the config item and the evicted stores",
+		Run:   listSchedulerConfigCommandFunc,
+	}, &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "set the config item, e.g. max-evicted-stores, recover-duration",
+		Run:   func(cmd *cobra.Command, args []string) { postSchedulerConfigCommandFunc(cmd, c.Name(), args) },
+	})
+
+	return c
+}
+
+func newConfigBalanceKeyspaceCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "balance-keyspace-scheduler",
+		Short: "balance-keyspace-scheduler config",
+		Run:   listSchedulerConfigCommandFunc,
+	}
+
+	c.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "show the config item",
+		Run:   listSchedulerConfigCommandFunc,
+	}, &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "set the config item, e.g. include-keyspaces 1,2, exclude-keyspaces 3, keyspace-priorities 1:10,2:5, tolerance-ratio 0.1",
+		Run:   func(cmd *cobra.Command, args []string) { postSchedulerConfigCommandFunc(cmd, c.Name(), args) },
+	})
+
+	return c
+}
+
 func newSplitBucketCommand() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "split-bucket-scheduler",
@@ -680,7 +881,7 @@ func postSchedulerConfigCommandFunc(cmd *cobra.Command, schedulerName string, ar
 	if err != nil {
 		val = value
 	}
-	if schedulerName == "balance-hot-region-scheduler" && (key == "read-priorities" || key == "write-leader-priorities" || key == "write-peer-priorities") {
+	if schedulerName == "balance-hot-region-scheduler" && (key == "read-priorities" || key == "write-leader-priorities" || key == "write-peer-priorities" || key == "tiflash-write-peer-priorities") {
 		priorities := make([]string, 0)
 		prioritiesMap := make(map[string]struct{})
 		for _, priority := range strings.Split(value, ",") {
@@ -691,8 +892,8 @@ func postSchedulerConfigCommandFunc(cmd *cobra.Command, schedulerName string, ar
 					statistics.KeyPriority))
 				return
 			}
-			if priority == statistics.QueryPriority && key == "write-peer-priorities" {
-				cmd.Println("query is not allowed to be set in priorities for write-peer-priorities")
+			if priority == statistics.QueryPriority && (key == "write-peer-priorities" || key == "tiflash-write-peer-priorities") {
+				cmd.Println("query is not allowed to be set in priorities for " + key)
 				return
 			}
 			priorities = append(priorities, priority)
@@ -707,12 +908,57 @@ func postSchedulerConfigCommandFunc(cmd *cobra.Command, schedulerName string, ar
 			cmd.Println("priorities shouldn't be repeated")
 			return
 		}
+	} else if schedulerName == "balance-keyspace-scheduler" && (key == "include-keyspaces" || key == "exclude-keyspaces" || key == "keyspace-priorities") {
+		v, err := parseKeyspaceConfigValue(key, value)
+		if err != nil {
+			cmd.Println(err)
+			return
+		}
+		input[key] = v
 	} else {
 		input[key] = val
 	}
 	postJSON(cmd, path.Join(schedulerConfigPrefix, schedulerName, "config"), input)
 }
 
+// parseKeyspaceConfigValue parses the keyspace IDs like "1,2" or the keyspace priorities like "1:10,2:5".
+// An empty value means clearing the config item.
+func parseKeyspaceConfigValue(key, value string) (interface{}, error) {
+	ids := make([]uint32, 0)
+	priorities := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if key != "keyspace-priorities" {
+			id, err := strconv.ParseUint(item, 10, 32)
+			if err != nil {
+				return nil, errors.Errorf("invalid keyspace id %s", item)
+			}
+			ids = append(ids, uint32(id))
+			continue
+		}
+		kv := strings.Split(item, ":")
+		if len(kv) != 2 {
+			return nil, errors.Errorf("invalid keyspace priority %s, it should be like <keyspace-id>:<priority>", item)
+		}
+		id, err := strconv.ParseUint(kv[0], 10, 32)
+		if err != nil {
+			return nil, errors.Errorf("invalid keyspace id %s", kv[0])
+		}
+		priority, err := strconv.Atoi(kv[1])
+		if err != nil {
+			return nil, errors.Errorf("invalid priority %s", kv[1])
+		}
+		priorities[strconv.FormatUint(id, 10)] = priority
+	}
+	if key == "keyspace-priorities" {
+		return priorities, nil
+	}
+	return ids, nil
+}
+
 func deleteStoreFromSchedulerConfig(cmd *cobra.Command, schedulerName string, args []string) {
 	if len(args) != 1 {
 		cmd.Println(cmd.Usage())
@@ -772,12 +1018,19 @@ func setShuffleRegionSchedulerRolesCommandFunc(cmd *cobra.Command, args []string
 func NewDescribeSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "describe",
-		Short: "describe a scheduler",
+		Short: "describe a scheduler or checker, and explain why no operator is produced for each store",
 	}
 	c.AddCommand(
 		newDescribeBalanceRegionCommand(),
 		newDescribeBalanceLeaderCommand(),
 	)
+	for _, name := range []string{"hot-region-scheduler", "evict-leader-scheduler", "grant-leader-scheduler", "rule-checker", "merge-checker"} {
+		c.AddCommand(&cobra.Command{
+			Use:   name,
+			Short: "describe the " + name,
+			Run:   describeSchedulerCommandFunc,
+		})
+	}
 	return c
 }
 
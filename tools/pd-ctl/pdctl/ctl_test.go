@@ -15,7 +15,10 @@
 package pdctl
 
 import (
+	"bytes"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -92,3 +95,27 @@ func TestReadStdin(t *testing.T) {
 		}
 	}
 }
+
+func TestOperatorHistoryWithPDAddr(t *testing.T) {
+	re := require.New(t)
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	execute := func(args ...string) {
+		rootCmd := GetRootCmd()
+		rootCmd.SetOutput(new(bytes.Buffer))
+		rootCmd.SetArgs(append([]string{"-u", server.URL}, args...))
+		re.NoError(rootCmd.Execute())
+	}
+	// The PD address flag does not make it query the persisted history.
+	execute("operator", "history", "100")
+	execute("operator", "history", "100", "--region=3")
+	re.Equal([]string{
+		"/pd/api/v1/operators/records?from=100",
+		"/pd/api/v1/operators/history?from=100&region_id=3",
+	}, requests)
+}